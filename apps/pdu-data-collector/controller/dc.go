@@ -52,6 +52,43 @@ func CollectDCMetrics(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "PDU 資料已接收"})
 }
 
+// @Summary  接收 InfluxDB line protocol 數據 (相容 InfluxDB v1 /write，支援 gzip)
+// @Tags     collect
+// @Accept   plain
+// @Param    precision query string false "時間精度" Enums(n, ns, u, us, ms, s, m, h)
+// @Success  204
+// @Failure  400 {object} map[string]string
+// @Failure  503 {object} map[string]string
+// @Router   /dc/write [post]
+func WriteDCLineProtocol(c *gin.Context) {
+	writeLineProtocol(c, false, dc.AddPoints)
+}
+
+// @Summary  接收 InfluxDB line protocol 數據 (相容 InfluxDB v2 /api/v2/write，支援 gzip)
+// @Tags     collect
+// @Accept   plain
+// @Param    org query string false "org (不使用)"
+// @Param    bucket query string true "bucket (需帶入，數據與 /dc/metrics 相同寫入 Raw bucket)"
+// @Param    precision query string false "時間精度" Enums(ns, us, ms, s)
+// @Success  204
+// @Failure  400 {object} map[string]string
+// @Failure  503 {object} map[string]string
+// @Router   /dc/api/v2/write [post]
+func WriteDCInfluxV2(c *gin.Context) {
+	writeLineProtocol(c, true, dc.AddPoints)
+}
+
+// @Summary  接收 Prometheus remote-write 數據 (snappy 壓縮的 protobuf)
+// @Tags     collect
+// @Accept   application/x-protobuf
+// @Success  204
+// @Failure  400 {object} map[string]string
+// @Failure  503 {object} map[string]string
+// @Router   /dc/prom/write [post]
+func WriteDCRemoteWrite(c *gin.Context) {
+	writeRemoteWrite(c, dc.AddPoints)
+}
+
 // @Summary  取得 PDU 或 Env 資料
 // @Tags     dc
 // @Accept   json
@@ -5,12 +5,20 @@ import (
 	"bimap-zbox/models"
 	"bimap-zbox/services/factory"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// PDUProfileTestResult 單一數據點套用 PDU 設定的結果
+type PDUProfileTestResult struct {
+	PDUKey  string         `json:"pdu_key"`
+	Model   string         `json:"model"`
+	Profile string         `json:"profile"`
+	Points  []models.Point `json:"points"`
+	Error   string         `json:"error,omitempty"`
+}
+
 // @Summary  接收 telegraf 發送的數據
 // @Tags     collect
 // @Accept   json
@@ -24,42 +32,105 @@ func CollectFactoryMetrics(c *gin.Context) {
 		return
 	}
 
-	points := data_list.Metrics
-	var formattedPoints []models.Point
+	// 寫入轉送佇列，失敗時回應 503 由 telegraf 重送
+	if err := factory.IngestPoints(data_list.Metrics); err != nil {
+		global.Logger.Error("添加數據到批次失敗", zap.Error(err))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "PDU 資料已接收"})
+}
+
+// @Summary  接收 InfluxDB line protocol 數據 (相容 InfluxDB v1 /write，支援 gzip)
+// @Tags     collect
+// @Accept   plain
+// @Param    precision query string false "時間精度" Enums(n, ns, u, us, ms, s, m, h)
+// @Success  204
+// @Failure  400 {object} map[string]string
+// @Failure  503 {object} map[string]string
+// @Router   /factory/write [post]
+func WriteFactoryLineProtocol(c *gin.Context) {
+	writeLineProtocol(c, false, factory.IngestPoints)
+}
 
-	// 處理每個數據點
-	for _, point := range points {
-		modelName := point.Tags["model"]
-		pduKey := point.Tags["pdu_key"]
-		deltaList := []string{"PDUE428", "PDU1315", "PDU4425"}
+// @Summary  接收 InfluxDB line protocol 數據 (相容 InfluxDB v2 /api/v2/write，支援 gzip)
+// @Tags     collect
+// @Accept   plain
+// @Param    org query string false "org (不使用)"
+// @Param    bucket query string true "bucket (需帶入，數據與 /factory/metrics 相同依 PDU 設定檔轉換後轉送 DC)"
+// @Param    precision query string false "時間精度" Enums(ns, us, ms, s)
+// @Success  204
+// @Failure  400 {object} map[string]string
+// @Failure  503 {object} map[string]string
+// @Router   /factory/api/v2/write [post]
+func WriteFactoryInfluxV2(c *gin.Context) {
+	writeLineProtocol(c, true, factory.IngestPoints)
+}
 
-		match := false
+// @Summary  接收 Prometheus remote-write 數據 (snappy 壓縮的 protobuf)
+// @Tags     collect
+// @Accept   application/x-protobuf
+// @Success  204
+// @Failure  400 {object} map[string]string
+// @Failure  503 {object} map[string]string
+// @Router   /factory/prom/write [post]
+func WriteFactoryRemoteWrite(c *gin.Context) {
+	writeRemoteWrite(c, factory.IngestPoints)
+}
 
-		for _, delta := range deltaList {
-			if strings.Contains(modelName, delta) {
-				p, err := factory.FormatDeltaPDU(pduKey, point)
-				if err != nil {
-					global.Logger.Error("PDU 格式化失敗", zap.Error(err))
-				} else {
-					formattedPoints = append(formattedPoints, p...)
-				}
-				match = true
-				break
-			}
-		}
+// @Summary  以 PDU 設定檔轉換擷取的範例數據 (不寫入)
+// @Tags     collect
+// @Accept   json
+// @Produce  json
+// @Success  200 {array} PDUProfileTestResult
+// @Router   /factory/pdu-profile/test [post]
+func TestPDUProfile(c *gin.Context) {
+	var data_list models.MetricsData
+	if err := c.BindJSON(&data_list); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	factory.ReloadPDUProfiles()
 
-		if !match {
-			global.Logger.Error(
-				"PDU 型號未匹配",
-				zap.Any(global.Logs.MatchTag.Name, global.Logs.MatchTag),
-			)
+	results := make([]PDUProfileTestResult, 0, len(data_list.Metrics))
+	for _, point := range data_list.Metrics {
+		result := PDUProfileTestResult{
+			PDUKey: point.Tags["pdu_key"],
+			Model:  point.Tags["model"],
 		}
+		profile, p, err := factory.FormatPDUPoint(point.Tags["pdu_key"], point)
+		result.Profile = profile
+		result.Points = p
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
 	}
 
-	// 添加到批次處理
-	if err := factory.AddPoints(formattedPoints); err != nil {
-		global.Logger.Error("添加數據到批次失敗", zap.Error(err))
+	c.JSON(http.StatusOK, results)
+}
+
+// @Summary  轉送 DC 的佇列狀態 (佇列深度、延遲與轉送結果)
+// @Tags     collect
+// @Produce  json
+// @Success  200 {object} factory.DCQueueStatus
+// @Router   /factory/queue [get]
+func GetFactoryQueueStatus(c *gin.Context) {
+	status, err := factory.GetDCQueueStatus()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
 	}
+	c.JSON(http.StatusOK, status)
+}
 
-	c.JSON(http.StatusOK, gin.H{"message": "PDU 資料已接收"})
+// @Summary  內建 SNMP 輪詢的狀態 (最近一次輪詢各設備的結果)
+// @Tags     collect
+// @Produce  json
+// @Success  200 {object} factory.SNMPStatus
+// @Router   /factory/snmp/status [get]
+func GetSNMPStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, factory.GetSNMPStatus())
 }
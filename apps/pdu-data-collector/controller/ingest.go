@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"bimap-zbox/global"
+	"bimap-zbox/models"
+	"bimap-zbox/services/ingest"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// line protocol 與 remote-write 的共用處理，轉換後的 models.Point 交由 sink 寫入
+// (廠區：PDU 格式化後寫入轉送佇列；DC：寫入 InfluxDB 批次)，sink 失敗時回應 503 由客戶端重送
+
+// writeLineProtocol 處理 InfluxDB v1 (/write) 與 v2 (/api/v2/write) 的寫入請求，成功時回應 204；
+// 格式錯誤的行不寫入，其餘資料照常寫入後回應 400 (與 InfluxDB 的 partial write 相同，客戶端不重送)
+func writeLineProtocol(c *gin.Context, v2 bool, sink func([]models.Point) error) {
+	if v2 && c.Query("bucket") == "" {
+		influxError(c, http.StatusBadRequest, v2, "缺少 bucket 參數")
+		return
+	}
+	unit, err := ingest.ParsePrecision(c.Query("precision"))
+	if err != nil {
+		influxError(c, http.StatusBadRequest, v2, err.Error())
+		return
+	}
+	body, err := ingest.ReadBody(c.Request)
+	if err != nil {
+		influxError(c, bodyErrorStatus(err), v2, err.Error())
+		return
+	}
+
+	points, parseErrs := ingest.ParseLineProtocol(body, unit, time.Now())
+	if err := sink(points); err != nil {
+		global.Logger.Error("line protocol 數據寫入失敗", zap.Int("points", len(points)), zap.Error(err))
+		influxError(c, http.StatusServiceUnavailable, v2, err.Error())
+		return
+	}
+	if len(parseErrs) > 0 {
+		global.Logger.Warn("line protocol 格式錯誤",
+			zap.Int("lines", len(parseErrs)),
+			zap.Int("points", len(points)),
+			zap.Error(parseErrs[0]))
+		influxError(c, http.StatusBadRequest, v2,
+			fmt.Sprintf("partial write: %d 行格式錯誤，%v", len(parseErrs), parseErrs[0]))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// writeRemoteWrite 處理 Prometheus remote-write 請求，成功時回應 204；
+// 格式錯誤回應 400 (Prometheus 不重送)，寫入失敗回應 503 (Prometheus 重送)
+func writeRemoteWrite(c *gin.Context, sink func([]models.Point) error) {
+	points, err := ingest.ReadRemoteWrite(c.Request)
+	if err != nil {
+		c.JSON(bodyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if err := sink(points); err != nil {
+		global.Logger.Error("remote-write 數據寫入失敗", zap.Int("points", len(points)), zap.Error(err))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func bodyErrorStatus(err error) int {
+	if errors.Is(err, ingest.ErrBodyTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// influxError 依 InfluxDB 版本回應錯誤格式：v1 為 {"error"}，v2 為 {"code", "message"}
+func influxError(c *gin.Context, status int, v2 bool, message string) {
+	if !v2 {
+		c.JSON(status, gin.H{"error": message})
+		return
+	}
+	code := "invalid"
+	switch status {
+	case http.StatusRequestEntityTooLarge:
+		code = "request too large"
+	case http.StatusServiceUnavailable:
+		code = "unavailable"
+	}
+	c.JSON(status, gin.H{"code": code, "message": message})
+}
@@ -0,0 +1,5 @@
+INSERT INTO `jobs` (`type`, `key`, `value`) VALUES
+	('FactoryPollSNMP', 'Enable', 'false'),
+	('FactoryPollSNMP', 'Name', 'PollSNMP'),
+	('FactoryPollSNMP', 'Description', '輪詢 SNMP PDU 數據'),
+	('FactoryPollSNMP', 'CronExpression', '*/30 * * * * *')
\ No newline at end of file
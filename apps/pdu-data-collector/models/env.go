@@ -18,10 +18,12 @@ type Envs struct {
 		SettingConfPath string `json:"SettingConfPath"`
 		BatchSize       string `json:"BatchSize"`
 		MaxWaitTime     string `json:"MaxWaitTime"`
+		MaxPending      string `json:"MaxPending"`
 	} `json:"FactoryConfig"`
 	DCConfig struct {
 		BatchSize   string `json:"BatchSize"`
 		MaxWaitTime string `json:"MaxWaitTime"`
+		MaxPending  string `json:"MaxPending"`
 	} `json:"DCConfig"`
 	InfluxDBBucket struct {
 		Raw       string `json:"Raw"`
@@ -109,7 +111,9 @@ type GlobalConfig struct {
 		MaxSize int    `mapstructure:"maxsize"`
 		MaxAge  int    `mapstructure:"maxage"`
 	} `mapstructure:"log"`
-	HttpTimeout int `mapstructure:"http_timeout"`
+	HttpTimeout     int          `mapstructure:"http_timeout"`
+	ShutdownTimeout int          `mapstructure:"shutdown_timeout"`
+	Ingest          IngestConfig `mapstructure:"ingest"`
 }
 
 type FactoryConfig struct {
@@ -117,6 +121,9 @@ type FactoryConfig struct {
 	Port           string        `mapstructure:"port"`
 	DCEndpoint     string        `mapstructure:"dc_endpoint"`
 	DeviceScale    []DeviceScale `mapstructure:"device_scale"`
+	PDUProfileFile string        `mapstructure:"pdu_profile_file"`
+	WAL            WALConfig     `mapstructure:"wal"`
+	SNMP           SNMPConfig    `mapstructure:"snmp"`
 	InitGlobalData struct {
 		PDU InitData `mapstructure:"pdu"`
 		Env InitData `mapstructure:"env"`
@@ -125,6 +132,45 @@ type FactoryConfig struct {
 	} `mapstructure:"init_global_data"`
 }
 
+// IngestConfig line protocol / remote-write 接收設定
+type IngestConfig struct {
+	MaxBodySizeMB         int    `mapstructure:"max_body_size_mb"`       // 單一請求解壓縮後的大小上限
+	PrometheusMeasurement string `mapstructure:"prometheus_measurement"` // remote-write 數據的 measurement 名稱
+}
+
+// SNMPConfig 內建 SNMP 輪詢 (輪詢週期由 FactoryPollSNMP job 設定)
+type SNMPConfig struct {
+	Version        string       `mapstructure:"version"` // 2c / 3
+	Community      string       `mapstructure:"community"`
+	Port           uint16       `mapstructure:"port"`
+	Timeout        int          `mapstructure:"timeout"` // 單一請求逾時，毫秒
+	Retries        int          `mapstructure:"retries"`
+	MaxRepetitions uint32       `mapstructure:"max_repetitions"` // GETBULK 每次取得的筆數
+	MaxConcurrency int          `mapstructure:"max_concurrency"` // 同時輪詢的設備數
+	V3             SNMPv3Config `mapstructure:"v3"`
+}
+
+// SNMPv3Config SNMP v3 USM 認證
+type SNMPv3Config struct {
+	Username      string `mapstructure:"username"`
+	SecurityLevel string `mapstructure:"security_level"` // noAuthNoPriv / authNoPriv / authPriv
+	AuthProtocol  string `mapstructure:"auth_protocol"`  // MD5 / SHA / SHA224 / SHA256 / SHA384 / SHA512
+	AuthPassword  string `mapstructure:"auth_password"`
+	PrivProtocol  string `mapstructure:"priv_protocol"` // DES / AES / AES192 / AES256 / AES192C / AES256C
+	PrivPassword  string `mapstructure:"priv_password"`
+	ContextName   string `mapstructure:"context_name"`
+}
+
+// WALConfig 轉送 DC 的磁碟佇列
+type WALConfig struct {
+	Dir            string `mapstructure:"dir"`
+	SegmentSizeMB  int64  `mapstructure:"segment_size_mb"`
+	MaxSizeMB      int64  `mapstructure:"max_size_mb"`     // 超過時刪除最舊的資料，-1 表示不限制
+	Fsync          string `mapstructure:"fsync"`           // always / interval / none
+	FsyncInterval  int    `mapstructure:"fsync_interval"`  // 毫秒
+	ReplayInterval int    `mapstructure:"replay_interval"` // 積壓資料重送時每批間隔 (毫秒)
+}
+
 type InitData struct {
 	Name string `mapstructure:"name"`
 	File string `mapstructure:"file"`
@@ -18,6 +18,7 @@ type Jobs struct {
 	FactorySyncGlobalLog      JobDetail `json:"FactorySyncGlobalLog"`
 	FactoryRecoverDCData      JobDetail `json:"FactoryRecoverDCData"`
 	FactoryFlushDCData        JobDetail `json:"FactoryFlushDCData"`
+	FactoryPollSNMP           JobDetail `json:"FactoryPollSNMP"`
 	DCSyncGlobalTag           JobDetail `json:"DCSyncGlobalTag"`
 	DCAggregateInfluxDBHourly JobDetail `json:"DCAggregateInfluxDBHourly"`
 	DCAggregateInfluxDBDaily  JobDetail `json:"DCAggregateInfluxDBDaily"`
@@ -0,0 +1,38 @@
+package models
+
+// PDUProfiles PDU 廠牌型號設定檔 (pdu_profiles.yml)
+type PDUProfiles struct {
+	Profiles []PDUProfile `yaml:"profiles"`
+}
+
+// PDUProfile 單一廠牌 / 型號的資料格式
+//
+// 原始欄位先依 Fields 轉換為標準欄位，標準欄位名稱為 {metric}_{phase}、{metric}_{branch} 或 total_{metric}，
+// metric 為 current / voltage / watt / energy，例如 current_L1、watt_L2-3、total_energy
+type PDUProfile struct {
+	Name         string             `yaml:"name"`
+	Manufacturer string             `yaml:"manufacturer"`  // 比對 manufacturer 標籤 (不分大小寫)，未設定時不比對
+	Models       []string           `yaml:"models"`        // model 標籤包含任一字串即符合，未設定時只比對廠牌
+	Fields       map[string]string  `yaml:"fields"`        // 原始欄位 -> 標準欄位
+	DropUnmapped bool               `yaml:"drop_unmapped"` // 捨棄未在 Fields 中的原始欄位
+	Phases       []string           `yaml:"phases"`        // 相位名稱，預設 L1 / L2 / L3
+	BranchPhase  map[string]string  `yaml:"branch_phase"`  // 迴路所屬相位，未設定時取迴路名稱 "-" 之前 (L1-2 -> L1)
+	Aggregate    []string           `yaml:"aggregate"`     // 缺少相位欄位時由迴路加總的 metric，預設 current / watt / energy
+	Totals       []string           `yaml:"totals"`        // 缺少總和欄位時由相位加總的 metric，預設 current / watt / energy
+	Scale        map[string]float64 `yaml:"scale"`         // 各 metric 原始值除以 scale (例如 current: 100 表示 0.01 A)
+	SNMP         PDUSNMP            `yaml:"snmp"`          // 內建 SNMP 輪詢的 OID 對應，未設定時不輪詢此型號
+}
+
+// PDUSNMP SNMP 輪詢的 OID 對應，輪詢結果以原始欄位名稱輸出，再依 Fields 轉換為標準欄位
+type PDUSNMP struct {
+	Measurement string    `yaml:"measurement"` // 預設 pdu
+	Fields      []SNMPOID `yaml:"fields"`      // 數值欄位
+	Tags        []SNMPOID `yaml:"tags"`        // 字串標籤 (例如序號、韌體版本)
+}
+
+// SNMPOID 單一 OID，Table 為 true 時以 walk 讀取整個欄位，名稱為 {name}_{index} (例如 rPDU2PhaseStatusCurrent_1)
+type SNMPOID struct {
+	Name  string `yaml:"name"`
+	OID   string `yaml:"oid"`
+	Table bool   `yaml:"table"`
+}
@@ -0,0 +1,245 @@
+// Package batcher 累積資料並分批送出
+//
+// 累積數量達到 Size，或最舊的資料等待超過 MaxAge 時送出一批 (背景計時器檢查)，
+// 同一時間只有一批在送出中，批次依加入順序送出。緩衝 (含送出中的批次) 上限為 MaxPending，
+// 已滿時 Add 依 Block 設定等待或回傳 ErrFull。Close 停止接收並送出剩餘的資料。
+package batcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrFull 緩衝已滿
+	ErrFull = errors.New("batcher: buffer full")
+	// ErrClosed 已關閉
+	ErrClosed = errors.New("batcher: closed")
+)
+
+const (
+	minTick = 100 * time.Millisecond
+	maxTick = time.Second
+)
+
+// Options 批次設定
+type Options[T any] struct {
+	Size       int           // 累積到此數量時送出
+	MaxAge     time.Duration // 最舊的資料等待超過此時間時送出，0 表示只依數量送出
+	MaxPending int           // 緩衝上限 (含送出中的批次)，預設 Size 的 10 倍
+	Block      bool          // 緩衝已滿時 Add 等待，否則回傳 ErrFull
+	SizeOf     func(T) int   // 單筆資料計入的數量，預設為 1
+}
+
+// FlushFunc 送出一批資料；ctx 在 Close 超過期限時取消，送出失敗需重試時應以 ctx 中止
+type FlushFunc[T any] func(ctx context.Context, items []T)
+
+// Batcher 依數量與等待時間分批送出
+type Batcher[T any] struct {
+	opts  Options[T]
+	flush FlushFunc[T]
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	items    []T
+	sizes    []int
+	added    []time.Time
+	buffered int // 緩衝中的數量
+	inflight int // 送出中的數量
+	closed   bool
+
+	kick   chan struct{}
+	force  chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// New 建立並啟動 Batcher
+func New[T any](opts Options[T], flush FlushFunc[T]) *Batcher[T] {
+	if opts.Size <= 0 {
+		opts.Size = 1
+	}
+	if opts.MaxPending <= 0 {
+		opts.MaxPending = opts.Size * 10
+	}
+	if opts.MaxPending < opts.Size {
+		opts.MaxPending = opts.Size
+	}
+	if opts.SizeOf == nil {
+		opts.SizeOf = func(T) int { return 1 }
+	}
+
+	b := &Batcher[T]{
+		opts:  opts,
+		flush: flush,
+		kick:  make(chan struct{}, 1),
+		force: make(chan struct{}, 1),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	b.cond = sync.NewCond(&b.mu)
+	b.ctx, b.cancel = context.WithCancel(context.Background())
+	go b.run()
+	return b
+}
+
+// Add 加入資料，數量達到 Size 時通知送出
+func (b *Batcher[T]) Add(items ...T) error {
+	if len(items) == 0 {
+		return nil
+	}
+	sizes := make([]int, len(items))
+	total := 0
+	for i, item := range items {
+		sizes[i] = b.opts.SizeOf(item)
+		total += sizes[i]
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for {
+		if b.closed {
+			return ErrClosed
+		}
+		// 緩衝為空時允許超過上限的單次加入，避免永遠無法加入
+		used := b.buffered + b.inflight
+		if used == 0 || used+total <= b.opts.MaxPending {
+			break
+		}
+		if !b.opts.Block {
+			return ErrFull
+		}
+		b.cond.Wait()
+	}
+
+	now := time.Now()
+	b.items = append(b.items, items...)
+	b.sizes = append(b.sizes, sizes...)
+	for range items {
+		b.added = append(b.added, now)
+	}
+	b.buffered += total
+	if b.buffered >= b.opts.Size {
+		notify(b.kick)
+	}
+	return nil
+}
+
+// Flush 立即送出緩衝中的資料 (不等待數量或時間)
+func (b *Batcher[T]) Flush() {
+	notify(b.force)
+}
+
+// Len 緩衝中與送出中的數量
+func (b *Batcher[T]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buffered + b.inflight
+}
+
+// Close 停止接收資料並送出剩餘的資料；ctx 到期時取消送出中的批次並回傳 ctx.Err()，未送出的資料捨棄
+func (b *Batcher[T]) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.stop)
+		b.cond.Broadcast()
+	}
+	b.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
+
+func (b *Batcher[T]) run() {
+	defer close(b.done)
+
+	tick := maxTick
+	if b.opts.MaxAge > 0 {
+		tick = min(max(b.opts.MaxAge/4, minTick), maxTick)
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		force := false
+		select {
+		case <-b.kick:
+		case <-ticker.C:
+		case <-b.force:
+			force = true
+		case <-b.stop:
+			// 送出剩餘的資料
+			for b.ctx.Err() == nil {
+				batch, size := b.take(true)
+				if len(batch) == 0 {
+					return
+				}
+				b.send(batch, size)
+			}
+			return
+		}
+
+		for {
+			batch, size := b.take(force)
+			if len(batch) == 0 {
+				break
+			}
+			b.send(batch, size)
+		}
+	}
+}
+
+// take 達到送出條件時取出一批 (至少一筆，不超過 Size)
+func (b *Batcher[T]) take(force bool) ([]T, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.items) == 0 {
+		return nil, 0
+	}
+	expired := b.opts.MaxAge > 0 && time.Since(b.added[0]) >= b.opts.MaxAge
+	if !force && !expired && b.buffered < b.opts.Size {
+		return nil, 0
+	}
+
+	n, size := 0, 0
+	for n < len(b.items) && (n == 0 || size+b.sizes[n] <= b.opts.Size) {
+		size += b.sizes[n]
+		n++
+	}
+	batch := make([]T, n)
+	copy(batch, b.items[:n])
+
+	b.items = b.items[n:]
+	b.sizes = b.sizes[n:]
+	b.added = b.added[n:]
+	b.buffered -= size
+	b.inflight += size
+	return batch, size
+}
+
+func (b *Batcher[T]) send(batch []T, size int) {
+	b.flush(b.ctx, batch)
+
+	b.mu.Lock()
+	b.inflight -= size
+	b.cond.Broadcast()
+	b.mu.Unlock()
+}
+
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -1,72 +1,54 @@
 package dc
 
 import (
+	"context"
 	"strconv"
 	"sync"
 	"time"
 
 	"bimap-zbox/global"
 	"bimap-zbox/models"
+	"bimap-zbox/services"
+	"bimap-zbox/services/batcher"
 )
 
 var (
-	dcPointBatch    []models.Point
-	dcPointMutex    sync.Mutex
-	dcLastBatchTime time.Time
+	dcBatcher     *batcher.Batcher[models.Point]
+	dcBatcherOnce sync.Once
 )
 
-func init() {
-	dcLastBatchTime = time.Now() // 初始化時間
+// pointBatcher 依 DCConfig 的 BatchSize / MaxWaitTime / MaxPending 建立批次，寫入 InfluxDB
+// 批次設定在第一次接收數據時讀取，之後的變更需重新啟動
+func pointBatcher() *batcher.Batcher[models.Point] {
+	dcBatcherOnce.Do(func() {
+		batchSize, _ := strconv.Atoi(global.Envs.DCConfig.BatchSize)
+		maxWaitTime, _ := strconv.Atoi(global.Envs.DCConfig.MaxWaitTime)
+		maxPending, _ := strconv.Atoi(global.Envs.DCConfig.MaxPending)
+
+		dcBatcher = batcher.New(batcher.Options[models.Point]{
+			Size:       batchSize,
+			MaxAge:     time.Duration(maxWaitTime) * time.Second,
+			MaxPending: maxPending,
+		}, func(ctx context.Context, points []models.Point) {
+			PointsToInfluxDB(points)
+		})
+
+		// 結束時寫入批次中的資料
+		services.RegisterShutdown("dc-influxdb-batch", dcBatcher.Close)
+	})
+	return dcBatcher
 }
 
-// AddPoints 添加新的數據點到批次中
+// AddPoints 添加新的數據點到批次中，批次已滿時回傳 batcher.ErrFull
 func AddPoints(points []models.Point) error {
 	if len(points) == 0 {
 		return nil
 	}
-
-	dcPointMutex.Lock()
-	defer dcPointMutex.Unlock()
-
-	dcPointBatch = append(dcPointBatch, points...)
-
-	// 判斷是否需要處理批次
-	batchSize, _ := strconv.Atoi(global.Envs.DCConfig.BatchSize)
-	if len(dcPointBatch) >= batchSize {
-		processDCBatch()
-	}
-
-	return nil
-}
-
-// processDCBatch 處理批次資料並寫入 InfluxDB
-// 此函數已在 AddPoints 中上鎖，不需要重複加鎖
-func processDCBatch() {
-	if len(dcPointBatch) == 0 {
-		return
-	}
-
-	pointsToProcess := make([]models.Point, len(dcPointBatch))
-	copy(pointsToProcess, dcPointBatch)
-
-	// 先嘗試寫入資料
-	PointsToInfluxDB(pointsToProcess)
-
-	// 寫入成功後才清空批次
-	dcPointBatch = []models.Point{}
-	dcLastBatchTime = time.Now()
-
+	return pointBatcher().Add(points...)
 }
 
-// ProcessPendingBatch 供 crontab 調用的批次處理函數
+// FlushInfluxDBBatch 供 crontab 調用，立即寫入批次中的資料 (批次已依 MaxWaitTime 自動寫入)
 func FlushInfluxDBBatch() error {
-	dcPointMutex.Lock()
-	defer dcPointMutex.Unlock()
-
-	maxWaitTime, _ := strconv.Atoi(global.Envs.DCConfig.MaxWaitTime)
-	if len(dcPointBatch) > 0 && time.Since(dcLastBatchTime) >= time.Duration(maxWaitTime)*time.Second {
-		processDCBatch()
-
-	}
+	pointBatcher().Flush()
 	return nil
 }
@@ -3,64 +3,144 @@ package factory
 import (
 	"bimap-zbox/global"
 	"bimap-zbox/models"
+	"bimap-zbox/services/wal"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strconv"
 	"sync"
 	"time"
+
+	"go.uber.org/zap"
+)
+
+// 轉送 DC 的資料先寫入磁碟佇列 (WAL)，再由背景轉送依序送出，確認 DC 收到後才刪除；
+// 與 DC 斷線期間資料保留在佇列中，恢復後依寫入順序重送
+
+const (
+	defaultQueueDir       = "./fail_data/dc_wal"
+	defaultSegmentSizeMB  = 64
+	defaultQueueMaxSizeMB = 10240
+	defaultFsyncInterval  = 1000
 )
 
 var (
-	factoryPointBatch    []models.Point // 批次數據
-	factoryPointMutex    sync.Mutex     // 保護批次數據的鎖
-	factoryLastBatchTime time.Time      // 上次批次處理時間
+	dcQueue     *wal.WAL
+	dcQueueErr  error
+	dcQueueOnce sync.Once
 )
 
-func init() {
-	factoryLastBatchTime = time.Now()
+// StartDCForwarder 開啟佇列並啟動背景轉送
+func StartDCForwarder() error {
+	_, err := openDCQueue()
+	return err
 }
 
-// AddPoints 添加新的數據點到批次中
+func openDCQueue() (*wal.WAL, error) {
+	dcQueueOnce.Do(func() {
+		cfg := global.EnvConfig.Factory.WAL
+		dir := cfg.Dir
+		if dir == "" {
+			dir = defaultQueueDir
+		}
+		segmentSize := cfg.SegmentSizeMB
+		if segmentSize <= 0 {
+			segmentSize = defaultSegmentSizeMB
+		}
+		maxSize := cfg.MaxSizeMB
+		if maxSize == 0 {
+			maxSize = defaultQueueMaxSizeMB
+		}
+		fsyncInterval := cfg.FsyncInterval
+		if fsyncInterval <= 0 {
+			fsyncInterval = defaultFsyncInterval
+		}
+
+		dcQueue, dcQueueErr = wal.Open(wal.Options{
+			Dir:          dir,
+			SegmentSize:  segmentSize << 20,
+			MaxSize:      max(maxSize, 0) << 20,
+			Sync:         wal.SyncPolicy(cfg.Fsync),
+			SyncInterval: time.Duration(fsyncInterval) * time.Millisecond,
+		})
+		if dcQueueErr != nil {
+			global.Logger.Error("開啟 DC 轉送佇列失敗", zap.String("dir", dir), zap.Error(dcQueueErr))
+			return
+		}
+
+		stats := dcQueue.Stats()
+		global.Logger.Info("開啟 DC 轉送佇列",
+			zap.String("dir", dir),
+			zap.Uint64("depth", stats.Depth),
+			zap.Int64("bytes", stats.Bytes))
+		go forwardDCQueue(dcQueue)
+	})
+	return dcQueue, dcQueueErr
+}
+
+// AddPoints 將數據寫入轉送佇列，寫入失敗時回傳錯誤 (由呼叫端要求重送)
 func AddPoints(points []models.Point) error {
 	if len(points) == 0 {
 		return nil
 	}
 
-	factoryPointMutex.Lock()
-	defer factoryPointMutex.Unlock()
+	queue, err := openDCQueue()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(points)
+	if err != nil {
+		return fmt.Errorf("PDU 轉換 JSON 失敗: %v", err)
+	}
+	if _, err := queue.Append(data); err != nil {
+		return fmt.Errorf("寫入 DC 轉送佇列失敗: %v", err)
+	}
+	return nil
+}
 
-	factoryPointBatch = append(factoryPointBatch, points...)
+// IngestPoints 依 PDU 設定檔轉換數據後寫入轉送佇列，型號未匹配或轉換失敗的數據記錄後略過，
+// 只有寫入佇列失敗時回傳錯誤
+func IngestPoints(points []models.Point) error {
+	// PDU 設定檔有更新時重新載入
+	ReloadPDUProfiles()
 
-	// 判斷是否需要處理批次
-	batchSize, _ := strconv.Atoi(global.Envs.FactoryConfig.BatchSize)
-	if len(factoryPointBatch) >= batchSize {
-		processFactoryBatch()
+	var formattedPoints []models.Point
+	for _, point := range points {
+		_, p, err := FormatPDUPoint(point.Tags["pdu_key"], point)
+		if errors.Is(err, ErrPDUProfileNotMatched) {
+			global.Logger.Error(
+				"PDU 型號未匹配",
+				zap.String("model", point.Tags["model"]),
+				zap.Any(global.Logs.MatchTag.Name, global.Logs.MatchTag),
+			)
+			continue
+		}
+		if err != nil {
+			global.Logger.Error("PDU 格式化失敗", zap.Error(err))
+			continue
+		}
+		formattedPoints = append(formattedPoints, p...)
 	}
+	return AddPoints(formattedPoints)
+}
 
+// FlushDCBatch 供 crontab 調用，立即送出批次中的資料 (批次已依 MaxWaitTime 自動送出)
+func FlushDCBatch() error {
+	if b := dcBatcher.Load(); b != nil {
+		b.Flush()
+	}
 	return nil
 }
 
-// processFactoryBatch 處理批次資料
-func processFactoryBatch() {
-	if len(factoryPointBatch) == 0 {
-		return
+func dcBatchSize() int {
+	batchSize, _ := strconv.Atoi(global.Envs.FactoryConfig.BatchSize)
+	if batchSize <= 0 {
+		batchSize = 5000
 	}
-
-	formattedPoints := make([]models.Point, len(factoryPointBatch))
-	copy(formattedPoints, factoryPointBatch)
-
-	factoryPointBatch = nil
-	factoryLastBatchTime = time.Now()
-
-	FactoryMetricsToDC(formattedPoints)
+	return batchSize
 }
 
-// ProcessPendingBatch 供 crontab 調用的批次處理函數
-func FlushDCBatch() error {
-	factoryPointMutex.Lock()
-	defer factoryPointMutex.Unlock()
-
+func dcMaxWaitTime() time.Duration {
 	maxWaitTime, _ := strconv.Atoi(global.Envs.FactoryConfig.MaxWaitTime)
-	if len(factoryPointBatch) > 0 && time.Since(factoryLastBatchTime) >= time.Duration(maxWaitTime)*time.Second {
-		processFactoryBatch()
-	}
-	return nil
+	return time.Duration(maxWaitTime) * time.Second
 }
@@ -17,6 +17,11 @@ func RunFactoryCrontab() {
 	services.IfNotExistCreateDir(global.Envs.FactoryRecoveryDir.DCMetrics)
 	services.IfNotExistCreateDir(global.Envs.FactoryRecoveryDir.DCEvents)
 
+	// 開啟轉送佇列並啟動背景轉送 (上次未送出的資料會依序重送)
+	if err := StartDCForwarder(); err != nil {
+		global.Logger.Error("啟動 DC 轉送失敗", zap.Error(err))
+	}
+
 	// 更新 PDU 名單
 	job := global.Jobs.FactorySyncGlobalPDU
 	if job.Enable != "false" && job.CronExpression != "" {
@@ -52,6 +57,34 @@ func RunFactoryCrontab() {
 		global.Logger.Warn(fmt.Sprintf("Cron job [%s] 未啟動，因為沒有設置 crontab 週期或未啟用", job.Name),
 			zap.Any(global.Logs.LoadCrontab.Name, global.Logs.LoadCrontab))
 	}
+
+	// 輪詢 SNMP 設備
+	job = global.Jobs.FactoryPollSNMP
+	if job.Enable != "false" && job.CronExpression != "" {
+		runPollSNMP(job)
+	} else {
+		global.Logger.Warn(fmt.Sprintf("Cron job [%s] 未啟動，因為沒有設置 crontab 週期或未啟用", job.Name),
+			zap.Any(global.Logs.LoadCrontab.Name, global.Logs.LoadCrontab))
+	}
+}
+
+func runPollSNMP(job models.JobDetail) {
+
+	cronID, err := global.Crontab.AddJob(
+		strings.Join([]string{global.Envs.GlobalConfig.CrontabTimezone, job.CronExpression}, " "),
+		cron.NewChain(cron.SkipIfStillRunning(cron.DefaultLogger)).
+			Then(cron.FuncJob(func() {
+				PollSNMPDevices()
+			})))
+
+	if err != nil {
+		global.Logger.Error(fmt.Sprintf("Cron job [%s] 設置失敗: %v", job.Name, err),
+			zap.Any(global.Logs.LoadCrontab.Name, global.Logs.LoadCrontab))
+	} else {
+		global.Logger.Info(fmt.Sprintf("Cron job [%s] 設置成功, CronID [%v]", job.Name, cronID),
+			zap.Any(global.Logs.LoadCrontab.Name, global.Logs.LoadCrontab))
+	}
+	global.Crontab.Start()
 }
 
 func runFlushDCData(job models.JobDetail) {
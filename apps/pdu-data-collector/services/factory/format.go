@@ -4,126 +4,189 @@ import (
 	"bimap-zbox/global"
 	"bimap-zbox/models"
 	"bimap-zbox/services"
+	"errors"
 	"strings"
 
 	"golang.org/x/exp/slices"
 )
 
-func FormatDeltaPDU(pduKey string, data models.Point) ([]models.Point, error) {
+// ErrPDUProfileNotMatched 沒有符合的廠牌型號設定
+var ErrPDUProfileNotMatched = errors.New("PDU 型號未匹配")
+
+// FormatPDUPoint 依 model / manufacturer 標籤 (數據標籤優先於 PDU 名單) 選擇設定並轉換數據，回傳使用的設定名稱
+func FormatPDUPoint(pduKey string, data models.Point) (string, []models.Point, error) {
+	tags := make(map[string]string)
+	for key, value := range GetTagsByPduKey(pduKey) {
+		tags[key] = value
+	}
+	for key, value := range data.Tags {
+		tags[key] = value
+	}
+
+	profile, ok := MatchPDUProfile(tags)
+	if !ok {
+		return "", nil, ErrPDUProfileNotMatched
+	}
+	points, err := FormatPDU(profile, pduKey, data)
+	return profile.Name, points, err
+}
+
+// FormatPDU 依廠牌型號設定將 PDU 數據轉換為標準格式：
+// 欄位對應與單位換算後，缺少的相位欄位由迴路加總、缺少的總和欄位由相位加總，
+// 每個欄位輸出為一個 Point，相位與迴路以標籤區分
+func FormatPDU(profile models.PDUProfile, pduKey string, data models.Point) ([]models.Point, error) {
 
-	newTags := make(map[string]string)
 	var res []models.Point
 	schema := global.Envs.PDUSchema
+	newTags := pduTags(pduKey, data)
 
-	// 獲取對應的標籤
-	allowTags := strings.Split(global.Envs.FactoryConfig.AllowTags, ",")
-	dbTags := GetTagsByPduKey(pduKey)
-	if dbTags == nil {
-		// 若無標籤，設定預設標籤
-		for _, tag := range allowTags {
-			newTags[tag] = "unknown"
-		}
-	} else {
-		for key, value := range dbTags {
-			data.Tags[key] = value
+	phases := profile.Phases
+	if len(phases) == 0 {
+		phases = defaultPhases
+	}
+	aggregate := profile.Aggregate
+	if aggregate == nil {
+		aggregate = defaultSumMetrics
+	}
+	totals := profile.Totals
+	if totals == nil {
+		totals = defaultSumMetrics
+	}
+
+	// 欄位對應與單位換算
+	fields := make(map[string]float64, len(data.Fields))
+	for key, value := range data.Fields {
+		if target, ok := profile.Fields[key]; ok {
+			key = target
+		} else if profile.DropUnmapped {
+			continue
 		}
-		// 過濾自定義標籤
-		for key := range data.Tags {
-			if slices.Contains(allowTags, key) {
-				newTags[key] = data.Tags[key]
+		if metric, _, ok := splitPDUField(key); ok {
+			if scale := profile.Scale[metric]; scale > 0 {
+				value = value / scale
 			}
 		}
+		fields[key] = value
 	}
 
-	// 檢查 current 是否需要補全
-	if _, exists := data.Fields["current_L1"]; !exists {
-		data.Fields["current_L1"] = data.Fields["current_L1-1"] + data.Fields["current_L1-2"] + data.Fields["current_L1-3"]
-		data.Fields["current_L2"] = data.Fields["current_L2-1"] + data.Fields["current_L2-2"] + data.Fields["current_L2-3"]
-		data.Fields["current_L3"] = data.Fields["current_L3-1"] + data.Fields["current_L3-2"] + data.Fields["current_L3-3"]
+	// 迴路所屬相位
+	branchPhase := func(branch string) string {
+		if phase, ok := profile.BranchPhase[branch]; ok {
+			return phase
+		}
+		if phase, _, found := strings.Cut(branch, "-"); found && slices.Contains(phases, phase) {
+			return phase
+		}
+		return ""
 	}
 
-	// 檢查 watt 是否需要補全
-	if _, exists := data.Fields["watt_L1"]; !exists {
-		data.Fields["watt_L1"] = data.Fields["watt_L1-1"] + data.Fields["watt_L1-2"] + data.Fields["watt_L1-3"]
-		data.Fields["watt_L2"] = data.Fields["watt_L2-1"] + data.Fields["watt_L2-2"] + data.Fields["watt_L2-3"]
-		data.Fields["watt_L3"] = data.Fields["watt_L3-1"] + data.Fields["watt_L3-2"] + data.Fields["watt_L3-3"]
+	// 檢查相位欄位是否需要由迴路補全
+	for _, metric := range aggregate {
+		sums := make(map[string]float64)
+		for key, value := range fields {
+			if m, suffix, ok := splitPDUField(key); ok && m == metric {
+				if phase := branchPhase(suffix); phase != "" {
+					sums[phase] += value
+				}
+			}
+		}
+		for phase, sum := range sums {
+			if _, exists := fields[metric+"_"+phase]; !exists {
+				fields[metric+"_"+phase] = sum
+			}
+		}
 	}
 
-	// 檢查 energy 是否需要補全
-	if _, exists := data.Fields["energy_L1"]; !exists {
-		data.Fields["energy_L1"] = data.Fields["energy_L1-1"] + data.Fields["energy_L1-2"] + data.Fields["energy_L1-3"]
-		data.Fields["energy_L2"] = data.Fields["energy_L2-1"] + data.Fields["energy_L2-2"] + data.Fields["energy_L2-3"]
-		data.Fields["energy_L3"] = data.Fields["energy_L3-1"] + data.Fields["energy_L3-2"] + data.Fields["energy_L3-3"]
-	}
-	// 處理總和欄位
-	if _, exists := data.Fields[schema.TotalCurrentField]; !exists {
-		data.Fields[schema.TotalCurrentField] = data.Fields["current_L1"] + data.Fields["current_L2"] + data.Fields["current_L3"]
-	}
-	if _, exists := data.Fields[schema.TotalWattField]; !exists {
-		data.Fields[schema.TotalWattField] = data.Fields["watt_L1"] + data.Fields["watt_L2"] + data.Fields["watt_L3"]
-	}
-	if _, exists := data.Fields[schema.TotalEnergyField]; !exists {
-		data.Fields[schema.TotalEnergyField] = data.Fields["energy_L1"] + data.Fields["energy_L2"] + data.Fields["energy_L3"]
+	// 檢查總和欄位是否需要由相位補全
+	for _, metric := range totals {
+		if _, exists := fields["total_"+metric]; exists {
+			continue
+		}
+		var sum float64
+		var found bool
+		for _, phase := range phases {
+			if value, exists := fields[metric+"_"+phase]; exists {
+				sum += value
+				found = true
+			}
+		}
+		if found {
+			fields["total_"+metric] = sum
+		}
 	}
 
-	for key, value := range data.Fields {
-		var val float64
-		fields := make(map[string]float64)
+	for key, value := range fields {
 		tags := make(map[string]string)
 		for k, v := range newTags {
 			tags[k] = v
 		}
 
-		// 單位轉換
-		val = services.ShortFloat(value)
-
-		// 自動補全 phase 和 branch
-		var phase, branch string
 		newFieldName := key // 預設為原始欄位名稱
-
-		if strings.HasSuffix(key, "-1") || strings.HasSuffix(key, "-2") || strings.HasSuffix(key, "-3") {
-			branch = strings.Split(key, "_")[1]   // L1-1, L2-1 等等
-			phase = strings.Split(branch, "-")[0] // 提取 L1, L2, L3
-			tags[schema.PhaseTag] = phase
-			tags[schema.BranchTag] = branch
-
-			// 根據 branch 和 phase 設置欄位名稱
-			if strings.Contains(key, "current") {
-				newFieldName = schema.BranchCurrentField
-			} else if strings.Contains(key, "energy") {
-				newFieldName = schema.BranchEnergyField
-			} else if strings.Contains(key, "watt") {
-				newFieldName = schema.BranchWattField
-			}
-		} else if strings.HasSuffix(key, "L1") || strings.HasSuffix(key, "L2") || strings.HasSuffix(key, "L3") {
-			phase = strings.Split(key, "_")[1]
-			tags[schema.PhaseTag] = phase
-
-			// 根據 phase 設置欄位名稱
-			if strings.Contains(key, "current") {
-				newFieldName = schema.PhaseCurrentField
-			} else if strings.Contains(key, "voltage") {
-				newFieldName = schema.PhaseVoltageField
-			} else if strings.Contains(key, "watt") {
-				newFieldName = schema.PhaseWattField
-			} else if strings.Contains(key, "energy") {
-				newFieldName = schema.PhaseEnergyField
+		if metric, suffix, ok := splitPDUField(key); ok {
+			switch {
+			case suffix == "total":
+				newFieldName = pduFieldName(metric, schema.TotalCurrentField, "", schema.TotalWattField, schema.TotalEnergyField, key)
+			case slices.Contains(phases, suffix):
+				tags[schema.PhaseTag] = suffix
+				newFieldName = pduFieldName(metric, schema.PhaseCurrentField, schema.PhaseVoltageField, schema.PhaseWattField, schema.PhaseEnergyField, key)
+			case branchPhase(suffix) != "":
+				tags[schema.PhaseTag] = branchPhase(suffix)
+				tags[schema.BranchTag] = suffix
+				newFieldName = pduFieldName(metric, schema.BranchCurrentField, "", schema.BranchWattField, schema.BranchEnergyField, key)
 			}
 		}
 
-		// 設定補全後的欄位值
-		fields[newFieldName] = val
-
 		// 創建新的 Point，並將結果加入 res
 		res = append(res, models.Point{
 			Name:   schema.Measurement,
 			Time:   data.Time,
 			Tags:   tags,
-			Fields: fields,
+			Fields: map[string]float64{newFieldName: services.ShortFloat(value)},
 		})
 	}
-	// b, _ := json.MarshalIndent(res, "", "\t")
-	// os.Stdout.Write(b)
-	// fmt.Printf("len(res): %v\n", len(res))
 	return res, nil
 }
+
+// pduTags 取得 PDU 名單中的標籤並過濾出允許的標籤，名單中沒有此 PDU 時標籤皆為 unknown
+func pduTags(pduKey string, data models.Point) map[string]string {
+	newTags := make(map[string]string)
+	allowTags := strings.Split(global.Envs.FactoryConfig.AllowTags, ",")
+	dbTags := GetTagsByPduKey(pduKey)
+	if dbTags == nil {
+		// 若無標籤，設定預設標籤
+		for _, tag := range allowTags {
+			newTags[tag] = "unknown"
+		}
+		return newTags
+	}
+
+	for key, value := range dbTags {
+		data.Tags[key] = value
+	}
+	// 過濾自定義標籤
+	for key := range data.Tags {
+		if slices.Contains(allowTags, key) {
+			newTags[key] = data.Tags[key]
+		}
+	}
+	return newTags
+}
+
+// pduFieldName 依 metric 取得輸出欄位名稱，schema 未定義時保留標準欄位名稱
+func pduFieldName(metric, current, voltage, watt, energy, fallback string) string {
+	var name string
+	switch metric {
+	case "current":
+		name = current
+	case "voltage":
+		name = voltage
+	case "watt":
+		name = watt
+	case "energy":
+		name = energy
+	}
+	if name == "" {
+		return fallback
+	}
+	return name
+}
@@ -0,0 +1,187 @@
+package factory
+
+import (
+	"bimap-zbox/global"
+	"bimap-zbox/models"
+	"bimap-zbox/services"
+	"bimap-zbox/services/batcher"
+	"bimap-zbox/services/wal"
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	dcReadAhead       = 256              // 每次由佇列讀取的資料筆數
+	dcRetryMinBackoff = time.Second      // 發送失敗後第一次重試的等待時間
+	dcRetryMaxBackoff = 60 * time.Second // 重試等待時間上限
+	dcIdleCheck       = time.Second      // 沒有新資料時檢查的間隔
+)
+
+// queuedPoints 佇列中的一筆資料
+type queuedPoints struct {
+	seq    uint64
+	points []models.Point
+}
+
+// DCForwarderStatus 轉送狀態
+type DCForwarderStatus struct {
+	LastSentAt  *time.Time `json:"last_sent_at"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+	Failures    int        `json:"failures"` // 連續失敗次數
+	SentBatches uint64     `json:"sent_batches"`
+	SentPoints  uint64     `json:"sent_points"`
+}
+
+// DCQueueStatus 轉送佇列狀態 (佇列深度、延遲與轉送結果)
+type DCQueueStatus struct {
+	Queue     wal.Stats         `json:"queue"`
+	Forwarder DCForwarderStatus `json:"forwarder"`
+}
+
+var (
+	dcForwarderMu     sync.Mutex
+	dcForwarderStatus DCForwarderStatus
+	dcBatcher         atomic.Pointer[batcher.Batcher[queuedPoints]]
+)
+
+// GetDCQueueStatus 取得轉送佇列狀態
+func GetDCQueueStatus() (DCQueueStatus, error) {
+	queue, err := openDCQueue()
+	if err != nil {
+		return DCQueueStatus{}, err
+	}
+	dcForwarderMu.Lock()
+	status := dcForwarderStatus
+	dcForwarderMu.Unlock()
+	return DCQueueStatus{Queue: queue.Stats(), Forwarder: status}, nil
+}
+
+// forwardDCQueue 依序讀取佇列加入批次，累積到 BatchSize 或最舊的資料等待超過 MaxWaitTime 時送出一批，
+// DC 回應成功後確認 (ack) 並刪除；失敗時以指數退避重試同一批，不跳過也不打亂順序
+// 一次只送出一批，批次已滿 (MaxPending) 時暫停讀取；積壓的資料重送時每批之間間隔 replay_interval，避免 DC 恢復連線時瞬間湧入
+func forwardDCQueue(queue *wal.WAL) {
+	maxPending, _ := strconv.Atoi(global.Envs.FactoryConfig.MaxPending)
+	b := batcher.New(batcher.Options[queuedPoints]{
+		Size:       dcBatchSize(),
+		MaxAge:     dcMaxWaitTime(),
+		MaxPending: maxPending,
+		Block:      true,
+		SizeOf:     func(item queuedPoints) int { return max(len(item.points), 1) },
+	}, func(ctx context.Context, items []queuedPoints) {
+		sendQueuedPoints(ctx, queue, items)
+	})
+
+	stop := make(chan struct{})
+	dcBatcher.Store(b)
+	// 結束時停止讀取佇列，送出批次中的資料後關閉佇列；未送出的資料保留在佇列中，下次啟動時重送
+	services.RegisterShutdown("dc-forwarder", func(ctx context.Context) error {
+		close(stop)
+		err := b.Close(ctx)
+		if cerr := queue.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	})
+
+	next := queue.Acked() + 1
+	for {
+		entries, err := queue.Read(next, dcReadAhead)
+		if err != nil {
+			global.Logger.Error("讀取 DC 轉送佇列失敗", zap.Error(err))
+		}
+		if len(entries) == 0 {
+			select {
+			case <-queue.Notify():
+			case <-time.After(dcIdleCheck):
+			case <-stop:
+				return
+			}
+			continue
+		}
+
+		for _, entry := range entries {
+			var points []models.Point
+			if err := json.Unmarshal(entry.Data, &points); err != nil {
+				global.Logger.Error("解析 DC 轉送佇列資料失敗，略過", zap.Uint64("seq", entry.Seq), zap.Error(err))
+			}
+			if err := b.Add(queuedPoints{seq: entry.Seq, points: points}); err != nil {
+				return
+			}
+			next = entry.Seq + 1
+		}
+	}
+}
+
+// sendQueuedPoints 送出一批並確認，失敗時重試直到成功或 ctx 取消
+func sendQueuedPoints(ctx context.Context, queue *wal.WAL, items []queuedPoints) {
+	var batch []models.Point
+	for _, item := range items {
+		batch = append(batch, item.points...)
+	}
+
+	backoff := time.Duration(0)
+	for len(batch) > 0 {
+		err := SendPointsToDC(models.MetricsData{Metrics: batch}, global.EnvConfig.Factory.DCEndpoint+"/metrics")
+		if err == nil {
+			break
+		}
+		backoff = min(max(backoff*2, dcRetryMinBackoff), dcRetryMaxBackoff)
+		failures := recordDCForwardFailure(err)
+		stats := queue.Stats()
+		global.Logger.Error("批量發送 DC 失敗，保留於佇列稍後重試",
+			zap.Error(err),
+			zap.Int("failures", failures),
+			zap.Duration("backoff", backoff),
+			zap.Uint64("depth", stats.Depth),
+			zap.Float64("lag_seconds", stats.Lag))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+	}
+
+	last := items[len(items)-1].seq
+	if err := queue.Ack(last); err != nil {
+		global.Logger.Error("確認 DC 轉送佇列失敗", zap.Uint64("seq", last), zap.Error(err))
+	}
+	if backoff > 0 {
+		global.Logger.Info("DC 連線恢復，重送佇列中的資料", zap.Uint64("depth", queue.Depth()))
+	}
+	recordDCForwardSuccess(len(batch))
+
+	// 仍有積壓時，每批之間間隔 replay_interval
+	if interval := global.EnvConfig.Factory.WAL.ReplayInterval; interval > 0 && queue.Depth() > 0 {
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Duration(interval) * time.Millisecond):
+		}
+	}
+}
+
+func recordDCForwardFailure(err error) int {
+	dcForwarderMu.Lock()
+	defer dcForwarderMu.Unlock()
+	now := time.Now()
+	dcForwarderStatus.LastError = err.Error()
+	dcForwarderStatus.LastErrorAt = &now
+	dcForwarderStatus.Failures++
+	return dcForwarderStatus.Failures
+}
+
+func recordDCForwardSuccess(points int) {
+	dcForwarderMu.Lock()
+	defer dcForwarderMu.Unlock()
+	now := time.Now()
+	dcForwarderStatus.LastSentAt = &now
+	dcForwarderStatus.Failures = 0
+	dcForwarderStatus.SentBatches++
+	dcForwarderStatus.SentPoints += uint64(points)
+}
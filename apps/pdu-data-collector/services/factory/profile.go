@@ -0,0 +1,188 @@
+package factory
+
+import (
+	"bimap-zbox/global"
+	"bimap-zbox/models"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// PDU 廠牌型號設定檔：
+// 由 factory.pdu_profile_file (與 env_data.yml 同目錄的 pdu_profiles.yml) 載入，檔案更新後下次接收數據時重新載入，
+// 載入失敗時沿用上一次的設定；未設定或檔案不存在時只使用內建的 Delta 設定
+
+// 標準欄位的 metric
+var pduMetrics = []string{"current", "voltage", "watt", "energy"}
+
+// 未設定 aggregate / totals 時加總的 metric
+var defaultSumMetrics = []string{"current", "watt", "energy"}
+
+var defaultPhases = []string{"L1", "L2", "L3"}
+
+type pduProfileRegistry struct {
+	mu       sync.RWMutex
+	path     string
+	modTime  time.Time
+	profiles []models.PDUProfile
+}
+
+var pduProfiles = &pduProfileRegistry{profiles: builtinPDUProfiles()}
+
+// builtinPDUProfiles 內建設定 (與 pdu_profiles.yml 的 delta 相同)
+func builtinPDUProfiles() []models.PDUProfile {
+	return []models.PDUProfile{
+		{
+			Name:   "delta",
+			Models: []string{"PDUE428", "PDU1315", "PDU4425"},
+		},
+	}
+}
+
+// ReloadPDUProfiles 設定檔有更新時重新載入
+func ReloadPDUProfiles() {
+	path := global.EnvConfig.Factory.PDUProfileFile
+	if path == "" {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			global.Logger.Error("讀取 PDU 設定檔失敗", zap.String("file", path), zap.Error(err))
+		}
+		return
+	}
+
+	pduProfiles.mu.RLock()
+	unchanged := pduProfiles.path == path && pduProfiles.modTime.Equal(info.ModTime())
+	pduProfiles.mu.RUnlock()
+	if unchanged {
+		return
+	}
+
+	profiles, err := LoadPDUProfiles(path)
+
+	pduProfiles.mu.Lock()
+	defer pduProfiles.mu.Unlock()
+	// 載入失敗也記錄修改時間，避免每次接收數據都重新解析同一個錯誤的檔案
+	pduProfiles.path = path
+	pduProfiles.modTime = info.ModTime()
+	if err != nil {
+		global.Logger.Error("載入 PDU 設定檔失敗，沿用目前設定", zap.String("file", path), zap.Error(err))
+		return
+	}
+	pduProfiles.profiles = profiles
+	global.Logger.Info("載入 PDU 設定檔成功", zap.String("file", path), zap.Int("profiles", len(profiles)))
+}
+
+// LoadPDUProfiles 讀取並檢查 PDU 設定檔
+func LoadPDUProfiles(path string) ([]models.PDUProfile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var data models.PDUProfiles
+	if err := yaml.Unmarshal(content, &data); err != nil {
+		return nil, fmt.Errorf("解析 YAML 失敗: %v", err)
+	}
+	if len(data.Profiles) == 0 {
+		return nil, fmt.Errorf("沒有任何 PDU 設定")
+	}
+
+	names := make(map[string]bool)
+	for i := range data.Profiles {
+		profile := &data.Profiles[i]
+		if err := validatePDUProfile(profile); err != nil {
+			return nil, fmt.Errorf("profiles[%d] %s: %v", i, profile.Name, err)
+		}
+		if names[profile.Name] {
+			return nil, fmt.Errorf("profiles[%d]: 名稱 %s 重複", i, profile.Name)
+		}
+		names[profile.Name] = true
+	}
+	return data.Profiles, nil
+}
+
+func validatePDUProfile(profile *models.PDUProfile) error {
+	if profile.Name == "" {
+		return fmt.Errorf("缺少 name")
+	}
+	if profile.Manufacturer == "" && len(profile.Models) == 0 {
+		return fmt.Errorf("需設定 manufacturer 或 models")
+	}
+	for source, target := range profile.Fields {
+		if _, _, ok := splitPDUField(target); !ok {
+			return fmt.Errorf("欄位 %s 的對應 %s 不是標準欄位", source, target)
+		}
+	}
+	for metric, scale := range profile.Scale {
+		if !isPDUMetric(metric) {
+			return fmt.Errorf("scale 不支援 %s", metric)
+		}
+		if scale <= 0 {
+			return fmt.Errorf("scale.%s 必須大於 0", metric)
+		}
+	}
+	for _, metric := range append(append([]string{}, profile.Aggregate...), profile.Totals...) {
+		if !isPDUMetric(metric) {
+			return fmt.Errorf("不支援的 metric %s", metric)
+		}
+	}
+	for _, oid := range append(append([]models.SNMPOID{}, profile.SNMP.Fields...), profile.SNMP.Tags...) {
+		if oid.Name == "" || oid.OID == "" {
+			return fmt.Errorf("snmp OID 需設定 name 與 oid")
+		}
+	}
+	return nil
+}
+
+// MatchPDUProfile 依 model / manufacturer 標籤取得第一個符合的設定
+func MatchPDUProfile(tags map[string]string) (models.PDUProfile, bool) {
+	pduProfiles.mu.RLock()
+	defer pduProfiles.mu.RUnlock()
+
+	for _, profile := range pduProfiles.profiles {
+		if profile.Manufacturer != "" && tags["manufacturer"] != "" &&
+			!strings.EqualFold(profile.Manufacturer, tags["manufacturer"]) {
+			continue
+		}
+		if len(profile.Models) == 0 {
+			if tags["manufacturer"] != "" {
+				return profile, true
+			}
+			continue
+		}
+		for _, model := range profile.Models {
+			if strings.Contains(tags["model"], model) {
+				return profile, true
+			}
+		}
+	}
+	return models.PDUProfile{}, false
+}
+
+// splitPDUField 解析標準欄位，回傳 metric 與相位 / 迴路 (總和欄位為 "total")
+func splitPDUField(name string) (string, string, bool) {
+	if metric, ok := strings.CutPrefix(name, "total_"); ok {
+		return metric, "total", isPDUMetric(metric)
+	}
+	metric, suffix, found := strings.Cut(name, "_")
+	if !found || suffix == "" || !isPDUMetric(metric) {
+		return "", "", false
+	}
+	return metric, suffix, true
+}
+
+func isPDUMetric(metric string) bool {
+	for _, m := range pduMetrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,536 @@
+package factory
+
+import (
+	"bimap-zbox/global"
+	"bimap-zbox/models"
+	"bimap-zbox/services"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"golang.org/x/exp/slices"
+)
+
+const testPDUKey = "10.1.1.10"
+
+// setupFormatTest 設定 env_data.yml 的 PDUSchema / AllowTags 與 PDU 名單，並載入 pdu_profiles.yml
+func setupFormatTest(t *testing.T) []models.PDUProfile {
+	t.Helper()
+
+	envs := &models.Envs{}
+	envs.FactoryConfig.AllowTags = "factory,phase,datacenter,room,rack,side,panel,manufacturer,ip,port,model"
+	envs.PDUSchema.Measurement = "pdu"
+	envs.PDUSchema.TotalCurrentField = "total_current"
+	envs.PDUSchema.TotalEnergyField = "total_energy"
+	envs.PDUSchema.TotalWattField = "total_watt"
+	envs.PDUSchema.BranchCurrentField = "branch_current"
+	envs.PDUSchema.BranchEnergyField = "branch_energy"
+	envs.PDUSchema.BranchWattField = "branch_watt"
+	envs.PDUSchema.PhaseCurrentField = "phase_current"
+	envs.PDUSchema.PhaseWattField = "phase_watt"
+	envs.PDUSchema.PhaseVoltageField = "phase_voltage"
+	envs.PDUSchema.PhaseEnergyField = "phase_energy"
+	envs.PDUSchema.BranchTag = "branch_name"
+	envs.PDUSchema.PhaseTag = "phase_name"
+
+	pduList := map[string]map[string]string{
+		testPDUKey: {
+			"factory": "F12", "phase": "P7", "datacenter": "DC1", "room": "R1", "rack": "A01", "side": "L",
+			"model": "PDUE428", "protocol": "modbus", "ip": testPDUKey, "name": "A01-L",
+		},
+	}
+
+	oldEnvs, oldPDUList, oldLogger := global.Envs, global.PDUList, global.Logger
+	oldProfiles := pduProfiles.profiles
+	global.Envs = envs
+	global.PDUList = &pduList
+	global.Logger = zap.NewNop()
+
+	profiles, err := LoadPDUProfiles("../../pdu_profiles.yml")
+	if err != nil {
+		t.Fatalf("LoadPDUProfiles: %v", err)
+	}
+	pduProfiles.profiles = profiles
+
+	t.Cleanup(func() {
+		global.Envs, global.PDUList, global.Logger = oldEnvs, oldPDUList, oldLogger
+		pduProfiles.profiles = oldProfiles
+	})
+	return profiles
+}
+
+func findProfile(t *testing.T, profiles []models.PDUProfile, name string) models.PDUProfile {
+	t.Helper()
+	for _, profile := range profiles {
+		if profile.Name == name {
+			return profile
+		}
+	}
+	t.Fatalf("profile %s not found", name)
+	return models.PDUProfile{}
+}
+
+// copyPoint 複製數據 (FormatPDU 與舊的格式化函數都會修改 Tags / Fields)
+func copyPoint(data models.Point) models.Point {
+	cp := data
+	cp.Tags = make(map[string]string, len(data.Tags))
+	for k, v := range data.Tags {
+		cp.Tags[k] = v
+	}
+	cp.Fields = make(map[string]float64, len(data.Fields))
+	for k, v := range data.Fields {
+		cp.Fields[k] = v
+	}
+	return cp
+}
+
+// pointLines 將輸出轉為排序後的文字，每個 Point 一行：measurement,標籤 欄位=值 時間
+func pointLines(points []models.Point) []string {
+	lines := make([]string, 0, len(points))
+	for _, p := range points {
+		tags := make([]string, 0, len(p.Tags))
+		for k, v := range p.Tags {
+			tags = append(tags, k+"="+v)
+		}
+		sort.Strings(tags)
+		fields := make([]string, 0, len(p.Fields))
+		for k, v := range p.Fields {
+			fields = append(fields, fmt.Sprintf("%s=%v", k, v))
+		}
+		sort.Strings(fields)
+		lines = append(lines, fmt.Sprintf("%s,%s %s %d", p.Name, strings.Join(tags, ","), strings.Join(fields, ","), p.Time))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// deltaBranchFields Delta telegraf 數據的迴路欄位 (每相 3 個迴路)
+func deltaBranchFields() map[string]float64 {
+	fields := make(map[string]float64)
+	for p, phase := range []string{"L1", "L2", "L3"} {
+		for b := 1; b <= 3; b++ {
+			branch := fmt.Sprintf("%s-%d", phase, b)
+			fields["current_"+branch] = 1.013*float64(p+1) + 0.25*float64(b)
+			fields["watt_"+branch] = 210.456*float64(p+1) + 12.5*float64(b)
+			fields["energy_"+branch] = 1520.777*float64(p+1) + 3.335*float64(b)
+		}
+	}
+	return fields
+}
+
+func TestFormatPDUDeltaMatchesLegacy(t *testing.T) {
+	profiles := setupFormatTest(t)
+	delta := findProfile(t, profiles, "delta")
+
+	withPhases := deltaBranchFields()
+	for _, phase := range []string{"L1", "L2", "L3"} {
+		withPhases["voltage_"+phase] = 220.125
+		withPhases["current_"+phase] = 9.999
+		withPhases["watt_"+phase] = 1000.004
+		withPhases["energy_"+phase] = 5000.5
+	}
+	withTotals := deltaBranchFields()
+	withTotals["total_current"] = 30.3
+	withTotals["total_watt"] = 4500.45
+	withTotals["total_energy"] = 99999.999
+
+	tests := []struct {
+		name   string
+		pduKey string
+		fields map[string]float64
+	}{
+		{"branches only", testPDUKey, deltaBranchFields()},
+		{"phase fields reported", testPDUKey, withPhases},
+		{"total fields reported", testPDUKey, withTotals},
+		{"unknown pdu", "10.9.9.9", deltaBranchFields()},
+		{"extra fields", testPDUKey, func() map[string]float64 {
+			fields := deltaBranchFields()
+			fields["voltage_L1"] = 219.8
+			fields["frequency"] = 60.02
+			return fields
+		}()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := models.Point{
+				Name:   "modbus",
+				Time:   1717200000,
+				Tags:   map[string]string{"pdu_key": tt.pduKey, "model": "PDUE428", "host": "telegraf-01"},
+				Fields: tt.fields,
+			}
+
+			name, got, err := FormatPDUPoint(tt.pduKey, copyPoint(data))
+			if err != nil {
+				t.Fatalf("FormatPDUPoint: %v", err)
+			}
+			if name != "delta" {
+				t.Errorf("profile = %s, want delta", name)
+			}
+			want, _ := legacyFormatDeltaPDU(tt.pduKey, copyPoint(data))
+
+			gotLines, wantLines := pointLines(got), pointLines(want)
+			if !slices.Equal(gotLines, wantLines) {
+				t.Errorf("output differs from legacy Delta formatter\n--- got ---\n%s\n--- want ---\n%s",
+					strings.Join(gotLines, "\n"), strings.Join(wantLines, "\n"))
+			}
+
+			// 直接以 delta 設定轉換結果相同
+			direct, err := FormatPDU(delta, tt.pduKey, copyPoint(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(pointLines(direct), wantLines) {
+				t.Errorf("FormatPDU(delta) differs from legacy Delta formatter")
+			}
+		})
+	}
+}
+
+func TestFormatPDUAPC(t *testing.T) {
+	profiles := setupFormatTest(t)
+	(*global.PDUList)[testPDUKey]["model"] = "AP8941"
+	(*global.PDUList)[testPDUKey]["manufacturer"] = "APC"
+
+	data := models.Point{
+		Name: "pdu",
+		Time: 1717200000,
+		Tags: map[string]string{"pdu_key": testPDUKey, "sys_name": "apc-a01-l"},
+		Fields: map[string]float64{
+			"rPDU2PhaseStatusCurrent_1":  51,
+			"rPDU2PhaseStatusCurrent_2":  48,
+			"rPDU2PhaseStatusCurrent_3":  50,
+			"rPDU2PhaseStatusVoltage_1":  229,
+			"rPDU2PhaseStatusVoltage_2":  230,
+			"rPDU2PhaseStatusVoltage_3":  231,
+			"rPDU2PhaseStatusPower_1":    117,
+			"rPDU2PhaseStatusPower_2":    110,
+			"rPDU2PhaseStatusPower_3":    118,
+			"rPDU2BankStatusCurrent_1":   26,
+			"rPDU2BankStatusCurrent_2":   25,
+			"rPDU2BankStatusCurrent_3":   24,
+			"rPDU2BankStatusCurrent_4":   24,
+			"rPDU2BankStatusCurrent_5":   25,
+			"rPDU2BankStatusCurrent_6":   25,
+			"rPDU2DeviceStatusPower":     345,
+			"rPDU2DeviceStatusEnergy":    12345,
+			"rPDU2DeviceStatusLoadState": 1, // 未對應的欄位捨棄
+		},
+	}
+
+	name, got, err := FormatPDUPoint(testPDUKey, data)
+	if err != nil {
+		t.Fatalf("FormatPDUPoint: %v", err)
+	}
+	if name != findProfile(t, profiles, "apc-rpdu2").Name {
+		t.Errorf("profile = %s, want apc-rpdu2", name)
+	}
+
+	point := func(phase, branch, field string, value float64) models.Point {
+		tags := map[string]string{
+			"datacenter": "DC1", "factory": "F12", "ip": testPDUKey, "manufacturer": "APC", "model": "AP8941",
+			"phase": "P7", "rack": "A01", "room": "R1", "side": "L",
+		}
+		if phase != "" {
+			tags["phase_name"] = phase
+		}
+		if branch != "" {
+			tags["branch_name"] = branch
+		}
+		return models.Point{Name: "pdu", Time: 1717200000, Tags: tags, Fields: map[string]float64{field: value}}
+	}
+	// 單位換算：電流 0.1 A、功率 0.01 kW、電能 0.1 kWh；相位電流已回報時不由 bank 加總
+	want := pointLines([]models.Point{
+		point("", "", "total_current", 14.9),
+		point("", "", "total_energy", 1234.5),
+		point("", "", "total_watt", 3450),
+		point("L1", "L1-1", "branch_current", 2.6),
+		point("L1", "L1-2", "branch_current", 2.5),
+		point("L2", "L2-1", "branch_current", 2.4),
+		point("L2", "L2-2", "branch_current", 2.4),
+		point("L3", "L3-1", "branch_current", 2.5),
+		point("L3", "L3-2", "branch_current", 2.5),
+		point("L1", "", "phase_current", 5.1),
+		point("L1", "", "phase_voltage", 229),
+		point("L1", "", "phase_watt", 1170),
+		point("L2", "", "phase_current", 4.8),
+		point("L2", "", "phase_voltage", 230),
+		point("L2", "", "phase_watt", 1100),
+		point("L3", "", "phase_current", 5),
+		point("L3", "", "phase_voltage", 231),
+		point("L3", "", "phase_watt", 1180),
+	})
+
+	if gotLines := pointLines(got); !slices.Equal(gotLines, want) {
+		t.Errorf("APC output\n--- got ---\n%s\n--- want ---\n%s", strings.Join(gotLines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestFormatPDUBranchPhase(t *testing.T) {
+	profiles := setupFormatTest(t)
+	raritan := findProfile(t, profiles, "raritan-px")
+
+	// 沒有相位電流時由斷路器依 branch_phase 加總
+	data := models.Point{
+		Time: 1717200000,
+		Tags: map[string]string{},
+		Fields: map[string]float64{
+			"ocpRmsCurrent_C1":  1500,
+			"ocpRmsCurrent_C2":  2250,
+			"ocpRmsCurrent_C3":  1000,
+			"ocpRmsCurrent_C4":  1000,
+			"ocpRmsCurrent_C5":  3000,
+			"ocpRmsCurrent_C6":  0,
+			"inletActiveEnergy": 2500000,
+		},
+	}
+	got, err := FormatPDU(raritan, testPDUKey, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := make(map[string]float64)
+	for _, p := range got {
+		for field, value := range p.Fields {
+			key := field
+			if phase := p.Tags["phase_name"]; phase != "" {
+				key += "/" + phase
+			}
+			if branch := p.Tags["branch_name"]; branch != "" {
+				key += "/" + branch
+			}
+			values[key] = value
+		}
+	}
+	want := map[string]float64{
+		"branch_current/L1/C1": 1.5,
+		"branch_current/L1/C2": 2.25,
+		"branch_current/L2/C3": 1,
+		"branch_current/L2/C4": 1,
+		"branch_current/L3/C5": 3,
+		"branch_current/L3/C6": 0,
+		"phase_current/L1":     3.75,
+		"phase_current/L2":     2,
+		"phase_current/L3":     3,
+		"total_current":        8.75,
+		"total_energy":         2500,
+	}
+	if len(values) != len(want) {
+		t.Errorf("got %d fields, want %d: %v", len(values), len(want), values)
+	}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("%s = %v, want %v", key, values[key], value)
+		}
+	}
+}
+
+func TestFormatPDUPointNotMatched(t *testing.T) {
+	setupFormatTest(t)
+	(*global.PDUList)[testPDUKey]["model"] = "X-1000"
+
+	_, _, err := FormatPDUPoint(testPDUKey, models.Point{
+		Tags:   map[string]string{"manufacturer": "Unknown"},
+		Fields: map[string]float64{"current_L1": 1},
+	})
+	if !errors.Is(err, ErrPDUProfileNotMatched) {
+		t.Errorf("err = %v, want ErrPDUProfileNotMatched", err)
+	}
+}
+
+func TestMatchPDUProfile(t *testing.T) {
+	setupFormatTest(t)
+
+	tests := []struct {
+		tags map[string]string
+		want string
+	}{
+		{map[string]string{"model": "PDUE428"}, "delta"},
+		{map[string]string{"model": "PDU4425-B"}, "delta"},
+		{map[string]string{"model": "AP8941", "manufacturer": "apc"}, "apc-rpdu2"},
+		{map[string]string{"model": "AP7900"}, "apc-rpdu2"},
+		{map[string]string{"model": "AP8941", "manufacturer": "Eaton"}, ""},
+		{map[string]string{"model": "EMA107-10", "manufacturer": "EATON"}, "eaton-epdu"},
+		{map[string]string{"model": "PX3-5190R", "manufacturer": "Raritan"}, "raritan-px"},
+		{map[string]string{"model": "MPH2", "manufacturer": "Vertiv"}, "vertiv-mph2"},
+		{map[string]string{"model": "Z-100"}, ""},
+		{map[string]string{}, ""},
+	}
+	for _, tt := range tests {
+		profile, ok := MatchPDUProfile(tt.tags)
+		if ok != (tt.want != "") || profile.Name != tt.want {
+			t.Errorf("MatchPDUProfile(%v) = %q, %v; want %q", tt.tags, profile.Name, ok, tt.want)
+		}
+	}
+}
+
+func TestLoadPDUProfiles(t *testing.T) {
+	profiles, err := LoadPDUProfiles("../../pdu_profiles.yml")
+	if err != nil {
+		t.Fatalf("LoadPDUProfiles: %v", err)
+	}
+	var names []string
+	for _, profile := range profiles {
+		names = append(names, profile.Name)
+	}
+	if want := []string{"delta", "apc-rpdu2", "eaton-epdu", "raritan-px", "vertiv-mph2"}; !slices.Equal(names, want) {
+		t.Errorf("profiles = %v, want %v", names, want)
+	}
+	apc := findProfile(t, profiles, "apc-rpdu2")
+	if apc.Scale["current"] != 10 || !apc.DropUnmapped || apc.Fields["rPDU2DeviceStatusPower"] != "total_watt" {
+		t.Errorf("apc-rpdu2 = %+v", apc)
+	}
+	if len(apc.SNMP.Fields) != 6 || len(apc.SNMP.Tags) != 1 || !apc.SNMP.Fields[0].Table {
+		t.Errorf("apc-rpdu2 snmp = %+v", apc.SNMP)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"invalid yaml", "profiles: [", "解析 YAML 失敗"},
+		{"empty", "profiles: []", "沒有任何 PDU 設定"},
+		{"missing name", "profiles:\n  - models: [A]", "缺少 name"},
+		{"missing match", "profiles:\n  - name: a", "需設定 manufacturer 或 models"},
+		{"bad field", "profiles:\n  - name: a\n    models: [A]\n    fields: {x: power_L1}", "不是標準欄位"},
+		{"bad scale metric", "profiles:\n  - name: a\n    models: [A]\n    scale: {power: 10}", "scale 不支援 power"},
+		{"zero scale", "profiles:\n  - name: a\n    models: [A]\n    scale: {current: 0}", "scale.current 必須大於 0"},
+		{"bad aggregate", "profiles:\n  - name: a\n    models: [A]\n    aggregate: [power]", "不支援的 metric power"},
+		{"duplicate", "profiles:\n  - name: a\n    models: [A]\n  - name: a\n    models: [B]", "名稱 a 重複"},
+		{"snmp oid", "profiles:\n  - name: a\n    models: [A]\n    snmp:\n      fields:\n        - name: x", "snmp OID 需設定 name 與 oid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pdu_profiles.yml")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadPDUProfiles(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// legacyFormatDeltaPDU 改為設定檔之前寫死的 Delta 格式化函數 (原樣保留作為比對基準)
+func legacyFormatDeltaPDU(pduKey string, data models.Point) ([]models.Point, error) {
+
+	newTags := make(map[string]string)
+	var res []models.Point
+	schema := global.Envs.PDUSchema
+
+	// 獲取對應的標籤
+	allowTags := strings.Split(global.Envs.FactoryConfig.AllowTags, ",")
+	dbTags := GetTagsByPduKey(pduKey)
+	if dbTags == nil {
+		// 若無標籤，設定預設標籤
+		for _, tag := range allowTags {
+			newTags[tag] = "unknown"
+		}
+	} else {
+		for key, value := range dbTags {
+			data.Tags[key] = value
+		}
+		// 過濾自定義標籤
+		for key := range data.Tags {
+			if slices.Contains(allowTags, key) {
+				newTags[key] = data.Tags[key]
+			}
+		}
+	}
+
+	// 檢查 current 是否需要補全
+	if _, exists := data.Fields["current_L1"]; !exists {
+		data.Fields["current_L1"] = data.Fields["current_L1-1"] + data.Fields["current_L1-2"] + data.Fields["current_L1-3"]
+		data.Fields["current_L2"] = data.Fields["current_L2-1"] + data.Fields["current_L2-2"] + data.Fields["current_L2-3"]
+		data.Fields["current_L3"] = data.Fields["current_L3-1"] + data.Fields["current_L3-2"] + data.Fields["current_L3-3"]
+	}
+
+	// 檢查 watt 是否需要補全
+	if _, exists := data.Fields["watt_L1"]; !exists {
+		data.Fields["watt_L1"] = data.Fields["watt_L1-1"] + data.Fields["watt_L1-2"] + data.Fields["watt_L1-3"]
+		data.Fields["watt_L2"] = data.Fields["watt_L2-1"] + data.Fields["watt_L2-2"] + data.Fields["watt_L2-3"]
+		data.Fields["watt_L3"] = data.Fields["watt_L3-1"] + data.Fields["watt_L3-2"] + data.Fields["watt_L3-3"]
+	}
+
+	// 檢查 energy 是否需要補全
+	if _, exists := data.Fields["energy_L1"]; !exists {
+		data.Fields["energy_L1"] = data.Fields["energy_L1-1"] + data.Fields["energy_L1-2"] + data.Fields["energy_L1-3"]
+		data.Fields["energy_L2"] = data.Fields["energy_L2-1"] + data.Fields["energy_L2-2"] + data.Fields["energy_L2-3"]
+		data.Fields["energy_L3"] = data.Fields["energy_L3-1"] + data.Fields["energy_L3-2"] + data.Fields["energy_L3-3"]
+	}
+	// 處理總和欄位
+	if _, exists := data.Fields[schema.TotalCurrentField]; !exists {
+		data.Fields[schema.TotalCurrentField] = data.Fields["current_L1"] + data.Fields["current_L2"] + data.Fields["current_L3"]
+	}
+	if _, exists := data.Fields[schema.TotalWattField]; !exists {
+		data.Fields[schema.TotalWattField] = data.Fields["watt_L1"] + data.Fields["watt_L2"] + data.Fields["watt_L3"]
+	}
+	if _, exists := data.Fields[schema.TotalEnergyField]; !exists {
+		data.Fields[schema.TotalEnergyField] = data.Fields["energy_L1"] + data.Fields["energy_L2"] + data.Fields["energy_L3"]
+	}
+
+	for key, value := range data.Fields {
+		var val float64
+		fields := make(map[string]float64)
+		tags := make(map[string]string)
+		for k, v := range newTags {
+			tags[k] = v
+		}
+
+		// 單位轉換
+		val = services.ShortFloat(value)
+
+		// 自動補全 phase 和 branch
+		var phase, branch string
+		newFieldName := key // 預設為原始欄位名稱
+
+		if strings.HasSuffix(key, "-1") || strings.HasSuffix(key, "-2") || strings.HasSuffix(key, "-3") {
+			branch = strings.Split(key, "_")[1]   // L1-1, L2-1 等等
+			phase = strings.Split(branch, "-")[0] // 提取 L1, L2, L3
+			tags[schema.PhaseTag] = phase
+			tags[schema.BranchTag] = branch
+
+			// 根據 branch 和 phase 設置欄位名稱
+			if strings.Contains(key, "current") {
+				newFieldName = schema.BranchCurrentField
+			} else if strings.Contains(key, "energy") {
+				newFieldName = schema.BranchEnergyField
+			} else if strings.Contains(key, "watt") {
+				newFieldName = schema.BranchWattField
+			}
+		} else if strings.HasSuffix(key, "L1") || strings.HasSuffix(key, "L2") || strings.HasSuffix(key, "L3") {
+			phase = strings.Split(key, "_")[1]
+			tags[schema.PhaseTag] = phase
+
+			// 根據 phase 設置欄位名稱
+			if strings.Contains(key, "current") {
+				newFieldName = schema.PhaseCurrentField
+			} else if strings.Contains(key, "voltage") {
+				newFieldName = schema.PhaseVoltageField
+			} else if strings.Contains(key, "watt") {
+				newFieldName = schema.PhaseWattField
+			} else if strings.Contains(key, "energy") {
+				newFieldName = schema.PhaseEnergyField
+			}
+		}
+
+		// 設定補全後的欄位值
+		fields[newFieldName] = val
+
+		// 創建新的 Point，並將結果加入 res
+		res = append(res, models.Point{
+			Name:   schema.Measurement,
+			Time:   data.Time,
+			Tags:   tags,
+			Fields: fields,
+		})
+	}
+	return res, nil
+}
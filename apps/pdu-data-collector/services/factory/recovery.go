@@ -7,16 +7,21 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"go.uber.org/zap"
 )
 
 // RecoveryFactoryData 處理失敗的 Factory 資料重傳
+// 指標資料改由轉送佇列保存，舊版留下的 JSON 檔案依檔名 (時間) 順序匯入佇列後移除
 func RecoveryFactoryData(fileDir string) {
+	if fileDir == global.Envs.FactoryRecoveryDir.DCMetrics {
+		importLegacyDCMetrics(fileDir)
+		return
+	}
+
 	var endpoint string
 	switch fileDir {
-	case global.Envs.FactoryRecoveryDir.DCMetrics:
-		endpoint = global.EnvConfig.Factory.DCEndpoint + "/metrics"
 	case global.Envs.FactoryRecoveryDir.DCEvents:
 		endpoint = global.EnvConfig.Factory.DCEndpoint + "/event"
 	}
@@ -93,3 +98,42 @@ func RecoveryFactoryData(fileDir string) {
 		}
 	}
 }
+
+// importLegacyDCMetrics 將舊版保存的 JSON 檔案匯入轉送佇列
+func importLegacyDCMetrics(fileDir string) {
+	files, err := os.ReadDir(fileDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			global.Logger.Error("讀取 FactoryData 目錄失敗", zap.String("dir", fileDir), zap.Error(err))
+		}
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		filename := filepath.Join(fileDir, file.Name())
+
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			global.Logger.Error("讀取檔案失敗", zap.String("file", filename), zap.Error(err))
+			continue
+		}
+		var points []models.Point
+		if err := json.Unmarshal(content, &points); err != nil {
+			global.Logger.Error("解析 JSON 失敗", zap.String("file", filename), zap.Error(err))
+			continue
+		}
+		if err := AddPoints(points); err != nil {
+			global.Logger.Error("匯入轉送佇列失敗", zap.String("file", filename), zap.Error(err))
+			return
+		}
+		if err := os.Remove(filename); err != nil {
+			global.Logger.Error("檔案移除失敗", zap.String("file", filename), zap.Error(err))
+			continue
+		}
+		global.Logger.Info("舊版資料已匯入轉送佇列", zap.String("file", filename), zap.Int("count", len(points)))
+	}
+}
@@ -0,0 +1,228 @@
+package factory
+
+import (
+	"bimap-zbox/global"
+	"bimap-zbox/models"
+	"bimap-zbox/services/snmp"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 內建 SNMP 輪詢：依 PDU 名單中 protocol 為 snmp 的設備，以符合的 PDU 設定檔 snmp 區段的 OID 輪詢，
+// 結果以原始欄位名稱組成 models.Point，與 telegraf 送入的數據相同經 IngestPoints 轉換後寫入轉送佇列
+
+const defaultSNMPMeasurement = "pdu"
+
+// SNMPDeviceStatus 單一設備的輪詢狀態
+type SNMPDeviceStatus struct {
+	PDUKey     string     `json:"pdu_key"`
+	IP         string     `json:"ip"`
+	Model      string     `json:"model"`
+	Profile    string     `json:"profile,omitempty"`
+	LastPollAt *time.Time `json:"last_poll_at,omitempty"`
+	Duration   float64    `json:"duration_seconds"`
+	Fields     int        `json:"fields"`
+	Error      string     `json:"error,omitempty"`
+	Failures   int        `json:"failures"` // 連續失敗次數
+}
+
+// SNMPStatus 最近一次輪詢的結果
+type SNMPStatus struct {
+	LastRunAt *time.Time         `json:"last_run_at"`
+	Duration  float64            `json:"duration_seconds"`
+	Points    int                `json:"points"`
+	Devices   []SNMPDeviceStatus `json:"devices"`
+}
+
+var (
+	snmpMu     sync.Mutex
+	snmpStatus SNMPStatus
+)
+
+// GetSNMPStatus 取得最近一次輪詢的結果
+func GetSNMPStatus() SNMPStatus {
+	snmpMu.Lock()
+	defer snmpMu.Unlock()
+
+	status := snmpStatus
+	status.Devices = append([]SNMPDeviceStatus(nil), snmpStatus.Devices...)
+	return status
+}
+
+// PollSNMPDevices 輪詢 PDU 名單中的 SNMP 設備並寫入轉送佇列 (供 crontab 調用)
+func PollSNMPDevices() {
+	startTime := time.Now()
+
+	opts := snmpOptions()
+	if err := opts.Validate(); err != nil {
+		global.Logger.Error("SNMP 設定錯誤", zap.Error(err))
+		return
+	}
+
+	// PDU 設定檔有更新時重新載入
+	ReloadPDUProfiles()
+
+	targets, devices, measurements := snmpTargets()
+	results := snmp.PollAll(context.Background(), opts, targets, global.EnvConfig.Factory.SNMP.MaxConcurrency)
+
+	snmpMu.Lock()
+	previous := make(map[string]SNMPDeviceStatus, len(snmpStatus.Devices))
+	for _, device := range snmpStatus.Devices {
+		previous[device.PDUKey] = device
+	}
+	snmpMu.Unlock()
+
+	var points []models.Point
+	failed := 0
+	for _, result := range results {
+		device := devices[result.Target.Key]
+		pollAt := result.Time
+		device.LastPollAt = &pollAt
+		device.Duration = result.Duration.Seconds()
+		device.Fields = len(result.Fields)
+
+		if result.Err != nil {
+			failed++
+			device.Error = result.Err.Error()
+			device.Failures = previous[device.PDUKey].Failures + 1
+			global.Logger.Warn("SNMP 輪詢失敗",
+				zap.String("pdu_key", device.PDUKey),
+				zap.String("ip", device.IP),
+				zap.Int("failures", device.Failures),
+				zap.Error(result.Err))
+		} else {
+			points = append(points, snmpPoint(measurements[device.PDUKey], device, result))
+		}
+		devices[result.Target.Key] = device
+	}
+
+	if err := IngestPoints(points); err != nil {
+		global.Logger.Error("SNMP 數據寫入轉送佇列失敗", zap.Int("points", len(points)), zap.Error(err))
+	}
+
+	duration := time.Since(startTime)
+	status := SNMPStatus{
+		LastRunAt: &startTime,
+		Duration:  duration.Seconds(),
+		Points:    len(points),
+		Devices:   make([]SNMPDeviceStatus, 0, len(devices)),
+	}
+	for _, device := range devices {
+		status.Devices = append(status.Devices, device)
+	}
+	sort.Slice(status.Devices, func(i, j int) bool {
+		return status.Devices[i].PDUKey < status.Devices[j].PDUKey
+	})
+	snmpMu.Lock()
+	snmpStatus = status
+	snmpMu.Unlock()
+
+	global.Logger.Info("SNMP 輪詢完成",
+		zap.Int("devices", len(targets)),
+		zap.Int("failed", failed),
+		zap.Int("points", len(points)),
+		zap.Duration("duration", duration))
+}
+
+func snmpOptions() snmp.Options {
+	cfg := global.EnvConfig.Factory.SNMP
+	return snmp.Options{
+		Version:        cfg.Version,
+		Community:      cfg.Community,
+		Port:           cfg.Port,
+		Timeout:        time.Duration(cfg.Timeout) * time.Millisecond,
+		Retries:        cfg.Retries,
+		MaxRepetitions: cfg.MaxRepetitions,
+		V3: snmp.V3Options{
+			Username:      cfg.V3.Username,
+			SecurityLevel: cfg.V3.SecurityLevel,
+			AuthProtocol:  cfg.V3.AuthProtocol,
+			AuthPassword:  cfg.V3.AuthPassword,
+			PrivProtocol:  cfg.V3.PrivProtocol,
+			PrivPassword:  cfg.V3.PrivPassword,
+			ContextName:   cfg.V3.ContextName,
+		},
+	}
+}
+
+// snmpTargets 從 PDU 名單取得 protocol 為 snmp 的設備與各設備的 measurement；
+// 型號未匹配或設定檔沒有 snmp OID 的設備不輪詢，只記錄狀態
+func snmpTargets() ([]snmp.Target, map[string]SNMPDeviceStatus, map[string]string) {
+	rwMu.RLock()
+	pduMap := *global.PDUList
+	rwMu.RUnlock()
+
+	var targets []snmp.Target
+	devices := make(map[string]SNMPDeviceStatus)
+	measurements := make(map[string]string)
+	for pduKey, pduInfo := range pduMap {
+		if pduInfo["protocol"] != "snmp" {
+			continue
+		}
+		ip := pduInfo["ip"]
+		if ip == "" {
+			ip = pduKey
+		}
+		device := SNMPDeviceStatus{PDUKey: pduKey, IP: ip, Model: pduInfo["model"]}
+
+		profile, ok := MatchPDUProfile(pduInfo)
+		if !ok {
+			device.Error = ErrPDUProfileNotMatched.Error()
+			devices[pduKey] = device
+			continue
+		}
+		device.Profile = profile.Name
+		if len(profile.SNMP.Fields) == 0 {
+			device.Error = "PDU 設定檔未設定 snmp OID"
+			devices[pduKey] = device
+			continue
+		}
+		devices[pduKey] = device
+		measurements[pduKey] = profile.SNMP.Measurement
+		if measurements[pduKey] == "" {
+			measurements[pduKey] = defaultSNMPMeasurement
+		}
+
+		targets = append(targets, snmp.Target{
+			Key:     pduKey,
+			Address: ip,
+			Fields:  snmpOIDs(profile.SNMP.Fields),
+			Tags:    snmpOIDs(profile.SNMP.Tags),
+		})
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Key < targets[j].Key
+	})
+	return targets, devices, measurements
+}
+
+func snmpOIDs(list []models.SNMPOID) []snmp.OID {
+	oids := make([]snmp.OID, 0, len(list))
+	for _, oid := range list {
+		oids = append(oids, snmp.OID{Name: oid.Name, OID: oid.OID, Table: oid.Table})
+	}
+	return oids
+}
+
+// snmpPoint 輪詢結果轉為 models.Point，標籤與 telegraf 數據相同 (pdu_key / model / protocol / ip)
+func snmpPoint(measurement string, device SNMPDeviceStatus, result snmp.Result) models.Point {
+	tags := make(map[string]string, len(result.Tags)+4)
+	for key, value := range result.Tags {
+		tags[key] = value
+	}
+	tags["pdu_key"] = device.PDUKey
+	tags["model"] = device.Model
+	tags["protocol"] = "snmp"
+	tags["ip"] = device.IP
+
+	return models.Point{
+		Name:   measurement,
+		Fields: result.Fields,
+		Tags:   tags,
+		Time:   result.Time.Unix(),
+	}
+}
@@ -0,0 +1,287 @@
+package factory
+
+import (
+	"bimap-zbox/global"
+	"bimap-zbox/models"
+	"bimap-zbox/services/snmp"
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"golang.org/x/exp/slices"
+)
+
+// startV2cAgent 本機 UDP 的 v2c agent，依 OID 數值順序回應 GET / GETNEXT / GETBULK
+func startV2cAgent(t *testing.T, mib map[string]gosnmp.SnmpPDU) uint16 {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	var oids []string
+	for oid := range mib {
+		oids = append(oids, oid)
+	}
+	sort.Slice(oids, func(i, j int) bool { return compareOID(oids[i], oids[j]) < 0 })
+	next := func(oid string) gosnmp.SnmpPDU {
+		for _, name := range oids {
+			if compareOID(name, oid) > 0 {
+				pdu := mib[name]
+				pdu.Name = name
+				return pdu
+			}
+		}
+		return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.EndOfMibView}
+	}
+
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			decoder := &gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: "public"}
+			req, err := decoder.SnmpDecodePacket(buf[:n])
+			if err != nil {
+				continue
+			}
+			var vars []gosnmp.SnmpPDU
+			for _, v := range req.Variables {
+				switch req.PDUType {
+				case gosnmp.GetRequest:
+					pdu, ok := mib[v.Name]
+					if !ok {
+						pdu = gosnmp.SnmpPDU{Type: gosnmp.NoSuchObject}
+					}
+					pdu.Name = v.Name
+					vars = append(vars, pdu)
+				case gosnmp.GetNextRequest:
+					vars = append(vars, next(v.Name))
+				case gosnmp.GetBulkRequest:
+					name := v.Name
+					for r := uint32(0); r < req.MaxRepetitions; r++ {
+						pdu := next(name)
+						vars = append(vars, pdu)
+						if pdu.Type == gosnmp.EndOfMibView {
+							break
+						}
+						name = pdu.Name
+					}
+				}
+			}
+			resp := &gosnmp.SnmpPacket{
+				Version:   gosnmp.Version2c,
+				Community: req.Community,
+				PDUType:   gosnmp.GetResponse,
+				RequestID: req.RequestID,
+				Variables: vars,
+			}
+			if out, err := resp.MarshalMsg(); err == nil {
+				conn.WriteTo(out, addr)
+			}
+		}
+	}()
+	return uint16(conn.LocalAddr().(*net.UDPAddr).Port)
+}
+
+func compareOID(a, b string) int {
+	as := strings.Split(strings.Trim(a, "."), ".")
+	bs := strings.Split(strings.Trim(b, "."), ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(as) - len(bs)
+}
+
+func TestSNMPTargets(t *testing.T) {
+	profiles := setupFormatTest(t)
+	apc := findProfile(t, profiles, "apc-rpdu2")
+
+	*global.PDUList = map[string]map[string]string{
+		"apc-1":      {"model": "AP8941", "manufacturer": "APC", "protocol": "snmp", "ip": "10.1.1.21"},
+		"10.1.1.22":  {"model": "AP7900", "protocol": "snmp"},                     // 沒有 ip 時以 pdu_key 作為位址
+		"delta-1":    {"model": "PDUE428", "protocol": "snmp", "ip": "10.1.1.23"}, // 設定檔沒有 snmp OID
+		"unknown-1":  {"model": "X-1000", "manufacturer": "Unknown", "protocol": "snmp", "ip": "10.1.1.24"},
+		"modbus-apc": {"model": "AP8941", "protocol": "modbus", "ip": "10.1.1.25"}, // 非 snmp 不輪詢
+	}
+
+	targets, devices, measurements := snmpTargets()
+
+	var keys []string
+	for _, target := range targets {
+		keys = append(keys, target.Key+"@"+target.Address)
+		if len(target.Fields) != len(apc.SNMP.Fields) || len(target.Tags) != len(apc.SNMP.Tags) {
+			t.Errorf("target %s has %d fields / %d tags, want %d / %d",
+				target.Key, len(target.Fields), len(target.Tags), len(apc.SNMP.Fields), len(apc.SNMP.Tags))
+		}
+		for i, oid := range apc.SNMP.Fields {
+			if target.Fields[i] != (snmp.OID{Name: oid.Name, OID: oid.OID, Table: oid.Table}) {
+				t.Errorf("target %s field %d = %+v, want %+v", target.Key, i, target.Fields[i], oid)
+			}
+		}
+	}
+	if want := []string{"10.1.1.22@10.1.1.22", "apc-1@10.1.1.21"}; !slices.Equal(keys, want) {
+		t.Errorf("targets = %v, want %v", keys, want)
+	}
+
+	wantDevices := map[string]SNMPDeviceStatus{
+		"apc-1":     {PDUKey: "apc-1", IP: "10.1.1.21", Model: "AP8941", Profile: "apc-rpdu2"},
+		"10.1.1.22": {PDUKey: "10.1.1.22", IP: "10.1.1.22", Model: "AP7900", Profile: "apc-rpdu2"},
+		"delta-1":   {PDUKey: "delta-1", IP: "10.1.1.23", Model: "PDUE428", Profile: "delta", Error: "PDU 設定檔未設定 snmp OID"},
+		"unknown-1": {PDUKey: "unknown-1", IP: "10.1.1.24", Model: "X-1000", Error: ErrPDUProfileNotMatched.Error()},
+	}
+	if len(devices) != len(wantDevices) {
+		t.Errorf("got %d devices, want %d: %v", len(devices), len(wantDevices), devices)
+	}
+	for key, want := range wantDevices {
+		if devices[key] != want {
+			t.Errorf("device %s = %+v, want %+v", key, devices[key], want)
+		}
+	}
+
+	// 設定檔未設定 measurement 時使用 pdu
+	if len(measurements) != 2 || measurements["apc-1"] != defaultSNMPMeasurement || measurements["10.1.1.22"] != defaultSNMPMeasurement {
+		t.Errorf("measurements = %v", measurements)
+	}
+}
+
+func TestSNMPPoint(t *testing.T) {
+	pollAt := time.Unix(1717200000, 500)
+	device := SNMPDeviceStatus{PDUKey: "apc-1", IP: "10.1.1.21", Model: "AP8941"}
+	result := snmp.Result{
+		Time:   pollAt,
+		Fields: map[string]float64{"rPDU2DeviceStatusPower": 345},
+		// 設備回報的同名標籤以 PDU 名單為準
+		Tags: map[string]string{"sys_name": "apc-a01-l", "model": "AP8941-X", "ip": "192.168.0.1"},
+	}
+
+	point := snmpPoint("pdu", device, result)
+	want := models.Point{
+		Name:   "pdu",
+		Time:   1717200000,
+		Fields: map[string]float64{"rPDU2DeviceStatusPower": 345},
+		Tags: map[string]string{
+			"sys_name": "apc-a01-l", "pdu_key": "apc-1", "model": "AP8941", "protocol": "snmp", "ip": "10.1.1.21",
+		},
+	}
+	if got, want := pointLines([]models.Point{point}), pointLines([]models.Point{want}); !slices.Equal(got, want) {
+		t.Errorf("snmpPoint = %v, want %v", got, want)
+	}
+}
+
+// TestSNMPPollFormat 由設定檔的 OID 輪詢本機 agent，再依設定檔轉換為標準欄位
+func TestSNMPPollFormat(t *testing.T) {
+	setupFormatTest(t)
+
+	const apc = ".1.3.6.1.4.1.318.1.1.26"
+	mib := map[string]gosnmp.SnmpPDU{
+		".1.3.6.1.2.1.1.5.0":     {Type: gosnmp.OctetString, Value: []byte("apc-a01-l")},
+		apc + ".4.3.1.5.1":       {Type: gosnmp.Integer, Value: 345},
+		apc + ".4.3.1.9.1":       {Type: gosnmp.Integer, Value: 12345},
+		apc + ".4.3.1.10.1":      {Type: gosnmp.Integer, Value: 1}, // 設定檔未輪詢的 OID
+		apc + ".6.3.1.5.1":       {Type: gosnmp.Gauge32, Value: uint(51)},
+		apc + ".6.3.1.5.2":       {Type: gosnmp.Gauge32, Value: uint(48)},
+		apc + ".6.3.1.5.3":       {Type: gosnmp.Gauge32, Value: uint(50)},
+		apc + ".6.3.1.6.1":       {Type: gosnmp.Integer, Value: 229},
+		apc + ".6.3.1.6.2":       {Type: gosnmp.Integer, Value: 230},
+		apc + ".6.3.1.6.3":       {Type: gosnmp.Integer, Value: 231},
+		apc + ".6.3.1.7.1":       {Type: gosnmp.Integer, Value: 117},
+		apc + ".6.3.1.7.2":       {Type: gosnmp.Integer, Value: 110},
+		apc + ".6.3.1.7.3":       {Type: gosnmp.Integer, Value: 118},
+		apc + ".8.3.1.5.1":       {Type: gosnmp.Gauge32, Value: uint(26)},
+		apc + ".8.3.1.5.2":       {Type: gosnmp.Gauge32, Value: uint(25)},
+		apc + ".8.3.1.5.3":       {Type: gosnmp.Gauge32, Value: uint(24)},
+		apc + ".8.3.1.5.4":       {Type: gosnmp.Gauge32, Value: uint(24)},
+		apc + ".8.3.1.5.5":       {Type: gosnmp.Gauge32, Value: uint(25)},
+		apc + ".8.3.1.5.6":       {Type: gosnmp.Gauge32, Value: uint(25)},
+		apc + ".8.3.1.6.1":       {Type: gosnmp.Integer, Value: 1},
+		".1.3.6.1.4.1.99999.1.0": {Type: gosnmp.Integer, Value: 7},
+	}
+	port := startV2cAgent(t, mib)
+
+	*global.PDUList = map[string]map[string]string{
+		"apc-1": {
+			"factory": "F12", "phase": "P7", "datacenter": "DC1", "room": "R1", "rack": "A01", "side": "L",
+			"model": "AP8941", "manufacturer": "APC", "protocol": "snmp", "ip": "127.0.0.1",
+		},
+	}
+	targets, devices, measurements := snmpTargets()
+	if len(targets) != 1 {
+		t.Fatalf("got %d targets, want 1", len(targets))
+	}
+
+	opts := snmp.Options{Community: "public", Port: port, Timeout: time.Second, MaxRepetitions: 4}
+	results := snmp.PollAll(context.Background(), opts, targets, 1)
+	if results[0].Err != nil {
+		t.Fatalf("poll: %v", results[0].Err)
+	}
+	point := snmpPoint(measurements["apc-1"], devices["apc-1"], results[0])
+
+	profile, got, err := FormatPDUPoint("apc-1", point)
+	if err != nil {
+		t.Fatalf("FormatPDUPoint: %v", err)
+	}
+	if profile != "apc-rpdu2" {
+		t.Errorf("profile = %s, want apc-rpdu2", profile)
+	}
+
+	values := make(map[string]float64)
+	for _, p := range got {
+		if p.Tags["protocol"] != "" || p.Tags["ip"] != "127.0.0.1" || p.Time != point.Time {
+			t.Errorf("unexpected point %+v", p)
+		}
+		for field, value := range p.Fields {
+			key := field
+			if phase := p.Tags["phase_name"]; phase != "" {
+				key += "/" + phase
+			}
+			if branch := p.Tags["branch_name"]; branch != "" {
+				key += "/" + branch
+			}
+			values[key] = value
+		}
+	}
+	want := map[string]float64{
+		"total_current":          14.9,
+		"total_energy":           1234.5,
+		"total_watt":             3450,
+		"branch_current/L1/L1-1": 2.6,
+		"branch_current/L1/L1-2": 2.5,
+		"branch_current/L2/L2-1": 2.4,
+		"branch_current/L2/L2-2": 2.4,
+		"branch_current/L3/L3-1": 2.5,
+		"branch_current/L3/L3-2": 2.5,
+		"phase_current/L1":       5.1,
+		"phase_current/L2":       4.8,
+		"phase_current/L3":       5,
+		"phase_voltage/L1":       229,
+		"phase_voltage/L2":       230,
+		"phase_voltage/L3":       231,
+		"phase_watt/L1":          1170,
+		"phase_watt/L2":          1100,
+		"phase_watt/L3":          1180,
+	}
+	if len(values) != len(want) {
+		t.Errorf("got %d fields, want %d: %v", len(values), len(want), values)
+	}
+	for key, value := range want {
+		if values[key] != value {
+			t.Errorf("%s = %v, want %v", key, values[key], value)
+		}
+	}
+}
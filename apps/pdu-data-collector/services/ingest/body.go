@@ -0,0 +1,79 @@
+package ingest
+
+import (
+	"bimap-zbox/global"
+	"bimap-zbox/models"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const defaultMaxBodySizeMB = 32
+
+// ErrBodyTooLarge 請求內容 (解壓縮後) 超過 global.ingest.max_body_size_mb
+var ErrBodyTooLarge = errors.New("請求內容超過大小上限")
+
+// MaxBodySize 單一請求解壓縮後的大小上限 (位元組)
+func MaxBodySize() int {
+	sizeMB := global.EnvConfig.Global.Ingest.MaxBodySizeMB
+	if sizeMB <= 0 {
+		sizeMB = defaultMaxBodySizeMB
+	}
+	return sizeMB << 20
+}
+
+// PrometheusMeasurement remote-write 數據寫入的 measurement 名稱
+func PrometheusMeasurement() string {
+	if name := global.EnvConfig.Global.Ingest.PrometheusMeasurement; name != "" {
+		return name
+	}
+	return DefaultPrometheusMeasurement
+}
+
+// ReadBody 讀取請求內容，Content-Encoding 為 gzip 時解壓縮，內容超過 MaxBodySize 時回傳 ErrBodyTooLarge
+func ReadBody(r *http.Request) ([]byte, error) {
+	maxSize := MaxBodySize()
+	var reader io.Reader = r.Body
+
+	switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("gzip 解壓縮失敗: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	default:
+		return nil, fmt.Errorf("不支援的 Content-Encoding: %s", encoding)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(reader, int64(maxSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("讀取請求內容失敗: %w", err)
+	}
+	if len(data) > maxSize {
+		return nil, ErrBodyTooLarge
+	}
+	return data, nil
+}
+
+// ReadRemoteWrite 讀取並解析 Prometheus remote-write 請求 (snappy 壓縮的 protobuf)
+func ReadRemoteWrite(r *http.Request) ([]models.Point, error) {
+	maxSize := MaxBodySize()
+	if encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding != "" && encoding != "snappy" {
+		return nil, fmt.Errorf("不支援的 Content-Encoding: %s", encoding)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, int64(maxSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("讀取請求內容失敗: %w", err)
+	}
+	if len(body) > maxSize {
+		return nil, ErrBodyTooLarge
+	}
+	return DecodeRemoteWrite(body, PrometheusMeasurement(), maxSize)
+}
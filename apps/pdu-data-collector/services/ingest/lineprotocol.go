@@ -0,0 +1,246 @@
+package ingest
+
+import (
+	"bimap-zbox/models"
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseError 單行解析失敗，Line 從 1 開始
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("第 %d 行: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// 時間精度對應的換算 (v1 使用 n / u / ms / s / m / h，v2 使用 ns / us / ms / s)
+var precisions = map[string]time.Duration{
+	"":   time.Nanosecond,
+	"n":  time.Nanosecond,
+	"ns": time.Nanosecond,
+	"u":  time.Microsecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// ParsePrecision 檢查時間精度參數，未指定時為奈秒
+func ParsePrecision(precision string) (time.Duration, error) {
+	unit, ok := precisions[precision]
+	if !ok {
+		return 0, fmt.Errorf("不支援的時間精度: %s", precision)
+	}
+	return unit, nil
+}
+
+// ParseLineProtocol 解析 InfluxDB line protocol，轉換為 models.Point (時間為秒)：
+// 整數 / 浮點數直接使用，布林值轉為 1 / 0，字串欄位略過，沒有數值欄位的資料略過；
+// 未帶時間戳的資料使用 now。解析失敗的行個別回傳錯誤，其餘資料照常回傳
+func ParseLineProtocol(data []byte, unit time.Duration, now time.Time) ([]models.Point, []*ParseError) {
+	var points []models.Point
+	var errs []*ParseError
+
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		point, err := parseLine(string(line), unit, now)
+		if err != nil {
+			errs = append(errs, &ParseError{Line: i + 1, Err: err})
+			continue
+		}
+		if len(point.Fields) == 0 {
+			continue
+		}
+		points = append(points, point)
+	}
+	return points, errs
+}
+
+func parseLine(line string, unit time.Duration, now time.Time) (models.Point, error) {
+	s := &lineScanner{line: line}
+
+	name := s.token(", ")
+	if name == "" {
+		return models.Point{}, errors.New("缺少 measurement")
+	}
+	point := models.Point{
+		Name:   name,
+		Tags:   make(map[string]string),
+		Fields: make(map[string]float64),
+	}
+
+	// 標籤
+	for s.peek() == ',' {
+		s.pos++
+		key := s.token("=, ")
+		if key == "" || s.peek() != '=' {
+			return models.Point{}, fmt.Errorf("標籤格式錯誤: %s", key)
+		}
+		s.pos++
+		value := s.token(", ")
+		if value == "" {
+			return models.Point{}, fmt.Errorf("標籤 %s 缺少值", key)
+		}
+		point.Tags[key] = value
+	}
+	if s.peek() != ' ' {
+		return models.Point{}, errors.New("缺少欄位")
+	}
+	s.skipSpaces()
+
+	// 欄位
+	for {
+		key := s.token("=, ")
+		if key == "" || s.peek() != '=' {
+			return models.Point{}, fmt.Errorf("欄位格式錯誤: %s", key)
+		}
+		s.pos++
+		if s.peek() == '"' {
+			if err := s.skipString(); err != nil {
+				return models.Point{}, fmt.Errorf("欄位 %s: %v", key, err)
+			}
+		} else {
+			raw := s.token(", ")
+			value, ok, err := parseFieldValue(raw)
+			if err != nil {
+				return models.Point{}, fmt.Errorf("欄位 %s: %v", key, err)
+			}
+			if ok {
+				point.Fields[key] = value
+			}
+		}
+		if s.peek() != ',' {
+			break
+		}
+		s.pos++
+	}
+
+	// 時間戳
+	s.skipSpaces()
+	if s.done() {
+		point.Time = now.Unix()
+		return point, nil
+	}
+	raw := s.token(" ")
+	ts, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return models.Point{}, fmt.Errorf("時間戳格式錯誤: %s", raw)
+	}
+	s.skipSpaces()
+	if !s.done() {
+		return models.Point{}, fmt.Errorf("時間戳後有多餘內容: %s", line[s.pos:])
+	}
+	if unit >= time.Second {
+		point.Time = ts * int64(unit/time.Second)
+	} else {
+		point.Time = ts / int64(time.Second/unit)
+	}
+	return point, nil
+}
+
+// parseFieldValue 回傳數值與是否為數值欄位
+func parseFieldValue(raw string) (float64, bool, error) {
+	if raw == "" {
+		return 0, false, errors.New("缺少值")
+	}
+	switch raw {
+	case "t", "T", "true", "True", "TRUE":
+		return 1, true, nil
+	case "f", "F", "false", "False", "FALSE":
+		return 0, true, nil
+	}
+	switch raw[len(raw)-1] {
+	case 'i':
+		v, err := strconv.ParseInt(raw[:len(raw)-1], 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("整數格式錯誤: %s", raw)
+		}
+		return float64(v), true, nil
+	case 'u':
+		v, err := strconv.ParseUint(raw[:len(raw)-1], 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("無號整數格式錯誤: %s", raw)
+		}
+		return float64(v), true, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("數值格式錯誤: %s", raw)
+	}
+	return v, true, nil
+}
+
+// lineScanner 逐字元讀取單行，處理反斜線跳脫
+type lineScanner struct {
+	line string
+	pos  int
+}
+
+func (s *lineScanner) done() bool {
+	return s.pos >= len(s.line)
+}
+
+func (s *lineScanner) peek() byte {
+	if s.done() {
+		return 0
+	}
+	return s.line[s.pos]
+}
+
+func (s *lineScanner) skipSpaces() {
+	for !s.done() && s.line[s.pos] == ' ' {
+		s.pos++
+	}
+}
+
+// token 讀取到未跳脫的分隔字元為止，並移除跳脫用的反斜線
+func (s *lineScanner) token(delims string) string {
+	var b strings.Builder
+	for !s.done() {
+		c := s.line[s.pos]
+		if c == '\\' && s.pos+1 < len(s.line) && strings.IndexByte(",= \\\"", s.line[s.pos+1]) >= 0 {
+			b.WriteByte(s.line[s.pos+1])
+			s.pos += 2
+			continue
+		}
+		if strings.IndexByte(delims, c) >= 0 {
+			break
+		}
+		b.WriteByte(c)
+		s.pos++
+	}
+	return b.String()
+}
+
+// skipString 略過雙引號字串欄位
+func (s *lineScanner) skipString() error {
+	s.pos++
+	for !s.done() {
+		switch s.line[s.pos] {
+		case '\\':
+			s.pos += 2
+			continue
+		case '"':
+			s.pos++
+			return nil
+		}
+		s.pos++
+	}
+	return errors.New("字串缺少結尾引號")
+}
@@ -0,0 +1,205 @@
+package ingest
+
+import (
+	"bimap-zbox/models"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Prometheus remote-write (prometheus.WriteRequest) 只解析 timeseries 的 labels 與 samples，
+// exemplars / histograms / metadata 略過，以 protowire 直接解析避免引入 prompb
+
+// DefaultPrometheusMeasurement remote-write 數據未指定 measurement 時使用的名稱
+const DefaultPrometheusMeasurement = "prometheus"
+
+const metricNameLabel = "__name__"
+
+// DecodeRemoteWrite 解壓縮並解析 remote-write 請求，轉換為 models.Point (時間為秒)：
+// 標籤相同 (不含 __name__) 且時間相同的樣本合併為同一筆，__name__ 為欄位名稱；
+// NaN (含 stale marker) 與 ±Inf 無法寫入 InfluxDB，直接略過
+func DecodeRemoteWrite(body []byte, measurement string, maxLen int) ([]models.Point, error) {
+	data, err := decodeSnappy(body, maxLen)
+	if err != nil {
+		return nil, err
+	}
+	if measurement == "" {
+		measurement = DefaultPrometheusMeasurement
+	}
+
+	var points []models.Point
+	index := make(map[string]int)
+
+	err = eachField(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		name, labels, samples, err := decodeTimeSeries(value)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			return errors.New("timeseries 缺少 __name__ 標籤")
+		}
+		seriesKey := labelsKey(labels)
+		for _, sample := range samples {
+			if math.IsNaN(sample.value) || math.IsInf(sample.value, 0) {
+				continue
+			}
+			ts := sample.timestamp / 1000
+			key := fmt.Sprintf("%s%d", seriesKey, ts)
+			i, ok := index[key]
+			if !ok {
+				tags := make(map[string]string, len(labels))
+				for k, v := range labels {
+					tags[k] = v
+				}
+				points = append(points, models.Point{
+					Name:   measurement,
+					Tags:   tags,
+					Fields: make(map[string]float64),
+					Time:   ts,
+				})
+				i = len(points) - 1
+				index[key] = i
+			}
+			points[i].Fields[name] = sample.value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("remote-write 格式錯誤: %w", err)
+	}
+	return points, nil
+}
+
+type sample struct {
+	value     float64
+	timestamp int64 // 毫秒
+}
+
+func decodeTimeSeries(data []byte) (string, map[string]string, []sample, error) {
+	var name string
+	labels := make(map[string]string)
+	var samples []sample
+
+	err := eachField(data, func(num protowire.Number, typ protowire.Type, value []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1: // labels
+			var labelName, labelValue string
+			err := eachField(value, func(num protowire.Number, typ protowire.Type, value []byte) error {
+				if typ != protowire.BytesType {
+					return nil
+				}
+				switch num {
+				case 1:
+					labelName = string(value)
+				case 2:
+					labelValue = string(value)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if labelName == metricNameLabel {
+				name = labelValue
+			} else if labelName != "" {
+				labels[labelName] = labelValue
+			}
+		case 2: // samples
+			s, err := decodeSample(value)
+			if err != nil {
+				return err
+			}
+			samples = append(samples, s)
+		}
+		return nil
+	})
+	return name, labels, samples, err
+}
+
+func decodeSample(data []byte) (sample, error) {
+	var s sample
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return s, protowire.ParseError(n)
+		}
+		data = data[n:]
+		switch {
+		case num == 1 && typ == protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(data)
+			if n < 0 {
+				return s, protowire.ParseError(n)
+			}
+			s.value = math.Float64frombits(v)
+			data = data[n:]
+		case num == 2 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return s, protowire.ParseError(n)
+			}
+			s.timestamp = int64(v)
+			data = data[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, data)
+			if n < 0 {
+				return s, protowire.ParseError(n)
+			}
+			data = data[n:]
+		}
+	}
+	return s, nil
+}
+
+// eachField 依序讀取 message 的欄位，length-delimited 欄位回傳內容，其他型別只略過
+func eachField(data []byte, fn func(num protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		var value []byte
+		if typ == protowire.BytesType {
+			value, n = protowire.ConsumeBytes(data)
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if err := fn(num, typ, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func labelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte(0xff)
+		b.WriteString(labels[k])
+		b.WriteByte(0xff)
+	}
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package ingest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/snappy"
+)
+
+// Prometheus remote-write 以 snappy block format 壓縮 (非 framing format)
+
+var errSnappyCorrupt = errors.New("snappy 資料損毀")
+
+// decodeSnappy 解壓縮 snappy block，解壓後大小超過 maxLen (>0) 時回傳 ErrBodyTooLarge
+// 先讀取標頭的解壓後長度檢查上限，避免惡意請求配置過大的緩衝區
+func decodeSnappy(src []byte, maxLen int) ([]byte, error) {
+	n, err := snappy.DecodedLen(src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errSnappyCorrupt, err)
+	}
+	if maxLen > 0 && n > maxLen {
+		return nil, ErrBodyTooLarge
+	}
+	dst, err := snappy.Decode(nil, src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errSnappyCorrupt, err)
+	}
+	return dst, nil
+}
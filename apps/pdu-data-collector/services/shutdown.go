@@ -0,0 +1,69 @@
+package services
+
+import (
+	"bimap-zbox/global"
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 收到 SIGTERM / SIGINT 時依註冊的相反順序執行關閉程序 (例如送出批次中的資料)，
+// 全部完成或超過 global.shutdown_timeout 後結束程式
+
+const defaultShutdownTimeout = 30 * time.Second
+
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+var (
+	shutdownMu    sync.Mutex
+	shutdownHooks []shutdownHook
+	shutdownOnce  sync.Once
+)
+
+// RegisterShutdown 註冊關閉程序，第一次註冊時開始監聽結束信號
+func RegisterShutdown(name string, fn func(ctx context.Context) error) {
+	shutdownMu.Lock()
+	shutdownHooks = append(shutdownHooks, shutdownHook{name: name, fn: fn})
+	shutdownMu.Unlock()
+
+	shutdownOnce.Do(func() {
+		go func() {
+			signals := make(chan os.Signal, 1)
+			signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+			sig := <-signals
+			global.Logger.Info("收到結束信號，開始關閉", zap.String("signal", sig.String()))
+			Shutdown()
+			os.Exit(0)
+		}()
+	})
+}
+
+// Shutdown 依註冊的相反順序執行關閉程序
+func Shutdown() {
+	timeout := defaultShutdownTimeout
+	if global.EnvConfig.Global.ShutdownTimeout > 0 {
+		timeout = time.Duration(global.EnvConfig.Global.ShutdownTimeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	shutdownMu.Lock()
+	hooks := append([]shutdownHook(nil), shutdownHooks...)
+	shutdownMu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].fn(ctx); err != nil {
+			global.Logger.Error("關閉失敗", zap.String("name", hooks[i].name), zap.Error(err))
+			continue
+		}
+		global.Logger.Info("關閉完成", zap.String("name", hooks[i].name))
+	}
+}
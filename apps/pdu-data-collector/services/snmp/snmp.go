@@ -0,0 +1,328 @@
+package snmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// SNMP v2c / v3 輪詢：純量 OID 以 GET 一次取得 (每次最多 gosnmp.MaxOids 個)，表格 OID 以 GETBULK walk 取得，
+// 逾時與重試由 gosnmp 處理，每台設備使用獨立的連線
+
+const (
+	defaultPort           = 161
+	defaultTimeout        = 2 * time.Second
+	defaultMaxRepetitions = 20
+	defaultConcurrency    = 20
+)
+
+// Options 連線與認證設定
+type Options struct {
+	Version        string // 2c / 3
+	Community      string
+	Port           uint16
+	Timeout        time.Duration // 單一請求逾時
+	Retries        int
+	MaxRepetitions uint32
+	V3             V3Options
+}
+
+// V3Options SNMP v3 USM 認證
+type V3Options struct {
+	Username      string
+	SecurityLevel string // noAuthNoPriv / authNoPriv / authPriv
+	AuthProtocol  string
+	AuthPassword  string
+	PrivProtocol  string
+	PrivPassword  string
+	ContextName   string
+}
+
+// OID 輪詢的 OID，Table 為 true 時 walk 整個欄位，結果名稱為 {Name}_{index}
+type OID struct {
+	Name  string
+	OID   string
+	Table bool
+}
+
+// Target 輪詢對象，Fields 轉為數值欄位，Tags 轉為字串標籤
+type Target struct {
+	Key     string
+	Address string
+	Fields  []OID
+	Tags    []OID
+}
+
+// Result 單一設備的輪詢結果
+type Result struct {
+	Target   Target
+	Time     time.Time // 開始輪詢的時間
+	Duration time.Duration
+	Fields   map[string]float64
+	Tags     map[string]string
+	Err      error
+}
+
+var authProtocols = map[string]gosnmp.SnmpV3AuthProtocol{
+	"":       gosnmp.NoAuth,
+	"MD5":    gosnmp.MD5,
+	"SHA":    gosnmp.SHA,
+	"SHA224": gosnmp.SHA224,
+	"SHA256": gosnmp.SHA256,
+	"SHA384": gosnmp.SHA384,
+	"SHA512": gosnmp.SHA512,
+}
+
+var privProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+	"":        gosnmp.NoPriv,
+	"DES":     gosnmp.DES,
+	"AES":     gosnmp.AES,
+	"AES192":  gosnmp.AES192,
+	"AES256":  gosnmp.AES256,
+	"AES192C": gosnmp.AES192C,
+	"AES256C": gosnmp.AES256C,
+}
+
+var securityLevels = map[string]gosnmp.SnmpV3MsgFlags{
+	"noauthnopriv": gosnmp.NoAuthNoPriv,
+	"authnopriv":   gosnmp.AuthNoPriv,
+	"authpriv":     gosnmp.AuthPriv,
+}
+
+// Validate 檢查版本與 v3 認證設定
+func (o Options) Validate() error {
+	switch o.Version {
+	case "", "2c":
+		return nil
+	case "3":
+	default:
+		return fmt.Errorf("不支援的 SNMP 版本: %s", o.Version)
+	}
+
+	if o.V3.Username == "" {
+		return errors.New("SNMP v3 缺少 username")
+	}
+	level, ok := securityLevels[strings.ToLower(o.V3.SecurityLevel)]
+	if !ok {
+		return fmt.Errorf("不支援的 security_level: %s", o.V3.SecurityLevel)
+	}
+	if _, ok := authProtocols[strings.ToUpper(o.V3.AuthProtocol)]; !ok {
+		return fmt.Errorf("不支援的 auth_protocol: %s", o.V3.AuthProtocol)
+	}
+	if _, ok := privProtocols[strings.ToUpper(o.V3.PrivProtocol)]; !ok {
+		return fmt.Errorf("不支援的 priv_protocol: %s", o.V3.PrivProtocol)
+	}
+	if level != gosnmp.NoAuthNoPriv && (o.V3.AuthProtocol == "" || o.V3.AuthPassword == "") {
+		return errors.New("authNoPriv / authPriv 需設定 auth_protocol 與 auth_password")
+	}
+	if level == gosnmp.AuthPriv && (o.V3.PrivProtocol == "" || o.V3.PrivPassword == "") {
+		return errors.New("authPriv 需設定 priv_protocol 與 priv_password")
+	}
+	return nil
+}
+
+// client 建立單一設備的連線設定 (v3 的 security parameters 含狀態，不可在設備間共用)
+func (o Options) client(ctx context.Context, address string) *gosnmp.GoSNMP {
+	port := o.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	timeout := o.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	maxRepetitions := o.MaxRepetitions
+	if maxRepetitions == 0 {
+		maxRepetitions = defaultMaxRepetitions
+	}
+
+	g := &gosnmp.GoSNMP{
+		Context:        ctx,
+		Target:         address,
+		Port:           port,
+		Transport:      "udp",
+		Community:      o.Community,
+		Version:        gosnmp.Version2c,
+		Timeout:        timeout,
+		Retries:        o.Retries,
+		MaxOids:        gosnmp.MaxOids,
+		MaxRepetitions: maxRepetitions,
+	}
+	if o.Version == "3" {
+		g.Version = gosnmp.Version3
+		g.SecurityModel = gosnmp.UserSecurityModel
+		g.MsgFlags = securityLevels[strings.ToLower(o.V3.SecurityLevel)]
+		g.ContextName = o.V3.ContextName
+		g.SecurityParameters = &gosnmp.UsmSecurityParameters{
+			UserName:                 o.V3.Username,
+			AuthenticationProtocol:   authProtocols[strings.ToUpper(o.V3.AuthProtocol)],
+			AuthenticationPassphrase: o.V3.AuthPassword,
+			PrivacyProtocol:          privProtocols[strings.ToUpper(o.V3.PrivProtocol)],
+			PrivacyPassphrase:        o.V3.PrivPassword,
+		}
+	}
+	return g
+}
+
+// PollAll 以最多 concurrency 台設備同時輪詢，回傳順序與 targets 相同
+func PollAll(ctx context.Context, opts Options, targets []Target, concurrency int) []Result {
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	results := make([]Result, len(targets))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, target Target) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = Poll(ctx, opts, target)
+		}(i, target)
+	}
+	wg.Wait()
+	return results
+}
+
+// Poll 輪詢單一設備，連線或請求失敗時回傳錯誤；設備不支援的 OID 略過
+func Poll(ctx context.Context, opts Options, target Target) Result {
+	result := Result{
+		Target: target,
+		Time:   time.Now(),
+		Fields: make(map[string]float64),
+		Tags:   make(map[string]string),
+	}
+	result.Err = poll(ctx, opts, target, &result)
+	result.Duration = time.Since(result.Time)
+	if result.Err == nil && len(result.Fields) == 0 {
+		result.Err = errors.New("沒有取得任何數值")
+	}
+	return result
+}
+
+func poll(ctx context.Context, opts Options, target Target, result *Result) error {
+	g := opts.client(ctx, target.Address)
+	if err := g.Connect(); err != nil {
+		return fmt.Errorf("連線失敗: %w", err)
+	}
+	defer g.Conn.Close()
+
+	groups := []struct {
+		oids  []OID
+		field bool
+	}{
+		{target.Fields, true},
+		{target.Tags, false},
+	}
+
+	// 純量 OID
+	type scalar struct {
+		name  string
+		field bool
+	}
+	scalars := make(map[string]scalar)
+	var oids []string
+	for _, group := range groups {
+		for _, oid := range group.oids {
+			if !oid.Table {
+				name := normalizeOID(oid.OID)
+				scalars[name] = scalar{name: oid.Name, field: group.field}
+				oids = append(oids, name)
+			}
+		}
+	}
+	for start := 0; start < len(oids); start += g.MaxOids {
+		end := min(start+g.MaxOids, len(oids))
+		packet, err := g.Get(oids[start:end])
+		if err != nil {
+			return fmt.Errorf("GET 失敗: %w", err)
+		}
+		if packet.Error != gosnmp.NoError {
+			return fmt.Errorf("GET 失敗: %v", packet.Error)
+		}
+		for _, pdu := range packet.Variables {
+			if s, ok := scalars[pdu.Name]; ok {
+				setValue(result, s.name, pdu, s.field)
+			}
+		}
+	}
+
+	// 表格 OID
+	for _, group := range groups {
+		for _, oid := range group.oids {
+			if !oid.Table {
+				continue
+			}
+			root := normalizeOID(oid.OID)
+			pdus, err := g.BulkWalkAll(root)
+			if err != nil {
+				return fmt.Errorf("walk %s 失敗: %w", oid.Name, err)
+			}
+			for _, pdu := range pdus {
+				index, ok := strings.CutPrefix(pdu.Name, root+".")
+				if !ok {
+					continue
+				}
+				setValue(result, oid.Name+"_"+index, pdu, group.field)
+			}
+		}
+	}
+	return nil
+}
+
+func setValue(result *Result, name string, pdu gosnmp.SnmpPDU, field bool) {
+	if field {
+		if value, ok := toFloat(pdu); ok {
+			result.Fields[name] = value
+		}
+		return
+	}
+	if value, ok := toString(pdu); ok {
+		result.Tags[name] = value
+	}
+}
+
+// toFloat 數值型別直接轉換，字串型別嘗試解析為數值 (部分設備以字串回報量測值)
+func toFloat(pdu gosnmp.SnmpPDU) (float64, bool) {
+	switch pdu.Type {
+	case gosnmp.Integer, gosnmp.Counter32, gosnmp.Gauge32, gosnmp.Counter64, gosnmp.TimeTicks, gosnmp.Uinteger32:
+		value, _ := new(big.Float).SetInt(gosnmp.ToBigInt(pdu.Value)).Float64()
+		return value, true
+	case gosnmp.OpaqueFloat:
+		value, ok := pdu.Value.(float32)
+		return float64(value), ok
+	case gosnmp.OpaqueDouble:
+		value, ok := pdu.Value.(float64)
+		return value, ok
+	case gosnmp.OctetString:
+		data, _ := pdu.Value.([]byte)
+		value, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+		return value, err == nil
+	}
+	return 0, false
+}
+
+func toString(pdu gosnmp.SnmpPDU) (string, bool) {
+	switch pdu.Type {
+	case gosnmp.NoSuchObject, gosnmp.NoSuchInstance, gosnmp.EndOfMibView, gosnmp.Null:
+		return "", false
+	case gosnmp.OctetString:
+		data, _ := pdu.Value.([]byte)
+		value := strings.TrimSpace(string(data))
+		return value, value != ""
+	}
+	return fmt.Sprint(pdu.Value), true
+}
+
+func normalizeOID(oid string) string {
+	return "." + strings.Trim(strings.TrimSpace(oid), ".")
+}
@@ -0,0 +1,565 @@
+package snmp
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+const (
+	testEngineID = "\x80\x00\x1f\x88\x80stub-agent"
+
+	usmStatsUnknownEngineIDs = ".1.3.6.1.6.3.15.1.1.4.0"
+	usmStatsUnknownUserNames = ".1.3.6.1.6.3.15.1.1.3.0"
+	usmStatsWrongDigests     = ".1.3.6.1.6.3.15.1.1.5.0"
+)
+
+// agent 測試用的 SNMP agent，在本機 UDP 回應 GET / GETNEXT / GETBULK
+// usm 為 nil 時只接受 v2c；設定 usm 時只接受 v3，處理 engine ID discovery 並檢查認證
+type agent struct {
+	community string
+	usm       *gosnmp.UsmSecurityParameters
+	silent    bool // 收到請求但不回應 (模擬逾時)
+	mib       []gosnmp.SnmpPDU
+
+	conn         net.PacketConn
+	requests     atomic.Int32
+	authFailures atomic.Int32
+}
+
+// testMIB APC rPDU2 的部分 OID，外加字串型別的數值
+func testMIB() []gosnmp.SnmpPDU {
+	return []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: []byte("apc-a01-l ")},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.4.3.1.5.1", Type: gosnmp.Integer, Value: 345},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.4.3.1.9.1", Type: gosnmp.Counter64, Value: uint64(12345)},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.6.3.1.5.1", Type: gosnmp.Gauge32, Value: uint(51)},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.6.3.1.5.2", Type: gosnmp.Gauge32, Value: uint(48)},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.6.3.1.5.3", Type: gosnmp.Gauge32, Value: uint(50)},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.6.3.1.6.1", Type: gosnmp.Integer, Value: 229},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.6.3.1.6.2", Type: gosnmp.Integer, Value: 230},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.6.3.1.6.3", Type: gosnmp.Integer, Value: 231},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.6.3.1.7.1", Type: gosnmp.Integer, Value: 117},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.8.3.1.5.1", Type: gosnmp.Gauge32, Value: uint(26)},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.8.3.1.5.2", Type: gosnmp.Gauge32, Value: uint(25)},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.8.3.1.5.3", Type: gosnmp.Gauge32, Value: uint(24)},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.8.3.1.5.4", Type: gosnmp.Gauge32, Value: uint(24)},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.8.3.1.5.5", Type: gosnmp.Gauge32, Value: uint(25)},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.8.3.1.5.6", Type: gosnmp.Gauge32, Value: uint(25)},
+		{Name: ".1.3.6.1.4.1.318.1.1.26.8.3.1.6.1", Type: gosnmp.Integer, Value: 1},
+		{Name: ".1.3.6.1.4.1.99999.1.0", Type: gosnmp.OctetString, Value: []byte(" 23.5 ")},
+	}
+}
+
+// testTarget 對應 pdu_profiles.yml 中 apc-rpdu2 的 snmp 設定，外加字串數值與設備不支援的 OID
+func testTarget(port uint16) Target {
+	return Target{
+		Key:     "pdu-1",
+		Address: "127.0.0.1",
+		Fields: []OID{
+			{Name: "rPDU2PhaseStatusCurrent", OID: ".1.3.6.1.4.1.318.1.1.26.6.3.1.5", Table: true},
+			{Name: "rPDU2PhaseStatusVoltage", OID: "1.3.6.1.4.1.318.1.1.26.6.3.1.6.", Table: true},
+			{Name: "rPDU2PhaseStatusPower", OID: ".1.3.6.1.4.1.318.1.1.26.6.3.1.7", Table: true},
+			{Name: "rPDU2BankStatusCurrent", OID: ".1.3.6.1.4.1.318.1.1.26.8.3.1.5", Table: true},
+			{Name: "rPDU2DeviceStatusPower", OID: ".1.3.6.1.4.1.318.1.1.26.4.3.1.5.1"},
+			{Name: "rPDU2DeviceStatusEnergy", OID: " .1.3.6.1.4.1.318.1.1.26.4.3.1.9.1"},
+			{Name: "temperature", OID: ".1.3.6.1.4.1.99999.1.0"},
+			{Name: "unsupported", OID: ".1.3.6.1.4.1.99999.2.0"},
+		},
+		Tags: []OID{
+			{Name: "sys_name", OID: ".1.3.6.1.2.1.1.5.0"},
+		},
+	}
+}
+
+// wantFields testTarget 在 testMIB 上應取得的欄位
+var wantFields = map[string]float64{
+	"rPDU2PhaseStatusCurrent_1": 51,
+	"rPDU2PhaseStatusCurrent_2": 48,
+	"rPDU2PhaseStatusCurrent_3": 50,
+	"rPDU2PhaseStatusVoltage_1": 229,
+	"rPDU2PhaseStatusVoltage_2": 230,
+	"rPDU2PhaseStatusVoltage_3": 231,
+	"rPDU2PhaseStatusPower_1":   117,
+	"rPDU2BankStatusCurrent_1":  26,
+	"rPDU2BankStatusCurrent_2":  25,
+	"rPDU2BankStatusCurrent_3":  24,
+	"rPDU2BankStatusCurrent_4":  24,
+	"rPDU2BankStatusCurrent_5":  25,
+	"rPDU2BankStatusCurrent_6":  25,
+	"rPDU2DeviceStatusPower":    345,
+	"rPDU2DeviceStatusEnergy":   12345,
+	"temperature":               23.5,
+}
+
+func startAgent(t *testing.T, a *agent) uint16 {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	a.conn = conn
+	sort.Slice(a.mib, func(i, j int) bool {
+		return compareOID(a.mib[i].Name, a.mib[j].Name) < 0
+	})
+	if a.usm != nil {
+		if err := a.usm.InitSecurityKeys(); err != nil {
+			t.Fatalf("init agent keys: %v", err)
+		}
+	}
+	go a.serve()
+	t.Cleanup(func() { conn.Close() })
+	return uint16(conn.LocalAddr().(*net.UDPAddr).Port)
+}
+
+func (a *agent) serve() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := a.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		a.requests.Add(1)
+		if a.silent {
+			continue
+		}
+		msg := append([]byte(nil), buf[:n]...)
+		var resp []byte
+		if a.usm == nil {
+			resp = a.handleV2c(msg)
+		} else {
+			resp = a.handleV3(msg)
+		}
+		if resp != nil {
+			a.conn.WriteTo(resp, addr)
+		}
+	}
+}
+
+// handleV2c community 不符時與一般設備相同不回應
+func (a *agent) handleV2c(msg []byte) []byte {
+	decoder := &gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: a.community}
+	req, err := decoder.SnmpDecodePacket(msg)
+	if err != nil || req.Version != gosnmp.Version2c || req.Community != a.community {
+		return nil
+	}
+	resp := &gosnmp.SnmpPacket{
+		Version:   gosnmp.Version2c,
+		Community: req.Community,
+		PDUType:   gosnmp.GetResponse,
+		RequestID: req.RequestID,
+		Variables: a.respond(req),
+	}
+	out, err := resp.MarshalMsg()
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+func (a *agent) handleV3(msg []byte) []byte {
+	decoder := &gosnmp.GoSNMP{
+		Version:            gosnmp.Version3,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		SecurityParameters: a.usm.Copy(),
+	}
+	// 以 agent 的金鑰檢查 digest 並解密
+	req, err := decoder.UnmarshalTrap(msg, true)
+	if err != nil {
+		a.authFailures.Add(1)
+		header := &gosnmp.GoSNMP{
+			Version:            gosnmp.Version3,
+			SecurityModel:      gosnmp.UserSecurityModel,
+			MsgFlags:           gosnmp.NoAuthNoPriv,
+			SecurityParameters: &gosnmp.UsmSecurityParameters{UserName: "unknown"},
+		}
+		packet, _ := header.SnmpDecodePacket(msg)
+		if packet == nil {
+			return nil
+		}
+		return a.report(packet, usmStatsWrongDigests)
+	}
+
+	usm := req.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if usm.AuthoritativeEngineID == "" {
+		return a.report(req, usmStatsUnknownEngineIDs)
+	}
+	if usm.UserName != a.usm.UserName {
+		return a.report(req, usmStatsUnknownUserNames)
+	}
+
+	params := a.usm.Copy().(*gosnmp.UsmSecurityParameters)
+	resp := &gosnmp.SnmpPacket{
+		Version:            gosnmp.Version3,
+		MsgFlags:           req.MsgFlags &^ gosnmp.Reportable,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		SecurityParameters: params,
+		MsgID:              req.MsgID,
+		ContextEngineID:    testEngineID,
+		ContextName:        req.ContextName,
+		PDUType:            gosnmp.GetResponse,
+		RequestID:          req.RequestID,
+		Variables:          a.respond(req),
+	}
+	if err := params.InitPacket(resp); err != nil {
+		return nil
+	}
+	out, err := resp.MarshalMsg()
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+// report 未認證的 Report PDU (engine ID discovery 與錯誤回報)
+func (a *agent) report(req *gosnmp.SnmpPacket, oid string) []byte {
+	resp := &gosnmp.SnmpPacket{
+		Version:       gosnmp.Version3,
+		MsgFlags:      gosnmp.NoAuthNoPriv,
+		SecurityModel: gosnmp.UserSecurityModel,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			AuthoritativeEngineID:    testEngineID,
+			AuthoritativeEngineBoots: a.usm.AuthoritativeEngineBoots,
+			AuthoritativeEngineTime:  a.usm.AuthoritativeEngineTime,
+		},
+		MsgID:           req.MsgID,
+		ContextEngineID: testEngineID,
+		PDUType:         gosnmp.Report,
+		RequestID:       req.RequestID,
+		Variables:       []gosnmp.SnmpPDU{{Name: oid, Type: gosnmp.Counter32, Value: uint32(1)}},
+	}
+	out, err := resp.MarshalMsg()
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+func (a *agent) respond(req *gosnmp.SnmpPacket) []gosnmp.SnmpPDU {
+	var vars []gosnmp.SnmpPDU
+	for i, v := range req.Variables {
+		switch req.PDUType {
+		case gosnmp.GetRequest:
+			vars = append(vars, a.get(v.Name))
+		case gosnmp.GetNextRequest:
+			vars = append(vars, a.next(v.Name))
+		case gosnmp.GetBulkRequest:
+			if i < int(req.NonRepeaters) {
+				vars = append(vars, a.next(v.Name))
+				continue
+			}
+			name := v.Name
+			for r := uint32(0); r < req.MaxRepetitions; r++ {
+				pdu := a.next(name)
+				vars = append(vars, pdu)
+				if pdu.Type == gosnmp.EndOfMibView {
+					break
+				}
+				name = pdu.Name
+			}
+		}
+	}
+	return vars
+}
+
+func (a *agent) get(oid string) gosnmp.SnmpPDU {
+	for _, pdu := range a.mib {
+		if compareOID(pdu.Name, oid) == 0 {
+			return pdu
+		}
+	}
+	return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.NoSuchObject}
+}
+
+func (a *agent) next(oid string) gosnmp.SnmpPDU {
+	for _, pdu := range a.mib {
+		if compareOID(pdu.Name, oid) > 0 {
+			return pdu
+		}
+	}
+	return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.EndOfMibView}
+}
+
+// compareOID 依各節點的數值比較 OID
+func compareOID(a, b string) int {
+	as := strings.Split(strings.Trim(a, "."), ".")
+	bs := strings.Split(strings.Trim(b, "."), ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(as) - len(bs)
+}
+
+func checkResult(t *testing.T, result Result) {
+	t.Helper()
+	if result.Err != nil {
+		t.Fatalf("Poll error: %v", result.Err)
+	}
+	if len(result.Fields) != len(wantFields) {
+		t.Errorf("got %d fields, want %d: %v", len(result.Fields), len(wantFields), result.Fields)
+	}
+	for name, want := range wantFields {
+		if got, ok := result.Fields[name]; !ok || got != want {
+			t.Errorf("field %s = %v (%v), want %v", name, got, ok, want)
+		}
+	}
+	if len(result.Tags) != 1 || result.Tags["sys_name"] != "apc-a01-l" {
+		t.Errorf("tags = %v, want sys_name=apc-a01-l", result.Tags)
+	}
+	if result.Target.Key != "pdu-1" || result.Time.IsZero() || result.Duration <= 0 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestPollV2c(t *testing.T) {
+	a := &agent{community: "public", mib: testMIB()}
+	port := startAgent(t, a)
+
+	// MaxRepetitions 2：bank 表格 6 筆需多次 GETBULK
+	opts := Options{Version: "2c", Community: "public", Port: port, Timeout: time.Second, MaxRepetitions: 2}
+	checkResult(t, Poll(context.Background(), opts, testTarget(port)))
+}
+
+func TestPollV3(t *testing.T) {
+	tests := []struct {
+		level string
+		auth  gosnmp.SnmpV3AuthProtocol
+		priv  gosnmp.SnmpV3PrivProtocol
+		opts  V3Options
+	}{
+		{"noAuthNoPriv", gosnmp.NoAuth, gosnmp.NoPriv, V3Options{SecurityLevel: "noAuthNoPriv"}},
+		{"authNoPriv MD5", gosnmp.MD5, gosnmp.NoPriv, V3Options{SecurityLevel: "authNoPriv", AuthProtocol: "md5", AuthPassword: "auth-pass-1"}},
+		{"authNoPriv SHA256", gosnmp.SHA256, gosnmp.NoPriv, V3Options{SecurityLevel: "authNoPriv", AuthProtocol: "SHA256", AuthPassword: "auth-pass-1"}},
+		{"authPriv SHA AES", gosnmp.SHA, gosnmp.AES, V3Options{SecurityLevel: "authPriv", AuthProtocol: "SHA", AuthPassword: "auth-pass-1", PrivProtocol: "AES", PrivPassword: "priv-pass-1"}},
+		{"authPriv SHA DES", gosnmp.SHA, gosnmp.DES, V3Options{SecurityLevel: "AuthPriv", AuthProtocol: "SHA", AuthPassword: "auth-pass-1", PrivProtocol: "des", PrivPassword: "priv-pass-1"}},
+		{"authPriv SHA512 AES256", gosnmp.SHA512, gosnmp.AES256, V3Options{SecurityLevel: "authPriv", AuthProtocol: "SHA512", AuthPassword: "auth-pass-1", PrivProtocol: "AES256", PrivPassword: "priv-pass-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			a := &agent{
+				mib: testMIB(),
+				usm: &gosnmp.UsmSecurityParameters{
+					UserName:                 "viz",
+					AuthoritativeEngineID:    testEngineID,
+					AuthoritativeEngineBoots: 3,
+					AuthoritativeEngineTime:  1000,
+					AuthenticationProtocol:   tt.auth,
+					AuthenticationPassphrase: tt.opts.AuthPassword,
+					PrivacyProtocol:          tt.priv,
+					PrivacyPassphrase:        tt.opts.PrivPassword,
+				},
+			}
+			port := startAgent(t, a)
+
+			v3 := tt.opts
+			v3.Username = "viz"
+			opts := Options{Version: "3", Port: port, Timeout: time.Second, MaxRepetitions: 4, V3: v3}
+			if err := opts.Validate(); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			checkResult(t, Poll(context.Background(), opts, testTarget(port)))
+			if n := a.authFailures.Load(); n != 0 {
+				t.Errorf("agent auth failures = %d, want 0", n)
+			}
+		})
+	}
+}
+
+func TestPollV3AuthFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		v3   V3Options
+	}{
+		{"wrong auth password", V3Options{Username: "viz", SecurityLevel: "authNoPriv", AuthProtocol: "SHA", AuthPassword: "wrong-pass-1"}},
+		{"wrong auth protocol", V3Options{Username: "viz", SecurityLevel: "authNoPriv", AuthProtocol: "MD5", AuthPassword: "auth-pass-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &agent{
+				mib: testMIB(),
+				usm: &gosnmp.UsmSecurityParameters{
+					UserName:                 "viz",
+					AuthoritativeEngineID:    testEngineID,
+					AuthoritativeEngineBoots: 3,
+					AuthoritativeEngineTime:  1000,
+					AuthenticationProtocol:   gosnmp.SHA,
+					AuthenticationPassphrase: "auth-pass-1",
+					PrivacyProtocol:          gosnmp.NoPriv,
+				},
+			}
+			port := startAgent(t, a)
+
+			opts := Options{Version: "3", Port: port, Timeout: 300 * time.Millisecond, V3: tt.v3}
+			result := Poll(context.Background(), opts, testTarget(port))
+			if result.Err == nil {
+				t.Fatal("Poll with wrong credentials succeeded")
+			}
+			if len(result.Fields) != 0 || len(result.Tags) != 0 {
+				t.Errorf("got values with wrong credentials: %v %v", result.Fields, result.Tags)
+			}
+			if a.authFailures.Load() == 0 {
+				t.Error("agent did not see an authentication failure")
+			}
+		})
+	}
+}
+
+func TestPollTimeout(t *testing.T) {
+	a := &agent{community: "public", mib: testMIB(), silent: true}
+	port := startAgent(t, a)
+
+	opts := Options{Community: "public", Port: port, Timeout: 100 * time.Millisecond, Retries: 1}
+	start := time.Now()
+	result := Poll(context.Background(), opts, testTarget(port))
+	elapsed := time.Since(start)
+
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "timeout") {
+		t.Fatalf("Poll error = %v, want timeout", result.Err)
+	}
+	// 第一次請求加上 1 次重試
+	if n := a.requests.Load(); n != 2 {
+		t.Errorf("agent received %d requests, want 2", n)
+	}
+	if elapsed < 200*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("Poll took %s, want about 200ms", elapsed)
+	}
+}
+
+func TestPollWrongCommunity(t *testing.T) {
+	a := &agent{community: "private", mib: testMIB()}
+	port := startAgent(t, a)
+
+	opts := Options{Community: "public", Port: port, Timeout: 100 * time.Millisecond}
+	result := Poll(context.Background(), opts, testTarget(port))
+	if result.Err == nil {
+		t.Fatal("Poll with wrong community succeeded")
+	}
+	if a.requests.Load() == 0 {
+		t.Error("agent received no requests")
+	}
+}
+
+func TestPollNoValues(t *testing.T) {
+	a := &agent{community: "public", mib: testMIB()[:1]} // 只有 sysName
+	port := startAgent(t, a)
+
+	opts := Options{Community: "public", Port: port, Timeout: time.Second}
+	result := Poll(context.Background(), opts, testTarget(port))
+	if result.Err == nil || result.Err.Error() != "沒有取得任何數值" {
+		t.Fatalf("Poll error = %v, want 沒有取得任何數值", result.Err)
+	}
+	if result.Tags["sys_name"] != "apc-a01-l" {
+		t.Errorf("tags = %v", result.Tags)
+	}
+}
+
+func TestPollAll(t *testing.T) {
+	a := &agent{community: "public", mib: testMIB()}
+	port := startAgent(t, a)
+
+	// 已關閉的 port 沒有 agent 回應
+	closed, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadPort := closed.LocalAddr().(*net.UDPAddr).Port
+	closed.Close()
+
+	targets := make([]Target, 5)
+	for i := range targets {
+		targets[i] = testTarget(port)
+		targets[i].Key = "pdu-" + strconv.Itoa(i)
+	}
+	targets[2].Address = "127.0.0.1:" + strconv.Itoa(deadPort)
+
+	opts := Options{Community: "public", Port: port, Timeout: 200 * time.Millisecond}
+	results := PollAll(context.Background(), opts, targets, 2)
+	if len(results) != len(targets) {
+		t.Fatalf("got %d results, want %d", len(results), len(targets))
+	}
+	for i, result := range results {
+		if result.Target.Key != targets[i].Key {
+			t.Errorf("results[%d].Target.Key = %s, want %s", i, result.Target.Key, targets[i].Key)
+		}
+		if (result.Err != nil) != (i == 2) {
+			t.Errorf("results[%d].Err = %v", i, result.Err)
+		}
+		if i != 2 && len(result.Fields) != len(wantFields) {
+			t.Errorf("results[%d] got %d fields", i, len(result.Fields))
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{
+		{"default v2c", Options{}, ""},
+		{"v2c", Options{Version: "2c"}, ""},
+		{"v1", Options{Version: "1"}, "不支援的 SNMP 版本"},
+		{"v3 no user", Options{Version: "3"}, "缺少 username"},
+		{"v3 bad level", Options{Version: "3", V3: V3Options{Username: "u", SecurityLevel: "high"}}, "不支援的 security_level"},
+		{"v3 bad auth", Options{Version: "3", V3: V3Options{Username: "u", SecurityLevel: "authNoPriv", AuthProtocol: "SHA1", AuthPassword: "p"}}, "不支援的 auth_protocol"},
+		{"v3 bad priv", Options{Version: "3", V3: V3Options{Username: "u", SecurityLevel: "authPriv", AuthProtocol: "SHA", AuthPassword: "p", PrivProtocol: "3DES", PrivPassword: "p"}}, "不支援的 priv_protocol"},
+		{"v3 auth without password", Options{Version: "3", V3: V3Options{Username: "u", SecurityLevel: "authNoPriv", AuthProtocol: "SHA"}}, "需設定 auth_protocol 與 auth_password"},
+		{"v3 priv without password", Options{Version: "3", V3: V3Options{Username: "u", SecurityLevel: "authPriv", AuthProtocol: "SHA", AuthPassword: "p", PrivProtocol: "AES"}}, "authPriv 需設定"},
+		{"v3 noAuthNoPriv", Options{Version: "3", V3: V3Options{Username: "u", SecurityLevel: "noAuthNoPriv"}}, ""},
+		{"v3 authPriv", Options{Version: "3", V3: V3Options{Username: "u", SecurityLevel: "authPriv", AuthProtocol: "sha256", AuthPassword: "p", PrivProtocol: "aes", PrivPassword: "p"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToFloat(t *testing.T) {
+	tests := []struct {
+		pdu    gosnmp.SnmpPDU
+		want   float64
+		wantOK bool
+	}{
+		{gosnmp.SnmpPDU{Type: gosnmp.Integer, Value: -5}, -5, true},
+		{gosnmp.SnmpPDU{Type: gosnmp.Counter32, Value: uint(42)}, 42, true},
+		{gosnmp.SnmpPDU{Type: gosnmp.Gauge32, Value: uint(7)}, 7, true},
+		{gosnmp.SnmpPDU{Type: gosnmp.Counter64, Value: uint64(1 << 40)}, 1 << 40, true},
+		{gosnmp.SnmpPDU{Type: gosnmp.TimeTicks, Value: uint32(360000)}, 360000, true},
+		{gosnmp.SnmpPDU{Type: gosnmp.OpaqueFloat, Value: float32(1.5)}, 1.5, true},
+		{gosnmp.SnmpPDU{Type: gosnmp.OpaqueDouble, Value: 2.25}, 2.25, true},
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte(" 12.5\n")}, 12.5, true},
+		{gosnmp.SnmpPDU{Type: gosnmp.OctetString, Value: []byte("normal")}, 0, false},
+		{gosnmp.SnmpPDU{Type: gosnmp.NoSuchObject}, 0, false},
+		{gosnmp.SnmpPDU{Type: gosnmp.ObjectIdentifier, Value: ".1.3"}, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := toFloat(tt.pdu)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("toFloat(%v %v) = %v, %v; want %v, %v", tt.pdu.Type, tt.pdu.Value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,600 @@
+// Package wal 以分段檔案保存待轉送的資料 (write-ahead log)
+//
+// 每筆資料 (record) 依序取得遞增的序號，寫入目前的分段檔；分段超過 SegmentSize 時換到新的分段，
+// 分段檔名為第一筆資料的序號。讀取端依序號讀取，送出成功後以 Ack 確認，
+// 全部已確認的分段會被刪除；總大小超過 MaxSize 時刪除最舊的分段 (未確認的資料計入 Dropped)。
+//
+// record 格式：長度 (uint32) + CRC32C (uint32) + 寫入時間 (int64, UnixNano) + 資料，
+// 開啟時檢查每個分段，最後不完整或 CRC 錯誤的資料 (例如寫入中斷電) 會被截斷。
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SyncPolicy 寫入後呼叫 fsync 的時機
+type SyncPolicy string
+
+const (
+	SyncAlways   SyncPolicy = "always"   // 每次寫入後
+	SyncInterval SyncPolicy = "interval" // 每 SyncInterval 一次
+	SyncNone     SyncPolicy = "none"     // 交由作業系統
+)
+
+const (
+	headerSize      = 16
+	segmentExt      = ".wal"
+	ackFile         = "ack"
+	maxRecordSize   = 256 << 20
+	defaultSegment  = 64 << 20
+	defaultInterval = time.Second
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrClosed WAL 已關閉
+var ErrClosed = errors.New("wal: closed")
+
+// Options WAL 設定
+type Options struct {
+	Dir          string
+	SegmentSize  int64 // 單一分段大小上限 (bytes)，預設 64MB
+	MaxSize      int64 // 總大小上限 (bytes)，超過時刪除最舊的分段，0 表示不限制
+	Sync         SyncPolicy
+	SyncInterval time.Duration // Sync 為 interval 時的間隔，預設 1 秒
+}
+
+// Entry 一筆資料
+type Entry struct {
+	Seq  uint64
+	Time time.Time
+	Data []byte
+}
+
+// Stats 佇列狀態
+type Stats struct {
+	Depth    uint64  `json:"depth"`       // 未確認的資料筆數
+	Bytes    int64   `json:"bytes"`       // 分段檔總大小
+	Segments int     `json:"segments"`    // 分段數
+	FirstSeq uint64  `json:"first_seq"`   // 保留中最舊的序號
+	LastSeq  uint64  `json:"last_seq"`    // 最新的序號
+	AckedSeq uint64  `json:"acked_seq"`   // 已確認的序號
+	Lag      float64 `json:"lag_seconds"` // 最舊未確認資料的等待時間 (秒)
+	Dropped  uint64  `json:"dropped"`     // 因超過總大小被刪除的未確認資料筆數 (開啟後累計)
+}
+
+type segment struct {
+	first uint64
+	count uint64
+	size  int64
+	path  string
+}
+
+func (s *segment) last() uint64 { return s.first + s.count - 1 }
+
+// 讀取位置快取，依序讀取時不需重新掃描分段
+type cursor struct {
+	seq    uint64
+	first  uint64
+	offset int64
+}
+
+// 已讀取資料的序號與寫入時間
+type mark struct {
+	seq  uint64
+	time time.Time
+}
+
+// WAL 分段式 write-ahead log
+type WAL struct {
+	mu       sync.Mutex
+	opts     Options
+	segments []*segment
+	active   *os.File
+	nextSeq  uint64
+	acked    uint64
+	dropped  uint64
+	dirty    bool
+	cursor   cursor
+	marks    []mark // 已讀取、未確認資料的寫入時間 (計算延遲用)
+	notify   chan struct{}
+	done     chan struct{}
+	closed   bool
+}
+
+// Open 開啟或建立 WAL，並修復最後不完整的資料
+func Open(opts Options) (*WAL, error) {
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = defaultSegment
+	}
+	if opts.Sync == "" {
+		opts.Sync = SyncInterval
+	}
+	if opts.SyncInterval <= 0 {
+		opts.SyncInterval = defaultInterval
+	}
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	w := &WAL{
+		opts:   opts,
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	if err := w.load(); err != nil {
+		return nil, err
+	}
+	if opts.Sync == SyncInterval {
+		go w.syncLoop()
+	}
+	return w, nil
+}
+
+func (w *WAL) load() error {
+	acked, err := readAck(filepath.Join(w.opts.Dir, ackFile))
+	if err != nil {
+		return err
+	}
+	w.acked = acked
+
+	names, err := filepath.Glob(filepath.Join(w.opts.Dir, "*"+segmentExt))
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		first, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(name), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		w.segments = append(w.segments, &segment{first: first, path: name})
+	}
+	sort.Slice(w.segments, func(i, j int) bool { return w.segments[i].first < w.segments[j].first })
+
+	for i := 0; i < len(w.segments); i++ {
+		seg := w.segments[i]
+		if err := scanSegment(seg); err != nil {
+			return err
+		}
+		// 分段之間序號不連續 (中間的分段遺失) 時，之後的資料仍依檔名序號讀取
+		if seg.count == 0 && i < len(w.segments)-1 {
+			os.Remove(seg.path)
+			w.segments = append(w.segments[:i], w.segments[i+1:]...)
+			i--
+		}
+	}
+
+	w.nextSeq = w.acked + 1
+	if n := len(w.segments); n > 0 {
+		last := w.segments[n-1]
+		if next := last.first + last.count; next > w.nextSeq {
+			w.nextSeq = next
+		}
+	}
+	w.truncateAcked()
+	return w.openActive()
+}
+
+// scanSegment 計算分段內完整的資料筆數，截斷最後不完整或損毀的資料
+func scanSegment(seg *segment) error {
+	f, err := os.OpenFile(seg.path, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var offset int64
+	for {
+		_, _, size, err := readRecord(r)
+		if err != nil {
+			if err != io.EOF {
+				if err := f.Truncate(offset); err != nil {
+					return err
+				}
+			}
+			break
+		}
+		offset += size
+		seg.count++
+	}
+	seg.size = offset
+	return nil
+}
+
+// openActive 開啟最後一個分段供寫入，沒有分段或已滿時建立新的分段
+func (w *WAL) openActive() error {
+	if n := len(w.segments); n > 0 {
+		last := w.segments[n-1]
+		if last.first+last.count == w.nextSeq && last.size < w.opts.SegmentSize {
+			f, err := os.OpenFile(last.path, os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				return err
+			}
+			w.active = f
+			return nil
+		}
+	}
+	return w.newSegment()
+}
+
+func (w *WAL) newSegment() error {
+	path := filepath.Join(w.opts.Dir, fmt.Sprintf("%020d%s", w.nextSeq, segmentExt))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	w.active = f
+	w.segments = append(w.segments, &segment{first: w.nextSeq, path: path})
+	return syncDir(w.opts.Dir)
+}
+
+// Append 寫入一筆資料，回傳序號
+func (w *WAL) Append(data []byte) (uint64, error) {
+	if len(data) > maxRecordSize {
+		return 0, fmt.Errorf("wal: record too large (%d bytes)", len(data))
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+
+	size := int64(headerSize + len(data))
+	active := w.segments[len(w.segments)-1]
+	if active.count > 0 && active.size+size > w.opts.SegmentSize {
+		if err := w.active.Sync(); err != nil {
+			return 0, err
+		}
+		w.active.Close()
+		if err := w.newSegment(); err != nil {
+			return 0, err
+		}
+		active = w.segments[len(w.segments)-1]
+	}
+
+	record := make([]byte, size)
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint64(record[8:16], uint64(time.Now().UnixNano()))
+	copy(record[headerSize:], data)
+	binary.BigEndian.PutUint32(record[4:8], crc32.Checksum(record[8:], crcTable))
+
+	if _, err := w.active.Write(record); err != nil {
+		// 寫入失敗可能留下不完整的資料，截斷回原本的大小
+		w.active.Truncate(active.size)
+		return 0, err
+	}
+	if w.opts.Sync == SyncAlways {
+		if err := w.active.Sync(); err != nil {
+			return 0, err
+		}
+	} else {
+		w.dirty = true
+	}
+
+	seq := w.nextSeq
+	w.nextSeq++
+	active.count++
+	active.size += size
+	w.enforceMaxSize()
+
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+	return seq, nil
+}
+
+// enforceMaxSize 總大小超過上限時刪除最舊的分段 (保留寫入中的分段)
+func (w *WAL) enforceMaxSize() {
+	if w.opts.MaxSize <= 0 {
+		return
+	}
+	total := w.totalSize()
+	for total > w.opts.MaxSize && len(w.segments) > 1 {
+		seg := w.segments[0]
+		if seg.last() > w.acked {
+			unacked := seg.count
+			if seg.first <= w.acked {
+				unacked = seg.last() - w.acked
+			}
+			w.dropped += unacked
+			w.acked = seg.last()
+			w.trimMarks()
+			writeAck(w.opts.Dir, w.acked)
+		}
+		os.Remove(seg.path)
+		total -= seg.size
+		w.segments = w.segments[1:]
+	}
+}
+
+func (w *WAL) totalSize() int64 {
+	var total int64
+	for _, seg := range w.segments {
+		total += seg.size
+	}
+	return total
+}
+
+// Read 由序號 from 開始依序讀取最多 max 筆資料 (from 已被刪除時由最舊的資料開始)
+func (w *WAL) Read(from uint64, max int) ([]Entry, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil, ErrClosed
+	}
+	return w.read(from, max)
+}
+
+func (w *WAL) read(from uint64, max int) ([]Entry, error) {
+	if first := w.firstSeq(); from < first {
+		from = first
+	}
+	var entries []Entry
+	for _, seg := range w.segments {
+		if len(entries) >= max {
+			break
+		}
+		if seg.count == 0 || from > seg.last() {
+			continue
+		}
+
+		f, err := os.Open(seg.path)
+		if err != nil {
+			return entries, err
+		}
+		seq, offset := seg.first, int64(0)
+		if w.cursor.first == seg.first && w.cursor.seq <= from && w.cursor.seq > seq {
+			seq, offset = w.cursor.seq, w.cursor.offset
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			f.Close()
+			return entries, err
+		}
+
+		r := bufio.NewReader(f)
+		for seq <= seg.last() && len(entries) < max {
+			t, data, size, err := readRecord(r)
+			if err != nil {
+				f.Close()
+				return entries, fmt.Errorf("wal: read %s seq %d: %w", seg.path, seq, err)
+			}
+			if seq >= from {
+				entries = append(entries, Entry{Seq: seq, Time: t, Data: data})
+				if seq > w.acked && (len(w.marks) == 0 || seq > w.marks[len(w.marks)-1].seq) {
+					w.marks = append(w.marks, mark{seq: seq, time: t})
+				}
+			}
+			seq++
+			offset += size
+		}
+		f.Close()
+		w.cursor = cursor{seq: seq, first: seg.first, offset: offset}
+		from = seq
+	}
+	return entries, nil
+}
+
+// Ack 確認序號 seq (含) 之前的資料已處理，並刪除全部已確認的分段
+func (w *WAL) Ack(seq uint64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return ErrClosed
+	}
+	if seq <= w.acked {
+		return nil
+	}
+	if seq >= w.nextSeq {
+		seq = w.nextSeq - 1
+	}
+	w.acked = seq
+	w.trimMarks()
+	if err := writeAck(w.opts.Dir, seq); err != nil {
+		return err
+	}
+	w.truncateAcked()
+	return nil
+}
+
+// truncateAcked 刪除全部已確認的分段 (保留寫入中的分段)
+func (w *WAL) truncateAcked() {
+	for len(w.segments) > 1 && w.segments[0].last() <= w.acked {
+		os.Remove(w.segments[0].path)
+		w.segments = w.segments[1:]
+	}
+}
+
+// Acked 已確認的序號
+func (w *WAL) Acked() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.acked
+}
+
+// Notify 有新資料寫入時通知
+func (w *WAL) Notify() <-chan struct{} {
+	return w.notify
+}
+
+// Stats 佇列狀態
+func (w *WAL) Stats() Stats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stats := Stats{
+		Bytes:    w.totalSize(),
+		Segments: len(w.segments),
+		FirstSeq: w.firstSeq(),
+		LastSeq:  w.nextSeq - 1,
+		AckedSeq: w.acked,
+		Dropped:  w.dropped,
+	}
+	if stats.LastSeq > w.acked {
+		stats.Depth = stats.LastSeq - w.acked
+		if len(w.marks) > 0 && w.marks[0].seq == w.acked+1 {
+			stats.Lag = time.Since(w.marks[0].time).Seconds()
+		} else if !w.closed {
+			// 讀取端尚未讀到最舊的資料，直接讀取 (不影響讀取端的讀取位置快取)
+			saved := w.cursor
+			if entries, err := w.read(w.acked+1, 1); err == nil && len(entries) > 0 {
+				stats.Lag = time.Since(entries[0].Time).Seconds()
+			}
+			w.cursor = saved
+		}
+	}
+	return stats
+}
+
+// Depth 未確認的資料筆數
+func (w *WAL) Depth() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.nextSeq - 1 - w.acked
+}
+
+// trimMarks 移除已確認資料的讀取紀錄
+func (w *WAL) trimMarks() {
+	i := 0
+	for i < len(w.marks) && w.marks[i].seq <= w.acked {
+		i++
+	}
+	w.marks = w.marks[i:]
+}
+
+func (w *WAL) firstSeq() uint64 {
+	for _, seg := range w.segments {
+		if seg.count > 0 {
+			return seg.first
+		}
+	}
+	return w.nextSeq
+}
+
+// Sync 將寫入的資料 fsync 到磁碟
+func (w *WAL) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.sync()
+}
+
+func (w *WAL) sync() error {
+	if !w.dirty || w.active == nil {
+		return nil
+	}
+	w.dirty = false
+	return w.active.Sync()
+}
+
+func (w *WAL) syncLoop() {
+	ticker := time.NewTicker(w.opts.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.Sync()
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// Close fsync 並關閉 WAL
+func (w *WAL) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	close(w.done)
+	err := w.sync()
+	if cerr := w.active.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// readRecord 讀取一筆資料，回傳寫入時間、資料與 record 大小
+func readRecord(r io.Reader) (time.Time, []byte, int64, error) {
+	var header [headerSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return time.Time{}, nil, 0, err
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length > maxRecordSize {
+		return time.Time{}, nil, 0, errors.New("wal: invalid record length")
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return time.Time{}, nil, 0, err
+	}
+	crc := crc32.Update(crc32.Checksum(header[8:16], crcTable), crcTable, data)
+	if crc != binary.BigEndian.Uint32(header[4:8]) {
+		return time.Time{}, nil, 0, errors.New("wal: checksum mismatch")
+	}
+	t := time.Unix(0, int64(binary.BigEndian.Uint64(header[8:16])))
+	return t, data, int64(headerSize) + int64(length), nil
+}
+
+func readAck(path string) (uint64, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+}
+
+// writeAck 以暫存檔替換的方式保存已確認的序號
+// rename 前同步暫存檔、rename 後同步目錄，避免當機後 ack 遺失而重送已送出的 segment
+func writeAck(dir string, seq uint64) error {
+	tmp := filepath.Join(dir, ackFile+".tmp")
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(strconv.FormatUint(seq, 10)); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, ackFile)); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,6 @@
+ALTER TABLE `rules`
+  DROP COLUMN `info_lower_threshold`,
+  DROP COLUMN `warn_lower_threshold`,
+  DROP COLUMN `crit_lower_threshold`,
+  DROP COLUMN `recovery_threshold`,
+  DROP COLUMN `recovery_lower_threshold`;
@@ -0,0 +1,6 @@
+ALTER TABLE `rules`
+  ADD COLUMN `info_lower_threshold` double DEFAULT NULL AFTER `crit_threshold`,
+  ADD COLUMN `warn_lower_threshold` double DEFAULT NULL AFTER `info_lower_threshold`,
+  ADD COLUMN `crit_lower_threshold` double DEFAULT NULL AFTER `warn_lower_threshold`,
+  ADD COLUMN `recovery_threshold` double DEFAULT NULL AFTER `crit_lower_threshold`,
+  ADD COLUMN `recovery_lower_threshold` double DEFAULT NULL AFTER `recovery_threshold`;
@@ -0,0 +1,7 @@
+ALTER TABLE `rule_states`
+  DROP COLUMN `breach_counter`;
+
+ALTER TABLE `rules`
+  DROP COLUMN `evaluation_mode`,
+  DROP COLUMN `evaluation_points`,
+  DROP COLUMN `notify_times`;
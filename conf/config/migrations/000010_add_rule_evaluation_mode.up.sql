@@ -0,0 +1,10 @@
+ALTER TABLE `rules`
+  ADD COLUMN `evaluation_mode` enum('all','n_of_m','consecutive') NOT NULL DEFAULT 'all' AFTER `recovery_lower_threshold`,
+  ADD COLUMN `evaluation_points` int NOT NULL DEFAULT '0' AFTER `evaluation_mode`,
+  ADD COLUMN `notify_times` int NOT NULL DEFAULT '3' COMMENT '連續通知次數，達到後進入靜默期' AFTER `times`;
+
+-- times 改為判斷模式的觸發次數，原本的通知次數移至 notify_times
+UPDATE `rules` SET `notify_times` = `times`;
+
+ALTER TABLE `rule_states`
+  ADD COLUMN `breach_counter` int DEFAULT '0' AFTER `contact_counter`;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `mute_resource_groups`;
+DROP TABLE IF EXISTS `mutes`;
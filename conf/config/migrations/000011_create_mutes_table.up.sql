@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS `mutes` (
+  `id` varchar(36) NOT NULL,
+  `realm_name` varchar(50) NOT NULL DEFAULT 'master',
+  `name` varchar(255) NOT NULL COMMENT '抑制規則名稱',
+  `years` json DEFAULT NULL COMMENT '指定年份範圍 (如 ["2020:2022", "2030"])',
+  `time_intervals` json NOT NULL COMMENT '一天內多個時間區間',
+  `repeat_type` enum('never','daily','weekly','monthly') NOT NULL DEFAULT 'never' COMMENT '重複類型',
+  `weekdays` json DEFAULT NULL COMMENT '允許的星期 (如 ["monday:wednesday", "saturday"])',
+  `months` json DEFAULT NULL COMMENT '允許的月份 (如 ["1:3", "may:august"])',
+  `resources` json DEFAULT NULL COMMENT '指定資源名稱',
+  `labels` json DEFAULT NULL COMMENT '規則標籤條件',
+  `created_at` bigint unsigned DEFAULT NULL,
+  `updated_at` bigint unsigned DEFAULT NULL,
+  `deleted_at` bigint DEFAULT '0',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_realm_name_name` (`realm_name`, `name`),
+  KEY `idx_mutes_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='告警抑制規則';
+
+CREATE TABLE IF NOT EXISTS `mute_resource_groups` (
+  `mute_id` varchar(36) NOT NULL,
+  `resource_group_id` bigint NOT NULL,
+  PRIMARY KEY (`mute_id`, `resource_group_id`),
+  KEY `idx_mute_resource_groups_group` (`resource_group_id`),
+  CONSTRAINT `fk_mute_resource_groups_mute` FOREIGN KEY (`mute_id`) REFERENCES `mutes` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
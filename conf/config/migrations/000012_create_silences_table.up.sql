@@ -0,0 +1,16 @@
+CREATE TABLE `silences` (
+  `realm_name` varchar(20) NOT NULL,
+  `id` binary(16) NOT NULL,
+  `matchers` json NOT NULL COMMENT '比對條件 (如 [{"name": "resource_name", "operator": "=", "value": "host-1"}])',
+  `starts_at` bigint NOT NULL,
+  `ends_at` bigint NOT NULL,
+  `created_by` varchar(255) DEFAULT NULL,
+  `comment` text,
+  `triggered_log_id` binary(16) DEFAULT NULL,
+  `created_at` bigint unsigned DEFAULT NULL,
+  `updated_at` bigint unsigned DEFAULT NULL,
+  `deleted_at` DATETIME DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_silences_realm_ends_at` (`realm_name`, `ends_at`),
+  KEY `idx_silences_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='臨時靜默';
@@ -0,0 +1,6 @@
+ALTER TABLE `triggered_logs`
+  DROP COLUMN `acked_by`,
+  DROP COLUMN `acked_at`,
+  DROP COLUMN `assigned_to`,
+  DROP COLUMN `assigned_by`,
+  DROP COLUMN `assigned_at`;
@@ -0,0 +1,6 @@
+ALTER TABLE `triggered_logs`
+  ADD COLUMN `acked_by` varchar(255) DEFAULT NULL AFTER `threshold`,
+  ADD COLUMN `acked_at` bigint DEFAULT NULL AFTER `acked_by`,
+  ADD COLUMN `assigned_to` varchar(255) DEFAULT NULL AFTER `acked_at`,
+  ADD COLUMN `assigned_by` varchar(255) DEFAULT NULL AFTER `assigned_to`,
+  ADD COLUMN `assigned_at` bigint DEFAULT NULL AFTER `assigned_by`;
@@ -0,0 +1,11 @@
+ALTER TABLE `triggered_logs`
+  DROP COLUMN `escalation_step`,
+  DROP COLUMN `escalated_at`;
+
+ALTER TABLE `rules`
+  DROP KEY `idx_rules_escalation_policy`,
+  DROP COLUMN `escalation_policy_id`;
+
+DROP TABLE IF EXISTS `escalation_step_contacts`;
+DROP TABLE IF EXISTS `escalation_steps`;
+DROP TABLE IF EXISTS `escalation_policies`;
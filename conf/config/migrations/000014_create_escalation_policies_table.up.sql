@@ -0,0 +1,38 @@
+CREATE TABLE `escalation_policies` (
+  `realm_name` varchar(20) NOT NULL,
+  `id` binary(16) NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `description` varchar(255) DEFAULT NULL,
+  `created_by` varchar(36) DEFAULT NULL,
+  `updated_by` varchar(36) DEFAULT NULL,
+  `created_at` bigint unsigned DEFAULT NULL,
+  `updated_at` bigint unsigned DEFAULT NULL,
+  `deleted_at` DATETIME DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_escalation_policies_realm` (`realm_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `escalation_steps` (
+  `policy_id` binary(16) NOT NULL,
+  `step_order` int NOT NULL,
+  `delay` varchar(10) NOT NULL DEFAULT '0s',
+  PRIMARY KEY (`policy_id`, `step_order`),
+  CONSTRAINT `fk_escalation_steps_policy` FOREIGN KEY (`policy_id`) REFERENCES `escalation_policies` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `escalation_step_contacts` (
+  `policy_id` binary(16) NOT NULL,
+  `step_order` int NOT NULL,
+  `contact_id` binary(16) NOT NULL,
+  PRIMARY KEY (`policy_id`, `step_order`, `contact_id`),
+  CONSTRAINT `fk_escalation_step_contacts_step` FOREIGN KEY (`policy_id`, `step_order`) REFERENCES `escalation_steps` (`policy_id`, `step_order`) ON DELETE CASCADE,
+  CONSTRAINT `fk_escalation_step_contacts_contact` FOREIGN KEY (`contact_id`) REFERENCES `contacts` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE `rules`
+  ADD COLUMN `escalation_policy_id` binary(16) DEFAULT NULL AFTER `silence_period`,
+  ADD KEY `idx_rules_escalation_policy` (`escalation_policy_id`);
+
+ALTER TABLE `triggered_logs`
+  ADD COLUMN `escalation_step` int NOT NULL DEFAULT '0' AFTER `assigned_at`,
+  ADD COLUMN `escalated_at` bigint DEFAULT NULL AFTER `escalation_step`;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `rule_oncall_schedules`;
+DROP TABLE IF EXISTS `oncall_overrides`;
+DROP TABLE IF EXISTS `oncall_schedules`;
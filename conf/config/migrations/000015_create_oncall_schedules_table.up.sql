@@ -0,0 +1,39 @@
+CREATE TABLE `oncall_schedules` (
+  `realm_name` varchar(20) NOT NULL,
+  `id` binary(16) NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `description` varchar(255) DEFAULT NULL,
+  `timezone` varchar(64) NOT NULL DEFAULT 'UTC',
+  `rotations` json DEFAULT NULL,
+  `created_by` varchar(36) DEFAULT NULL,
+  `updated_by` varchar(36) DEFAULT NULL,
+  `created_at` bigint unsigned DEFAULT NULL,
+  `updated_at` bigint unsigned DEFAULT NULL,
+  `deleted_at` DATETIME DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_oncall_schedules_realm` (`realm_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `oncall_overrides` (
+  `id` binary(16) NOT NULL,
+  `schedule_id` binary(16) NOT NULL,
+  `contact_id` binary(16) NOT NULL,
+  `starts_at` bigint NOT NULL,
+  `ends_at` bigint NOT NULL,
+  `reason` varchar(255) DEFAULT NULL,
+  `created_by` varchar(100) DEFAULT NULL,
+  `created_at` bigint unsigned DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_oncall_overrides_schedule` (`schedule_id`, `ends_at`),
+  CONSTRAINT `fk_oncall_overrides_schedule` FOREIGN KEY (`schedule_id`) REFERENCES `oncall_schedules` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_oncall_overrides_contact` FOREIGN KEY (`contact_id`) REFERENCES `contacts` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE `rule_oncall_schedules` (
+  `rule_id` binary(16) NOT NULL,
+  `schedule_id` binary(16) NOT NULL,
+  PRIMARY KEY (`rule_id`, `schedule_id`),
+  KEY `idx_rule_oncall_schedules_schedule` (`schedule_id`),
+  CONSTRAINT `fk_rule_oncall_schedules_rule` FOREIGN KEY (`rule_id`) REFERENCES `rules` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_rule_oncall_schedules_schedule` FOREIGN KEY (`schedule_id`) REFERENCES `oncall_schedules` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,5 @@
+ALTER TABLE `notify_logs`
+  DROP KEY `idx_notify_logs_state_retry`,
+  DROP COLUMN `notify_type`,
+  DROP COLUMN `next_retry_at`,
+  DROP COLUMN `error_class`;
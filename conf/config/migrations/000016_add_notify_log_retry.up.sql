@@ -0,0 +1,5 @@
+ALTER TABLE `notify_logs`
+  ADD COLUMN `notify_type` varchar(20) NOT NULL DEFAULT 'alerting' AFTER `state`,
+  ADD COLUMN `next_retry_at` bigint DEFAULT NULL AFTER `last_retry_at`,
+  ADD COLUMN `error_class` varchar(20) DEFAULT NULL AFTER `next_retry_at`,
+  ADD KEY `idx_notify_logs_state_retry` (`state`, `next_retry_at`);
@@ -0,0 +1,2 @@
+ALTER TABLE `triggered_logs`
+  DROP COLUMN `metric_window`;
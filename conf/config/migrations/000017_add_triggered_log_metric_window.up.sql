@@ -0,0 +1,2 @@
+ALTER TABLE `triggered_logs`
+  ADD COLUMN `metric_window` json DEFAULT NULL AFTER `threshold`;
@@ -0,0 +1,2 @@
+ALTER TABLE `notify_logs`
+  DROP COLUMN `suppressed_count`;
@@ -0,0 +1,2 @@
+ALTER TABLE `notify_logs`
+  ADD COLUMN `suppressed_count` int NOT NULL DEFAULT 0 AFTER `error_class`;
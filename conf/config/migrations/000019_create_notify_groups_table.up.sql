@@ -0,0 +1,15 @@
+CREATE TABLE `notify_groups` (
+  `realm_name` varchar(20) NOT NULL,
+  `id` binary(16) NOT NULL,
+  `contact_id` binary(16) NOT NULL,
+  `group_key` varchar(64) NOT NULL,
+  `labels` json DEFAULT NULL,
+  `first_seen_at` bigint NOT NULL,
+  `last_notified_at` bigint DEFAULT NULL,
+  `created_at` bigint unsigned DEFAULT NULL,
+  `updated_at` bigint unsigned DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_notify_groups_contact_key` (`contact_id`, `group_key`),
+  KEY `idx_notify_groups_realm` (`realm_name`),
+  CONSTRAINT `fk_notify_groups_contact` FOREIGN KEY (`contact_id`) REFERENCES `contacts` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,9 @@
+CREATE TABLE `notify_routes` (
+  `realm_name` varchar(20) NOT NULL,
+  `root` json NOT NULL,
+  `created_by` varchar(36) DEFAULT NULL,
+  `updated_by` varchar(36) DEFAULT NULL,
+  `created_at` bigint unsigned DEFAULT NULL,
+  `updated_at` bigint unsigned DEFAULT NULL,
+  PRIMARY KEY (`realm_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
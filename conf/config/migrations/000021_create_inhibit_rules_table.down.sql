@@ -0,0 +1,3 @@
+ALTER TABLE `triggered_logs` DROP COLUMN `inhibited_by`;
+
+DROP TABLE IF EXISTS `inhibit_rules`;
@@ -0,0 +1,18 @@
+CREATE TABLE `inhibit_rules` (
+  `realm_name` varchar(20) NOT NULL,
+  `id` binary(16) NOT NULL,
+  `name` varchar(100) NOT NULL,
+  `source_matchers` json NOT NULL COMMENT '來源告警比對條件',
+  `target_matchers` json NOT NULL COMMENT '被抑制告警比對條件',
+  `equal` json DEFAULT NULL COMMENT '來源與目標需相同的欄位 (如 ["room"])',
+  `created_by` varchar(255) DEFAULT NULL,
+  `created_at` bigint unsigned DEFAULT NULL,
+  `updated_at` bigint unsigned DEFAULT NULL,
+  `deleted_at` DATETIME DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_inhibit_rules_realm` (`realm_name`),
+  KEY `idx_inhibit_rules_deleted_at` (`deleted_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='告警抑制(inhibition)規則：來源告警觸發時抑制目標告警';
+
+ALTER TABLE `triggered_logs`
+  ADD COLUMN `inhibited_by` binary(16) DEFAULT NULL COMMENT '抑制此告警的來源告警' AFTER `escalated_at`;
@@ -0,0 +1,3 @@
+ALTER TABLE `triggered_logs` DROP COLUMN `resolved_by`;
+
+DROP TABLE IF EXISTS `notify_action_logs`;
@@ -0,0 +1,19 @@
+CREATE TABLE `notify_action_logs` (
+  `realm_name` varchar(20) NOT NULL,
+  `id` binary(16) NOT NULL,
+  `notify_log_id` binary(16) NOT NULL,
+  `triggered_log_id` binary(16) NOT NULL,
+  `contact_id` binary(16) DEFAULT NULL,
+  `channel_type` varchar(20) NOT NULL COMMENT '回呼來源 (slack / line / teams / link)',
+  `action` varchar(20) NOT NULL COMMENT 'ack / silence / resolve',
+  `actor` varchar(255) NOT NULL,
+  `error` text,
+  `created_at` bigint unsigned DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_notify_action_logs_realm` (`realm_name`),
+  UNIQUE KEY `uk_notify_action_logs_action` (`notify_log_id`, `triggered_log_id`, `action`),
+  KEY `idx_notify_action_logs_triggered_log` (`triggered_log_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='通知訊息操作紀錄';
+
+ALTER TABLE `triggered_logs`
+  ADD COLUMN `resolved_by` varchar(255) DEFAULT NULL COMMENT '手動恢復人' AFTER `acked_at`;
@@ -0,0 +1,13 @@
+CREATE TABLE `notify_deliveries` (
+  `realm_name` varchar(20) NOT NULL,
+  `triggered_log_id` binary(16) NOT NULL,
+  `contact_id` binary(16) NOT NULL,
+  `notify_log_id` binary(16) NOT NULL COMMENT '第一次發送異常通知的通知日誌',
+  `state` varchar(20) NOT NULL COMMENT '第一次異常通知的結果 (sent / delayed / dead_letter / suppressed)',
+  `message_id` varchar(255) DEFAULT NULL COMMENT '郵件串起點：第一封異常通知郵件的 Message-ID',
+  `created_at` bigint unsigned DEFAULT NULL,
+  PRIMARY KEY (`triggered_log_id`, `contact_id`),
+  KEY `idx_notify_deliveries_realm` (`realm_name`),
+  CONSTRAINT `fk_notify_deliveries_triggered_log` FOREIGN KEY (`triggered_log_id`) REFERENCES `triggered_logs` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_notify_deliveries_contact` FOREIGN KEY (`contact_id`) REFERENCES `contacts` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='告警對各聯絡人的異常通知紀錄';
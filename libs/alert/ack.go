@@ -0,0 +1,148 @@
+package alert
+
+import (
+	"errors"
+	"time"
+
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/storage/mysql"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 已確認的告警在恢復或升級前不再重複通知
+// 確認後的異常通知狀態標記為 NotifyStateAcked，取消確認時恢復為 pending
+// 手動恢復的告警記錄恢復人 (resolved_by)，恢復通知照常發送
+
+// AckTriggeredLog 確認告警
+func (s *Service) AckTriggeredLog(realm, id, user string) (*models.TriggeredLog, error) {
+	log, err := s.getRealmTriggeredLog(realm, id)
+	if err != nil {
+		return nil, err
+	}
+	if log.ResolvedAt != nil {
+		return nil, apierrors.NewAPIError(409, "告警已恢復，無法確認", nil)
+	}
+	if log.AckedAt != nil {
+		return log, nil
+	}
+
+	now := time.Now().Unix()
+	if err := s.mysql.AckTriggeredLog(log.ID, user, now); err != nil {
+		return nil, err
+	}
+	s.logger.Info("告警已確認",
+		zap.String("triggered_log_id", formatID(log.ID)),
+		zap.String("acked_by", user))
+
+	log.AckedBy = &user
+	log.AckedAt = &now
+	return log, nil
+}
+
+// UnackTriggeredLog 取消確認告警
+func (s *Service) UnackTriggeredLog(realm, id, user string) (*models.TriggeredLog, error) {
+	log, err := s.getRealmTriggeredLog(realm, id)
+	if err != nil {
+		return nil, err
+	}
+	if log.AckedAt == nil {
+		return log, nil
+	}
+
+	if err := s.mysql.UnackTriggeredLog(log.ID, NotifyStateAcked); err != nil {
+		return nil, err
+	}
+	s.logger.Info("告警已取消確認",
+		zap.String("triggered_log_id", formatID(log.ID)),
+		zap.String("user", user))
+
+	log.AckedBy = nil
+	log.AckedAt = nil
+	if log.NotifyState == NotifyStateAcked {
+		log.NotifyState = NotifyStatePending
+	}
+	return log, nil
+}
+
+// ResolveTriggeredLog 手動恢復告警，之後依一般流程發送恢復通知；
+// 若指標仍超過閾值，下次評估時會建立新的告警
+func (s *Service) ResolveTriggeredLog(realm, id, user string) (*models.TriggeredLog, error) {
+	log, err := s.getRealmTriggeredLog(realm, id)
+	if err != nil {
+		return nil, err
+	}
+	if log.ResolvedAt != nil {
+		return log, nil
+	}
+
+	now := time.Now().Unix()
+	if err := s.mysql.ResolveTriggeredLogManually(log.ID, now, user); err != nil {
+		return nil, err
+	}
+	s.logger.Info("告警已手動恢復",
+		zap.String("triggered_log_id", formatID(log.ID)),
+		zap.String("resolved_by", user))
+
+	log.ResolvedAt = &now
+	log.ResolvedBy = &user
+	return log, nil
+}
+
+// AssignTriggeredLog 指派告警處理人
+func (s *Service) AssignTriggeredLog(realm, id, assignee, user string) (*models.TriggeredLog, error) {
+	if assignee == "" {
+		return nil, apierrors.NewAPIError(400, "assignee 不可為空", nil)
+	}
+	log, err := s.getRealmTriggeredLog(realm, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	if err := s.mysql.AssignTriggeredLog(log.ID, assignee, user, now); err != nil {
+		return nil, err
+	}
+	s.logger.Info("告警已指派",
+		zap.String("triggered_log_id", formatID(log.ID)),
+		zap.String("assigned_to", assignee),
+		zap.String("assigned_by", user))
+
+	log.AssignedTo = &assignee
+	log.AssignedBy = &user
+	log.AssignedAt = &now
+	return log, nil
+}
+
+// 取得指定域內的告警
+func (s *Service) getRealmTriggeredLog(realm, id string) (*models.TriggeredLog, error) {
+	logID, err := mysql.ParseTriggeredLogID(id)
+	if err != nil {
+		return nil, err
+	}
+	log, err := s.mysql.GetTriggeredLog(logID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierrors.ErrNotFound
+		}
+		return nil, err
+	}
+	if log.RealmName != realm {
+		return nil, apierrors.ErrNotFound
+	}
+	return log, nil
+}
+
+// 嚴重程度等級，數字越大越嚴重
+func severityLevel(severity string) int {
+	switch severity {
+	case "crit":
+		return 3
+	case "warn":
+		return 2
+	case "info":
+		return 1
+	}
+	return 0
+}
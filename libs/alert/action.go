@@ -0,0 +1,388 @@
+package alert
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/models/common"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 通知訊息操作：
+// 設定 alert.public_url 與 alert.action_secret 時，異常通知 (slack / line / teams / email) 附上確認、靜默 1 小時與恢復三個操作，
+// 操作套用到該則通知的所有告警，結果與執行者記錄於 notify_action_logs
+// 操作權杖內容為通知日誌 ID、操作與有效期限，以 HMAC-SHA256 簽章
+// 聊天通道設定回呼驗證 (alert.callback) 時以互動按鈕回呼，處理前先驗證通道簽章：
+// Slack interactive actions 驗證 X-Slack-Signature 與時間戳，LINE postback 驗證 X-Line-Signature，
+// Teams Action.Submit (outgoing webhook) 驗證 Authorization: HMAC
+// 郵件與未設定回呼驗證的通道使用簽章連結 /api/v1/alert/action?token=...，開啟確認頁面後送出
+
+// 通知訊息操作
+const (
+	NotifyActionAck     = "ack"     // 確認告警
+	NotifyActionSilence = "silence" // 靜默 1 小時
+	NotifyActionResolve = "resolve" // 手動恢復
+)
+
+const (
+	actionTokenExpiry     = 7 * 24 * time.Hour // 操作權杖有效期間
+	actionSilenceDuration = time.Hour          // 由通知訊息靜默的時間
+	callbackMaxSkew       = 5 * time.Minute    // Slack 回呼時間戳允許的誤差
+)
+
+// 通知訊息的操作與顯示名稱 (依按鈕順序)
+var notifyActions = []struct {
+	name  string
+	label string
+}{
+	{NotifyActionAck, "確認"},
+	{NotifyActionSilence, "靜默 1 小時"},
+	{NotifyActionResolve, "標記恢復"},
+}
+
+// NotifyActionLabel 操作的顯示名稱
+func NotifyActionLabel(action string) string {
+	for _, a := range notifyActions {
+		if a.name == action {
+			return a.label
+		}
+	}
+	return action
+}
+
+// notifyActionButtons 通知訊息的操作按鈕，未設定或不支援的通道回傳 nil
+func (s *Service) notifyActionButtons(contact *models.Contact, notifyLogID []byte, notifyType string, now time.Time) []common.NotifyAction {
+	if notifyType != "alerting" || len(notifyLogID) == 0 || s.config.PublicURL == "" || s.config.ActionSecret == "" {
+		return nil
+	}
+
+	var interactive bool
+	switch contact.ChannelType {
+	case "slack":
+		interactive = s.config.Callback.SlackSigningSecret != ""
+	case "line":
+		interactive = s.config.Callback.LineChannelSecret != ""
+	case "teams":
+		interactive = s.config.Callback.TeamsSecret != ""
+	case "email":
+	default:
+		return nil
+	}
+
+	buttons := make([]common.NotifyAction, 0, len(notifyActions))
+	for _, a := range notifyActions {
+		token := s.actionToken(notifyLogID, a.name, now)
+		button := common.NotifyAction{
+			Name:  a.name,
+			Label: a.label,
+			URL: fmt.Sprintf("%s/api/v1/alert/action?token=%s",
+				strings.TrimRight(s.config.PublicURL, "/"), url.QueryEscape(token)),
+		}
+		if interactive {
+			button.Value = token
+		}
+		buttons = append(buttons, button)
+	}
+	return buttons
+}
+
+// actionToken 產生操作權杖：{通知日誌 ID}.{操作}.{到期時間}.{簽章}
+func (s *Service) actionToken(notifyLogID []byte, action string, now time.Time) string {
+	payload := fmt.Sprintf("%s.%s.%d", hex.EncodeToString(notifyLogID), action, now.Add(actionTokenExpiry).Unix())
+	return payload + "." + s.actionSignature(payload)
+}
+
+func (s *Service) actionSignature(payload string) string {
+	mac := hmac.New(sha256.New, []byte(s.config.ActionSecret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// parseActionToken 驗證操作權杖，回傳通知日誌 ID 與操作
+func (s *Service) parseActionToken(token string, now time.Time) ([]byte, string, error) {
+	if s.config.ActionSecret == "" {
+		return nil, "", apierrors.ErrNotFound
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return nil, "", apierrors.NewAPIError(400, "操作權杖格式錯誤", nil)
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(s.actionSignature(payload))) {
+		return nil, "", apierrors.NewAPIError(403, "操作權杖簽章無效", nil)
+	}
+	expAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || now.Unix() > expAt {
+		return nil, "", apierrors.NewAPIError(403, "操作連結已失效", nil)
+	}
+	notifyLogID, err := hex.DecodeString(parts[0])
+	if err != nil || len(notifyLogID) != 16 {
+		return nil, "", apierrors.ErrInvalidID
+	}
+	switch action := parts[1]; action {
+	case NotifyActionAck, NotifyActionSilence, NotifyActionResolve:
+		return notifyLogID, action, nil
+	default:
+		return nil, "", apierrors.NewAPIError(400, "不支援的操作", nil)
+	}
+}
+
+// DescribeNotifyAction 驗證操作權杖，回傳操作名稱與通知包含的告警數 (簽章連結的確認頁面使用)
+func (s *Service) DescribeNotifyAction(token string) (string, int, error) {
+	notifyLogID, action, err := s.parseActionToken(token, time.Now())
+	if err != nil {
+		return "", 0, err
+	}
+	notifyLog, err := s.getActionNotifyLog(notifyLogID)
+	if err != nil {
+		return "", 0, err
+	}
+	return action, len(notifyLog.TriggeredLogIDs), nil
+}
+
+// ExecuteNotifyAction 執行操作權杖指定的操作，channel 為回呼來源 (slack / line / teams / link)
+// actor 為空時以聯絡人名稱作為執行者；回傳執行結果說明
+func (s *Service) ExecuteNotifyAction(token, channel, actor string) (string, error) {
+	now := time.Now()
+	notifyLogID, action, err := s.parseActionToken(token, now)
+	if err != nil {
+		return "", err
+	}
+	notifyLog, err := s.getActionNotifyLog(notifyLogID)
+	if err != nil {
+		return "", err
+	}
+
+	if actor == "" {
+		actor = channel
+		if contact, err := s.mysql.GetContact(notifyLog.ContactID); err == nil {
+			actor = channel + ":" + contact.Name
+		}
+	}
+
+	// 先寫入操作紀錄再執行，重複點擊或通道重送回呼時只有一個請求能領取
+	var succeeded, failed, done int
+	for _, log := range s.getNotifyLogTriggeredLogs(notifyLog) {
+		record := models.NotifyActionLog{
+			RealmName:      notifyLog.RealmName,
+			NotifyLogID:    notifyLog.ID,
+			TriggeredLogID: log.ID,
+			ContactID:      notifyLog.ContactID,
+			ChannelType:    channel,
+			Action:         action,
+			Actor:          actor,
+		}
+		claimed, err := s.mysql.ClaimNotifyActionLog(&record)
+		if err != nil {
+			s.logger.Error("記錄通知操作失敗", zap.Error(err), zap.String("triggered_log_id", formatID(log.ID)))
+			failed++
+			continue
+		}
+		if !claimed {
+			done++
+			continue
+		}
+
+		if err := s.applyNotifyAction(log, action, actor, now); err != nil {
+			record.Error = err.Error()
+			failed++
+			if err := s.mysql.UpdateNotifyActionLogError(&record); err != nil {
+				s.logger.Error("記錄通知操作失敗原因失敗", zap.Error(err), zap.String("triggered_log_id", formatID(log.ID)))
+			}
+		} else {
+			succeeded++
+		}
+	}
+
+	if succeeded == 0 && failed == 0 && done > 0 {
+		return fmt.Sprintf("此通知已執行過「%s」", NotifyActionLabel(action)), nil
+	}
+
+	s.logger.Info("執行通知訊息操作",
+		zap.String("notify_log_id", formatID(notifyLog.ID)),
+		zap.String("action", action),
+		zap.String("channel", channel),
+		zap.String("actor", actor),
+		zap.Int("succeeded", succeeded),
+		zap.Int("failed", failed))
+
+	result := fmt.Sprintf("%s：%d 個告警完成", NotifyActionLabel(action), succeeded)
+	if failed > 0 {
+		result += fmt.Sprintf("，%d 個失敗", failed)
+	}
+	return result, nil
+}
+
+// applyNotifyAction 將操作對應到告警狀態：確認、建立 1 小時靜默或手動恢復
+func (s *Service) applyNotifyAction(log models.TriggeredLog, action, actor string, now time.Time) error {
+	id := hex.EncodeToString(log.ID)
+	var err error
+	switch action {
+	case NotifyActionAck:
+		_, err = s.AckTriggeredLog(log.RealmName, id, actor)
+	case NotifyActionSilence:
+		_, err = s.muteService.CreateSilence(log.RealmName, actor, models.SilenceResponse{
+			TriggeredLogID: id,
+			StartsAt:       now.Unix(),
+			EndsAt:         now.Add(actionSilenceDuration).Unix(),
+			Comment:        "由通知訊息靜默",
+		})
+	case NotifyActionResolve:
+		_, err = s.ResolveTriggeredLog(log.RealmName, id, actor)
+	}
+	return err
+}
+
+// getActionNotifyLog 獲取操作權杖對應的通知日誌
+func (s *Service) getActionNotifyLog(id []byte) (*models.NotifyLog, error) {
+	notifyLog, err := s.mysql.GetNotifyLog(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierrors.ErrNotFound
+		}
+		return nil, err
+	}
+	return notifyLog, nil
+}
+
+// ListNotifyActionLogs 獲取告警由通知訊息執行的操作紀錄
+func (s *Service) ListNotifyActionLogs(realm, id string) ([]models.NotifyActionLog, error) {
+	log, err := s.getRealmTriggeredLog(realm, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.mysql.ListNotifyActionLogs(log.ID)
+}
+
+// HandleSlackCallback 處理 Slack interactive actions 回呼
+// 簽章為 v0=hex(HMAC-SHA256(signing secret, "v0:{timestamp}:{body}"))
+func (s *Service) HandleSlackCallback(timestamp, signature string, body []byte) (string, error) {
+	secret := s.config.Callback.SlackSigningSecret
+	if secret == "" {
+		return "", apierrors.ErrNotFound
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(ts, 0)).Abs() > callbackMaxSkew {
+		return "", apierrors.NewAPIError(403, "Slack 回呼時間戳無效", nil)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	if !hmac.Equal([]byte(signature), []byte("v0="+hex.EncodeToString(mac.Sum(nil)))) {
+		return "", apierrors.NewAPIError(403, "Slack 回呼簽章無效", nil)
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return "", apierrors.ErrInvalidPayload
+	}
+	var payload struct {
+		User struct {
+			ID       string `json:"id"`
+			Username string `json:"username"`
+			Name     string `json:"name"`
+		} `json:"user"`
+		Actions []struct {
+			ActionID string `json:"action_id"`
+			Value    string `json:"value"`
+		} `json:"actions"`
+	}
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil || len(payload.Actions) == 0 {
+		return "", apierrors.ErrInvalidPayload
+	}
+	return s.ExecuteNotifyAction(payload.Actions[0].Value, "slack",
+		"slack:"+firstNonEmpty(payload.User.Username, payload.User.Name, payload.User.ID))
+}
+
+// HandleLineCallback 處理 LINE webhook 的 postback 事件
+// 簽章為 base64(HMAC-SHA256(channel secret, body))；單一事件處理失敗只記錄錯誤，LINE 要求 webhook 一律回應 200
+func (s *Service) HandleLineCallback(signature string, body []byte) error {
+	secret := s.config.Callback.LineChannelSecret
+	if secret == "" {
+		return apierrors.ErrNotFound
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal([]byte(signature), []byte(base64.StdEncoding.EncodeToString(mac.Sum(nil)))) {
+		return apierrors.NewAPIError(403, "LINE 回呼簽章無效", nil)
+	}
+
+	var webhook struct {
+		Events []struct {
+			Type   string `json:"type"`
+			Source struct {
+				UserID string `json:"userId"`
+			} `json:"source"`
+			Postback struct {
+				Data string `json:"data"`
+			} `json:"postback"`
+		} `json:"events"`
+	}
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return apierrors.ErrInvalidPayload
+	}
+	for _, event := range webhook.Events {
+		if event.Type != "postback" {
+			continue
+		}
+		if _, err := s.ExecuteNotifyAction(event.Postback.Data, "line", "line:"+event.Source.UserID); err != nil {
+			s.logger.Error("處理 LINE postback 失敗", zap.Error(err), zap.String("user_id", event.Source.UserID))
+		}
+	}
+	return nil
+}
+
+// HandleTeamsCallback 處理 Teams Action.Submit 回呼 (outgoing webhook)
+// Authorization 為 "HMAC " + base64(HMAC-SHA256(base64 解碼的安全性權杖, body))
+func (s *Service) HandleTeamsCallback(authorization string, body []byte) (string, error) {
+	secret := s.config.Callback.TeamsSecret
+	if secret == "" {
+		return "", apierrors.ErrNotFound
+	}
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return "", fmt.Errorf("Teams 安全性權杖格式錯誤: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	signature := strings.TrimPrefix(authorization, "HMAC ")
+	if !hmac.Equal([]byte(signature), []byte(base64.StdEncoding.EncodeToString(mac.Sum(nil)))) {
+		return "", apierrors.NewAPIError(403, "Teams 回呼簽章無效", nil)
+	}
+
+	var activity struct {
+		From struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"from"`
+		Value struct {
+			Token string `json:"token"`
+		} `json:"value"`
+	}
+	if err := json.Unmarshal(body, &activity); err != nil || activity.Value.Token == "" {
+		return "", apierrors.ErrInvalidPayload
+	}
+	return s.ExecuteNotifyAction(activity.Value.Token, "teams",
+		"teams:"+firstNonEmpty(activity.From.Name, activity.From.ID))
+}
+
+// firstNonEmpty 回傳第一個非空字串
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
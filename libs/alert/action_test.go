@@ -0,0 +1,203 @@
+package alert
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+)
+
+// 驗證失敗時的錯誤訊息
+const (
+	errTokenFormat  = "操作權杖格式錯誤"
+	errTokenSign    = "操作權杖簽章無效"
+	errTokenExpired = "操作連結已失效"
+	errSlackTime    = "Slack 回呼時間戳無效"
+	errSlackSign    = "Slack 回呼簽章無效"
+	errLineSign     = "LINE 回呼簽章無效"
+	errTeamsSign    = "Teams 回呼簽章無效"
+)
+
+func TestParseActionToken(t *testing.T) {
+	s := &Service{config: models.AlertConfig{ActionSecret: "secret"}}
+	now := time.Unix(1700000000, 0)
+	notifyLogID := []byte("0123456789abcdef")
+	token := s.actionToken(notifyLogID, NotifyActionAck, now)
+	parts := strings.Split(token, ".")
+
+	// resign 以 payload 重新簽章，模擬持有金鑰者產生的權杖
+	resign := func(payload string) string { return payload + "." + s.actionSignature(payload) }
+
+	cases := []struct {
+		name    string
+		token   string
+		now     time.Time
+		wantErr string
+	}{
+		{name: "有效權杖", token: token, now: now},
+		{name: "到期前一刻仍有效", token: token, now: now.Add(actionTokenExpiry)},
+		{name: "過期", token: token, now: now.Add(actionTokenExpiry + time.Second), wantErr: errTokenExpired},
+		{name: "竄改操作", token: strings.Join([]string{parts[0], NotifyActionResolve, parts[2], parts[3]}, "."), now: now, wantErr: errTokenSign},
+		{name: "竄改到期時間", token: strings.Join([]string{parts[0], parts[1], strconv.FormatInt(now.Add(365*24*time.Hour).Unix(), 10), parts[3]}, "."), now: now, wantErr: errTokenSign},
+		{name: "竄改簽章", token: strings.Join(parts[:3], ".") + "." + strings.Repeat("0", 64), now: now, wantErr: errTokenSign},
+		{name: "其他金鑰簽章", token: (&Service{config: models.AlertConfig{ActionSecret: "other"}}).actionToken(notifyLogID, NotifyActionAck, now), now: now, wantErr: errTokenSign},
+		{name: "格式錯誤", token: "abc", now: now, wantErr: errTokenFormat},
+		{name: "不支援的操作", token: resign(fmt.Sprintf("%s.%s.%s", parts[0], "delete", parts[2])), now: now, wantErr: "不支援的操作"},
+		{name: "通知日誌 ID 錯誤", token: resign(fmt.Sprintf("%s.%s.%s", "zz", NotifyActionAck, parts[2])), now: now, wantErr: apierrors.ErrInvalidID.Error()},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, action, err := s.parseActionToken(tc.token, tc.now)
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("err = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseActionToken: %v", err)
+			}
+			if string(id) != string(notifyLogID) || action != NotifyActionAck {
+				t.Fatalf("got (%x, %s), want (%x, %s)", id, action, notifyLogID, NotifyActionAck)
+			}
+		})
+	}
+
+	s.config.ActionSecret = ""
+	if _, _, err := s.parseActionToken(token, now); !errors.Is(err, apierrors.ErrNotFound) {
+		t.Fatalf("未設定金鑰: err = %v, want ErrNotFound", err)
+	}
+}
+
+// 回呼簽章通過後以格式錯誤的操作權杖執行，回傳權杖格式錯誤表示已通過通道驗證
+const callbackToken = "invalid-token"
+
+func TestHandleSlackCallback(t *testing.T) {
+	s := &Service{config: models.AlertConfig{ActionSecret: "secret"}}
+	s.config.Callback.SlackSigningSecret = "slack-secret"
+	body := []byte("payload=" + url.QueryEscape(`{"user":{"username":"ops"},"actions":[{"action_id":"ack","value":"`+callbackToken+`"}]}`))
+	sign := func(timestamp string, body []byte) string {
+		mac := hmac.New(sha256.New, []byte("slack-secret"))
+		fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+		return "v0=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-callbackMaxSkew-time.Minute).Unix(), 10)
+	future := strconv.FormatInt(time.Now().Add(callbackMaxSkew+time.Minute).Unix(), 10)
+
+	cases := []struct {
+		name      string
+		timestamp string
+		signature string
+		body      []byte
+		wantErr   string
+	}{
+		{name: "簽章正確", timestamp: now, signature: sign(now, body), body: body, wantErr: errTokenFormat},
+		{name: "時間戳過舊", timestamp: stale, signature: sign(stale, body), body: body, wantErr: errSlackTime},
+		{name: "時間戳超前", timestamp: future, signature: sign(future, body), body: body, wantErr: errSlackTime},
+		{name: "時間戳格式錯誤", timestamp: "abc", signature: sign("abc", body), body: body, wantErr: errSlackTime},
+		{name: "簽章錯誤", timestamp: now, signature: sign(now, []byte("payload=other")), body: body, wantErr: errSlackSign},
+		{name: "簽章使用其他時間戳", timestamp: now, signature: sign(stale, body), body: body, wantErr: errSlackSign},
+		{name: "缺少操作", timestamp: now, signature: sign(now, []byte("payload={}")), body: []byte("payload={}"), wantErr: apierrors.ErrInvalidPayload.Error()},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := s.HandleSlackCallback(tc.timestamp, tc.signature, tc.body)
+			if err == nil || err.Error() != tc.wantErr {
+				t.Fatalf("err = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+
+	s.config.Callback.SlackSigningSecret = ""
+	if _, err := s.HandleSlackCallback(now, sign(now, body), body); !errors.Is(err, apierrors.ErrNotFound) {
+		t.Fatalf("未設定回呼驗證: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestHandleLineCallback(t *testing.T) {
+	s := &Service{logger: nopLogger{}, config: models.AlertConfig{ActionSecret: "secret"}}
+	s.config.Callback.LineChannelSecret = "line-secret"
+	body := []byte(`{"events":[{"type":"postback","source":{"userId":"U1"},"postback":{"data":"` + callbackToken + `"}}]}`)
+	sign := func(secret string, body []byte) string {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	cases := []struct {
+		name      string
+		signature string
+		body      []byte
+		wantErr   string
+	}{
+		// 事件處理失敗只記錄錯誤，仍回應成功
+		{name: "簽章正確", signature: sign("line-secret", body), body: body},
+		{name: "其他金鑰簽章", signature: sign("other", body), body: body, wantErr: errLineSign},
+		{name: "內容被竄改", signature: sign("line-secret", body), body: []byte(strings.Replace(string(body), "U1", "U2", 1)), wantErr: errLineSign},
+		{name: "缺少簽章", signature: "", body: body, wantErr: errLineSign},
+		{name: "內容格式錯誤", signature: sign("line-secret", []byte("{")), body: []byte("{"), wantErr: apierrors.ErrInvalidPayload.Error()},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := s.HandleLineCallback(tc.signature, tc.body)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("HandleLineCallback: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.wantErr {
+				t.Fatalf("err = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandleTeamsCallback(t *testing.T) {
+	key := []byte("teams-security-token")
+	s := &Service{config: models.AlertConfig{ActionSecret: "secret"}}
+	s.config.Callback.TeamsSecret = base64.StdEncoding.EncodeToString(key)
+	body := []byte(`{"from":{"id":"29:1","name":"ops"},"value":{"token":"` + callbackToken + `"}}`)
+	sign := func(key, body []byte) string {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(body)
+		return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	cases := []struct {
+		name          string
+		authorization string
+		body          []byte
+		wantErr       string
+	}{
+		{name: "簽章正確", authorization: "HMAC " + sign(key, body), body: body, wantErr: errTokenFormat},
+		{name: "以原始權杖 (未解碼) 簽章", authorization: "HMAC " + sign([]byte(s.config.Callback.TeamsSecret), body), body: body, wantErr: errTeamsSign},
+		{name: "內容被竄改", authorization: "HMAC " + sign(key, body), body: []byte(strings.Replace(string(body), "ops", "admin", 1)), wantErr: errTeamsSign},
+		{name: "缺少 Authorization", authorization: "", body: body, wantErr: errTeamsSign},
+		{name: "缺少操作權杖", authorization: "HMAC " + sign(key, []byte(`{}`)), body: []byte(`{}`), wantErr: apierrors.ErrInvalidPayload.Error()},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := s.HandleTeamsCallback(tc.authorization, tc.body)
+			if err == nil || err.Error() != tc.wantErr {
+				t.Fatalf("err = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+
+	s.config.Callback.TeamsSecret = "not base64!"
+	if _, err := s.HandleTeamsCallback("HMAC "+sign(key, body), body); err == nil {
+		t.Fatal("expected error for invalid teams secret")
+	}
+}
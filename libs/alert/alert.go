@@ -13,11 +13,15 @@ import (
 	"time"
 
 	"github.com/detect-viz/shared-lib/contacts"
+	"github.com/detect-viz/shared-lib/escalations"
 	"github.com/detect-viz/shared-lib/infra/logger"
 	"github.com/detect-viz/shared-lib/infra/scheduler"
 	"github.com/detect-viz/shared-lib/models"
 	"github.com/detect-viz/shared-lib/models/common"
+	"github.com/detect-viz/shared-lib/mutes"
 	"github.com/detect-viz/shared-lib/notifier"
+	"github.com/detect-viz/shared-lib/oncall"
+	"github.com/detect-viz/shared-lib/routes"
 	"github.com/detect-viz/shared-lib/rules"
 	"github.com/detect-viz/shared-lib/storage/mysql"
 	"github.com/detect-viz/shared-lib/templates"
@@ -31,21 +35,27 @@ import (
 // AutoApplyTarget - 自動匹配監控對象
 // AutoApplyRule - 自動匹配告警規則
 // GetActiveRules - 查詢符合條件的規則
-// CheckSingle - 執行異常檢測 (CheckAbsolute / CheckAmplitude)
+// CheckSingle - 執行異常檢測 (依 detection_type 選擇 Detector)
 // updateAlertState - 更新 rule_states
 // processTriggerLog - 建立 TriggeredLog 記錄
 
 type Service struct {
-	ruleService      rules.Service
-	contactService   contacts.Service
-	notifyService    notifier.Service
-	schedulerService scheduler.Service
-	templateService  templates.Service
-	config           models.AlertConfig
-	global           models.GlobalConfig
-	globalRules      map[string]map[string]map[string][]models.Rule
-	logger           logger.Logger
-	mysql            *mysql.Client
+	ruleService       rules.Service
+	contactService    contacts.Service
+	notifyService     notifier.Service
+	schedulerService  scheduler.Service
+	templateService   templates.Service
+	muteService       mutes.Service
+	escalationService escalations.Service
+	onCallService     oncall.Service
+	routeService      routes.Service
+	config            models.AlertConfig
+	global            models.GlobalConfig
+	globalRules       map[string]map[string]map[string][]models.Rule
+	logger            logger.Logger
+	mysql             *mysql.Client
+	limiter           *notifyLimiter
+	lastSeen          sync.Map // 規則 ID -> 最後收到數據的時間 (unix 秒)，供缺值偵測定期檢查使用
 }
 
 func (s *Service) GetRuleService() rules.Service {
@@ -60,6 +70,22 @@ func (s *Service) GetContactService() contacts.Service {
 	return s.contactService
 }
 
+func (s *Service) GetMuteService() mutes.Service {
+	return s.muteService
+}
+
+func (s *Service) GetEscalationService() escalations.Service {
+	return s.escalationService
+}
+
+func (s *Service) GetOnCallService() oncall.Service {
+	return s.onCallService
+}
+
+func (s *Service) GetRouteService() routes.Service {
+	return s.routeService
+}
+
 func (s *Service) GetSchedulerService() scheduler.Service {
 	return s.schedulerService
 }
@@ -83,17 +109,26 @@ func NewService(
 	contact contacts.Service,
 	scheduler scheduler.Service,
 	template templates.Service,
+	mute mutes.Service,
+	escalation escalations.Service,
+	onCall oncall.Service,
+	route routes.Service,
 ) *Service {
 	alertService := &Service{
-		ruleService:      rule,
-		contactService:   contact,
-		notifyService:    notify,
-		schedulerService: scheduler,
-		templateService:  template,
-		config:           config,
-		global:           global,
-		logger:           logSvc,
-		mysql:            mysqlClient,
+		ruleService:       rule,
+		contactService:    contact,
+		notifyService:     notify,
+		schedulerService:  scheduler,
+		templateService:   template,
+		muteService:       mute,
+		escalationService: escalation,
+		onCallService:     onCall,
+		routeService:      route,
+		config:            config,
+		global:            global,
+		logger:            logSvc,
+		mysql:             mysqlClient,
+		limiter:           newNotifyLimiter(),
 	}
 
 	// 註冊通知任務
@@ -101,6 +136,11 @@ func NewService(
 		logSvc.Error("註冊通知任務失敗", zap.Error(err))
 	}
 
+	// 註冊缺值偵測定期檢查任務
+	if err := alertService.registerStaleCheckTask(); err != nil {
+		logSvc.Error("註冊缺值偵測任務失敗", zap.Error(err))
+	}
+
 	// 載入告警遷移
 	alertService.mysql.LoadAlertMigrate(config.MigratePath)
 
@@ -276,9 +316,18 @@ func (s *Service) AutoApply(payload models.AlertPayload) ([]models.Rule, error)
 					newRule.InfoThreshold = rule.InfoThreshold
 					newRule.WarnThreshold = rule.WarnThreshold
 					newRule.CritThreshold = rule.CritThreshold
+					newRule.InfoLowerThreshold = rule.InfoLowerThreshold
+					newRule.WarnLowerThreshold = rule.WarnLowerThreshold
+					newRule.CritLowerThreshold = rule.CritLowerThreshold
+					newRule.RecoveryThreshold = rule.RecoveryThreshold
+					newRule.RecoveryLowerThreshold = rule.RecoveryLowerThreshold
+					newRule.EvaluationMode = rule.EvaluationMode
+					newRule.EvaluationPoints = rule.EvaluationPoints
 					newRule.Duration = rule.Duration
 					newRule.Times = rule.Times
+					newRule.NotifyTimes = rule.NotifyTimes
 					newRule.SilencePeriod = rule.SilencePeriod
+					newRule.EscalationPolicyID = rule.EscalationPolicyID
 					createdRules = append(createdRules, newRule)
 				}
 			} else {
@@ -314,6 +363,11 @@ func (s *Service) AutoApply(payload models.AlertPayload) ([]models.Rule, error)
 							newRule.WarnThreshold = metricRule.Thresholds.Warn
 						}
 						newRule.CritThreshold = metricRule.Thresholds.Crit
+						newRule.InfoLowerThreshold = metricRule.Thresholds.InfoLower
+						newRule.WarnLowerThreshold = metricRule.Thresholds.WarnLower
+						newRule.CritLowerThreshold = metricRule.Thresholds.CritLower
+						newRule.RecoveryThreshold = metricRule.Thresholds.Recovery
+						newRule.RecoveryLowerThreshold = metricRule.Thresholds.RecoveryLower
 
 						// 設置其他參數
 						// 解析持續時間
@@ -326,6 +380,7 @@ func (s *Service) AutoApply(payload models.AlertPayload) ([]models.Rule, error)
 						// 默認值
 						times := 1
 						newRule.Times = times
+						newRule.NotifyTimes = times
 
 						silencePeriod := "1h" // 默認 1 小時
 						newRule.SilencePeriod = silencePeriod
@@ -515,10 +570,32 @@ func (s *Service) matchRulesFromGlobalRules(payload models.AlertPayload) []model
 			continue
 		}
 
+		partitionName := ""
+		if len(parts) > 1 {
+			partitionName = strings.Join(parts[1:], ":")
+		}
+
+		// 表達式規則沒有單一 metric_raw_name，payload 含任一引用的指標即匹配
+		if metricRule.DetectionType == "expression" {
+			keys, err := expressionMetricKeys(metricRule, partitionName)
+			if err != nil {
+				s.logger.Error("解析表達式失敗",
+					zap.String("metric_rule_uid", metricRuleUID),
+					zap.Error(err))
+				continue
+			}
+			for _, key := range keys {
+				if metricKeyMap[key] {
+					matchedRules = append(matchedRules, rules...)
+					break
+				}
+			}
+			continue
+		}
+
 		// 構建完整的 metricKey
 		metricKey := metricRule.MetricRawName
-		if len(parts) > 1 {
-			partitionName := strings.Join(parts[1:], ":")
+		if partitionName != "" {
 			metricKey = metricKey + ":" + partitionName
 		}
 
@@ -732,6 +809,10 @@ func (s *Service) ProcessAlert(payload models.AlertPayload) error {
 		}
 
 		metricValues, ok := payload.Data[metricKey]
+		if detector, found := GetDetector(metricRule.DetectionType); !ok && found && handlesMissingData(detector) {
+			// 缺值偵測：沒有數據仍需檢查
+			ok = true
+		}
 		if !ok {
 			s.logger.Debug("找不到對應的 metric 數據",
 				zap.String("metric_key", metricKey),
@@ -746,52 +827,25 @@ func (s *Service) ProcessAlert(payload models.AlertPayload) error {
 			metricData = append(metricData, models.MetricValue(mv))
 		}
 
+		// 缺值偵測記錄最後收到數據的時間，供定期檢查使用
+		s.recordLastSeen(rule, metricRule, metricData)
+
 		// 4. 檢查告警邏輯
 		currentTime := time.Now().Unix()
-		exceeded, value, severity := s.CheckSingle(&rule, metricData, currentTime)
-
-		// 5. 更新告警狀態
-		// 無論是否觸發告警，都更新 LastCheckValue
-		var newState *models.RuleState
-		var err error
-		if exceeded {
-			// 如果觸發告警，使用完整的更新邏輯
-			newState, err = s.updateAlertState(rule, value, severity, currentTime)
-		} else {
-			// 如果未觸發告警，僅更新 LastCheckValue
-			s.logger.Debug("未觸發告警，僅更新最後檢查值",
-				zap.String("rule_id", string(rule.ID)),
-				zap.Float64("value", value),
-				zap.String("metric", metricKey))
-			newState, err = s.updateLastCheckValue(rule, value, currentTime)
-		}
-
+		result, err := s.evaluateRule(&rule, metricData, payload.Data, currentTime)
 		if err != nil {
-			s.logger.Error("更新告警狀態失敗",
+			s.logger.Error("規則評估失敗",
 				zap.String("rule_id", string(rule.ID)),
-				zap.Error(err),
+				zap.String("metric_rule_uid", rule.MetricRuleUID),
 				zap.String("resource", payload.Metadata.ResourceName),
-				zap.String("metric", metricKey),
-				zap.Float64("value", value),
-				zap.String("severity", severity))
+				zap.Error(err))
+			// 評估失敗不改變告警狀態，錯誤記錄於進行中的觸發日誌
+			s.recordEvaluationError(rule, result.Details, err, currentTime)
 			continue
 		}
 
-		// 只有在觸發告警時才處理觸發日誌
-		if exceeded {
-			// 6. 處理觸發日誌
-			if err := s.processTriggerLog(rule, metricRule, *newState, value, severity, currentTime); err != nil {
-				s.logger.Error("處理觸發日誌失敗",
-					zap.String("rule_id", string(rule.ID)),
-					zap.Error(err),
-					zap.String("resource", payload.Metadata.ResourceName),
-					zap.String("metric", metricKey),
-					zap.Float64("value", value),
-					zap.String("severity", severity),
-					zap.String("state", newState.State))
-				continue
-			}
-
+		// 5. 更新告警狀態及觸發日誌
+		if s.applyDetectResult(rule, metricRule, metricKey, result, currentTime) {
 			triggeredRuleCounter++
 		}
 	}
@@ -805,210 +859,122 @@ func (s *Service) ProcessAlert(payload models.AlertPayload) error {
 	return nil
 }
 
-// CheckSingle 檢查單個規則是否觸發告警
-func (s *Service) CheckSingle(rule *models.Rule, metricData []models.MetricValue, currentTime int64) (bool, float64, string) {
-	// 獲取對應的 MetricRule
-	var metricRule models.MetricRule
-	metricRule, exists := s.global.MetricRules[rule.MetricRuleUID]
-	if !exists {
-		s.logger.Error("找不到對應的 MetricRule",
-			zap.String("metric_rule_uid", rule.MetricRuleUID))
-		return false, 0, ""
-	}
-
-	// 檢查數據是否足夠
-	if len(metricData) == 0 {
-		s.logger.Warn("沒有數據可供檢查",
-			zap.String("rule_id", string(rule.ID)))
-		return false, 0, ""
-	}
-
-	// 根據 detection_type 選擇檢查方法
-	switch metricRule.DetectionType {
-	case "absolute":
-		return s.checkAbsolute(rule, metricData, metricRule)
-	case "amplitude":
-		return s.checkAmplitude(rule, metricData, metricRule)
-	default:
-		// 默認使用 absolute 檢查
-		return s.checkAbsolute(rule, metricData, metricRule)
-	}
-}
-
-// checkAbsolute 檢查絕對值是否超過閾值
-func (s *Service) checkAbsolute(rule *models.Rule, metricData []models.MetricValue, metricRule models.MetricRule) (bool, float64, string) {
-	// 檢查數據是否足夠
-	if len(metricData) == 0 {
-		return false, 0, ""
-	}
-
-	// 獲取時間窗口（Duration）
-	durationSeconds := 300 // 默認 5 分鐘
-	if rule.Duration != "" {
-		// 解析 duration 字符串，例如 "5m"、"1h" 等
-		durationValue, unit := parseDuration(rule.Duration)
-		switch unit {
-		case "s":
-			durationSeconds = durationValue
-		case "m":
-			durationSeconds = durationValue * 60
-		case "h":
-			durationSeconds = durationValue * 3600
-		case "d":
-			durationSeconds = durationValue * 86400
-		}
-	}
-
-	// 獲取時間窗口內的數據點
-	latestTimestamp := metricData[len(metricData)-1].Timestamp
-	windowStartTimestamp := latestTimestamp - int64(durationSeconds)
-
-	var windowData []models.MetricValue
-	for _, point := range metricData {
-		if point.Timestamp >= windowStartTimestamp {
-			windowData = append(windowData, point)
-		}
+// applyDetectResult 依偵測結果更新告警狀態，觸發或恢復時處理觸發日誌，回傳是否已觸發並記錄
+func (s *Service) applyDetectResult(rule models.Rule, metricRule models.MetricRule, metricKey string, result DetectResult, currentTime int64) bool {
+	exceeded, value, severity := result.Exceeded, result.Value, result.Severity
+
+	// 無論是否觸發告警，都更新 LastCheckValue
+	var newState *models.RuleState
+	var err error
+	if exceeded {
+		// 如果觸發告警，使用完整的更新邏輯
+		newState, err = s.updateAlertState(rule, value, severity, currentTime)
+	} else if s.checkRecovered(&rule, metricRule, value) {
+		// 未觸發且達到恢復條件，恢復告警狀態
+		newState, err = s.updateAlertState(rule, value, "", currentTime)
+	} else {
+		// 如果未觸發告警，僅更新 LastCheckValue
+		s.logger.Debug("未觸發告警，僅更新最後檢查值",
+			zap.String("rule_id", string(rule.ID)),
+			zap.Float64("value", value),
+			zap.String("metric", metricKey))
+		newState, err = s.updateLastCheckValue(rule, value, currentTime)
 	}
 
-	// 如果窗口內沒有足夠的數據點，則使用所有可用的數據點
-	if len(windowData) < 2 {
-		windowData = metricData
+	if err != nil {
+		s.logger.Error("更新告警狀態失敗",
+			zap.String("rule_id", string(rule.ID)),
+			zap.Error(err),
+			zap.String("resource", rule.Target.ResourceName),
+			zap.String("metric", metricKey),
+			zap.Float64("value", value),
+			zap.String("severity", severity))
+		return false
 	}
 
-	// 檢查窗口內的所有數據點是否都超過閾值
-	exceededCount := 0
-	totalPoints := len(windowData)
-	var lastValue float64
-	var lastSeverity string
-
-	// 檢查每個數據點
-	for _, point := range windowData {
-		value := point.Value * metricRule.Scale
-		lastValue = value // 記錄最後檢查的值
-
-		// 檢查是否超過閾值
-		operator := metricRule.Operator
-		if operator == "" {
-			operator = "gt" // 默認為大於
-		}
-
-		// 檢查嚴重程度（從高到低）
-		exceeded := false
-		if rule.CritThreshold > 0 {
-			if (operator == "gt" && value > rule.CritThreshold) ||
-				(operator == "lt" && value < rule.CritThreshold) {
-				exceeded = true
-				lastSeverity = "crit"
-			}
-		}
-
-		if !exceeded && rule.WarnThreshold != nil && *rule.WarnThreshold > 0 {
-			if (operator == "gt" && value > *rule.WarnThreshold) ||
-				(operator == "lt" && value < *rule.WarnThreshold) {
-				exceeded = true
-				lastSeverity = "warn"
-			}
-		}
-
-		if !exceeded && rule.InfoThreshold != nil && *rule.InfoThreshold > 0 {
-			if (operator == "gt" && value > *rule.InfoThreshold) ||
-				(operator == "lt" && value < *rule.InfoThreshold) {
-				exceeded = true
-				lastSeverity = "info"
-			}
-		}
-
-		if exceeded {
-			exceededCount++
+	// 觸發告警或恢復時處理觸發日誌
+	if exceeded || newState.State == "resolved" {
+		if err := s.processTriggerLog(rule, metricRule, *newState, value, severity, result.Details, result.Window, currentTime); err != nil {
+			s.logger.Error("處理觸發日誌失敗",
+				zap.String("rule_id", string(rule.ID)),
+				zap.Error(err),
+				zap.String("resource", rule.Target.ResourceName),
+				zap.String("metric", metricKey),
+				zap.Float64("value", value),
+				zap.String("severity", severity),
+				zap.String("state", newState.State))
+			return false
 		}
 	}
-
-	// 檢查是否所有數據點都超過閾值
-	// 如果窗口內的所有數據點都超過閾值，則觸發告警
-	if exceededCount == totalPoints && totalPoints > 0 {
-		return true, lastValue, lastSeverity
-	}
-
-	// 如果沒有達到所需的條件，則返回未觸發
-	return false, lastValue, ""
+	return exceeded
 }
 
-// checkAmplitude 檢查振幅是否超過閾值
-func (s *Service) checkAmplitude(rule *models.Rule, metricData []models.MetricValue, metricRule models.MetricRule) (bool, float64, string) {
-	// 檢查數據是否足夠
-	if len(metricData) < 2 {
-		return false, 0, ""
-	}
-
-	// 獲取時間窗口
-	durationSeconds := 300 // 默認 5 分鐘
-	if rule.Duration != "" {
-		// 解析 duration 字符串，例如 "5m"、"1h" 等
-		durationValue, unit := parseDuration(rule.Duration)
-		switch unit {
-		case "s":
-			durationSeconds = durationValue
-		case "m":
-			durationSeconds = durationValue * 60
-		case "h":
-			durationSeconds = durationValue * 3600
-		case "d":
-			durationSeconds = durationValue * 86400
-		}
-	}
-
-	// 獲取時間窗口內的數據點
-	latestTimestamp := metricData[len(metricData)-1].Timestamp
-	windowStartTimestamp := latestTimestamp - int64(durationSeconds)
-
-	var windowData []models.MetricValue
-	for _, point := range metricData {
-		if point.Timestamp >= windowStartTimestamp {
-			windowData = append(windowData, point)
-		}
+// CheckSingle 檢查單個規則是否觸發告警
+// metricData 只有單一指標，expression 規則需要多個指標，請經由 ProcessAlert 評估
+func (s *Service) CheckSingle(rule *models.Rule, metricData []models.MetricValue, currentTime int64) (bool, float64, string, error) {
+	if metricRule, ok := s.global.MetricRules[rule.MetricRuleUID]; ok && metricRule.DetectionType == "expression" {
+		return false, 0, "", fmt.Errorf("expression 規則不支援單一指標檢查 [metric_rule_uid:%s]", rule.MetricRuleUID)
 	}
 
-	// 如果窗口內沒有足夠的數據點，則使用所有可用的數據點
-	if len(windowData) < 2 {
-		windowData = metricData
+	result, err := s.evaluateRule(rule, metricData, nil, currentTime)
+	if err != nil {
+		s.logger.Error("規則評估失敗",
+			zap.String("rule_id", string(rule.ID)),
+			zap.String("metric_rule_uid", rule.MetricRuleUID),
+			zap.Error(err))
+		return false, 0, "", err
 	}
+	return result.Exceeded, result.Value, result.Severity, nil
+}
 
-	// 計算最大值和最小值
-	var maxValue, minValue float64
-	maxValue = windowData[0].Value * metricRule.Scale
-	minValue = windowData[0].Value * metricRule.Scale
-
-	for _, point := range windowData {
-		value := point.Value * metricRule.Scale
-		if value > maxValue {
-			maxValue = value
-		}
-		if value < minValue {
-			minValue = value
-		}
+// evaluateRule 依 detection_type 選擇偵測器執行檢查，series 為 payload 中同一監控對象的所有指標
+func (s *Service) evaluateRule(rule *models.Rule, metricData []models.MetricValue, series map[string][]models.MetricValue, currentTime int64) (DetectResult, error) {
+	// 獲取對應的 MetricRule
+	var metricRule models.MetricRule
+	metricRule, exists := s.global.MetricRules[rule.MetricRuleUID]
+	if !exists {
+		return DetectResult{}, fmt.Errorf("找不到對應的 MetricRule [metric_rule_uid:%s]", rule.MetricRuleUID)
 	}
 
-	// 計算振幅
-	if minValue == 0 {
-		// 避免除以零
-		minValue = 0.000001
+	// 根據 detection_type 選擇偵測器
+	detector, ok := GetDetector(metricRule.DetectionType)
+	if !ok {
+		return DetectResult{}, fmt.Errorf("不支援的 detection_type [metric_rule_uid:%s, detection_type:%s]",
+			rule.MetricRuleUID, metricRule.DetectionType)
 	}
-	amplitude := (maxValue - minValue) / minValue * 100
 
-	// 檢查是否超過閾值
-	if rule.CritThreshold > 0 && amplitude > rule.CritThreshold {
-		return true, amplitude, "crit"
+	// 檢查數據是否足夠
+	if len(metricData) == 0 && !handlesMissingData(detector) {
+		s.logger.Warn("沒有數據可供檢查",
+			zap.String("rule_id", string(rule.ID)))
+		return DetectResult{}, nil
 	}
 
-	if rule.WarnThreshold != nil && *rule.WarnThreshold > 0 && amplitude > *rule.WarnThreshold {
-		return true, amplitude, "warn"
-	}
+	// 獲取時間窗口（Duration）
+	seconds := durationSeconds(rule.Duration)
+
+	window := windowData(metricData, seconds)
+	result, err := runDetector(detector, DetectInput{
+		Rule:          rule,
+		MetricRule:    metricRule,
+		Data:          metricData,
+		Series:        series,
+		Window:        window,
+		WindowSeconds: seconds,
+		CurrentTime:   currentTime,
+		LastSeen:      s.lastSeenAt(rule.ID),
+	})
+	result.Window = scaledWindow(window, metricRule.Scale)
+	return result, err
+}
 
-	if rule.InfoThreshold != nil && *rule.InfoThreshold > 0 && amplitude > *rule.InfoThreshold {
-		return true, amplitude, "info"
+// checkRecovered 檢查未觸發的規則是否達到恢復條件 (hysteresis)
+func (s *Service) checkRecovered(rule *models.Rule, metricRule models.MetricRule, value float64) bool {
+	detector, ok := GetDetector(metricRule.DetectionType)
+	if !ok {
+		return true
 	}
-
-	return false, amplitude, ""
+	return isRecovered(rule, detectorOperator(detector, metricRule), value)
 }
 
 // 解析 duration 字符串，例如 "5m"、"1h" 等
@@ -1139,6 +1105,20 @@ func (s *Service) updateAlertState(rule models.Rule, triggeredValue float64, sev
 
 	// 根據是否觸發異常更新狀態
 	if severity != "" {
+		// 連續超過閾值次數
+		newState.BreachCounter++
+
+		// consecutive 模式：尚未達到連續次數 (Rule.Times) 前僅記錄次數
+		if evaluationMode(&rule) == EvaluationModeConsecutive &&
+			newState.State != "alerting" &&
+			newState.BreachCounter < requiredTimes(&rule) {
+			if err := s.mysql.UpdateRuleStateWithUpdates(*oldState, newState); err != nil {
+				return nil, fmt.Errorf("更新規則狀態失敗 [規則ID:%s, 資源:%s, 分區:%s, 連續次數:%d]: %w",
+					string(rule.ID), rule.Target.ResourceName, rule.Target.PartitionName, newState.BreachCounter, err)
+			}
+			return &newState, nil
+		}
+
 		// 異常觸發
 		newState.LastTriggeredValue = &triggeredValue
 		newState.LastTriggeredSeverity = &severity
@@ -1175,7 +1155,8 @@ func (s *Service) updateAlertState(rule models.Rule, triggeredValue float64, sev
 		stackDuration := currentTime - *newState.FirstTriggeredAt
 
 		// 如果超過持續時間，則更新為 alerting
-		if stackDuration >= duration {
+		// n_of_m / consecutive 模式已由觸發次數判斷，不再等待持續時間
+		if stackDuration >= duration || evaluationMode(&rule) != EvaluationModeAll {
 			// 如果狀態不是 alerting，則更新為 alerting
 			if newState.State != "alerting" {
 				newState.State = "alerting"
@@ -1185,14 +1166,14 @@ func (s *Service) updateAlertState(rule models.Rule, triggeredValue float64, sev
 			if newState.State == "alerting" && !inSilencePeriod {
 				newState.ContactCounter++
 
-				// 檢查是否達到連續觸發通知的次數
-				requiredTimes := rule.Times
-				if requiredTimes <= 0 {
-					requiredTimes = 1 // 默認至少需要一次
+				// 檢查是否達到連續通知的次數 (Rule.NotifyTimes，與判斷模式的 Rule.Times 無關)
+				notifyTimes := rule.NotifyTimes
+				if notifyTimes <= 0 {
+					notifyTimes = 1 // 默認至少需要一次
 				}
 
-				// 如果達到連續觸發次數，則設置靜默期
-				if newState.ContactCounter >= requiredTimes {
+				// 如果達到連續通知次數，則設置靜默期
+				if newState.ContactCounter >= notifyTimes {
 					// 設置靜默期開始時間
 					newState.SilenceStartAt = &currentTime
 
@@ -1242,6 +1223,7 @@ func (s *Service) updateAlertState(rule models.Rule, triggeredValue float64, sev
 		// 清除觸發相關信息
 		newState.FirstTriggeredAt = nil
 		newState.LastTriggeredSeverity = nil
+		newState.BreachCounter = 0
 
 		// 如果不在靜默期內，重置通知計數器
 		if !inSilencePeriod {
@@ -1281,8 +1263,9 @@ func (s *Service) updateLastCheckValue(rule models.Rule, value float64, currentT
 	// 創建新狀態，初始化為舊狀態的副本
 	newState := *oldState
 
-	// 只更新最後檢查值
+	// 只更新最後檢查值，未超過閾值時重置連續次數
 	newState.LastCheckValue = value
+	newState.BreachCounter = 0
 
 	s.logger.Debug("更新最後檢查值",
 		zap.String("rule_id", string(rule.ID)),
@@ -1300,7 +1283,7 @@ func (s *Service) updateLastCheckValue(rule models.Rule, value float64, currentT
 }
 
 // processTriggerLog 建立或更新 TriggeredLog 記錄
-func (s *Service) processTriggerLog(rule models.Rule, metricRule models.MetricRule, state models.RuleState, triggeredValue float64, severity string, currentTime int64) error {
+func (s *Service) processTriggerLog(rule models.Rule, metricRule models.MetricRule, state models.RuleState, triggeredValue float64, severity string, details map[string]string, window []models.MetricValue, currentTime int64) error {
 	// 檢查是否在靜默期內
 	inSilencePeriod := false
 	if state.SilenceStartAt != nil && state.SilenceEndAt != nil {
@@ -1324,20 +1307,20 @@ func (s *Service) processTriggerLog(rule models.Rule, metricRule models.MetricRu
 	// 檢查是否需要創建新的 TriggeredLog
 	if state.State == "alerting" && (state.LastTriggeredLogID == nil || len(*state.LastTriggeredLogID) == 0) {
 		// 需要創建新的 TriggeredLog
-		return s.createTriggeredLog(rule, metricRule, state, triggeredValue, severity, currentTime)
+		return s.createTriggeredLog(rule, metricRule, state, triggeredValue, severity, details, window, currentTime)
 	} else if state.State == "alerting" && state.LastTriggeredLogID != nil && len(*state.LastTriggeredLogID) > 0 {
 		// 需要更新現有的 TriggeredLog
-		return s.updateTriggeredLog(rule, metricRule, state, triggeredValue, severity, currentTime)
+		return s.updateTriggeredLog(rule, metricRule, state, triggeredValue, severity, details, window, currentTime)
 	} else if state.State == "resolved" && state.LastTriggeredLogID != nil && len(*state.LastTriggeredLogID) > 0 {
 		// 需要標記 TriggeredLog 為已解決
-		return s.resolveTriggeredLog(rule, state, currentTime)
+		return s.resolveTriggeredLog(rule, state, triggeredValue, currentTime)
 	}
 
 	return nil
 }
 
 // createTriggeredLog 創建新的 TriggeredLog
-func (s *Service) createTriggeredLog(rule models.Rule, metricRule models.MetricRule, state models.RuleState, triggeredValue float64, severity string, currentTime int64) error {
+func (s *Service) createTriggeredLog(rule models.Rule, metricRule models.MetricRule, state models.RuleState, triggeredValue float64, severity string, details map[string]string, window []models.MetricValue, currentTime int64) error {
 	// 序列化 rule 和 state 為 JSON
 	ruleSnapshot, err := json.Marshal(rule)
 	if err != nil {
@@ -1377,6 +1360,11 @@ func (s *Service) createTriggeredLog(rule models.Rule, metricRule models.MetricR
 		stateJSONMap[k] = fmt.Sprintf("%v", v)
 	}
 
+	// 記錄偵測器計算細節 (例如表達式各指標數值)
+	for k, v := range details {
+		stateJSONMap[k] = v
+	}
+
 	// 創建 TriggeredLog
 	triggeredLog := models.TriggeredLog{
 		NotifyState:       "pending",
@@ -1391,7 +1379,8 @@ func (s *Service) createTriggeredLog(rule models.Rule, metricRule models.MetricR
 		RuleStateSnapshot: stateJSONMap,
 		Severity:          severity,
 		TriggeredValue:    triggeredValue,
-		Threshold:         *state.LastTriggeredValue,
+		Threshold:         thresholdBySeverity(&rule, severity),
+		MetricWindow:      window,
 	}
 
 	// 保存到數據庫
@@ -1418,23 +1407,48 @@ func (s *Service) createTriggeredLog(rule models.Rule, metricRule models.MetricR
 }
 
 // updateTriggeredLog 更新現有的 TriggeredLog
-func (s *Service) updateTriggeredLog(rule models.Rule, metricRule models.MetricRule, state models.RuleState, triggeredValue float64, severity string, currentTime int64) error {
+func (s *Service) updateTriggeredLog(rule models.Rule, metricRule models.MetricRule, state models.RuleState, triggeredValue float64, severity string, details map[string]string, window []models.MetricValue, currentTime int64) error {
 	// 獲取現有的 TriggeredLog
-	triggeredLog, err := s.mysql.GetActiveTriggeredLog(rule.ID, rule.Target.ResourceName, metricRule.MetricRawName)
+	triggeredLog, err := s.mysql.GetActiveTriggeredLog(rule.ID, rule.Target.ResourceName)
 	if err != nil {
-		return fmt.Errorf("獲取 TriggeredLog 失敗 [規則ID:%s, 資源:%s, 指標:%s]: %w",
-			string(rule.ID), rule.Target.ResourceName, metricRule.MetricRawName, err)
+		return fmt.Errorf("獲取 TriggeredLog 失敗 [規則ID:%s, 資源:%s]: %w",
+			string(rule.ID), rule.Target.ResourceName, err)
 	}
 
 	// 如果沒有找到活動的 TriggeredLog，則創建一個新的
 	if triggeredLog == nil {
-		return s.createTriggeredLog(rule, metricRule, state, triggeredValue, severity, currentTime)
+		return s.createTriggeredLog(rule, metricRule, state, triggeredValue, severity, details, window, currentTime)
+	}
+
+	// 更新偵測器計算細節，並清除先前的評估錯誤
+	if triggeredLog.RuleStateSnapshot == nil {
+		triggeredLog.RuleStateSnapshot = make(common.JSONMap)
+	}
+	delete(triggeredLog.RuleStateSnapshot, "evaluation_error")
+	delete(triggeredLog.RuleStateSnapshot, "evaluation_error_at")
+	for k, v := range details {
+		triggeredLog.RuleStateSnapshot[k] = v
+	}
+
+	// 已確認的告警升級時取消確認，重新發送通知
+	if triggeredLog.AckedAt != nil && severityLevel(severity) > severityLevel(triggeredLog.Severity) {
+		s.logger.Info("已確認的告警升級，重新通知",
+			zap.String("triggered_log_id", formatID(triggeredLog.ID)),
+			zap.String("from_severity", triggeredLog.Severity),
+			zap.String("to_severity", severity))
+		triggeredLog.AckedBy = nil
+		triggeredLog.AckedAt = nil
+		triggeredLog.NotifyState = NotifyStatePending
 	}
 
 	// 更新 TriggeredLog
 	triggeredLog.LastTriggeredAt = currentTime
 	triggeredLog.TriggeredValue = triggeredValue
 	triggeredLog.Severity = severity
+	triggeredLog.Threshold = thresholdBySeverity(&rule, severity)
+	if len(window) > 0 {
+		triggeredLog.MetricWindow = window
+	}
 
 	// 保存到數據庫
 	if err := s.mysql.UpdateTriggeredLog(*triggeredLog); err != nil {
@@ -1446,9 +1460,9 @@ func (s *Service) updateTriggeredLog(rule models.Rule, metricRule models.MetricR
 }
 
 // resolveTriggeredLog 標記 TriggeredLog 為已解決
-func (s *Service) resolveTriggeredLog(rule models.Rule, state models.RuleState, currentTime int64) error {
+func (s *Service) resolveTriggeredLog(rule models.Rule, state models.RuleState, resolvedValue float64, currentTime int64) error {
 	// 獲取現有的 TriggeredLog
-	triggeredLog, err := s.mysql.GetActiveTriggeredLog(rule.ID, rule.Target.ResourceName, "")
+	triggeredLog, err := s.mysql.GetActiveTriggeredLog(rule.ID, rule.Target.ResourceName)
 	if err != nil {
 		return fmt.Errorf("獲取 TriggeredLog 失敗 [規則ID:%s, 資源:%s]: %w",
 			string(rule.ID), rule.Target.ResourceName, err)
@@ -1461,6 +1475,7 @@ func (s *Service) resolveTriggeredLog(rule models.Rule, state models.RuleState,
 
 	// 標記為已解決
 	triggeredLog.ResolvedAt = &currentTime
+	triggeredLog.ResolvedValue = &resolvedValue
 
 	// 保存到數據庫
 	if err := s.mysql.UpdateTriggeredLog(*triggeredLog); err != nil {
@@ -1481,6 +1496,29 @@ func (s *Service) resolveTriggeredLog(rule models.Rule, state models.RuleState,
 	return nil
 }
 
+// recordEvaluationError 將評估錯誤記錄於進行中的 TriggeredLog 狀態快照
+func (s *Service) recordEvaluationError(rule models.Rule, details map[string]string, evalErr error, currentTime int64) {
+	triggeredLog, err := s.mysql.GetActiveTriggeredLog(rule.ID, rule.Target.ResourceName)
+	if err != nil || triggeredLog == nil {
+		return
+	}
+
+	if triggeredLog.RuleStateSnapshot == nil {
+		triggeredLog.RuleStateSnapshot = make(common.JSONMap)
+	}
+	for k, v := range details {
+		triggeredLog.RuleStateSnapshot[k] = v
+	}
+	triggeredLog.RuleStateSnapshot["evaluation_error"] = evalErr.Error()
+	triggeredLog.RuleStateSnapshot["evaluation_error_at"] = strconv.FormatInt(currentTime, 10)
+
+	if err := s.mysql.UpdateTriggeredLog(*triggeredLog); err != nil {
+		s.logger.Error("記錄評估錯誤失敗",
+			zap.String("rule_id", string(rule.ID)),
+			zap.Error(err))
+	}
+}
+
 // autoApplyContacts 自動套用通知管道
 func (s *Service) autoApplyContacts(rules []models.Rule) error {
 	// 獲取所有設置了 AutoApply 的通知管道
@@ -0,0 +1,190 @@
+package alert
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/chart"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/models/common"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 走勢圖：
+// 觸發日誌記錄評估時的時間窗口 (MetricWindow)，通知時繪製為 PNG / SVG 並加上規則的閾值線
+// 模板資料的每筆告警提供 chart_svg，聯絡人設定 attach_chart = true 時另提供 chart_cid 並附上 PNG：
+// 郵件以內嵌圖片 (<img src="cid:{{ .chart_cid }}">)，Discord 以檔案上傳；
+// Slack / LINE 只接受圖片網址，需設定 alert.public_url 與 alert.chart_secret，
+// 由簽章網址 /api/v1/alert/chart/{id}.png 提供 (chart_url)
+
+const (
+	maxChartAttachments = 4                  // 每則通知最多附上的走勢圖
+	chartURLExpiry      = 7 * 24 * time.Hour // 走勢圖網址有效期間
+)
+
+// 規則快照中的閾值欄位
+var chartThresholdKeys = []struct {
+	key   string
+	name  string
+	color chart.Threshold
+}{
+	{"crit_threshold", "crit", chart.Threshold{Color: chart.CritColor}},
+	{"crit_lower_threshold", "crit", chart.Threshold{Color: chart.CritColor}},
+	{"warn_threshold", "warn", chart.Threshold{Color: chart.WarnColor}},
+	{"warn_lower_threshold", "warn", chart.Threshold{Color: chart.WarnColor}},
+	{"info_threshold", "info", chart.Threshold{Color: chart.InfoColor}},
+	{"info_lower_threshold", "info", chart.Threshold{Color: chart.InfoColor}},
+}
+
+// triggeredLogSparkline 告警的走勢圖，數據點不足 2 個時回傳 false
+func triggeredLogSparkline(log models.TriggeredLog) (chart.Sparkline, bool) {
+	if len(log.MetricWindow) < 2 {
+		return chart.Sparkline{}, false
+	}
+	return chart.Sparkline{
+		Values:     log.MetricWindow,
+		Thresholds: chartThresholds(log.RuleSnapshot),
+	}, true
+}
+
+// chartThresholds 由規則快照取得閾值 (未設定的閾值在快照中為 <nil>)
+func chartThresholds(snapshot common.JSONMap) []chart.Threshold {
+	var thresholds []chart.Threshold
+	for _, t := range chartThresholdKeys {
+		value, err := strconv.ParseFloat(snapshot[t.key], 64)
+		if err != nil {
+			continue
+		}
+		thresholds = append(thresholds, chart.Threshold{Name: t.name, Value: value, Color: t.color.Color})
+	}
+	return thresholds
+}
+
+// chartContentID 郵件內嵌走勢圖的 Content-ID
+func chartContentID(id []byte) string {
+	return "chart." + hex.EncodeToString(id)
+}
+
+// chartTemplateData 模板中每筆告警的走勢圖資料
+func (s *Service) chartTemplateData(contact *models.Contact, log models.TriggeredLog) map[string]interface{} {
+	data := make(map[string]interface{})
+	sparkline, ok := triggeredLogSparkline(log)
+	if !ok {
+		return data
+	}
+	data["chart_svg"] = sparkline.SVG()
+	if contact.Config["attach_chart"] == "true" {
+		data["chart_cid"] = chartContentID(log.ID)
+		if chartURL := s.chartURL(log.ID, "png", time.Now()); chartURL != "" {
+			data["chart_url"] = chartURL
+		}
+	}
+	return data
+}
+
+// chartAttachments 依聯絡人設定產生走勢圖附件
+func (s *Service) chartAttachments(contact *models.Contact, logs []models.TriggeredLog) []common.NotifyAttachment {
+	if contact.Config["attach_chart"] != "true" {
+		return nil
+	}
+
+	var attachments []common.NotifyAttachment
+	now := time.Now()
+	for _, log := range logs {
+		if len(attachments) >= maxChartAttachments {
+			break
+		}
+		sparkline, ok := triggeredLogSparkline(log)
+		if !ok {
+			continue
+		}
+		data, err := sparkline.PNG()
+		if err != nil {
+			s.logger.Error("繪製走勢圖失敗", zap.Error(err), zap.String("triggered_log_id", formatID(log.ID)))
+			continue
+		}
+		attachments = append(attachments, common.NotifyAttachment{
+			Filename:    fmt.Sprintf("chart-%s.png", hex.EncodeToString(log.ID)),
+			ContentType: "image/png",
+			Data:        data,
+			Inline:      true,
+			ContentID:   chartContentID(log.ID),
+			URL:         s.chartURL(log.ID, "png", now),
+		})
+	}
+	return attachments
+}
+
+// chartURL 產生走勢圖簽章網址，未設定 public_url 或 chart_secret 時回傳空字串
+func (s *Service) chartURL(id []byte, format string, now time.Time) string {
+	if s.config.PublicURL == "" || s.config.ChartSecret == "" {
+		return ""
+	}
+	idStr := hex.EncodeToString(id)
+	exp := now.Add(chartURLExpiry).Unix()
+	query := url.Values{}
+	query.Set("exp", strconv.FormatInt(exp, 10))
+	query.Set("sig", s.chartSignature(idStr, format, exp))
+	return fmt.Sprintf("%s/api/v1/alert/chart/%s.%s?%s",
+		strings.TrimRight(s.config.PublicURL, "/"), idStr, format, query.Encode())
+}
+
+func (s *Service) chartSignature(id, format string, exp int64) string {
+	mac := hmac.New(sha256.New, []byte(s.config.ChartSecret))
+	fmt.Fprintf(mac, "%s.%s.%d", id, format, exp)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RenderTriggeredLogChart 驗證簽章後繪製告警走勢圖，file 為 {id}.png 或 {id}.svg
+// 回傳圖片內容與 Content-Type
+func (s *Service) RenderTriggeredLogChart(file, exp, sig string) ([]byte, string, error) {
+	if s.config.ChartSecret == "" {
+		return nil, "", apierrors.ErrNotFound
+	}
+
+	idStr, format, _ := strings.Cut(file, ".")
+	if format != "png" && format != "svg" {
+		return nil, "", apierrors.ErrNotFound
+	}
+	expAt, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() > expAt {
+		return nil, "", apierrors.NewAPIError(403, "走勢圖網址已失效", nil)
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.chartSignature(idStr, format, expAt))) {
+		return nil, "", apierrors.NewAPIError(403, "走勢圖網址簽章無效", nil)
+	}
+
+	id, err := hex.DecodeString(idStr)
+	if err != nil || len(id) != 16 {
+		return nil, "", apierrors.ErrInvalidID
+	}
+	log, err := s.mysql.GetTriggeredLog(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", apierrors.ErrNotFound
+		}
+		return nil, "", err
+	}
+	sparkline, ok := triggeredLogSparkline(*log)
+	if !ok {
+		return nil, "", apierrors.ErrNotFound
+	}
+
+	if format == "svg" {
+		return []byte(sparkline.SVG()), "image/svg+xml", nil
+	}
+	data, err := sparkline.PNG()
+	if err != nil {
+		return nil, "", err
+	}
+	return data, "image/png", nil
+}
@@ -0,0 +1,506 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/detect-viz/shared-lib/models"
+)
+
+// 預設偵測類型 (detection_type 為空時使用)
+const DefaultDetectionType = "absolute"
+
+// 預設參數
+const (
+	DefaultPercentile = 95.0 // percentile 偵測預設百分位
+	DefaultMinSamples = 5    // zscore 偵測預設最少樣本數
+)
+
+// DetectInput 偵測器輸入
+type DetectInput struct {
+	Rule          *models.Rule
+	MetricRule    models.MetricRule
+	Data          []models.MetricValue            // payload 中的完整數據
+	Series        map[string][]models.MetricValue // payload 中同一監控對象的所有指標 (複合表達式使用)
+	Window        []models.MetricValue            // Duration 時間窗口內的數據
+	WindowSeconds int                             // Duration 時間窗口 (秒)
+	CurrentTime   int64
+	LastSeen      int64 // 最後收到數據的時間 (unix 秒)，0 表示服務啟動後尚未記錄
+}
+
+// Detector 異常偵測器
+type Detector interface {
+	// Validate 於載入設定時檢查 MetricRule 參數
+	Validate(metricRule models.MetricRule) error
+	// Detect 回傳 (是否觸發, 檢查值, 嚴重程度)
+	Detect(input DetectInput) (bool, float64, string)
+}
+
+// DetectResult 偵測結果，Details 會記錄於觸發日誌的狀態快照
+type DetectResult struct {
+	Exceeded bool
+	Value    float64
+	Severity string
+	Details  map[string]string
+	Window   []models.MetricValue // 評估的時間窗口 (已換算)，記錄於觸發日誌供繪製走勢圖
+}
+
+// EvaluatingDetector 可回傳評估錯誤及計算細節的偵測器 (例如 expression)
+type EvaluatingDetector interface {
+	Detector
+	Evaluate(input DetectInput) (DetectResult, error)
+}
+
+// FixedOperatorDetector 固定比較運算子的偵測器，不使用 MetricRule.Operator
+type FixedOperatorDetector interface {
+	Detector
+	Operator() string
+}
+
+// MissingDataDetector 無數據時仍需執行檢查的偵測器 (例如 stale)
+type MissingDataDetector interface {
+	Detector
+	HandlesMissingData() bool
+}
+
+// PeriodicDetector 不依賴新數據、需定期檢查的偵測器 (例如 stale)
+type PeriodicDetector interface {
+	Detector
+	ChecksPeriodically() bool
+}
+
+var (
+	detectorsMu sync.RWMutex
+	detectors   = map[string]Detector{}
+)
+
+// RegisterDetector 註冊偵測器，重複註冊同一類型會覆蓋
+func RegisterDetector(detectionType string, detector Detector) {
+	detectorsMu.Lock()
+	defer detectorsMu.Unlock()
+	detectors[detectionType] = detector
+}
+
+// GetDetector 依 detection_type 取得偵測器
+func GetDetector(detectionType string) (Detector, bool) {
+	if detectionType == "" {
+		detectionType = DefaultDetectionType
+	}
+	detectorsMu.RLock()
+	defer detectorsMu.RUnlock()
+	detector, ok := detectors[detectionType]
+	return detector, ok
+}
+
+// ValidateMetricRules 載入設定時檢查所有 MetricRule，未知的 detection_type 直接回傳錯誤
+func ValidateMetricRules(metricRules map[string]models.MetricRule) error {
+	uids := make([]string, 0, len(metricRules))
+	for uid := range metricRules {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	var errs []error
+	for _, uid := range uids {
+		metricRule := metricRules[uid]
+		detector, ok := GetDetector(metricRule.DetectionType)
+		if !ok {
+			errs = append(errs, fmt.Errorf("不支援的 detection_type [uid:%s, detection_type:%s]", uid, metricRule.DetectionType))
+			continue
+		}
+		if _, fixed := detector.(FixedOperatorDetector); !fixed {
+			if err := validateOperator(metricRule.Operator); err != nil {
+				errs = append(errs, fmt.Errorf("指標規則設定錯誤 [uid:%s]: %w", uid, err))
+				continue
+			}
+		}
+		if err := detector.Validate(metricRule); err != nil {
+			errs = append(errs, fmt.Errorf("指標規則設定錯誤 [uid:%s]: %w", uid, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 取得偵測器實際使用的比較運算子
+func detectorOperator(detector Detector, metricRule models.MetricRule) string {
+	if d, ok := detector.(FixedOperatorDetector); ok {
+		return d.Operator()
+	}
+	if metricRule.Operator == "" {
+		return DefaultOperator
+	}
+	return metricRule.Operator
+}
+
+// 執行偵測，EvaluatingDetector 可回傳評估錯誤
+func runDetector(detector Detector, input DetectInput) (DetectResult, error) {
+	if d, ok := detector.(EvaluatingDetector); ok {
+		return d.Evaluate(input)
+	}
+	exceeded, value, severity := detector.Detect(input)
+	return DetectResult{Exceeded: exceeded, Value: value, Severity: severity}, nil
+}
+
+func handlesMissingData(detector Detector) bool {
+	d, ok := detector.(MissingDataDetector)
+	return ok && d.HandlesMissingData()
+}
+
+func checksPeriodically(detector Detector) bool {
+	d, ok := detector.(PeriodicDetector)
+	return ok && d.ChecksPeriodically()
+}
+
+func init() {
+	RegisterDetector("absolute", absoluteDetector{})
+	RegisterDetector("amplitude", amplitudeDetector{})
+	RegisterDetector("rate_of_change", rateOfChangeDetector{})
+	RegisterDetector("derivative", derivativeDetector{})
+	RegisterDetector("percentile", percentileDetector{})
+	RegisterDetector("stale", staleDetector{})
+	RegisterDetector("zscore", zscoreDetector{})
+	RegisterDetector("expression", &expressionDetector{})
+}
+
+//* ======================== 時間窗口 ========================
+
+// 解析 Duration 為秒數，預設 5 分鐘
+func durationSeconds(duration string) int {
+	seconds := 300
+	if duration != "" {
+		value, unit := parseDuration(duration)
+		switch unit {
+		case "s":
+			seconds = value
+		case "m":
+			seconds = value * 60
+		case "h":
+			seconds = value * 3600
+		case "d":
+			seconds = value * 86400
+		}
+	}
+	return seconds
+}
+
+// 取得時間窗口內的數據點，窗口內不足 2 點時使用所有可用的數據點
+func windowData(metricData []models.MetricValue, seconds int) []models.MetricValue {
+	if len(metricData) == 0 {
+		return nil
+	}
+	latestTimestamp := metricData[len(metricData)-1].Timestamp
+	windowStartTimestamp := latestTimestamp - int64(seconds)
+
+	var window []models.MetricValue
+	for _, point := range metricData {
+		if point.Timestamp >= windowStartTimestamp {
+			window = append(window, point)
+		}
+	}
+	if len(window) < 2 {
+		window = metricData
+	}
+	return window
+}
+
+// 觸發日誌最多記錄的時間窗口數據點
+const maxWindowPoints = 240
+
+// 依 MetricRule.Scale 換算時間窗口，只保留最後 maxWindowPoints 個數據點
+func scaledWindow(window []models.MetricValue, scale float64) []models.MetricValue {
+	if len(window) > maxWindowPoints {
+		window = window[len(window)-maxWindowPoints:]
+	}
+	scaled := make([]models.MetricValue, len(window))
+	for i, point := range window {
+		scaled[i] = models.MetricValue{Timestamp: point.Timestamp, Value: point.Value * scale}
+	}
+	return scaled
+}
+
+// 依 MetricRule.Scale 換算數值
+func scaledValues(data []models.MetricValue, scale float64) []float64 {
+	values := make([]float64, len(data))
+	for i, point := range data {
+		values[i] = point.Value * scale
+	}
+	return values
+}
+
+//* ======================== absolute 絕對值 ========================
+
+// 依 Rule.EvaluationMode 檢查時間窗口內的數據點：
+// all - 所有數據點都超過閾值才觸發
+// n_of_m - 最近 M 個數據點中至少 N (Rule.Times) 個超過閾值
+// consecutive - 只看最新數據點，連續次數由 RuleState.BreachCounter 判斷
+type absoluteDetector struct{}
+
+func (absoluteDetector) Validate(metricRule models.MetricRule) error {
+	return nil
+}
+
+func (absoluteDetector) Detect(input DetectInput) (bool, float64, string) {
+	if len(input.Window) == 0 {
+		return false, 0, ""
+	}
+
+	values := scaledValues(input.Window, input.MetricRule.Scale)
+	lastValue := values[len(values)-1] // 記錄最後檢查的值
+
+	required := len(values)
+	switch evaluationMode(input.Rule) {
+	case EvaluationModeNOfM:
+		if m := input.Rule.EvaluationPoints; m > 0 && m < len(values) {
+			values = values[len(values)-m:]
+		}
+		required = requiredTimes(input.Rule)
+		if required > len(values) {
+			return false, lastValue, ""
+		}
+	case EvaluationModeConsecutive:
+		values = values[len(values)-1:]
+		required = 1
+	}
+
+	// 統計各嚴重程度（含更高等級）的超過次數，取達到次數的最高等級
+	counts := map[string]int{}
+	for _, value := range values {
+		if exceeded, severity := matchThreshold(input.Rule, input.MetricRule.Operator, value); exceeded {
+			counts[severity]++
+		}
+	}
+	total := 0
+	for _, severity := range []string{"crit", "warn", "info"} {
+		total += counts[severity]
+		if total >= required {
+			return true, lastValue, severity
+		}
+	}
+	return false, lastValue, ""
+}
+
+//* ======================== amplitude 振幅 ========================
+
+// 振幅 = (最大值 - 最小值) / 最小值 * 100
+type amplitudeDetector struct{}
+
+func (amplitudeDetector) Operator() string {
+	return "gt"
+}
+
+func (amplitudeDetector) Validate(metricRule models.MetricRule) error {
+	return nil
+}
+
+func (amplitudeDetector) Detect(input DetectInput) (bool, float64, string) {
+	if len(input.Window) < 2 {
+		return false, 0, ""
+	}
+
+	values := scaledValues(input.Window, input.MetricRule.Scale)
+	maxValue, minValue := values[0], values[0]
+	for _, value := range values {
+		maxValue = math.Max(maxValue, value)
+		minValue = math.Min(minValue, value)
+	}
+
+	if minValue == 0 {
+		// 避免除以零
+		minValue = 0.000001
+	}
+	amplitude := (maxValue - minValue) / minValue * 100
+
+	// 振幅固定以大於比較
+	exceeded, severity := matchThreshold(input.Rule, amplitudeDetector{}.Operator(), amplitude)
+	return exceeded, amplitude, severity
+}
+
+//* ======================== rate_of_change 變化率 ========================
+
+// 變化率 = (窗口最後值 - 窗口第一值) / |窗口第一值| * 100
+type rateOfChangeDetector struct{}
+
+func (rateOfChangeDetector) Validate(metricRule models.MetricRule) error {
+	return nil
+}
+
+func (rateOfChangeDetector) Detect(input DetectInput) (bool, float64, string) {
+	if len(input.Window) < 2 {
+		return false, 0, ""
+	}
+
+	values := scaledValues(input.Window, input.MetricRule.Scale)
+	first, last := values[0], values[len(values)-1]
+	if first == 0 {
+		// 避免除以零
+		first = 0.000001
+	}
+	rate := (last - first) / math.Abs(first) * 100
+
+	exceeded, severity := matchThreshold(input.Rule, input.MetricRule.Operator, rate)
+	return exceeded, rate, severity
+}
+
+//* ======================== derivative 每秒增量 (counter) ========================
+
+// 計數器每秒增量，數值下降視為 counter reset，以重置後的值作為增量
+type derivativeDetector struct{}
+
+func (derivativeDetector) Validate(metricRule models.MetricRule) error {
+	return nil
+}
+
+func (derivativeDetector) Detect(input DetectInput) (bool, float64, string) {
+	if len(input.Window) < 2 {
+		return false, 0, ""
+	}
+
+	elapsed := input.Window[len(input.Window)-1].Timestamp - input.Window[0].Timestamp
+	if elapsed <= 0 {
+		return false, 0, ""
+	}
+
+	var increase float64
+	for i := 1; i < len(input.Window); i++ {
+		delta := input.Window[i].Value - input.Window[i-1].Value
+		if delta < 0 {
+			// counter reset
+			delta = input.Window[i].Value
+		}
+		increase += delta
+	}
+	rate := increase / float64(elapsed) * input.MetricRule.Scale
+
+	exceeded, severity := matchThreshold(input.Rule, input.MetricRule.Operator, rate)
+	return exceeded, rate, severity
+}
+
+//* ======================== percentile 百分位 ========================
+
+// 時間窗口內第 N 百分位數 (線性插值)
+type percentileDetector struct{}
+
+func (percentileDetector) Validate(metricRule models.MetricRule) error {
+	if metricRule.Percentile < 0 || metricRule.Percentile > 100 {
+		return fmt.Errorf("percentile 必須介於 0 ~ 100 [percentile:%v]", metricRule.Percentile)
+	}
+	return nil
+}
+
+func (percentileDetector) Detect(input DetectInput) (bool, float64, string) {
+	if len(input.Window) == 0 {
+		return false, 0, ""
+	}
+
+	p := input.MetricRule.Percentile
+	if p == 0 {
+		p = DefaultPercentile
+	}
+	value := percentile(scaledValues(input.Window, input.MetricRule.Scale), p)
+
+	exceeded, severity := matchThreshold(input.Rule, input.MetricRule.Operator, value)
+	return exceeded, value, severity
+}
+
+func percentile(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+//* ======================== stale 缺值 / 數據停滯 ========================
+
+// 檢查值為距今未收到數據的秒數，沒有任何數據時視為整個時間窗口都缺值
+// 設備停止送數據時不會再收到 payload，由定期檢查 (checkStaleRules) 以 LastSeen 判斷
+type staleDetector struct{}
+
+func (staleDetector) Operator() string {
+	return "gt"
+}
+
+func (staleDetector) Validate(metricRule models.MetricRule) error {
+	return nil
+}
+
+func (staleDetector) HandlesMissingData() bool {
+	return true
+}
+
+func (staleDetector) ChecksPeriodically() bool {
+	return true
+}
+
+func (staleDetector) Detect(input DetectInput) (bool, float64, string) {
+	lastSeen := input.LastSeen
+	if len(input.Data) > 0 && input.Data[len(input.Data)-1].Timestamp > lastSeen {
+		lastSeen = input.Data[len(input.Data)-1].Timestamp
+	}
+	staleSeconds := float64(input.WindowSeconds)
+	if lastSeen > 0 {
+		staleSeconds = float64(input.CurrentTime - lastSeen)
+	}
+
+	// 缺值秒數固定以大於比較
+	exceeded, severity := matchThreshold(input.Rule, staleDetector{}.Operator(), staleSeconds)
+	return exceeded, staleSeconds, severity
+}
+
+//* ======================== zscore 標準分數 ========================
+
+// 以窗口內最後一點之前的數據為基準，計算最後一點的 |z-score|
+type zscoreDetector struct{}
+
+func (zscoreDetector) Operator() string {
+	return "gt"
+}
+
+func (zscoreDetector) Validate(metricRule models.MetricRule) error {
+	if metricRule.MinSamples < 0 {
+		return fmt.Errorf("min_samples 不可小於 0 [min_samples:%d]", metricRule.MinSamples)
+	}
+	return nil
+}
+
+func (zscoreDetector) Detect(input DetectInput) (bool, float64, string) {
+	minSamples := input.MetricRule.MinSamples
+	if minSamples == 0 {
+		minSamples = DefaultMinSamples
+	}
+	if len(input.Window) < minSamples || len(input.Window) < 3 {
+		return false, 0, ""
+	}
+
+	values := scaledValues(input.Window, input.MetricRule.Scale)
+	baseline := values[:len(values)-1]
+	last := values[len(values)-1]
+
+	var sum float64
+	for _, value := range baseline {
+		sum += value
+	}
+	mean := sum / float64(len(baseline))
+
+	var variance float64
+	for _, value := range baseline {
+		variance += (value - mean) * (value - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(baseline)))
+	if stddev == 0 {
+		return false, 0, ""
+	}
+	zscore := math.Abs(last-mean) / stddev
+
+	// z-score 固定以大於比較
+	exceeded, severity := matchThreshold(input.Rule, zscoreDetector{}.Operator(), zscore)
+	return exceeded, zscore, severity
+}
@@ -0,0 +1,129 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/models/common"
+	"go.uber.org/zap"
+)
+
+// 郵件通知：
+// 每封郵件的 Message-ID 為 <類型>.<通知日誌 ID> (異常通知為 alert.、恢復通知為 resolved.)，重試沿用同一通知日誌因此 ID 不變
+// 告警第一次寄給聯絡人的異常通知為郵件串起點，其 Message-ID 記錄於告警對聯絡人的通知紀錄 (notify_deliveries)
+// 之後的重複通知、升級通知與恢復通知以 In-Reply-To / References 指向記錄的 Message-ID，
+// 告警分組或部分恢復時郵件包含的告警與第一封不同，仍回覆實際寄出的郵件
+// 聯絡人設定 attach_csv = true 時附上告警明細 CSV
+
+// emailThreadConfig 產生郵件串使用的 message_id / in_reply_to / references 設定
+// threads 為告警 ID 對應的郵件串起點 Message-ID (該聯絡人的第一封異常通知)
+func emailThreadConfig(logs []models.TriggeredLog, notifyLogID []byte, notifyType string, threads map[string]string) map[string]string {
+	config := make(map[string]string)
+	messageID := alertMessageID(notifyLogID)
+	if notifyType != "alerting" {
+		messageID = "resolved." + hex.EncodeToString(notifyLogID)
+	}
+	config["message_id"] = messageID
+
+	// 依告警順序列出不重複的郵件串起點，略過本封郵件 (第一封異常通知重試時)
+	var refs []string
+	seen := map[string]bool{messageID: true}
+	for _, log := range logs {
+		thread := threads[string(log.ID)]
+		if thread == "" || seen[thread] {
+			continue
+		}
+		seen[thread] = true
+		refs = append(refs, thread)
+	}
+	if len(refs) > 0 {
+		config["in_reply_to"] = refs[0]
+		config["references"] = strings.Join(refs, ",")
+	}
+	return config
+}
+
+// emailThreads 獲取告警寄給聯絡人的郵件串起點 Message-ID (key 為告警 ID)
+func (s *Service) emailThreads(contact *models.Contact, logs []models.TriggeredLog) map[string]string {
+	ids := make([][]byte, 0, len(logs))
+	for _, log := range logs {
+		ids = append(ids, log.ID)
+	}
+	deliveries, err := s.mysql.GetNotifyDeliveries(ids)
+	if err != nil {
+		s.logger.Error("獲取告警通知紀錄失敗，郵件不串接", zap.Error(err), zap.String("contact_id", formatID(contact.ID)))
+		return nil
+	}
+	threads := make(map[string]string, len(deliveries))
+	for _, delivery := range deliveries {
+		if string(delivery.ContactID) == string(contact.ID) && delivery.MessageID != "" {
+			threads[string(delivery.TriggeredLogID)] = delivery.MessageID
+		}
+	}
+	return threads
+}
+
+// alertMessageID 異常通知郵件的 Message-ID (不含網域)
+func alertMessageID(notifyLogID []byte) string {
+	return "alert." + hex.EncodeToString(notifyLogID)
+}
+
+// emailAttachments 依聯絡人設定產生郵件附件
+func (s *Service) emailAttachments(contact *models.Contact, logs []models.TriggeredLog) []common.NotifyAttachment {
+	var attachments []common.NotifyAttachment
+	if contact.Config["attach_csv"] == "true" {
+		data, err := triggeredLogsCSV(logs)
+		if err != nil {
+			s.logger.Error("產生告警明細 CSV 失敗", zap.Error(err))
+		} else {
+			attachments = append(attachments, common.NotifyAttachment{
+				Filename:    "triggered_logs.csv",
+				ContentType: "text/csv",
+				Data:        data,
+			})
+		}
+	}
+	return attachments
+}
+
+// triggeredLogsCSV 告警明細 CSV (UTF-8 BOM，方便以 Excel 開啟中文內容)
+func triggeredLogsCSV(logs []models.TriggeredLog) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\xEF\xBB\xBF")
+
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"id", "resource_name", "partition_name", "metric_rule_uid", "severity",
+		"triggered_value", "threshold", "triggered_at", "resolved_value", "resolved_at", "acked_by"})
+	for _, log := range logs {
+		record := []string{
+			hex.EncodeToString(log.ID),
+			log.ResourceName,
+			log.PartitionName,
+			log.MetricRuleUID,
+			formatSeverity(log.Severity),
+			fmt.Sprintf("%g", log.TriggeredValue),
+			fmt.Sprintf("%g", log.Threshold),
+			time.Unix(log.TriggeredAt, 0).Format(time.RFC3339),
+			"",
+			"",
+			"",
+		}
+		if log.ResolvedValue != nil {
+			record[8] = fmt.Sprintf("%g", *log.ResolvedValue)
+		}
+		if log.ResolvedAt != nil {
+			record[9] = time.Unix(*log.ResolvedAt, 0).Format(time.RFC3339)
+		}
+		if log.AckedBy != nil {
+			record[10] = *log.AckedBy
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,99 @@
+package alert
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/detect-viz/shared-lib/models"
+)
+
+// 郵件串：依序寄出的郵件，threads 模擬 notify_deliveries 只記錄告警第一封異常通知的 Message-ID
+func TestEmailThreadConfig(t *testing.T) {
+	l1 := models.TriggeredLog{ID: []byte{0x01}}
+	l2 := models.TriggeredLog{ID: []byte{0x02}}
+	l3 := models.TriggeredLog{ID: []byte{0x03}}
+	id := func(b byte) []byte { return []byte{0xa0, b} }
+	alertID := func(b byte) string { return "alert." + hex.EncodeToString(id(b)) }
+
+	mails := []struct {
+		name       string
+		notifyLog  byte
+		notifyType string
+		logs       []models.TriggeredLog
+		messageID  string
+		inReplyTo  string
+		references string
+	}{
+		{
+			name:       "分組的第一封異常通知",
+			notifyLog:  1,
+			notifyType: "alerting",
+			logs:       []models.TriggeredLog{l1, l2},
+			messageID:  alertID(1),
+		},
+		{
+			name:       "第一封異常通知重試不回覆自己",
+			notifyLog:  1,
+			notifyType: "alerting",
+			logs:       []models.TriggeredLog{l1, l2},
+			messageID:  alertID(1),
+		},
+		{
+			name:       "重複通知使用新的 Message-ID",
+			notifyLog:  2,
+			notifyType: "alerting",
+			logs:       []models.TriggeredLog{l1, l2},
+			messageID:  alertID(2),
+			inReplyTo:  alertID(1),
+			references: alertID(1),
+		},
+		{
+			name:       "部分恢復回覆分組郵件",
+			notifyLog:  3,
+			notifyType: "resolved",
+			logs:       []models.TriggeredLog{l1},
+			messageID:  "resolved." + hex.EncodeToString(id(3)),
+			inReplyTo:  alertID(1),
+			references: alertID(1),
+		},
+		{
+			name:       "之後的新告警另起郵件串",
+			notifyLog:  4,
+			notifyType: "alerting",
+			logs:       []models.TriggeredLog{l3},
+			messageID:  alertID(4),
+		},
+		{
+			name:       "恢復包含不同郵件串的告警",
+			notifyLog:  5,
+			notifyType: "resolved",
+			logs:       []models.TriggeredLog{l2, l3},
+			messageID:  "resolved." + hex.EncodeToString(id(5)),
+			inReplyTo:  alertID(1),
+			references: alertID(1) + "," + alertID(4),
+		},
+	}
+
+	threads := make(map[string]string)
+	for _, mail := range mails {
+		config := emailThreadConfig(mail.logs, id(mail.notifyLog), mail.notifyType, threads)
+		if config["message_id"] != mail.messageID {
+			t.Errorf("%s: message_id = %q, want %q", mail.name, config["message_id"], mail.messageID)
+		}
+		if config["in_reply_to"] != mail.inReplyTo {
+			t.Errorf("%s: in_reply_to = %q, want %q", mail.name, config["in_reply_to"], mail.inReplyTo)
+		}
+		if config["references"] != mail.references {
+			t.Errorf("%s: references = %q, want %q", mail.name, config["references"], mail.references)
+		}
+
+		// 記錄異常通知為尚未有郵件串的告警的起點 (與 recordNotifyDeliveries 相同，已有紀錄不更新)
+		if mail.notifyType == "alerting" {
+			for _, log := range mail.logs {
+				if _, ok := threads[string(log.ID)]; !ok {
+					threads[string(log.ID)] = alertMessageID(id(mail.notifyLog))
+				}
+			}
+		}
+	}
+}
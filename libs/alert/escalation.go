@@ -0,0 +1,110 @@
+package alert
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/detect-viz/shared-lib/escalations"
+	"github.com/detect-viz/shared-lib/models"
+	"go.uber.org/zap"
+)
+
+// 升級通知：
+// 告警的第一次異常通知只發送給升級策略第一步的聯絡人 (EscalationStep = 0)
+// 告警在發送後未確認也未恢復時，每次排程檢查已到達的步驟 (TriggeredAt + Delay <= now)
+// 只通知新到達步驟的聯絡人，並記錄 EscalationStep 與 EscalatedAt，恢復通知則發送給所有已通知的步驟
+// 升級前與異常通知相同經過抑制規則、臨時靜默與告警抑制的檢查，被抑制的告警標記為 muted / inhibited 不升級，
+// 由異常通知流程於抑制結束後重新發送，之後再依已記錄的步驟繼續升級
+
+// processEscalations 處理需要升級的告警
+func (s *Service) processEscalations(now int64) error {
+	logs, err := s.mysql.GetEscalatableTriggeredLogs()
+	if err != nil {
+		return fmt.Errorf("獲取待升級的 TriggeredLog 失敗: %w", err)
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+
+	// 被抑制規則、臨時靜默或其他告警抑制的告警不升級
+	logs = s.filterMutedLogs(logs, "alerting", time.Unix(now, 0))
+	logs = s.filterInhibitedLogs(logs, "alerting")
+
+	// 同一次排程內快取規則的升級策略
+	policies := make(map[string]*models.EscalationPolicy)
+	groupedLogs := make(map[string][]models.TriggeredLog)
+	escalated := make(map[string]int)
+
+	for _, log := range logs {
+		ruleID := string(log.RuleID)
+		policy, ok := policies[ruleID]
+		if !ok {
+			policy, err = s.mysql.GetEscalationPolicyByRuleID(log.RuleID)
+			if err != nil {
+				s.logger.Error("獲取升級策略失敗",
+					zap.Error(err),
+					zap.String("rule_id", formatID(log.RuleID)))
+				continue
+			}
+			policies[ruleID] = policy
+		}
+		if policy == nil {
+			continue
+		}
+
+		step := reachedEscalationStep(policy, log.TriggeredAt, now)
+		if step <= log.EscalationStep {
+			continue
+		}
+
+		// 只通知新到達步驟的聯絡人
+		prevStep := log.EscalationStep
+		log.EscalationStep = step
+		seen := make(map[string]bool)
+		for _, st := range policy.Steps {
+			if st.StepOrder <= prevStep || st.StepOrder > step {
+				continue
+			}
+			for _, contact := range st.Contacts {
+				contactID := string(contact.ID)
+				if seen[contactID] || !contact.Enabled || !s.shouldNotifyContact(contact, log.Severity) {
+					continue
+				}
+				seen[contactID] = true
+				groupedLogs[contactID] = append(groupedLogs[contactID], log)
+			}
+		}
+		escalated[string(log.ID)] = step
+
+		s.logger.Info("告警升級",
+			zap.String("triggered_log_id", formatID(log.ID)),
+			zap.String("policy", policy.Name),
+			zap.Int("step", step))
+	}
+
+	s.sendGroupedNotifications(groupedLogs, "alerting", false)
+
+	for id, step := range escalated {
+		if err := s.mysql.UpdateTriggeredLogEscalation([]byte(id), step, now); err != nil {
+			s.logger.Error("更新告警升級步驟失敗",
+				zap.Error(err),
+				zap.String("triggered_log_id", formatID([]byte(id))))
+		}
+	}
+	return nil
+}
+
+// reachedEscalationStep 計算告警在指定時間已到達的最高升級步驟
+func reachedEscalationStep(policy *models.EscalationPolicy, triggeredAt, now int64) int {
+	reached := 0
+	for _, step := range policy.Steps {
+		delay, err := escalations.ParseDelay(step.Delay)
+		if err != nil {
+			continue
+		}
+		if triggeredAt+int64(delay/time.Second) <= now {
+			reached = step.StepOrder
+		}
+	}
+	return reached
+}
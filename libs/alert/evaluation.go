@@ -0,0 +1,26 @@
+package alert
+
+import "github.com/detect-viz/shared-lib/models"
+
+// 告警判斷模式 (Rule.EvaluationMode)
+const (
+	EvaluationModeAll         = "all"         // 時間窗口內所有數據點都超過閾值
+	EvaluationModeNOfM        = "n_of_m"      // 最近 M 個數據點中至少 N 個超過閾值
+	EvaluationModeConsecutive = "consecutive" // 連續 N 次檢查都超過閾值
+)
+
+// 取得規則的判斷模式，預設為 all
+func evaluationMode(rule *models.Rule) string {
+	if rule.EvaluationMode == "" {
+		return EvaluationModeAll
+	}
+	return rule.EvaluationMode
+}
+
+// 取得規則需要的觸發次數 N (Rule.Times)，至少為 1
+func requiredTimes(rule *models.Rule) int {
+	if rule.Times <= 0 {
+		return 1
+	}
+	return rule.Times
+}
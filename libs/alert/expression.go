@@ -0,0 +1,619 @@
+package alert
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/detect-viz/shared-lib/models"
+)
+
+// 表達式中代表監控對象分區的變數，例如 current:$partition
+const expressionPartitionVar = "$partition"
+
+// Expression 已解析的複合指標表達式
+// 語法：
+//
+//	數值運算  + - * / %
+//	比較運算  > >= < <= == !=（成立為 1，否則為 0）
+//	邏輯運算  && || !（亦可使用 AND OR NOT）
+//	函數      abs(x) min(a, b, ...) max(a, b, ...)
+//	指標      metric_raw_name 或 metric_raw_name:partition
+//	          分區名稱僅允許 [A-Za-z0-9_] 或 $partition，其他字元需加上雙引號，例如 current:"L1-1"
+type Expression struct {
+	source    string
+	root      exprNode
+	variables []string
+}
+
+// ParseExpression 解析表達式
+func ParseExpression(source string) (*Expression, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, fmt.Errorf("expression 不能為空")
+	}
+
+	tokens, err := tokenizeExpression(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("表達式語法錯誤 [位置:%d, token:%s]", p.peek().pos, p.peek().text)
+	}
+
+	seen := map[string]bool{}
+	var variables []string
+	collectVariables(root, func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			variables = append(variables, name)
+		}
+	})
+	sort.Strings(variables)
+
+	return &Expression{source: source, root: root, variables: variables}, nil
+}
+
+// String 回傳原始表達式
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Variables 回傳表達式引用的指標名稱
+func (e *Expression) Variables() []string {
+	return e.variables
+}
+
+// Eval 以 lookup 取得指標數值並計算表達式
+func (e *Expression) Eval(lookup func(name string) (float64, error)) (float64, error) {
+	return e.root.eval(lookup)
+}
+
+//* ======================== expression 偵測器 ========================
+
+// 以表達式計算同一監控對象的多個指標 (各取最新值)，結果再依 Rule 閾值判斷
+type expressionDetector struct {
+	cache sync.Map // source -> *Expression
+}
+
+func (d *expressionDetector) Validate(metricRule models.MetricRule) error {
+	expr, err := ParseExpression(metricRule.Expression)
+	if err != nil {
+		return err
+	}
+	d.cache.Store(metricRule.Expression, expr)
+	return nil
+}
+
+// 指標數據由表達式自行取得，不依賴 metric_raw_name
+func (d *expressionDetector) HandlesMissingData() bool {
+	return true
+}
+
+func (d *expressionDetector) Detect(input DetectInput) (bool, float64, string) {
+	result, err := d.Evaluate(input)
+	if err != nil {
+		return false, 0, ""
+	}
+	return result.Exceeded, result.Value, result.Severity
+}
+
+func (d *expressionDetector) Evaluate(input DetectInput) (DetectResult, error) {
+	expr, err := d.expression(input.MetricRule.Expression)
+	if err != nil {
+		return DetectResult{}, err
+	}
+
+	details := map[string]string{"expression": expr.String()}
+	values := make([]string, 0, len(expr.Variables()))
+
+	value, err := expr.Eval(func(name string) (float64, error) {
+		key := strings.ReplaceAll(name, expressionPartitionVar, input.Rule.Target.PartitionName)
+		series, ok := input.Series[key]
+		if !ok || len(series) == 0 {
+			return 0, fmt.Errorf("找不到指標數據 [metric:%s]", key)
+		}
+		latest := series[len(series)-1].Value
+		values = append(values, fmt.Sprintf("%s=%v", key, latest))
+		return latest, nil
+	})
+	details["expression_values"] = strings.Join(values, ", ")
+	if err != nil {
+		details["evaluation_error"] = err.Error()
+		return DetectResult{Details: details}, fmt.Errorf("表達式計算失敗 [expression:%s]: %w", expr.String(), err)
+	}
+
+	// 與一般指標相同依 MetricRule.Scale 換算後再判斷閾值
+	value *= input.MetricRule.Scale
+	details["expression_result"] = strconv.FormatFloat(value, 'f', -1, 64)
+
+	exceeded, severity := matchThreshold(input.Rule, input.MetricRule.Operator, value)
+	return DetectResult{Exceeded: exceeded, Value: value, Severity: severity, Details: details}, nil
+}
+
+// expressionMetricKeys 回傳表達式在指定分區下引用的指標 key (payload.Data 的 key)
+func expressionMetricKeys(metricRule models.MetricRule, partition string) ([]string, error) {
+	var expr *Expression
+	var err error
+	if detector, ok := GetDetector("expression"); ok {
+		if d, ok := detector.(*expressionDetector); ok {
+			expr, err = d.expression(metricRule.Expression)
+		}
+	}
+	if expr == nil && err == nil {
+		expr, err = ParseExpression(metricRule.Expression)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(expr.Variables()))
+	for _, name := range expr.Variables() {
+		keys = append(keys, strings.ReplaceAll(name, expressionPartitionVar, partition))
+	}
+	return keys, nil
+}
+
+func (d *expressionDetector) expression(source string) (*Expression, error) {
+	if cached, ok := d.cache.Load(source); ok {
+		return cached.(*Expression), nil
+	}
+	expr, err := ParseExpression(source)
+	if err != nil {
+		return nil, err
+	}
+	d.cache.Store(source, expr)
+	return expr, nil
+}
+
+//* ======================== 語法樹 ========================
+
+type exprNode interface {
+	eval(lookup func(name string) (float64, error)) (float64, error)
+}
+
+type numberNode struct {
+	value float64
+}
+
+func (n numberNode) eval(lookup func(string) (float64, error)) (float64, error) {
+	return n.value, nil
+}
+
+type variableNode struct {
+	name string
+}
+
+func (n variableNode) eval(lookup func(string) (float64, error)) (float64, error) {
+	return lookup(n.name)
+}
+
+type unaryNode struct {
+	op string
+	x  exprNode
+}
+
+func (n unaryNode) eval(lookup func(string) (float64, error)) (float64, error) {
+	x, err := n.x.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+	if n.op == "!" {
+		return boolValue(x == 0), nil
+	}
+	return -x, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n binaryNode) eval(lookup func(string) (float64, error)) (float64, error) {
+	left, err := n.left.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+
+	// 邏輯運算短路
+	switch n.op {
+	case "&&":
+		if left == 0 {
+			return 0, nil
+		}
+	case "||":
+		if left != 0 {
+			return 1, nil
+		}
+	}
+
+	right, err := n.right.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/":
+		if right == 0 {
+			return 0, fmt.Errorf("除以零")
+		}
+		return left / right, nil
+	case "%":
+		if right == 0 {
+			return 0, fmt.Errorf("除以零")
+		}
+		return math.Mod(left, right), nil
+	case ">":
+		return boolValue(left > right), nil
+	case ">=":
+		return boolValue(left >= right), nil
+	case "<":
+		return boolValue(left < right), nil
+	case "<=":
+		return boolValue(left <= right), nil
+	case "==":
+		return boolValue(math.Abs(left-right) <= operatorEpsilon), nil
+	case "!=":
+		return boolValue(math.Abs(left-right) > operatorEpsilon), nil
+	case "&&", "||":
+		return boolValue(right != 0), nil
+	}
+	return 0, fmt.Errorf("不支援的運算子 [operator:%s]", n.op)
+}
+
+type callNode struct {
+	fn   string
+	args []exprNode
+}
+
+func (n callNode) eval(lookup func(string) (float64, error)) (float64, error) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		value, err := arg.eval(lookup)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = value
+	}
+
+	switch n.fn {
+	case "abs":
+		return math.Abs(args[0]), nil
+	case "min":
+		result := args[0]
+		for _, value := range args[1:] {
+			result = math.Min(result, value)
+		}
+		return result, nil
+	case "max":
+		result := args[0]
+		for _, value := range args[1:] {
+			result = math.Max(result, value)
+		}
+		return result, nil
+	}
+	return 0, fmt.Errorf("不支援的函數 [function:%s]", n.fn)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func collectVariables(node exprNode, visit func(name string)) {
+	switch n := node.(type) {
+	case variableNode:
+		visit(n.name)
+	case unaryNode:
+		collectVariables(n.x, visit)
+	case binaryNode:
+		collectVariables(n.left, visit)
+		collectVariables(n.right, visit)
+	case callNode:
+		for _, arg := range n.args {
+			collectVariables(arg, visit)
+		}
+	}
+}
+
+//* ======================== 詞法分析 ========================
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type exprToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// 關鍵字邏輯運算子
+var expressionKeywords = map[string]string{
+	"and": "&&",
+	"or":  "||",
+	"not": "!",
+}
+
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_' || r == '$'
+}
+
+func isIdentPart(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '$'
+}
+
+func isPartitionPart(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+// 讀取 ':' 之後的分區名稱，回傳名稱與下一個字元的位置
+func scanPartition(runes []rune, i int) (string, int, error) {
+	if i < len(runes) && runes[i] == '"' {
+		end := i + 1
+		for end < len(runes) && runes[end] != '"' {
+			end++
+		}
+		if end >= len(runes) {
+			return "", 0, fmt.Errorf("分區名稱缺少結束引號 [位置:%d]", i)
+		}
+		if end == i+1 {
+			return "", 0, fmt.Errorf("分區名稱不能為空 [位置:%d]", i)
+		}
+		return string(runes[i+1 : end]), end + 1, nil
+	}
+	if strings.HasPrefix(string(runes[i:]), expressionPartitionVar) {
+		return expressionPartitionVar, i + len(expressionPartitionVar), nil
+	}
+	start := i
+	for i < len(runes) && isPartitionPart(runes[i]) {
+		i++
+	}
+	if i == start {
+		return "", 0, fmt.Errorf("缺少分區名稱 [位置:%d]", start)
+	}
+	return string(runes[start:i]), i, nil
+}
+
+func tokenizeExpression(source string) ([]exprToken, error) {
+	runes := []rune(source)
+	var tokens []exprToken
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E' ||
+				((runes[i] == '+' || runes[i] == '-') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+			tokens = append(tokens, exprToken{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+		case isIdentStart(r):
+			start := i
+			for i < len(runes) && isIdentPart(runes[i]) {
+				i++
+			}
+			text := string(runes[start:i])
+			// metric:partition
+			if i < len(runes) && runes[i] == ':' {
+				partition, next, err := scanPartition(runes, i+1)
+				if err != nil {
+					return nil, err
+				}
+				tokens = append(tokens, exprToken{kind: tokenIdent, text: text + ":" + partition, pos: start})
+				i = next
+				continue
+			}
+			if op, ok := expressionKeywords[strings.ToLower(text)]; ok {
+				tokens = append(tokens, exprToken{kind: tokenOperator, text: op, pos: start})
+			} else {
+				tokens = append(tokens, exprToken{kind: tokenIdent, text: text, pos: start})
+			}
+		case r == '(':
+			tokens = append(tokens, exprToken{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, exprToken{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, exprToken{kind: tokenComma, text: ",", pos: i})
+			i++
+		default:
+			// 兩字元運算子優先
+			if i+1 < len(runes) {
+				two := string(runes[i : i+2])
+				switch two {
+				case ">=", "<=", "==", "!=", "&&", "||":
+					tokens = append(tokens, exprToken{kind: tokenOperator, text: two, pos: i})
+					i += 2
+					continue
+				}
+			}
+			if strings.ContainsRune("+-*/%<>!", r) {
+				tokens = append(tokens, exprToken{kind: tokenOperator, text: string(r), pos: i})
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("表達式包含無效字元 [位置:%d, 字元:%q]", i, r)
+		}
+	}
+
+	return append(tokens, exprToken{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+//* ======================== 語法分析 ========================
+
+// 運算子優先順序 (低到高)：|| → && → 比較 → + - → * / % → 單元運算
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	token := p.tokens[p.pos]
+	if token.kind != tokenEOF {
+		p.pos++
+	}
+	return token
+}
+
+func (p *exprParser) acceptOperator(ops ...string) (string, bool) {
+	token := p.peek()
+	if token.kind != tokenOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if token.text == op {
+			p.next()
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) parseBinary(next func() (exprNode, error), ops ...string) (exprNode, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.acceptOperator(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseBinary(p.parseAnd, "||")
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	return p.parseBinary(p.parseAdditive, ">", ">=", "<", "<=", "==", "!=")
+}
+
+func (p *exprParser) parseAdditive() (exprNode, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *exprParser) parseMultiplicative() (exprNode, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if op, ok := p.acceptOperator("-", "!"); ok {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{op: op, x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	token := p.next()
+	switch token.kind {
+	case tokenNumber:
+		value, err := strconv.ParseFloat(token.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("無效的數字 [位置:%d, 數字:%s]", token.pos, token.text)
+		}
+		return numberNode{value: value}, nil
+	case tokenIdent:
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(token)
+		}
+		return variableNode{name: token.text}, nil
+	case tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokenRParen {
+			return nil, fmt.Errorf("缺少右括號 [位置:%d]", token.pos)
+		}
+		return node, nil
+	case tokenEOF:
+		return nil, fmt.Errorf("表達式不完整")
+	}
+	return nil, fmt.Errorf("表達式語法錯誤 [位置:%d, token:%s]", token.pos, token.text)
+}
+
+func (p *exprParser) parseCall(name exprToken) (exprNode, error) {
+	fn := strings.ToLower(name.text)
+	p.next() // (
+
+	var args []exprNode
+	if p.peek().kind != tokenRParen {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+	if p.next().kind != tokenRParen {
+		return nil, fmt.Errorf("函數缺少右括號 [位置:%d, function:%s]", name.pos, name.text)
+	}
+
+	switch fn {
+	case "abs":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("abs 需要 1 個參數 [位置:%d]", name.pos)
+		}
+	case "min", "max":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("%s 至少需要 1 個參數 [位置:%d]", fn, name.pos)
+		}
+	default:
+		return nil, fmt.Errorf("不支援的函數 [位置:%d, function:%s]", name.pos, name.text)
+	}
+	return callNode{fn: fn, args: args}, nil
+}
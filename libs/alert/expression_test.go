@@ -0,0 +1,347 @@
+package alert
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/detect-viz/shared-lib/infra/logger"
+	"github.com/detect-viz/shared-lib/models"
+	"go.uber.org/zap"
+)
+
+// nopLogger 測試用，不輸出任何日誌
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...zap.Field)         {}
+func (nopLogger) Error(string, ...zap.Field)        {}
+func (nopLogger) Warn(string, ...zap.Field)         {}
+func (nopLogger) Debug(string, ...zap.Field)        {}
+func (l nopLogger) With(...zap.Field) logger.Logger { return l }
+func (l nopLogger) Named(string) logger.Logger      { return l }
+func (nopLogger) IsDebugMode() bool                 { return false }
+func (l nopLogger) Clone() logger.Logger            { return l }
+func (nopLogger) Sync() error                       { return nil }
+func (nopLogger) Close() error                      { return nil }
+func (nopLogger) GetLogger() *zap.Logger            { return zap.NewNop() }
+
+// 以固定數值查詢指標
+func lookupValues(values map[string]float64) func(string) (float64, error) {
+	return func(name string) (float64, error) {
+		value, ok := values[name]
+		if !ok {
+			return 0, fmt.Errorf("missing %s", name)
+		}
+		return value, nil
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	cases := []struct {
+		source string
+		errMsg string
+	}{
+		{"", "expression 不能為空"},
+		{"   ", "expression 不能為空"},
+		{"a +", "表達式不完整"},
+		{"(a + b", "缺少右括號"},
+		{"a b", "表達式語法錯誤"},
+		{"a # b", "表達式包含無效字元"},
+		{"1.2.3", "無效的數字"},
+		{"sqrt(a)", "不支援的函數"},
+		{"abs(a, b)", "abs 需要 1 個參數"},
+		{"max()", "max 至少需要 1 個參數"},
+		{"min(a, b", "函數缺少右括號"},
+		{`current:"L1`, "分區名稱缺少結束引號"},
+		{`current:""`, "分區名稱不能為空"},
+		{"current:", "缺少分區名稱"},
+		{"current:-L1", "缺少分區名稱"},
+	}
+
+	for _, tc := range cases {
+		_, err := ParseExpression(tc.source)
+		if err == nil {
+			t.Errorf("ParseExpression(%q) 應回傳錯誤", tc.source)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.errMsg) {
+			t.Errorf("ParseExpression(%q) 錯誤 = %q，應包含 %q", tc.source, err, tc.errMsg)
+		}
+	}
+}
+
+func TestExpressionEval(t *testing.T) {
+	values := map[string]float64{
+		"a":                  2,
+		"b":                  3,
+		"c":                  4,
+		"watt:L1":            3200,
+		"voltage:L1":         220,
+		"current:L1-1":       10,
+		"current:$partition": 5,
+		"pdu.temp":           45,
+		"a:L1":               3,
+	}
+
+	cases := []struct {
+		source string
+		want   float64
+	}{
+		// 數值運算優先順序
+		{"a + b * c", 14},
+		{"(a + b) * c", 20},
+		{"c - b - a", -1},
+		{"c / a / a", 1},
+		{"c % b", 1},
+		{"-a * b", -6},
+		{"--a", 2},
+		{"1e3 + .5", 1000.5},
+		// 比較與邏輯運算
+		{"a + b > c", 1},
+		{"a < b == 1", 1},
+		{"a > b || b > a && c > b", 1},
+		{"(a > b || b > a) && c < b", 0},
+		{"a > b AND b > a OR c > b", 1},
+		{"NOT a > b", 0}, // 單元運算優先：(!a) > b
+		{"NOT (a > b)", 1},
+		{"!a", 0},
+		{"!(a == 2)", 0},
+		{"a != b", 1},
+		{"a >= 2 && a <= 2", 1},
+		// 函數
+		{"abs(a - c)", 2},
+		{"min(c, a, b)", 2},
+		{"max(c, a, b)", 4},
+		{"MAX(a, -c)", 2},
+		// 指標分區
+		{"watt:L1 / voltage:L1", 3200.0 / 220},
+		{"watt:L1 > 3000 && voltage:L1 < 230", 1},
+		{`current:"L1-1" * 2`, 20},
+		{"current:$partition + 1", 6},
+		{"pdu.temp - 40", 5},
+		// 未加引號的分區只取 [A-Za-z0-9_]，'-' 為減號
+		{"a:L1-b", 0},
+	}
+
+	lookup := lookupValues(values)
+	for _, tc := range cases {
+		expr, err := ParseExpression(tc.source)
+		if err != nil {
+			t.Errorf("ParseExpression(%q) 錯誤: %v", tc.source, err)
+			continue
+		}
+		got, err := expr.Eval(lookup)
+		if err != nil {
+			t.Errorf("Eval(%q) 錯誤: %v", tc.source, err)
+			continue
+		}
+		if math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("Eval(%q) = %v，應為 %v", tc.source, got, tc.want)
+		}
+	}
+}
+
+func TestExpressionEvalErrors(t *testing.T) {
+	lookup := lookupValues(map[string]float64{"a": 1, "zero": 0})
+
+	cases := []struct {
+		source string
+		errMsg string
+	}{
+		{"a / zero", "除以零"},
+		{"a % zero", "除以零"},
+		{"a + missing", "missing missing"},
+	}
+	for _, tc := range cases {
+		expr, err := ParseExpression(tc.source)
+		if err != nil {
+			t.Fatalf("ParseExpression(%q) 錯誤: %v", tc.source, err)
+		}
+		if _, err := expr.Eval(lookup); err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+			t.Errorf("Eval(%q) 錯誤 = %v，應包含 %q", tc.source, err, tc.errMsg)
+		}
+	}
+
+	// 邏輯運算短路，不查詢右側指標
+	for _, source := range []string{"zero && missing", "a || missing"} {
+		expr, _ := ParseExpression(source)
+		if _, err := expr.Eval(lookup); err != nil {
+			t.Errorf("Eval(%q) 應短路，錯誤: %v", source, err)
+		}
+	}
+}
+
+func TestExpressionVariables(t *testing.T) {
+	expr, err := ParseExpression(`max(watt:L1, watt:"L2-1") / voltage + watt:L1 > current:$partition`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(expr.Variables(), ",")
+	want := "current:$partition,voltage,watt:L1,watt:L2-1"
+	if got != want {
+		t.Errorf("Variables() = %s，應為 %s", got, want)
+	}
+
+	keys, err := expressionMetricKeys(models.MetricRule{Expression: expr.String()}, "L3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(keys, ","); got != "current:L3,voltage,watt:L1,watt:L2-1" {
+		t.Errorf("expressionMetricKeys() = %s", got)
+	}
+}
+
+func TestExpressionDetectorEvaluate(t *testing.T) {
+	detector := &expressionDetector{}
+	warn := 1.0
+
+	cases := []struct {
+		name         string
+		expression   string
+		scale        float64
+		series       map[string][]models.MetricValue
+		wantExceeded bool
+		wantValue    float64
+		wantSeverity string
+		wantErr      string
+	}{
+		{
+			name:       "取最新值計算並依 Scale 換算",
+			expression: "watt:$partition / voltage:$partition",
+			scale:      0.5,
+			series: map[string][]models.MetricValue{
+				"watt:L1":    {{Timestamp: 1, Value: 100}, {Timestamp: 2, Value: 4400}},
+				"voltage:L1": {{Timestamp: 2, Value: 220}},
+			},
+			wantExceeded: true,
+			wantValue:    10,
+			wantSeverity: "crit",
+		},
+		{
+			name:       "換算後只超過 warn 閾值",
+			expression: "watt:$partition / voltage:$partition",
+			scale:      0.1,
+			series: map[string][]models.MetricValue{
+				"watt:L1":    {{Timestamp: 2, Value: 4400}},
+				"voltage:L1": {{Timestamp: 2, Value: 220}},
+			},
+			wantValue:    2,
+			wantSeverity: "warn",
+			wantExceeded: true,
+		},
+		{
+			name:       "布林表達式",
+			expression: "watt:L1 > 3000 && voltage:L1 < 200",
+			scale:      1,
+			series: map[string][]models.MetricValue{
+				"watt:L1":    {{Timestamp: 2, Value: 3200}},
+				"voltage:L1": {{Timestamp: 2, Value: 210}},
+			},
+			wantValue: 0,
+		},
+		{
+			name:       "缺少指標數據",
+			expression: "watt:L1 / voltage:L1",
+			scale:      1,
+			series: map[string][]models.MetricValue{
+				"watt:L1": {{Timestamp: 2, Value: 3200}},
+			},
+			wantErr: "找不到指標數據 [metric:voltage:L1]",
+		},
+		{
+			name:       "指標沒有數據點",
+			expression: "watt:L1",
+			scale:      1,
+			series: map[string][]models.MetricValue{
+				"watt:L1": {},
+			},
+			wantErr: "找不到指標數據 [metric:watt:L1]",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := &models.Rule{CritThreshold: 5, WarnThreshold: &warn}
+			rule.Target.PartitionName = "L1"
+			result, err := detector.Evaluate(DetectInput{
+				Rule:       rule,
+				MetricRule: models.MetricRule{Expression: tc.expression, Scale: tc.scale, Operator: "gt"},
+				Series:     tc.series,
+			})
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("錯誤 = %v，應包含 %q", err, tc.wantErr)
+				}
+				if result.Details["evaluation_error"] == "" {
+					t.Errorf("Details 應記錄 evaluation_error: %v", result.Details)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Exceeded != tc.wantExceeded || math.Abs(result.Value-tc.wantValue) > 1e-9 || result.Severity != tc.wantSeverity {
+				t.Errorf("結果 = (%v, %v, %q)，應為 (%v, %v, %q)",
+					result.Exceeded, result.Value, result.Severity, tc.wantExceeded, tc.wantValue, tc.wantSeverity)
+			}
+			if result.Details["expression_result"] == "" {
+				t.Errorf("Details 應記錄 expression_result: %v", result.Details)
+			}
+		})
+	}
+}
+
+// payload 只帶表達式引用的指標 (沒有 metric_raw_name) 時仍應匹配並觸發規則
+func TestExpressionRuleMatchesReferencedMetrics(t *testing.T) {
+	metricRule := models.MetricRule{
+		UID:           "pdu_power_factor",
+		DetectionType: "expression",
+		Expression:    "watt:$partition / voltage:$partition",
+		Scale:         1,
+		Operator:      "gt",
+	}
+	rule := models.Rule{ID: []byte("rule-1"), MetricRuleUID: metricRule.UID, CritThreshold: 10}
+	rule.Target.ResourceName = "pdu-01"
+	rule.Target.PartitionName = "L1"
+
+	s := &Service{
+		logger: nopLogger{},
+		global: models.GlobalConfig{MetricRules: map[string]models.MetricRule{metricRule.UID: metricRule}},
+		globalRules: map[string]map[string]map[string][]models.Rule{
+			"master": {"pdu-01": {metricRule.UID + ":L1": {rule}}},
+		},
+	}
+
+	payload := models.AlertPayload{
+		Metadata: models.Metadata{RealmName: "master", ResourceName: "pdu-01"},
+		Data: map[string][]models.MetricValue{
+			"watt:L1":    {{Timestamp: 100, Value: 3300}},
+			"voltage:L1": {{Timestamp: 100, Value: 220}},
+		},
+	}
+
+	matched := s.matchRulesFromGlobalRules(payload)
+	if len(matched) != 1 {
+		t.Fatalf("應匹配 1 條規則，實際 %d", len(matched))
+	}
+
+	result, err := s.evaluateRule(&matched[0], nil, payload.Data, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Exceeded || result.Value != 15 || result.Severity != "crit" {
+		t.Errorf("結果 = (%v, %v, %q)，應觸發 crit 且數值為 15", result.Exceeded, result.Value, result.Severity)
+	}
+
+	// 其他分區的指標不應匹配
+	payload.Data = map[string][]models.MetricValue{"watt:L2": {{Timestamp: 100, Value: 3300}}}
+	if matched := s.matchRulesFromGlobalRules(payload); len(matched) != 0 {
+		t.Errorf("不相關的指標不應匹配，實際 %d", len(matched))
+	}
+
+	// 單一指標檢查不支援 expression 規則
+	if _, _, _, err := s.CheckSingle(&rule, nil, 100); err == nil {
+		t.Error("CheckSingle 應拒絕 expression 規則")
+	}
+}
@@ -0,0 +1,342 @@
+package alert
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/detect-viz/shared-lib/models"
+	"go.uber.org/zap"
+)
+
+// 告警分組：
+// 告警依聯絡人與分組欄位 (group_by) 分組，每組合併為一則通知
+// 新分組等待 group_wait 收集同時發生的告警後第一次通知；已通知的分組有新告警時，距上次通知至少 group_interval 才再通知
+// 分組中仍在告警 (已發送、未確認、未恢復) 的告警每 repeat_interval 重新通知
+// 聯絡人設定 group_by / group_wait / group_interval / repeat_interval 時優先於 alert.grouping；
+// 未設定任何分組設定時，與過去相同：同一聯絡人的告警合併，每次處理立即發送、不重複通知
+// 恢復通知依相同分組合併，不等待
+// 同一告警路由到多個聯絡人時，各聯絡人的分組分別等待並記錄通知紀錄 (notify_deliveries)，
+// 所有聯絡人都已通知後才更新告警的通知狀態，尚未通知的聯絡人於下一輪繼續處理
+// 告警符合的通知路由設定 group_policy 時，該路由聯絡人的分組設定以路由優先 (見 route.go)
+
+// waitingGroupExpiry 尚未通知即已沒有告警的分組保留時間
+const waitingGroupExpiry = 24 * time.Hour
+
+// 告警的內建欄位 (其餘欄位為規則標籤，例如 datacenter / room / rack)
+const (
+	alertFieldRealmName     = "realm_name"
+	alertFieldResourceName  = "resource_name"
+	alertFieldPartitionName = "partition_name"
+	alertFieldMetricRuleUID = "metric_rule_uid"
+	alertFieldCategory      = "category"
+	alertFieldSeverity      = "severity"
+)
+
+// alertGroup 同一聯絡人、同一分組的告警
+type alertGroup struct {
+	contactID string
+	key       string
+	labels    map[string]string
+	policy    models.GroupPolicy
+	logs      []models.TriggeredLog
+}
+
+// groupPolicy 聯絡人的分組設定
+func (s *Service) groupPolicy(contact *models.Contact) models.GroupPolicy {
+	policy := s.config.Grouping
+	if v, ok := contact.Config["group_by"]; ok {
+		policy.GroupBy = splitGroupBy(v)
+	}
+	if v := contact.Config["group_wait"]; v != "" {
+		policy.GroupWait = v
+	}
+	if v := contact.Config["group_interval"]; v != "" {
+		policy.GroupInterval = v
+	}
+	if v := contact.Config["repeat_interval"]; v != "" {
+		policy.RepeatInterval = v
+	}
+	return policy
+}
+
+// splitGroupBy 解析以逗號分隔的分組欄位
+func splitGroupBy(value string) []string {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// alertLabels 告警可用於分組與比對的欄位：規則標籤 (datacenter / room / rack ...) 加上內建欄位
+// ruleLabels 為規則標籤快取，避免同一規則重複查詢
+func (s *Service) alertLabels(log models.TriggeredLog, ruleLabels map[string]map[string]string) map[string]string {
+	labels, ok := ruleLabels[string(log.RuleID)]
+	if !ok {
+		var err error
+		labels, err = s.mysql.GetRuleLabelByRuleID(log.RuleID)
+		if err != nil {
+			s.logger.Error("獲取規則標籤失敗", zap.Error(err), zap.String("rule_id", formatID(log.RuleID)))
+		}
+		ruleLabels[string(log.RuleID)] = labels
+	}
+
+	fields := make(map[string]string, len(labels)+6)
+	for k, v := range labels {
+		fields[k] = v
+	}
+	fields[alertFieldRealmName] = log.RealmName
+	fields[alertFieldResourceName] = log.ResourceName
+	fields[alertFieldPartitionName] = log.PartitionName
+	fields[alertFieldMetricRuleUID] = log.MetricRuleUID
+	fields[alertFieldSeverity] = log.Severity
+	if metricRule, exists := s.global.MetricRules[log.MetricRuleUID]; exists {
+		fields[alertFieldCategory] = metricRule.Category
+	}
+	return fields
+}
+
+// splitAlertGroups 將各聯絡人的告警依分組設定拆分
+func (s *Service) splitAlertGroups(groupedLogs map[string][]models.TriggeredLog) []alertGroup {
+	ruleLabels := make(map[string]map[string]string)
+	var groups []alertGroup
+
+	for contactID, logs := range groupedLogs {
+		contact, err := s.mysql.GetContact([]byte(contactID))
+		if err != nil {
+			s.logger.Error("獲取聯絡人信息失敗", zap.Error(err), zap.String("contact_id", formatID([]byte(contactID))))
+			continue
+		}
+		contactPolicy := s.groupPolicy(contact)
+
+		byKey := make(map[string]*alertGroup)
+		var keys []string
+		for _, log := range logs {
+			fields := s.alertLabels(log, ruleLabels)
+			policy := contactPolicy
+			routePolicy, route := routeGroupPolicy(s.routeMatches(log, fields), contactID)
+			if routePolicy != nil {
+				policy = mergeGroupPolicy(contactPolicy, *routePolicy)
+			}
+
+			labels := make(map[string]string, len(policy.GroupBy))
+			for _, name := range policy.GroupBy {
+				labels[name] = fields[name]
+			}
+			key := groupKey(contactID, route, labels)
+			group, exists := byKey[key]
+			if !exists {
+				group = &alertGroup{contactID: contactID, key: key, labels: labels, policy: policy}
+				byKey[key] = group
+				keys = append(keys, key)
+			}
+			group.logs = append(group.logs, log)
+		}
+
+		sort.Strings(keys)
+		for _, key := range keys {
+			groups = append(groups, *byKey[key])
+		}
+	}
+	return groups
+}
+
+// groupKey 以聯絡人、路由與排序後的分組欄位值計算分組鍵 (未套用路由分組設定時 route 為空)
+func groupKey(contactID, route string, labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(hex.EncodeToString([]byte(contactID)))
+	if route != "" {
+		b.WriteString("\nroute:" + route)
+	}
+	for _, name := range names {
+		b.WriteString("\n" + name + "=" + labels[name])
+	}
+	sum := sha1.Sum([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// groupDurations 解析分組時間設定，格式錯誤時視為未設定
+func (s *Service) groupDurations(group alertGroup) (wait, interval, repeat time.Duration) {
+	wait, interval, repeat, err := group.policy.Durations()
+	if err != nil {
+		s.logger.Error("分組時間設定格式無效，改為立即通知",
+			zap.Error(err),
+			zap.String("contact_id", formatID([]byte(group.contactID))))
+		return 0, 0, 0
+	}
+	return wait, interval, repeat
+}
+
+// notifyGroup 獲取告警分組的通知狀態，不存在時建立 (開始 group_wait)
+func (s *Service) notifyGroup(group alertGroup, realm string, now time.Time) (*models.NotifyGroup, error) {
+	notifyGroup, err := s.mysql.GetNotifyGroup([]byte(group.contactID), group.key)
+	if err != nil || notifyGroup != nil {
+		return notifyGroup, err
+	}
+
+	notifyGroup = &models.NotifyGroup{
+		RealmName:   realm,
+		ContactID:   []byte(group.contactID),
+		GroupKey:    group.key,
+		Labels:      group.labels,
+		FirstSeenAt: now.Unix(),
+	}
+	if err := s.mysql.CreateNotifyGroup(notifyGroup); err != nil {
+		return nil, err
+	}
+	return notifyGroup, nil
+}
+
+// groupReady 判斷分組是否到達通知時間：尚未通知時等待 group_wait，已通知時等待 group_interval
+func (s *Service) groupReady(group alertGroup, notifyGroup *models.NotifyGroup, now time.Time) bool {
+	wait, interval, _ := s.groupDurations(group)
+	if notifyGroup.LastNotifiedAt == nil {
+		return !now.Before(time.Unix(notifyGroup.FirstSeenAt, 0).Add(wait))
+	}
+	return !now.Before(time.Unix(*notifyGroup.LastNotifiedAt, 0).Add(interval))
+}
+
+// markGroupNotified 更新分組的最後通知時間
+func (s *Service) markGroupNotified(notifyGroup *models.NotifyGroup, now time.Time) {
+	if err := s.mysql.UpdateNotifyGroupNotifiedAt(notifyGroup.ID, now.Unix()); err != nil {
+		s.logger.Error("更新分組通知時間失敗", zap.Error(err), zap.String("group_key", notifyGroup.GroupKey))
+	}
+}
+
+// notifyDeliveries 告警對聯絡人的異常通知狀態，key 為 deliveryKey
+type notifyDeliveries map[string]string
+
+func deliveryKey(triggeredLogID []byte, contactID string) string {
+	return string(triggeredLogID) + "\x00" + contactID
+}
+
+// loadNotifyDeliveries 獲取告警已發送的異常通知紀錄
+func (s *Service) loadNotifyDeliveries(logs []models.TriggeredLog) (notifyDeliveries, error) {
+	ids := make([][]byte, 0, len(logs))
+	for _, log := range logs {
+		ids = append(ids, log.ID)
+	}
+	records, err := s.mysql.GetNotifyDeliveries(ids)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make(notifyDeliveries, len(records))
+	for _, record := range records {
+		deliveries[deliveryKey(record.TriggeredLogID, string(record.ContactID))] = record.State
+	}
+	return deliveries, nil
+}
+
+// set 記錄告警對聯絡人的通知狀態
+func (d notifyDeliveries) set(logs []models.TriggeredLog, contactID, state string) {
+	for _, log := range logs {
+		d[deliveryKey(log.ID, contactID)] = state
+	}
+}
+
+// undelivered 分組中尚未通知該聯絡人的告警
+func (d notifyDeliveries) undelivered(group alertGroup) []models.TriggeredLog {
+	var logs []models.TriggeredLog
+	for _, log := range group.logs {
+		if _, ok := d[deliveryKey(log.ID, group.contactID)]; !ok {
+			logs = append(logs, log)
+		}
+	}
+	return logs
+}
+
+// completedNotifyStates 所有分組的聯絡人都已通知的告警與其通知狀態 (key 為告警 ID)
+// 各聯絡人的結果不同時，等待重試 (delayed) 優先於已發送，其次為死信與被限流
+func completedNotifyStates(groups []alertGroup, deliveries notifyDeliveries) map[string]string {
+	states := make(map[string]string)
+	incomplete := make(map[string]bool)
+	for _, group := range groups {
+		for _, log := range group.logs {
+			id := string(log.ID)
+			state, ok := deliveries[deliveryKey(log.ID, group.contactID)]
+			if !ok {
+				incomplete[id] = true
+				continue
+			}
+			if current, exists := states[id]; !exists || notifyStateRank(state) < notifyStateRank(current) {
+				states[id] = state
+			}
+		}
+	}
+	for id := range incomplete {
+		delete(states, id)
+	}
+	return states
+}
+
+// notifyStateRank 合併多個聯絡人的通知結果時的優先順序，數字越小越優先
+func notifyStateRank(state string) int {
+	switch state {
+	case NotifyStateDelayed:
+		return 0
+	case NotifyStateSent:
+		return 1
+	case NotifyStateDeadLetter:
+		return 2
+	case NotifyStateSuppressed:
+		return 3
+	}
+	return 4
+}
+
+// processRepeatNotifications 重新通知超過 repeat_interval 仍在告警中的分組，並清除已沒有告警的分組
+// activeGroups 為本次處理中仍有待通知告警的分組
+// 重複通知前與異常通知相同經過抑制規則、臨時靜默與告警抑制的檢查，被抑制的告警不重複通知
+func (s *Service) processRepeatNotifications(now time.Time, activeGroups [][]byte) error {
+	logs, err := s.mysql.GetFiringTriggeredLogs()
+	if err != nil {
+		return err
+	}
+	logs = s.filterMutedLogs(logs, "alerting", now)
+	logs = s.filterInhibitedLogs(logs, "alerting")
+
+	for _, group := range s.splitAlertGroups(s.GroupByContact(logs)) {
+		notifyGroup, err := s.mysql.GetNotifyGroup([]byte(group.contactID), group.key)
+		if err != nil {
+			s.logger.Error("獲取告警分組失敗", zap.Error(err), zap.String("group_key", group.key))
+			continue
+		}
+		if notifyGroup == nil {
+			// 分組功能啟用前已發送的告警，從現在開始計算重複通知時間
+			if notifyGroup, err = s.notifyGroup(group, group.logs[0].RealmName, now); err != nil {
+				s.logger.Error("建立告警分組失敗", zap.Error(err), zap.String("group_key", group.key))
+				continue
+			}
+			s.markGroupNotified(notifyGroup, now)
+			activeGroups = append(activeGroups, notifyGroup.ID)
+			continue
+		}
+		activeGroups = append(activeGroups, notifyGroup.ID)
+
+		_, _, repeat := s.groupDurations(group)
+		if repeat <= 0 || notifyGroup.LastNotifiedAt == nil ||
+			now.Before(time.Unix(*notifyGroup.LastNotifiedAt, 0).Add(repeat)) {
+			continue
+		}
+
+		s.logger.Info("重複通知仍在告警中的分組",
+			zap.String("contact_id", formatID([]byte(group.contactID))),
+			zap.Any("labels", group.labels),
+			zap.Int("count", len(group.logs)))
+		s.sendGroupedNotifications(map[string][]models.TriggeredLog{group.contactID: group.logs}, "alerting", false)
+		s.markGroupNotified(notifyGroup, now)
+	}
+
+	return s.mysql.DeleteIdleNotifyGroups(activeGroups, now.Add(-waitingGroupExpiry).Unix())
+}
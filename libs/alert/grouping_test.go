@@ -0,0 +1,123 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/detect-viz/shared-lib/models"
+)
+
+// 同一告警路由到 group_wait 不同的兩個聯絡人：先到達通知時間的聯絡人發送後，
+// 告警仍保持待處理，另一聯絡人到達 group_wait 後才發送並更新告警的通知狀態
+func TestGroupDeliveriesWithDifferentGroupWait(t *testing.T) {
+	s := &Service{logger: nopLogger{}}
+	start := time.Unix(1700000000, 0)
+	log := models.TriggeredLog{ID: []byte("log-1"), NotifyState: NotifyStatePending}
+
+	groups := []alertGroup{
+		{contactID: "contact-a", key: "a", policy: models.GroupPolicy{GroupWait: "30s"}, logs: []models.TriggeredLog{log}},
+		{contactID: "contact-b", key: "b", policy: models.GroupPolicy{GroupWait: "5m"}, logs: []models.TriggeredLog{log}},
+	}
+	notifyGroups := []*models.NotifyGroup{{FirstSeenAt: start.Unix()}, {FirstSeenAt: start.Unix()}}
+	deliveries := make(notifyDeliveries)
+
+	// process 模擬一輪通知處理，回傳本輪發送的聯絡人
+	process := func(now time.Time) []string {
+		var sent []string
+		for i, group := range groups {
+			pending := deliveries.undelivered(group)
+			if len(pending) == 0 || !s.groupReady(group, notifyGroups[i], now) {
+				continue
+			}
+			deliveries.set(pending, group.contactID, NotifyStateSent)
+			notified := now.Unix()
+			notifyGroups[i].LastNotifiedAt = &notified
+			sent = append(sent, group.contactID)
+		}
+		return sent
+	}
+
+	rounds := []struct {
+		at        time.Duration
+		sent      []string
+		completed bool
+	}{
+		{at: 0, sent: nil, completed: false},
+		{at: 30 * time.Second, sent: []string{"contact-a"}, completed: false},
+		{at: 2 * time.Minute, sent: nil, completed: false},
+		{at: 5 * time.Minute, sent: []string{"contact-b"}, completed: true},
+		{at: 10 * time.Minute, sent: nil, completed: true},
+	}
+	for _, round := range rounds {
+		sent := process(start.Add(round.at))
+		if len(sent) != len(round.sent) || (len(sent) > 0 && sent[0] != round.sent[0]) {
+			t.Fatalf("%v: sent = %v, want %v", round.at, sent, round.sent)
+		}
+		state, completed := completedNotifyStates(groups, deliveries)[string(log.ID)]
+		if completed != round.completed {
+			t.Fatalf("%v: completed = %v, want %v", round.at, completed, round.completed)
+		}
+		if completed && state != NotifyStateSent {
+			t.Fatalf("%v: state = %q, want %q", round.at, state, NotifyStateSent)
+		}
+	}
+}
+
+func TestCompletedNotifyStates(t *testing.T) {
+	logA := models.TriggeredLog{ID: []byte("a")}
+	logB := models.TriggeredLog{ID: []byte("b")}
+	groups := []alertGroup{
+		{contactID: "c1", logs: []models.TriggeredLog{logA, logB}},
+		{contactID: "c2", logs: []models.TriggeredLog{logA}},
+	}
+
+	cases := []struct {
+		name       string
+		deliveries notifyDeliveries
+		want       map[string]string
+	}{
+		{
+			name:       "無通知紀錄",
+			deliveries: notifyDeliveries{},
+			want:       map[string]string{},
+		},
+		{
+			name: "只通知部分聯絡人",
+			deliveries: notifyDeliveries{
+				deliveryKey(logA.ID, "c1"): NotifyStateSent,
+				deliveryKey(logB.ID, "c1"): NotifyStateSent,
+			},
+			want: map[string]string{"b": NotifyStateSent},
+		},
+		{
+			name: "等待重試優先於已發送",
+			deliveries: notifyDeliveries{
+				deliveryKey(logA.ID, "c1"): NotifyStateSent,
+				deliveryKey(logA.ID, "c2"): NotifyStateDelayed,
+			},
+			want: map[string]string{"a": NotifyStateDelayed},
+		},
+		{
+			name: "已發送優先於被限流",
+			deliveries: notifyDeliveries{
+				deliveryKey(logA.ID, "c1"): NotifyStateSuppressed,
+				deliveryKey(logA.ID, "c2"): NotifyStateSent,
+				deliveryKey(logB.ID, "c1"): NotifyStateSuppressed,
+			},
+			want: map[string]string{"a": NotifyStateSent, "b": NotifyStateSuppressed},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := completedNotifyStates(groups, tc.deliveries)
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			for id, state := range tc.want {
+				if got[id] != state {
+					t.Fatalf("%s: got %q, want %q", id, got[id], state)
+				}
+			}
+		})
+	}
+}
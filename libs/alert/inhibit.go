@@ -0,0 +1,147 @@
+package alert
+
+import (
+	"github.com/detect-viz/shared-lib/models"
+	"go.uber.org/zap"
+)
+
+// 告警抑制 (inhibition)：
+// 通知前以域內的抑制規則比對：符合來源 matcher 且尚未恢復的告警，抑制符合目標 matcher 且 equal 欄位值相同的告警
+// 被抑制的告警標記為 inhibited 並記錄來源告警 (inhibited_by)，每輪重新檢查，來源告警恢復後若仍在告警中才發送
+// 異常通知被抑制而未發送的告警，恢復通知也一併抑制
+
+// inhibitSource 可抑制其他告警的來源告警與其欄位
+// source / target 為告警是否符合各抑制規則的來源 / 目標 matcher (與規則順序對應)，載入時計算一次
+type inhibitSource struct {
+	log    models.TriggeredLog
+	fields map[string]string
+	source []bool
+	target []bool
+}
+
+// filterInhibitedLogs 過濾被抑制的告警
+func (s *Service) filterInhibitedLogs(logs []models.TriggeredLog, notifyType string) []models.TriggeredLog {
+	if s.muteService == nil || len(logs) == 0 {
+		return logs
+	}
+
+	filtered := make([]models.TriggeredLog, 0, len(logs))
+	if notifyType == "resolved" {
+		for _, log := range logs {
+			if log.NotifyState != NotifyStateInhibited {
+				filtered = append(filtered, log)
+				continue
+			}
+			if err := s.mysql.UpdateTriggeredLogResolvedNotifyState(log.ID, NotifyStateInhibited); err != nil {
+				s.logger.Error("更新 TriggeredLog 通知狀態失敗",
+					zap.Error(err),
+					zap.String("triggered_log_id", formatID(log.ID)),
+					zap.String("notify_type", notifyType))
+			}
+		}
+		return filtered
+	}
+
+	ruleLabels := make(map[string]map[string]string)
+	byRealm := make(map[string][]models.TriggeredLog)
+	var realms []string
+	for _, log := range logs {
+		if _, ok := byRealm[log.RealmName]; !ok {
+			realms = append(realms, log.RealmName)
+		}
+		byRealm[log.RealmName] = append(byRealm[log.RealmName], log)
+	}
+
+	for _, realm := range realms {
+		rules, sources, err := s.inhibitSources(realm, ruleLabels)
+		if err != nil {
+			// 查詢失敗時不抑制，避免漏發告警
+			s.logger.Error("檢查告警抑制規則失敗", zap.Error(err), zap.String("realm", realm))
+		}
+
+		for _, log := range byRealm[realm] {
+			var source *models.TriggeredLog
+			if len(rules) > 0 {
+				source = s.matchInhibitSource(log, s.alertLabels(log, ruleLabels), rules, sources)
+			}
+
+			if source == nil {
+				if log.InhibitedBy != nil {
+					if err := s.mysql.ClearTriggeredLogInhibitedBy(log.ID); err != nil {
+						s.logger.Error("清除告警抑制來源失敗", zap.Error(err), zap.String("triggered_log_id", formatID(log.ID)))
+					}
+				}
+				filtered = append(filtered, log)
+				continue
+			}
+
+			if log.NotifyState == NotifyStateInhibited && log.InhibitedBy != nil && string(*log.InhibitedBy) == string(source.ID) {
+				continue
+			}
+			s.logger.Debug("告警被抑制",
+				zap.String("triggered_log_id", formatID(log.ID)),
+				zap.String("inhibited_by", formatID(source.ID)))
+			if err := s.mysql.InhibitTriggeredLog(log.ID, source.ID); err != nil {
+				s.logger.Error("更新告警抑制狀態失敗", zap.Error(err), zap.String("triggered_log_id", formatID(log.ID)))
+			}
+		}
+	}
+	return filtered
+}
+
+// inhibitSources 獲取域內的抑制規則與可作為來源的告警 (尚未恢復)
+func (s *Service) inhibitSources(realm string, ruleLabels map[string]map[string]string) ([]models.InhibitRule, []inhibitSource, error) {
+	rules, err := s.muteService.ListRealmInhibitRules(realm)
+	if err != nil || len(rules) == 0 {
+		return nil, nil, err
+	}
+
+	logs, err := s.mysql.GetUnresolvedTriggeredLogs(realm)
+	if err != nil {
+		return nil, nil, err
+	}
+	sources := make([]inhibitSource, 0, len(logs))
+	for _, log := range logs {
+		fields := s.alertLabels(log, ruleLabels)
+		var source, target []bool
+		for i := range rules {
+			if !rules[i].SourceMatches(fields) {
+				continue
+			}
+			if source == nil {
+				source = make([]bool, len(rules))
+				target = make([]bool, len(rules))
+			}
+			source[i] = true
+			target[i] = rules[i].TargetMatches(fields)
+		}
+		if source != nil {
+			sources = append(sources, inhibitSource{log: log, fields: fields, source: source, target: target})
+		}
+	}
+	return rules, sources, nil
+}
+
+// matchInhibitSource 找出抑制該告警的來源告警，沒有時回傳 nil
+// 同時符合來源與目標 matcher 的告警，不會被同樣同時符合兩者的告警抑制
+func (s *Service) matchInhibitSource(log models.TriggeredLog, fields map[string]string, rules []models.InhibitRule, sources []inhibitSource) *models.TriggeredLog {
+	for i := range rules {
+		if !rules[i].TargetMatches(fields) {
+			continue
+		}
+		isSource := rules[i].SourceMatches(fields)
+		for j := range sources {
+			src := &sources[j]
+			if !src.source[i] || string(src.log.ID) == string(log.ID) {
+				continue
+			}
+			if isSource && src.target[i] {
+				continue
+			}
+			if rules[i].EqualMatches(src.fields, fields) {
+				return &src.log
+			}
+		}
+	}
+	return nil
+}
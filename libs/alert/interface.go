@@ -19,8 +19,8 @@ type AlertService interface {
 	GetContactService() contacts.Service
 
 	//* 監控頁面
-	ListRuleState(realm string) ([]models.RuleState, error)
-	ListAlertHistory(realm string) ([]models.TriggeredLog, error)
+	ListRuleState(query models.MonitorQuery) ([]models.RuleStateOverview, string, error)
+	ListAlertHistory(query models.MonitorQuery) ([]models.TriggeredLog, string, error)
 
 	//* 指標規則管理
 	GetMetricRule(uid string) (*models.MetricRule, error)
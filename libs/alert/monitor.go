@@ -1,18 +1,111 @@
 package alert
 
 import (
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/detect-viz/shared-lib/apierrors"
 	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/models/mute"
+	"go.uber.org/zap"
 )
 
-func (s *Service) ListRuleState(realm string) ([]models.RuleState, error) {
-	//TODO: 實作 Alert State 查詢
-	return nil, nil
+// * 告警狀態查詢 (目前觸發中的規則)
+func (s *Service) ListRuleState(query models.MonitorQuery) ([]models.RuleStateOverview, string, error) {
+	if err := validateMonitorQuery(&query, "alerting", "resolved", "normal", "disabled"); err != nil {
+		return nil, "", err
+	}
+	states, nextCursor, err := s.mysql.ListRuleStates(query)
+	if err != nil {
+		return nil, "", err
+	}
+	s.markSilencedStates(query.RealmName, states)
+	return states, nextCursor, nil
 }
 
-func (s *Service) ListAlertHistory(realm string) ([]models.TriggeredLog, error) {
-	//TODO: 實作 Triggered Log 查詢
-	return nil, nil
+// * 標記被生效中靜默覆蓋的告警狀態
+func (s *Service) markSilencedStates(realm string, states []models.RuleStateOverview) {
+	if s.muteService == nil || len(states) == 0 {
+		return
+	}
+
+	silences, err := s.muteService.ListActiveSilences(realm, time.Now())
+	if err != nil {
+		s.logger.Error("獲取生效中的靜默失敗", zap.Error(err))
+		return
+	}
+	if len(silences) == 0 {
+		return
+	}
+
+	// 只有在 matcher 需要比對規則標籤時才查詢標籤
+	needLabels := false
+	for _, silence := range silences {
+		if silence.NeedsLabels() {
+			needLabels = true
+		}
+	}
+
+	// 一次查詢所有規則的標籤，避免逐筆查詢
+	var ruleLabels map[string]map[string]string
+	if needLabels {
+		ruleIDs := make([][]byte, 0, len(states))
+		for _, state := range states {
+			ruleIDs = append(ruleIDs, state.RuleID)
+		}
+		ruleLabels, err = s.mysql.GetRuleLabelsByRuleIDs(ruleIDs)
+		if err != nil {
+			s.logger.Error("獲取規則標籤失敗", zap.Error(err), zap.Int("rule_count", len(ruleIDs)))
+			return
+		}
+	}
+
+	for i := range states {
+		state := &states[i]
+		fields := mute.SilenceFields(state.ResourceName, state.PartitionName, state.MetricRuleUID, ruleLabels[string(state.RuleID)])
+		for _, silence := range silences {
+			if silence.Matches(fields) {
+				state.SilencedBy = append(state.SilencedBy, formatID(silence.ID))
+			}
+		}
+	}
+}
+
+// * 告警歷史查詢 (Triggered Log)
+func (s *Service) ListAlertHistory(query models.MonitorQuery) ([]models.TriggeredLog, string, error) {
+	if err := validateMonitorQuery(&query, "alerting", "resolved"); err != nil {
+		return nil, "", err
+	}
+	return s.mysql.ListTriggeredLogs(query)
+}
+
+// * 檢查監控頁面查詢條件，states 為該頁面支援的告警狀態
+func validateMonitorQuery(query *models.MonitorQuery, states ...string) error {
+	if query.RealmName == "" {
+		return apierrors.ErrInvalidRealm
+	}
+	if query.Limit <= 0 {
+		query.Limit = 10
+	}
+	if query.Limit > 1000 {
+		query.Limit = 1000
+	}
+	if query.StartTime > 0 && query.EndTime > 0 && query.StartTime > query.EndTime {
+		return apierrors.NewAPIError(400, "start_time 不可大於 end_time", nil)
+	}
+	if query.Order != "" && query.Order != "asc" && query.Order != "desc" {
+		return apierrors.NewAPIError(400, "order 僅支援 asc / desc", nil)
+	}
+	switch query.Severity {
+	case "", "info", "warn", "crit":
+	default:
+		return apierrors.NewAPIError(400, "severity 僅支援 info / warn / crit", nil)
+	}
+	if query.State != "" && !slices.Contains(states, query.State) {
+		return apierrors.NewAPIError(400, "state 僅支援 "+strings.Join(states, " / "), nil)
+	}
+	return nil
 }
 
 func (s *Service) GetMetricRuleCategoryOptions() ([]models.OptionResponse, error) {
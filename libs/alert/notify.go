@@ -14,6 +14,9 @@ import (
 
 	"github.com/detect-viz/shared-lib/models"
 	"github.com/detect-viz/shared-lib/models/common"
+	"github.com/detect-viz/shared-lib/notifier"
+	notifyerrors "github.com/detect-viz/shared-lib/notifier/errors"
+	"github.com/detect-viz/shared-lib/storage/mysql"
 	"go.uber.org/zap"
 )
 
@@ -24,23 +27,36 @@ import (
 // NotifyStateProcessed - 已處理
 // NotifyStateDelayed - 等待重試
 // NotifyStateFailed - 發送失敗
+// NotifyStateMuted - 抑制期間不發送
+// NotifyStateAcked - 已確認，恢復或升級前不發送
+// NotifyStateDeadLetter - 永久錯誤或超過重試次數，等待手動重送
+// NotifyStateSuppressed - 超過通知流量限制，未發送
+// NotifyStateInhibited - 被告警抑制規則的來源告警抑制，未發送
 
 // NotificationService 子函數說明：
 // GetTriggeredLogs - 查詢未發送通知的 TriggeredLog
-// GroupByContact - 按 ContactID 分組
-// RenderTemplate - 依 FormatType 渲染模板 (HTML / Markdown / JSON / Text)
+// GroupByContact - 按 ContactID 分組 (規則設定升級策略時依已通知的升級步驟取得聯絡人)，再依 group_by 拆分告警分組
+// ProcessRepeatNotifications - 仍在告警中的分組每 repeat_interval 重新通知
+// ProcessEscalations - 告警未確認或恢復時依升級策略通知下一步驟
+// CheckRateLimit - 聯絡人與通道的通知流量限制 (token bucket)，被限流的通知記錄為 suppressed
+// RenderTemplate - 依 FormatType 渲染模板 (HTML / Markdown / JSON / Text)，告警數超過門檻時改發告警風暴摘要
 // SendNotification - 發送通知 (Webhook, Email, Slack)
 // RecordNotifyLog - 記錄 NotifyLog
-// RetryFailedNotifications - retry 機制 (RetryDelay & MaxRetry)
+// RetryFailedNotifications - retry 機制 (聯絡人 RetryDelay & MaxRetry，指數退避，耗盡後移入死信)
 
 // 通知狀態常量
 const (
-	NotifyStatePending   = "pending"   // 等待處理
-	NotifyStateSent      = "sent"      // 已發送告警
-	NotifyStateSolved    = "solved"    // 已發送恢復
-	NotifyStateProcessed = "processed" // 已處理
-	NotifyStateDelayed   = "delayed"   // 等待重試
-	NotifyStateFailed    = "failed"    // 發送失敗
+	NotifyStatePending    = "pending"     // 等待處理
+	NotifyStateSent       = "sent"        // 已發送告警
+	NotifyStateSolved     = "solved"      // 已發送恢復
+	NotifyStateProcessed  = "processed"   // 已處理
+	NotifyStateDelayed    = "delayed"     // 等待重試
+	NotifyStateFailed     = "failed"      // 發送失敗
+	NotifyStateMuted      = "muted"       // 抑制期間不發送
+	NotifyStateAcked      = "acked"       // 已確認，恢復或升級前不發送
+	NotifyStateDeadLetter = "dead_letter" // 永久錯誤或超過重試次數，等待手動重送
+	NotifyStateSuppressed = "suppressed"  // 超過通知流量限制，未發送
+	NotifyStateInhibited  = "inhibited"   // 被告警抑制規則的來源告警抑制，未發送
 )
 
 // ErrorMessage 錯誤訊息結構
@@ -74,16 +90,29 @@ func (s *Service) ProcessNotifyLog() error {
 		zap.Int("alerting_count", len(alertingLogs)),
 		zap.Int("resolved_count", len(resolvedLogs)))
 
-	// 2. 分別處理異常通知和恢復通知
-	if err := s.processNotifications(alertingLogs, "alerting"); err != nil {
-		s.logger.Error("處理異常通知失敗", zap.Error(err))
+	// 2. 過濾已確認、抑制期間及被其他告警抑制的告警
+	alertingLogs = s.filterAckedLogs(alertingLogs)
+	alertingLogs = s.filterMutedLogs(alertingLogs, "alerting", time.Unix(currentTime, 0))
+	resolvedLogs = s.filterMutedLogs(resolvedLogs, "resolved", time.Unix(currentTime, 0))
+	alertingLogs = s.filterInhibitedLogs(alertingLogs, "alerting")
+	resolvedLogs = s.filterInhibitedLogs(resolvedLogs, "resolved")
+
+	// 3. 分別處理異常通知和恢復通知 (異常通知依分組的 group_wait / group_interval 發送)
+	now := time.Unix(currentTime, 0)
+	activeGroups := s.processNotifications(alertingLogs, "alerting", now)
+	s.processNotifications(resolvedLogs, "resolved", now)
+
+	// 4. 處理需要升級的告警
+	if err := s.processEscalations(currentTime); err != nil {
+		s.logger.Error("處理告警升級失敗", zap.Error(err))
 	}
 
-	if err := s.processNotifications(resolvedLogs, "resolved"); err != nil {
-		s.logger.Error("處理恢復通知失敗", zap.Error(err))
+	// 5. 重複通知仍在告警中的分組 (repeat_interval)
+	if err := s.processRepeatNotifications(now, activeGroups); err != nil {
+		s.logger.Error("處理重複通知失敗", zap.Error(err))
 	}
 
-	// 3. 處理需要重試的通知
+	// 6. 處理需要重試的通知
 	if err := s.retryFailedNotifications(); err != nil {
 		s.logger.Error("重試失敗的通知時出錯", zap.Error(err))
 	}
@@ -91,18 +120,168 @@ func (s *Service) ProcessNotifyLog() error {
 	return nil
 }
 
-// processNotifications 處理指定類型的通知
-func (s *Service) processNotifications(logs []models.TriggeredLog, notifyType string) error {
+// filterAckedLogs 過濾已確認的告警，並將其通知狀態標記為 acked
+func (s *Service) filterAckedLogs(logs []models.TriggeredLog) []models.TriggeredLog {
+	filtered := make([]models.TriggeredLog, 0, len(logs))
+	for _, log := range logs {
+		if log.AckedAt == nil {
+			filtered = append(filtered, log)
+			continue
+		}
+		if err := s.mysql.UpdateTriggeredLogNotifyState(log.ID, NotifyStateAcked); err != nil {
+			s.logger.Error("更新 TriggeredLog 通知狀態失敗",
+				zap.Error(err),
+				zap.String("triggered_log_id", formatID(log.ID)),
+				zap.String("notify_state", NotifyStateAcked))
+		}
+	}
+	return filtered
+}
+
+// filterMutedLogs 過濾被 mute 或臨時靜默抑制的告警，並將其通知狀態標記為 muted
+// 異常通知被抑制的告警仍會在下一輪重新檢查，抑制結束後若尚未恢復才發送；
+// 異常通知未曾發送過 (muted) 的告警，恢復通知也一併抑制；
+// 查詢抑制規則或靜默失敗的告警不發送也不更新狀態，維持待處理於下一輪重新檢查
+func (s *Service) filterMutedLogs(logs []models.TriggeredLog, notifyType string, t time.Time) []models.TriggeredLog {
+	if s.muteService == nil || len(logs) == 0 {
+		return logs
+	}
+
+	// 異常通知未曾發送過的恢復通知直接抑制，其餘告警一次批次比對
+	var pending []models.TriggeredLog
+	for _, log := range logs {
+		if notifyType != "resolved" || log.NotifyState != NotifyStateMuted {
+			pending = append(pending, log)
+		}
+	}
+	matches := s.muteService.MatchTriggeredLogs(pending, t)
+
+	filtered := make([]models.TriggeredLog, 0, len(logs))
+	next := 0
+	for _, log := range logs {
+		muted := notifyType == "resolved" && log.NotifyState == NotifyStateMuted
+		if !muted {
+			match := matches[next]
+			next++
+			if match.Err != nil {
+				// 查詢失敗時無法判斷是否在抑制期間，保留待處理狀態於下一輪重新檢查
+				s.logger.Error("檢查抑制規則或靜默失敗，延後通知",
+					zap.Error(match.Err),
+					zap.String("triggered_log_id", formatID(log.ID)))
+				continue
+			}
+			if match.Mute != nil {
+				muted = true
+				s.logger.Debug("告警被抑制",
+					zap.String("triggered_log_id", formatID(log.ID)),
+					zap.String("mute_id", match.Mute.ID),
+					zap.String("mute_name", match.Mute.Name),
+					zap.String("notify_type", notifyType))
+			}
+			if match.Silence != nil {
+				muted = true
+				s.logger.Debug("告警被靜默",
+					zap.String("triggered_log_id", formatID(log.ID)),
+					zap.String("silence_id", formatID(match.Silence.ID)),
+					zap.String("notify_type", notifyType))
+			}
+		}
+		if !muted {
+			filtered = append(filtered, log)
+			continue
+		}
+
+		var err error
+		if notifyType == "alerting" {
+			if log.NotifyState == NotifyStateMuted {
+				continue
+			}
+			err = s.mysql.UpdateTriggeredLogNotifyState(log.ID, NotifyStateMuted)
+		} else {
+			err = s.mysql.UpdateTriggeredLogResolvedNotifyState(log.ID, NotifyStateMuted)
+		}
+		if err != nil {
+			s.logger.Error("更新 TriggeredLog 通知狀態失敗",
+				zap.Error(err),
+				zap.String("triggered_log_id", formatID(log.ID)),
+				zap.String("notify_type", notifyType))
+		}
+	}
+
+	return filtered
+}
+
+// processNotifications 處理指定類型的通知，回傳仍有待通知告警的分組 ID
+// 異常通知依各聯絡人分組的 group_wait / group_interval 分別發送，只發送尚未通知該聯絡人的告警；
+// 告警路由到的所有聯絡人都已通知後才更新告警的通知狀態，未到達通知時間的聯絡人於下一輪繼續處理
+func (s *Service) processNotifications(logs []models.TriggeredLog, notifyType string, now time.Time) [][]byte {
 	if len(logs) == 0 {
 		return nil
 	}
 
-	// 1. 按 ContactID 分組
+	// 1. 按 ContactID 分組，再依分組欄位拆分
+	groups := s.splitAlertGroups(s.GroupByContact(logs))
 
-	groupedLogs := s.GroupByContact(logs)
+	if notifyType != "alerting" {
+		for _, group := range groups {
+			s.sendGroupedNotifications(map[string][]models.TriggeredLog{group.contactID: group.logs}, notifyType, true)
+		}
+		return nil
+	}
+
+	deliveries, err := s.loadNotifyDeliveries(logs)
+	if err != nil {
+		// 無法判斷已通知的聯絡人，保留待處理狀態於下一輪重新處理，避免重複通知
+		s.logger.Error("獲取告警通知紀錄失敗，延後通知", zap.Error(err))
+		return nil
+	}
 
 	// 2. 處理每組通知
+	var activeGroups [][]byte
+	for _, group := range groups {
+		notifyGroup, err := s.notifyGroup(group, group.logs[0].RealmName, now)
+		if err != nil {
+			s.logger.Error("獲取告警分組失敗", zap.Error(err), zap.String("group_key", group.key))
+			continue
+		}
+		activeGroups = append(activeGroups, notifyGroup.ID)
+
+		pending := deliveries.undelivered(group)
+		if len(pending) == 0 {
+			continue
+		}
+		if !s.groupReady(group, notifyGroup, now) {
+			s.logger.Debug("分組尚未到達通知時間",
+				zap.String("contact_id", formatID([]byte(group.contactID))),
+				zap.Any("labels", group.labels),
+				zap.Int("count", len(pending)))
+			continue
+		}
+		states := s.sendGroupedNotifications(map[string][]models.TriggeredLog{group.contactID: pending}, notifyType, false)
+		s.markGroupNotified(notifyGroup, now)
+		if state, ok := states[group.contactID]; ok {
+			deliveries.set(pending, group.contactID, state)
+		}
+	}
+
+	// 3. 所有聯絡人都已通知的告警更新通知狀態
+	completed := completedNotifyStates(groups, deliveries)
+	byState := make(map[string][]models.TriggeredLog)
+	for _, log := range logs {
+		if state, ok := completed[string(log.ID)]; ok {
+			byState[state] = append(byState[state], log)
+		}
+	}
+	for state, stateLogs := range byState {
+		s.updateTriggeredNotifyState(stateLogs, notifyType, state)
+	}
+	return activeGroups
+}
 
+// sendGroupedNotifications 發送已分組的通知，updateState 為 false 時不更新 TriggeredLog 的通知狀態
+// 回傳各聯絡人 (key 為聯絡人 ID) 通知結果對應的告警通知狀態；異常通知同時記錄告警對聯絡人的通知紀錄
+func (s *Service) sendGroupedNotifications(groupedLogs map[string][]models.TriggeredLog, notifyType string, updateState bool) map[string]string {
+	states := make(map[string]string)
 	for contactID, logs := range groupedLogs {
 		// 獲取聯絡人信息
 		contact, err := s.mysql.GetContact([]byte(contactID))
@@ -148,39 +327,50 @@ func (s *Service) processNotifications(logs []models.TriggeredLog, notifyType st
 
 		// 創建通知日誌
 		notifyLog := s.createNotifyLog(contact, logs)
+		notifyLog.NotifyType = notifyType
 
-		// 3. 渲染模板
-		title, message, err := s.renderTemplate(contact, logs, notifyType)
+		// 3. 流量限制
+		if !s.checkRateLimit(&notifyLog, contact, len(logs), time.Now()) {
+			if err := s.mysql.CreateNotifyLog(notifyLog); err != nil {
+				s.logger.Error("記錄通知日誌失敗", zap.Error(err))
+				continue
+			}
+			states[contactID] = s.recordNotifyDeliveries(contact, logs, notifyLog)
+			if updateState {
+				s.updateTriggeredNotifyState(logs, notifyType, NotifyStateSuppressed)
+			}
+			continue
+		}
+
+		// 4. 渲染模板 (告警風暴時改為摘要)
+		title, message, body, err := s.renderNotification(contact, logs, notifyType)
 		if err != nil {
 			s.logger.Error("渲染模板失敗", zap.Error(err), zap.String("contact_id", formatID([]byte(contactID))))
-			notifyLog.State = NotifyStateFailed
-
-			// 創建錯誤訊息
-			errorMsg := fmt.Sprintf("渲染模板失敗: %s", err.Error())
-			errorMessages := make(common.JSONMap)
-			errorMessages["error"] = errorMsg
-			notifyLog.ErrorMessages = &errorMessages
+			s.markNotifyFailed(&notifyLog, contact, notifier.ErrorPermanent, fmt.Errorf("渲染模板失敗: %w", err), time.Now())
 
 			if err := s.mysql.CreateNotifyLog(notifyLog); err != nil {
 				s.logger.Error("記錄通知失敗日誌失敗", zap.Error(err))
+				continue
 			}
+			states[contactID] = s.recordNotifyDeliveries(contact, logs, notifyLog)
 			continue
 		}
 
-		// 4. 發送通知
-		err = s.sendNotification(contact, title, message)
+		// 附註自上次發送後被限流的告警數
+		if suppressed := s.limiter.takeSuppressed(formatID(contact.ID)); suppressed > 0 {
+			message = appendSuppressedNote(GetFormatByType(contact.ChannelType), message, suppressed)
+			notifyLog.SuppressedCount = suppressed
+		}
+
+		// 5. 發送通知
+		err = s.sendNotification(contact, logs, notifyLog.ID, notifyType, title, message, body)
 		sentTime := time.Now().Unix()
 
 		if err != nil {
 			// 通知發送失敗
 			s.logger.Error("發送通知失敗", zap.Error(err), zap.String("contact_id", formatID([]byte(contactID))))
-			notifyLog.State = NotifyStateFailed
-
-			// 創建錯誤訊息
-			errorMsg := fmt.Sprintf("發送通知失敗: %s", err.Error())
-			errorMessages := make(common.JSONMap)
-			errorMessages["error"] = errorMsg
-			notifyLog.ErrorMessages = &errorMessages
+			class := s.notifyService.Classify(contact.ChannelType, err)
+			s.markNotifyFailed(&notifyLog, contact, class, fmt.Errorf("發送通知失敗: %w", err), time.Now())
 		} else {
 			// 通知發送成功
 			s.logger.Info("發送通知成功", zap.String("contact_id", formatID([]byte(contactID))))
@@ -192,30 +382,66 @@ func (s *Service) processNotifications(logs []models.TriggeredLog, notifyType st
 			notifyLog.SentAt = &sentTime
 		}
 
-		// 5. 記錄 NotifyLog
+		// 6. 記錄 NotifyLog
 		if err := s.mysql.CreateNotifyLog(notifyLog); err != nil {
 			s.logger.Error("記錄通知日誌失敗", zap.Error(err))
 			continue
 		}
+		states[contactID] = s.recordNotifyDeliveries(contact, logs, notifyLog)
 
-		// 6. 更新 TriggeredLog 的通知狀態
-		for _, log := range logs {
-			var err error
-			if notifyType == "alerting" {
-				err = s.mysql.UpdateTriggeredLogNotifyState(log.ID, notifyLog.State)
-			} else {
-				err = s.mysql.UpdateTriggeredLogResolvedNotifyState(log.ID, notifyLog.State)
-			}
-			if err != nil {
-				s.logger.Error("更新 TriggeredLog 通知狀態失敗",
-					zap.Error(err),
-					zap.String("triggered_log_id", formatID(log.ID)),
-					zap.String("notify_type", notifyType))
-			}
+		// 7. 更新 TriggeredLog 的通知狀態
+		if !updateState {
+			continue
 		}
+		s.updateTriggeredNotifyState(logs, notifyType, triggeredNotifyState(notifyLog.State))
 	}
+	return states
+}
 
-	return nil
+// recordNotifyDeliveries 記錄異常通知對聯絡人的發送結果，回傳對應的告警通知狀態
+func (s *Service) recordNotifyDeliveries(contact *models.Contact, logs []models.TriggeredLog, notifyLog models.NotifyLog) string {
+	state := triggeredNotifyState(notifyLog.State)
+	if notifyLog.NotifyType != "alerting" {
+		return state
+	}
+	// 郵件的第一封異常通知作為郵件串起點 (已有紀錄的告警不更新)
+	messageID := ""
+	if contact.ChannelType == "email" {
+		messageID = alertMessageID(notifyLog.ID)
+	}
+	deliveries := make([]models.NotifyDelivery, 0, len(logs))
+	for _, log := range logs {
+		deliveries = append(deliveries, models.NotifyDelivery{
+			RealmName:      log.RealmName,
+			TriggeredLogID: log.ID,
+			ContactID:      contact.ID,
+			NotifyLogID:    notifyLog.ID,
+			State:          state,
+			MessageID:      messageID,
+		})
+	}
+	if err := s.mysql.CreateNotifyDeliveries(deliveries); err != nil {
+		s.logger.Error("記錄告警通知紀錄失敗", zap.Error(err), zap.String("contact_id", formatID(contact.ID)))
+	}
+	return state
+}
+
+// updateTriggeredNotifyState 更新告警 (或恢復) 的通知狀態
+func (s *Service) updateTriggeredNotifyState(logs []models.TriggeredLog, notifyType, state string) {
+	for _, log := range logs {
+		var err error
+		if notifyType == "alerting" {
+			err = s.mysql.UpdateTriggeredLogNotifyState(log.ID, state)
+		} else {
+			err = s.mysql.UpdateTriggeredLogResolvedNotifyState(log.ID, state)
+		}
+		if err != nil {
+			s.logger.Error("更新 TriggeredLog 通知狀態失敗",
+				zap.Error(err),
+				zap.String("triggered_log_id", formatID(log.ID)),
+				zap.String("notify_type", notifyType))
+		}
+	}
 }
 
 // 檢查聯絡人是否應該接收該嚴重度的告警
@@ -248,8 +474,9 @@ func (s *Service) createNotifyLog(contact *models.Contact, logs []models.Trigger
 	// 創建錯誤訊息存儲
 	errorMessages := make(common.JSONMap)
 
-	// 生成通知日誌
+	// 生成通知日誌 (預先產生 ID，通知訊息的操作權杖需引用)
 	return models.NotifyLog{
+		ID:              mysql.GenerateUUID16(),
 		RealmName:       contact.RealmName,
 		State:           NotifyStatePending,
 		RetryCounter:    0,
@@ -261,6 +488,16 @@ func (s *Service) createNotifyLog(contact *models.Contact, logs []models.Trigger
 	}
 }
 
+// joinSortedKeys 將 map 的 key 排序後以逗號串接
+func joinSortedKeys(m map[string]bool) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
+
 // formatSeverity 將 severity 值轉換為標準格式
 func formatSeverity(severity string) string {
 	// 先去除前後空格
@@ -279,7 +516,8 @@ func formatSeverity(severity string) string {
 }
 
 // 渲染通知模板
-func (s *Service) renderTemplate(contact *models.Contact, logs []models.TriggeredLog, notifyType string) (string, string, error) {
+// 回傳標題、訊息，以及 webhook 聯絡人設定 body_template 時渲染的請求內容
+func (s *Service) renderTemplate(contact *models.Contact, logs []models.TriggeredLog, notifyType string) (string, string, string, error) {
 
 	// 獲取 FormatType
 	formatType := GetFormatByType(contact.ChannelType)
@@ -302,7 +540,7 @@ func (s *Service) renderTemplate(contact *models.Contact, logs []models.Triggere
 			zap.String("notify_type", notifyType),
 			zap.String("format_type", formatType),
 			zap.Int("templates_count", len(s.global.Templates)))
-		return "", "", fmt.Errorf("找不到匹配的通知模板 [notify_type=%s, format_type=%s]", notifyType, formatType)
+		return "", "", "", fmt.Errorf("找不到匹配的通知模板 [notify_type=%s, format_type=%s]", notifyType, formatType)
 	}
 
 	// 準備模板數據
@@ -320,9 +558,18 @@ func (s *Service) renderTemplate(contact *models.Contact, logs []models.Triggere
 	categoryMap := make(map[string]bool)
 	severityMap := make(map[string]bool)
 
+	ackedByMap := make(map[string]bool)
+	assignedToMap := make(map[string]bool)
+
 	for _, log := range logs {
 		hostMap[log.ResourceName] = true
 		categoryMap[log.MetricRuleUID] = true
+		if log.AckedBy != nil && *log.AckedBy != "" {
+			ackedByMap[*log.AckedBy] = true
+		}
+		if log.AssignedTo != nil && *log.AssignedTo != "" {
+			assignedToMap[*log.AssignedTo] = true
+		}
 		if log.Severity != "" {
 			// 去除前後空格後再添加到 severityMap
 			severityMap[strings.TrimSpace(log.Severity)] = true
@@ -390,6 +637,10 @@ func (s *Service) renderTemplate(contact *models.Contact, logs []models.Triggere
 
 	data["alert_categories"] = strings.Join(categories, ", ")
 
+	// 確認人與處理人 (多筆告警時以逗號分隔)
+	data["acked_by"] = joinSortedKeys(ackedByMap)
+	data["assigned_to"] = joinSortedKeys(assignedToMap)
+
 	s.logger.Debug("收集告警類別",
 		zap.Int("category_count", len(categoryMap)),
 		zap.String("alert_categories", strings.Join(categories, ", ")),
@@ -433,6 +684,17 @@ func (s *Service) renderTemplate(contact *models.Contact, logs []models.Triggere
 				"triggered_value":     log.TriggeredValue,
 				"threshold":           log.Threshold,
 				"last_triggered_at":   time.Unix(log.LastTriggeredAt, 0).Format(time.RFC3339),
+				"acked_by":            "",
+				"assigned_to":         "",
+			}
+			if log.AckedBy != nil {
+				metricInfo["acked_by"] = *log.AckedBy
+			}
+			if log.AssignedTo != nil {
+				metricInfo["assigned_to"] = *log.AssignedTo
+			}
+			for k, v := range s.chartTemplateData(contact, log) {
+				metricInfo[k] = v
 			}
 
 			// 持續時間計算
@@ -482,6 +744,9 @@ func (s *Service) renderTemplate(contact *models.Contact, logs []models.Triggere
 				"triggered_value":     log.TriggeredValue,
 				"threshold":           log.Threshold,
 			}
+			for k, v := range s.chartTemplateData(contact, log) {
+				alertData[k] = v
+			}
 			alertsByHost = append(alertsByHost, alertData)
 		}
 		data["alerts_by_host"] = alertsByHost
@@ -527,6 +792,13 @@ func (s *Service) renderTemplate(contact *models.Contact, logs []models.Triggere
 				"resolved_value":      log.ResolvedValue,
 				"threshold":           log.Threshold,
 				"resolved_at":         time.Unix(*log.ResolvedAt, 0).Format(time.RFC3339),
+				"acked_by":            "",
+			}
+			if log.AckedBy != nil {
+				metricInfo["acked_by"] = *log.AckedBy
+			}
+			for k, v := range s.chartTemplateData(contact, log) {
+				metricInfo[k] = v
 			}
 
 			// 計算持續時間（如果有）
@@ -578,6 +850,9 @@ func (s *Service) renderTemplate(contact *models.Contact, logs []models.Triggere
 					"resolved_value":      log.ResolvedValue,
 					"resolved_at":         time.Unix(*log.ResolvedAt, 0).Format(time.RFC3339),
 				}
+				for k, v := range s.chartTemplateData(contact, log) {
+					alertData[k] = v
+				}
 				resolvedAlertsByHost = append(resolvedAlertsByHost, alertData)
 			}
 		}
@@ -587,7 +862,7 @@ func (s *Service) renderTemplate(contact *models.Contact, logs []models.Triggere
 	// 透過 templates 模組渲染消息內容
 	message, err := s.templateService.RenderMessage(tmpl, data)
 	if err != nil {
-		return "", "", fmt.Errorf("渲染模板失敗: %w", err)
+		return "", "", "", fmt.Errorf("渲染模板失敗: %w", err)
 	}
 
 	// 處理消息格式
@@ -689,18 +964,32 @@ func (s *Service) renderTemplate(contact *models.Contact, logs []models.Triggere
 	// 渲染標題
 	titleTmpl, err := template.New("title").Parse(tmpl.Title)
 	if err != nil {
-		return "", "", fmt.Errorf("解析標題模板失敗: %w", err)
+		return "", "", "", fmt.Errorf("解析標題模板失敗: %w", err)
 	}
 
 	var titleBuf bytes.Buffer
 	if err := titleTmpl.Execute(&titleBuf, data); err != nil {
-		return "", "", fmt.Errorf("渲染標題失敗: %w", err)
+		return "", "", "", fmt.Errorf("渲染標題失敗: %w", err)
 	}
 
 	title := titleBuf.String()
 	s.logger.Debug("渲染標題結果", zap.String("title", title))
 
-	return title, message, nil
+	// 渲染 webhook 自訂內容，可使用完整的告警資料以及渲染後的 title / message
+	body := ""
+	if bodyTemplate := contact.Config["body_template"]; contact.ChannelType == "webhook" && bodyTemplate != "" {
+		data["title"] = title
+		data["message"] = message
+		body, err = s.templateService.RenderMessage(models.Template{
+			FormatType: webhookBodyFormat(contact.Config["content_type"]),
+			Message:    bodyTemplate,
+		}, data)
+		if err != nil {
+			return "", "", "", fmt.Errorf("渲染 webhook 內容失敗: %w", err)
+		}
+	}
+
+	return title, message, body, nil
 }
 
 func GetFormatByType(contactType string) string {
@@ -718,18 +1007,46 @@ func GetFormatByType(contactType string) string {
 	}
 }
 
+// webhookBodyFormat JSON 內容以 json 格式渲染 (檢查渲染結果)，其他內容原樣輸出
+func webhookBodyFormat(contentType string) string {
+	if contentType == "" || strings.HasPrefix(contentType, "application/json") {
+		return "json"
+	}
+	return "raw"
+}
+
 // 發送通知
-func (s *Service) sendNotification(contact *models.Contact, title, message string) error {
+func (s *Service) sendNotification(contact *models.Contact, logs []models.TriggeredLog, notifyLogID []byte, notifyType, title, message, body string) error {
 	newConfig := contact.Config
 	newConfig["title"] = title
 	newConfig["message"] = message
+	if body != "" {
+		newConfig["body"] = body
+	}
+
+	// 郵件通知：郵件串與附件
+	var attachments []common.NotifyAttachment
+	if contact.ChannelType == "email" {
+		for k, v := range emailThreadConfig(logs, notifyLogID, notifyType, s.emailThreads(contact, logs)) {
+			newConfig[k] = v
+		}
+		attachments = s.emailAttachments(contact, logs)
+	}
+
+	// 走勢圖：郵件內嵌、Discord 上傳檔案、Slack / LINE 使用簽章網址 (告警風暴摘要不附上)
+	switch contact.ChannelType {
+	case "email", "slack", "discord", "line":
+		if !s.isStorm(contact, logs) {
+			attachments = append(attachments, s.chartAttachments(contact, logs)...)
+		}
+	}
 
 	// 確保 LINE 通知有必要的配置
 	if contact.ChannelType == "line" {
 
 		// 確保 to 字段存在
 		if newConfig["to"] == "" {
-			return fmt.Errorf("LINE 通知缺少接收者 ID (to)")
+			return fmt.Errorf("LINE 通知缺少接收者 ID (to): %w", notifyerrors.ErrMissingRequiredConf)
 		}
 
 		// 使用 contact.Config 中的 channel_token
@@ -741,14 +1058,16 @@ func (s *Service) sendNotification(contact *models.Contact, title, message strin
 				zap.String("token_prefix", token[:min(10, len(token))]+"..."))
 		} else {
 			s.logger.Error("未設置 LINE token，LINE 通知將會失敗")
-			return fmt.Errorf("未設置 LINE token，無法發送 LINE 通知")
+			return fmt.Errorf("未設置 LINE token，無法發送 LINE 通知: %w", notifyerrors.ErrMissingRequiredConf)
 		}
 	}
 
 	// 發送通知
 	return s.notifyService.Send(common.NotifySetting{
-		Type:   contact.ChannelType,
-		Config: newConfig,
+		Type:        contact.ChannelType,
+		Config:      newConfig,
+		Attachments: attachments,
+		Actions:     s.notifyActionButtons(contact, notifyLogID, notifyType, time.Now()),
 	})
 }
 
@@ -768,13 +1087,16 @@ const (
 )
 
 // HandleNotifyPendingTime 處理通知等待時間
+// 通知時機改由告警分組 (group_wait / group_interval) 控制，此處使用預設分組的 group_wait
 func (s *Service) HandleNotifyPendingTime(triggeredLog *models.TriggeredLog) error {
 	// 檢查是否需要等待
 	currentTime := time.Now().Unix()
 	pendingTime := DefaultPendingTime
 
 	// 使用配置中的等待時間
-	if s.config.NotifyPeriod > 0 {
+	if wait, _, _, err := s.config.Grouping.Durations(); err == nil && wait > 0 {
+		pendingTime = int(wait.Seconds())
+	} else if s.config.NotifyPeriod > 0 {
 		pendingTime = s.config.NotifyPeriod
 	}
 
@@ -787,179 +1109,16 @@ func (s *Service) HandleNotifyPendingTime(triggeredLog *models.TriggeredLog) err
 	return s.mysql.UpdateTriggeredLogNotifyState(triggeredLog.ID, NotifyStatePending)
 }
 
-// retryFailedNotifications 重試失敗的通知
-func (s *Service) retryFailedNotifications() error {
-	s.logger.Info("開始處理失敗的通知")
-
-	// 獲取所有失敗的通知記錄
-	failedLogs, err := s.mysql.GetFailedNotifyLogs()
-	if err != nil {
-		return fmt.Errorf("獲取失敗的通知記錄失敗: %w", err)
-	}
-
-	if len(failedLogs) == 0 {
-		s.logger.Info("沒有需要重試的失敗通知")
-		return nil
-	}
-
-	// 獲取配置
-	maxRetry := DefaultMaxRetry
-	retryDelay := DefaultRetryDelay
-
-	// 使用聯絡人的重試次數
-	for _, notifyLog := range failedLogs {
-		// 檢查是否超過最大重試次數
-		if notifyLog.RetryCounter >= maxRetry {
-			s.logger.Warn("通知已超過最大重試次數，標記為最終失敗",
-				zap.String("notify_log_id", formatID(notifyLog.ID)),
-				zap.Int("retry_counter", notifyLog.RetryCounter))
-
-			notifyLog.State = NotifyStateFailed
-
-			// 創建錯誤訊息
-			errorMessages := make(common.JSONMap)
-			errorMessages["error"] = fmt.Sprintf("超過最大重試次數 %d", maxRetry)
-			errorMessages["time"] = fmt.Sprintf("%d", time.Now().Unix())
-			notifyLog.ErrorMessages = &errorMessages
-
-			if err := s.mysql.UpdateNotifyLog(notifyLog); err != nil {
-				s.logger.Error("更新通知日誌狀態失敗", zap.Error(err))
-			}
-			continue
-		}
-
-		// 檢查是否達到重試延遲時間
-		currentTime := time.Now().Unix()
-		if notifyLog.LastRetryAt != nil && currentTime-*notifyLog.LastRetryAt < int64(retryDelay) {
-			continue
-		}
-
-		// 重新發送通知
-		contact, err := s.mysql.GetContact(notifyLog.ContactID)
-		if err != nil {
-			s.logger.Error("獲取聯絡人信息失敗", zap.Error(err))
-			continue
-		}
-
-		// 如果是 LINE 通知，獲取 channel_token
-		if contact.ChannelType == "line" {
-			config, err := s.contactService.GetConfig(context.Background(), contact.ChannelType)
-			if err != nil {
-				s.logger.Error("獲取聯絡人配置失敗", zap.Error(err), zap.String("contact_id", formatID(notifyLog.ContactID)))
-				continue
-			}
-
-			// 印出完整的 LINE 配置
-			fmt.Println("重試時的 LINE 通知配置:")
-			for k, v := range config {
-				if k == "channel_token" {
-					fmt.Printf("  %s: %s\n", k, v[:min(10, len(v))]+"...")
-				} else if k == "message" {
-					fmt.Printf("  %s: %s...(省略)\n", k, v[:min(50, len(v))])
-				} else {
-					fmt.Printf("  %s: %s\n", k, v)
-				}
-			}
-
-			// 確保 token 格式正確
-			if token, ok := config["channel_token"]; ok && token != "" {
-				config["channel_token"] = strings.TrimSpace(token)
-			}
-
-			contact.Config["channel_token"] = config["channel_token"]
-			s.logger.Info("聯絡人類型為 LINE",
-				zap.String("contact_id", formatID(notifyLog.ContactID)),
-				zap.String("token_prefix", config["channel_token"][:min(10, len(config["channel_token"]))]+"..."))
-		}
-
-		// 重新渲染模板
-		var triggeredLogs []models.TriggeredLog
-		for _, logIDMap := range notifyLog.TriggeredLogIDs {
-			id, ok := logIDMap["id"].(string)
-			if !ok {
-				continue
-			}
-
-			// 嘗試從 base64 解碼 ID
-			binaryID, err := base64.StdEncoding.DecodeString(id)
-			if err != nil {
-				// 如果 base64 解碼失敗，嘗試從十六進制解碼（向後兼容）
-				binaryID, err = hex.DecodeString(id)
-				if err != nil {
-					s.logger.Error("解析 ID 失敗", zap.Error(err), zap.String("id", id))
-					continue
-				}
-			}
-
-			log, err := s.mysql.GetTriggeredLog(binaryID)
-			if err != nil {
-				s.logger.Error("獲取觸發日誌失敗", zap.Error(err))
-				continue
-			}
-			if log != nil {
-				triggeredLogs = append(triggeredLogs, *log)
-			}
-		}
-
-		// 判斷通知類型
-		notifyType := "alerting"
-		if notifyLog.State == NotifyStateSolved {
-			notifyType = "resolved"
-		}
-
-		title, message, err := s.renderTemplate(contact, triggeredLogs, notifyType)
-		if err != nil {
-			s.logger.Error("重試時渲染模板失敗", zap.Error(err))
-			continue
-		}
-
-		// 重新發送通知
-		err = s.sendNotification(contact, title, message)
-		retryTime := time.Now().Unix()
-
-		// 更新通知日誌
-		notifyLog.RetryCounter++
-		notifyLog.LastRetryAt = &retryTime
-
-		if err != nil {
-			s.logger.Error("重試發送通知失敗",
-				zap.Error(err),
-				zap.String("notify_log_id", formatID(notifyLog.ID)),
-				zap.Int("retry_counter", notifyLog.RetryCounter))
-
-			notifyLog.State = NotifyStateFailed
-
-			// 創建錯誤訊息
-			errorMessages := make(common.JSONMap)
-			errorMessages["error"] = fmt.Sprintf("重試發送通知失敗: %s", err.Error())
-			errorMessages["retry"] = fmt.Sprintf("%d", notifyLog.RetryCounter)
-			errorMessages["time"] = fmt.Sprintf("%d", time.Now().Unix())
-			notifyLog.ErrorMessages = &errorMessages
-		} else {
-			s.logger.Info("重試發送通知成功",
-				zap.String("notify_log_id", formatID(notifyLog.ID)),
-				zap.Int("retry_counter", notifyLog.RetryCounter))
-
-			notifyLog.State = NotifyStateSent
-			notifyLog.SentAt = &retryTime
-		}
-
-		if err := s.mysql.UpdateNotifyLog(notifyLog); err != nil {
-			s.logger.Error("更新通知日誌失敗", zap.Error(err))
-		}
-	}
-
-	return nil
-}
-
 // GroupByContact 將觸發日誌按聯絡人分組
 func (s *Service) GroupByContact(triggeredLogs []models.TriggeredLog) map[string][]models.TriggeredLog {
 	groupedLogs := make(map[string][]models.TriggeredLog)
+	ruleLabels := make(map[string]map[string]string)
+	routeContacts := make(map[string]*models.Contact)
 
 	for _, log := range triggeredLogs {
 		// 獲取規則關聯的聯絡人
 		s.logger.Debug("獲取規則關聯的聯絡人", zap.String("rule_id", formatID(log.RuleID)))
-		contacts, err := s.mysql.GetContactsByRuleID(log.RuleID)
+		contacts, err := s.getNotifyContacts(log, ruleLabels, routeContacts)
 		if err != nil {
 			s.logger.Error("獲取規則關聯的聯絡人失敗",
 				zap.Error(err),
@@ -985,6 +1144,78 @@ func (s *Service) GroupByContact(triggeredLogs []models.TriggeredLog) map[string
 	return groupedLogs
 }
 
+// getNotifyContacts 獲取告警的通知對象
+// 未設定升級策略時為規則聯絡人加上規則值班表目前的值班人員與符合通知路由的聯絡人
+// 規則設定升級策略時，使用已通知到的升級步驟 (0 ~ EscalationStep) 的聯絡人取代規則聯絡人
+// ruleLabels / routeContacts 為規則標籤與路由聯絡人快取
+func (s *Service) getNotifyContacts(log models.TriggeredLog, ruleLabels map[string]map[string]string, routeContacts map[string]*models.Contact) ([]models.Contact, error) {
+	policy, err := s.mysql.GetEscalationPolicyByRuleID(log.RuleID)
+	if err != nil {
+		return nil, fmt.Errorf("獲取升級策略失敗 [rule_id:%s]: %w", formatID(log.RuleID), err)
+	}
+	if policy == nil {
+		contacts, err := s.getRuleContacts(log.RuleID)
+		if err != nil {
+			return nil, err
+		}
+		matches := s.routeMatches(log, s.alertLabels(log, ruleLabels))
+		for _, routed := range s.routeContacts(matches, routeContacts) {
+			if !containsContact(contacts, routed.ID) {
+				contacts = append(contacts, routed)
+			}
+		}
+		return contacts, nil
+	}
+
+	var contacts []models.Contact
+	seen := make(map[string]bool)
+	for _, step := range policy.Steps {
+		if step.StepOrder > log.EscalationStep {
+			break
+		}
+		for _, contact := range step.Contacts {
+			if seen[string(contact.ID)] {
+				continue
+			}
+			seen[string(contact.ID)] = true
+			contacts = append(contacts, contact)
+		}
+	}
+	return contacts, nil
+}
+
+// getRuleContacts 獲取規則聯絡人與值班表目前的值班聯絡人
+func (s *Service) getRuleContacts(ruleID []byte) ([]models.Contact, error) {
+	contacts, err := s.mysql.GetContactsByRuleID(ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	onCallContacts, err := s.onCallService.ResolveRuleContacts(ruleID, time.Now())
+	if err != nil {
+		s.logger.Error("解析值班聯絡人失敗",
+			zap.Error(err),
+			zap.String("rule_id", formatID(ruleID)))
+		return contacts, nil
+	}
+	for _, onCall := range onCallContacts {
+		if !containsContact(contacts, onCall.ID) {
+			contacts = append(contacts, onCall)
+		}
+	}
+	return contacts, nil
+}
+
+// containsContact 判斷聯絡人是否已在清單中
+func containsContact(contacts []models.Contact, id []byte) bool {
+	for _, contact := range contacts {
+		if string(contact.ID) == string(id) {
+			return true
+		}
+	}
+	return false
+}
+
 // 添加一個輔助函數來格式化 ID
 func formatID(id []byte) string {
 	if len(id) == 0 {
@@ -0,0 +1,105 @@
+package alert
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/detect-viz/shared-lib/models"
+)
+
+// 預設比較運算子
+const DefaultOperator = "gt"
+
+// eq / ne 比較時的誤差容忍值
+const operatorEpsilon = 1e-9
+
+// 支援的比較運算子
+// inside / outside 為區間運算子，區間為 [下限閾值, 閾值]
+var supportedOperators = map[string]bool{
+	"gt":      true,
+	"ge":      true,
+	"lt":      true,
+	"le":      true,
+	"eq":      true,
+	"ne":      true,
+	"inside":  true,
+	"outside": true,
+}
+
+// 檢查運算子是否支援
+func validateOperator(operator string) error {
+	if operator == "" {
+		return nil
+	}
+	if !supportedOperators[operator] {
+		return fmt.Errorf("不支援的 operator [operator:%s]", operator)
+	}
+	return nil
+}
+
+// 是否為區間運算子
+func isRangeOperator(operator string) bool {
+	return operator == "inside" || operator == "outside"
+}
+
+// 依運算子比較數值與閾值，區間運算子缺少下限時視為未設定
+func compareThreshold(operator string, value float64, lower *float64, threshold float64) bool {
+	switch operator {
+	case "", "gt":
+		return value > threshold
+	case "ge":
+		return value >= threshold
+	case "lt":
+		return value < threshold
+	case "le":
+		return value <= threshold
+	case "eq":
+		return math.Abs(value-threshold) <= operatorEpsilon
+	case "ne":
+		return math.Abs(value-threshold) > operatorEpsilon
+	case "inside":
+		return lower != nil && value >= *lower && value <= threshold
+	case "outside":
+		return lower != nil && (value < *lower || value > threshold)
+	}
+	return false
+}
+
+// 依 Rule 的閾值判斷嚴重程度（從高到低），未設定的閾值 (nil) 不檢查
+func matchThreshold(rule *models.Rule, operator string, value float64) (bool, string) {
+	if compareThreshold(operator, value, rule.CritLowerThreshold, rule.CritThreshold) {
+		return true, "crit"
+	}
+	if rule.WarnThreshold != nil && compareThreshold(operator, value, rule.WarnLowerThreshold, *rule.WarnThreshold) {
+		return true, "warn"
+	}
+	if rule.InfoThreshold != nil && compareThreshold(operator, value, rule.InfoLowerThreshold, *rule.InfoThreshold) {
+		return true, "info"
+	}
+	return false, ""
+}
+
+// 依嚴重程度取得對應的閾值
+func thresholdBySeverity(rule *models.Rule, severity string) float64 {
+	switch severity {
+	case "warn":
+		if rule.WarnThreshold != nil {
+			return *rule.WarnThreshold
+		}
+	case "info":
+		if rule.InfoThreshold != nil {
+			return *rule.InfoThreshold
+		}
+	}
+	return rule.CritThreshold
+}
+
+// 檢查是否達到恢復條件 (hysteresis)
+// 未設定恢復閾值時，只要未觸發即視為恢復；
+// 設定後需脫離恢復閾值 (同一運算子) 才恢復，避免數值在閾值附近來回跳動
+func isRecovered(rule *models.Rule, operator string, value float64) bool {
+	if rule.RecoveryThreshold == nil {
+		return true
+	}
+	return !compareThreshold(operator, value, rule.RecoveryLowerThreshold, *rule.RecoveryThreshold)
+}
@@ -0,0 +1,174 @@
+package alert
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/models/common"
+	"go.uber.org/zap"
+)
+
+// 通知流量限制：
+// 每個聯絡人與每種通道各有一個 token bucket，發送前兩者都需取得 token，否則該則通知不發送
+// 聯絡人可以 rate_limit_per_minute / rate_limit_burst 覆寫 alert.rate_limit.contact
+// 被限流的通知仍記錄 NotifyLog (state = suppressed, suppressed_count = 告警數)，告警標記為 suppressed 不再重送，
+// 累計的數量會附註在該聯絡人下一則成功發送的通知中
+// 重試 (retry / 死信重送) 不受流量限制，以免已排程的通知被延後到耗盡重試次數
+
+// tokenBucket 令牌桶，rate 為每秒補充的 token 數
+type tokenBucket struct {
+	limit  models.RateLimit
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit models.RateLimit, now time.Time) *tokenBucket {
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		limit:  limit,
+		rate:   limit.PerMinute / 60,
+		burst:  burst,
+		tokens: burst,
+		last:   now,
+	}
+}
+
+// take 取得一個 token，不足時回傳 false
+func (b *tokenBucket) take(now time.Time) bool {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refund 歸還 token (另一個限制未通過時使用)
+func (b *tokenBucket) refund() {
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// notifyLimiter 聯絡人與通道的通知流量限制
+type notifyLimiter struct {
+	mu         sync.Mutex
+	buckets    map[string]*tokenBucket
+	suppressed map[string]int // 聯絡人自上次發送後被限流的告警數
+}
+
+func newNotifyLimiter() *notifyLimiter {
+	return &notifyLimiter{
+		buckets:    make(map[string]*tokenBucket),
+		suppressed: make(map[string]int),
+	}
+}
+
+// bucket 取得 token bucket，設定變更時重新建立
+func (l *notifyLimiter) bucket(key string, limit models.RateLimit, now time.Time) *tokenBucket {
+	b, ok := l.buckets[key]
+	if !ok || b.limit != limit {
+		b = newTokenBucket(limit, now)
+		l.buckets[key] = b
+	}
+	return b
+}
+
+// allow 檢查聯絡人與通道的流量限制，被限流時回傳限制來源 (contact / channel)
+func (l *notifyLimiter) allow(contactKey string, contactLimit models.RateLimit, channel string, channelLimit models.RateLimit, now time.Time) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var contactBucket *tokenBucket
+	if contactLimit.PerMinute > 0 {
+		contactBucket = l.bucket("contact:"+contactKey, contactLimit, now)
+		if !contactBucket.take(now) {
+			return "contact", false
+		}
+	}
+	if channelLimit.PerMinute > 0 {
+		if !l.bucket("channel:"+channel, channelLimit, now).take(now) {
+			if contactBucket != nil {
+				contactBucket.refund()
+			}
+			return "channel", false
+		}
+	}
+	return "", true
+}
+
+// addSuppressed 累計聯絡人被限流的告警數
+func (l *notifyLimiter) addSuppressed(contactKey string, count int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.suppressed[contactKey] += count
+}
+
+// takeSuppressed 取出並清除聯絡人累計被限流的告警數
+func (l *notifyLimiter) takeSuppressed(contactKey string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	count := l.suppressed[contactKey]
+	delete(l.suppressed, contactKey)
+	return count
+}
+
+// contactRateLimit 聯絡人的速率限制，聯絡人設定優先於全域設定
+func contactRateLimit(contact *models.Contact, defaults models.RateLimit) models.RateLimit {
+	limit := defaults
+	if v, err := strconv.ParseFloat(contact.Config["rate_limit_per_minute"], 64); err == nil && v >= 0 {
+		limit.PerMinute = v
+	}
+	if v, err := strconv.Atoi(contact.Config["rate_limit_burst"]); err == nil && v > 0 {
+		limit.Burst = v
+	}
+	return limit
+}
+
+// checkRateLimit 檢查是否可發送，被限流時將通知日誌標記為 suppressed
+func (s *Service) checkRateLimit(notifyLog *models.NotifyLog, contact *models.Contact, count int, now time.Time) bool {
+	contactKey := formatID(contact.ID)
+	source, ok := s.limiter.allow(
+		contactKey, contactRateLimit(contact, s.config.RateLimit.Contact),
+		contact.ChannelType, s.config.RateLimit.Channels[contact.ChannelType],
+		now)
+	if ok {
+		return true
+	}
+
+	s.limiter.addSuppressed(contactKey, count)
+
+	scope := "聯絡人"
+	if source == "channel" {
+		scope = "通道"
+	}
+	errorMessages := make(common.JSONMap)
+	errorMessages["error"] = fmt.Sprintf("超過%s通知速率限制，未發送", scope)
+	errorMessages["limit"] = source
+	errorMessages["time"] = fmt.Sprintf("%d", now.Unix())
+	notifyLog.ErrorMessages = &errorMessages
+	notifyLog.State = NotifyStateSuppressed
+	notifyLog.SuppressedCount = count
+
+	s.logger.Warn("通知已被流量限制",
+		zap.String("contact_id", contactKey),
+		zap.String("channel_type", contact.ChannelType),
+		zap.String("limit", source),
+		zap.Int("suppressed_count", count))
+	return false
+}
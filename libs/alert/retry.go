@@ -0,0 +1,300 @@
+package alert
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/models/common"
+	"github.com/detect-viz/shared-lib/notifier"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 通知重試：
+// 發送失敗依聯絡人的 MaxRetry / RetryDelay 排程重試 (NextRetryAt)，期間告警通知狀態標記為 delayed
+// 重試間隔為 RetryDelay × 2^(第幾次重試-1)，上限 maxRetryBackoff，並取一半作為隨機抖動避免同時重送
+// 通道回應 Retry-After (例如 429) 時至少等待該時間
+// 永久錯誤 (4xx、設定錯誤、模板錯誤) 或超過重試次數的通知移入死信，可透過 API 查詢並手動重送
+
+const maxRetryBackoff = 6 * time.Hour // 重試間隔上限
+
+// retryFailedNotifications 重試已到達重試時間的失敗通知
+func (s *Service) retryFailedNotifications() error {
+	now := time.Now()
+	failedLogs, err := s.mysql.GetFailedNotifyLogs(now.Unix())
+	if err != nil {
+		return fmt.Errorf("獲取失敗的通知記錄失敗: %w", err)
+	}
+
+	if len(failedLogs) == 0 {
+		s.logger.Debug("沒有需要重試的失敗通知")
+		return nil
+	}
+	s.logger.Info("開始重試失敗的通知", zap.Int("count", len(failedLogs)))
+
+	for i := range failedLogs {
+		notifyLog := &failedLogs[i]
+		if err := s.resendNotifyLog(notifyLog); err != nil {
+			s.logger.Error("重試通知失敗",
+				zap.Error(err),
+				zap.String("notify_log_id", formatID(notifyLog.ID)))
+			continue
+		}
+		if err := s.mysql.UpdateNotifyLog(*notifyLog); err != nil {
+			s.logger.Error("更新通知日誌失敗", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// resendNotifyLog 重新發送通知並更新通知日誌的狀態 (不寫入資料庫)
+// 回傳錯誤表示本次未處理，保留原狀態等待下次排程
+func (s *Service) resendNotifyLog(notifyLog *models.NotifyLog) error {
+	contact, err := s.mysql.GetContact(notifyLog.ContactID)
+	if err != nil {
+		if errors.Is(err, apierrors.ErrNotFound) || errors.Is(err, apierrors.ErrRecordDeleted) {
+			s.markNotifyFailed(notifyLog, nil, notifier.ErrorPermanent, fmt.Errorf("聯絡人不存在: %w", err), time.Now())
+			return nil
+		}
+		return fmt.Errorf("獲取聯絡人信息失敗: %w", err)
+	}
+	if !contact.Enabled {
+		s.markNotifyFailed(notifyLog, contact, notifier.ErrorPermanent, fmt.Errorf("聯絡人已停用"), time.Now())
+		return nil
+	}
+
+	// 如果是 LINE 通知，獲取 channel_token
+	if contact.ChannelType == "line" {
+		config, err := s.contactService.GetConfig(context.Background(), contact.ChannelType)
+		if err != nil {
+			return fmt.Errorf("獲取聯絡人配置失敗: %w", err)
+		}
+		contact.Config["channel_token"] = strings.TrimSpace(config["channel_token"])
+	}
+
+	notifyType := notifyLog.NotifyType
+	if notifyType == "" {
+		notifyType = "alerting"
+	}
+	triggeredLogs := s.getNotifyLogTriggeredLogs(notifyLog)
+
+	// 已確認且尚未恢復的告警不再重試通知
+	if notifyType == "alerting" {
+		triggeredLogs = s.filterAckedLogs(triggeredLogs)
+		if len(triggeredLogs) == 0 {
+			notifyLog.State = NotifyStateProcessed
+			notifyLog.NextRetryAt = nil
+			return nil
+		}
+	}
+
+	title, message, body, err := s.renderNotification(contact, triggeredLogs, notifyType)
+	if err != nil {
+		s.markNotifyFailed(notifyLog, contact, notifier.ErrorPermanent, fmt.Errorf("渲染模板失敗: %w", err), time.Now())
+		s.updateDelayedNotifyState(triggeredLogs, notifyType, notifyLog.State)
+		return nil
+	}
+
+	err = s.sendNotification(contact, triggeredLogs, notifyLog.ID, notifyType, title, message, body)
+	now := time.Now()
+	retryTime := now.Unix()
+	notifyLog.RetryCounter++
+	notifyLog.LastRetryAt = &retryTime
+
+	if err != nil {
+		class := s.notifyService.Classify(contact.ChannelType, err)
+		s.markNotifyFailed(notifyLog, contact, class, fmt.Errorf("重試發送通知失敗: %w", err), now)
+		s.updateDelayedNotifyState(triggeredLogs, notifyType, notifyLog.State)
+		return nil
+	}
+
+	s.logger.Info("重試發送通知成功",
+		zap.String("notify_log_id", formatID(notifyLog.ID)),
+		zap.Int("retry_counter", notifyLog.RetryCounter))
+
+	if notifyType == "alerting" {
+		notifyLog.State = NotifyStateSent
+	} else {
+		notifyLog.State = NotifyStateSolved
+	}
+	notifyLog.SentAt = &retryTime
+	notifyLog.NextRetryAt = nil
+	notifyLog.ErrorClass = ""
+	s.updateTriggeredNotifyState(triggeredLogs, notifyType, notifyLog.State)
+	return nil
+}
+
+// markNotifyFailed 記錄發送失敗，依錯誤分類與重試次數排程下次重試或移入死信
+func (s *Service) markNotifyFailed(notifyLog *models.NotifyLog, contact *models.Contact, class notifier.ErrorClass, sendErr error, now time.Time) {
+	errorMessages := make(common.JSONMap)
+	errorMessages["error"] = sendErr.Error()
+	errorMessages["class"] = string(class)
+	errorMessages["retry"] = fmt.Sprintf("%d", notifyLog.RetryCounter)
+	errorMessages["time"] = fmt.Sprintf("%d", now.Unix())
+	notifyLog.ErrorMessages = &errorMessages
+	notifyLog.ErrorClass = string(class)
+
+	maxRetry, baseDelay := 0, time.Duration(0)
+	if contact != nil {
+		maxRetry, baseDelay = retryPolicy(contact)
+	}
+
+	if class == notifier.ErrorPermanent || notifyLog.RetryCounter >= maxRetry {
+		notifyLog.State = NotifyStateDeadLetter
+		notifyLog.NextRetryAt = nil
+		s.logger.Warn("通知移入死信",
+			zap.String("notify_log_id", formatID(notifyLog.ID)),
+			zap.String("error_class", string(class)),
+			zap.Int("retry_counter", notifyLog.RetryCounter),
+			zap.Int("max_retry", maxRetry))
+		return
+	}
+
+	nextRetryAt := now.Add(retryBackoff(baseDelay, notifyLog.RetryCounter+1, notifier.RetryAfter(sendErr))).Unix()
+	notifyLog.State = NotifyStateFailed
+	notifyLog.NextRetryAt = &nextRetryAt
+}
+
+// retryPolicy 取得聯絡人的最大重試次數與基礎重試延遲
+func retryPolicy(contact *models.Contact) (int, time.Duration) {
+	maxRetry := contact.MaxRetry
+	if maxRetry < 0 {
+		maxRetry = DefaultMaxRetry
+	}
+	delay, err := time.ParseDuration(contact.RetryDelay)
+	if err != nil || delay <= 0 {
+		delay = DefaultRetryDelay * time.Second
+	}
+	return maxRetry, delay
+}
+
+// retryBackoff 計算第 attempt 次重試 (從 1 開始) 前的等待時間
+func retryBackoff(base time.Duration, attempt int, retryAfter time.Duration) time.Duration {
+	backoff := base
+	for i := 1; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+
+	// 一半固定、一半隨機抖動
+	half := backoff / 2
+	backoff = half + time.Duration(rand.Int63n(int64(half)+1))
+
+	if retryAfter > backoff {
+		backoff = retryAfter
+	}
+	return backoff
+}
+
+// triggeredNotifyState 通知日誌狀態對應的告警通知狀態，排程重試中的告警標記為 delayed
+func triggeredNotifyState(notifyState string) string {
+	if notifyState == NotifyStateFailed {
+		return NotifyStateDelayed
+	}
+	return notifyState
+}
+
+// updateDelayedNotifyState 重試失敗時只更新仍在等待重試的告警，避免覆蓋升級通知等已發送的狀態
+func (s *Service) updateDelayedNotifyState(logs []models.TriggeredLog, notifyType, notifyState string) {
+	delayed := make([]models.TriggeredLog, 0, len(logs))
+	for _, log := range logs {
+		state := log.NotifyState
+		if notifyType != "alerting" {
+			if log.ResolvedNotifyState == nil {
+				continue
+			}
+			state = *log.ResolvedNotifyState
+		}
+		if state == NotifyStateDelayed {
+			delayed = append(delayed, log)
+		}
+	}
+	s.updateTriggeredNotifyState(delayed, notifyType, triggeredNotifyState(notifyState))
+}
+
+// getNotifyLogTriggeredLogs 依通知日誌記錄的 ID 取回告警
+func (s *Service) getNotifyLogTriggeredLogs(notifyLog *models.NotifyLog) []models.TriggeredLog {
+	var triggeredLogs []models.TriggeredLog
+	for _, logIDMap := range notifyLog.TriggeredLogIDs {
+		id, ok := logIDMap["id"].(string)
+		if !ok {
+			continue
+		}
+
+		// 嘗試從 base64 解碼 ID
+		binaryID, err := base64.StdEncoding.DecodeString(id)
+		if err != nil {
+			// 如果 base64 解碼失敗，嘗試從十六進制解碼（向後兼容）
+			binaryID, err = hex.DecodeString(id)
+			if err != nil {
+				s.logger.Error("解析 ID 失敗", zap.Error(err), zap.String("id", id))
+				continue
+			}
+		}
+
+		log, err := s.mysql.GetTriggeredLog(binaryID)
+		if err != nil {
+			s.logger.Error("獲取觸發日誌失敗", zap.Error(err))
+			continue
+		}
+		if log != nil {
+			triggeredLogs = append(triggeredLogs, *log)
+		}
+	}
+	return triggeredLogs
+}
+
+// ListDeadLetters 獲取死信通知列表
+func (s *Service) ListDeadLetters(realm string, cursor int64, limit int) ([]models.NotifyLog, int64, error) {
+	return s.mysql.ListNotifyLogsByState(realm, NotifyStateDeadLetter, cursor, limit)
+}
+
+// GetDeadLetter 獲取死信通知
+func (s *Service) GetDeadLetter(realm, id string) (*models.NotifyLog, error) {
+	notifyID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, apierrors.ErrInvalidID
+	}
+	notifyLog, err := s.mysql.GetNotifyLog(notifyID[:])
+	if err != nil {
+		return nil, err
+	}
+	if notifyLog.RealmName != realm || notifyLog.State != NotifyStateDeadLetter {
+		return nil, apierrors.ErrNotFound
+	}
+	return notifyLog, nil
+}
+
+// ReplayDeadLetter 手動重送死信通知，重試次數歸零後立即發送
+// 再次失敗時依聯絡人的重試設定排程重試或移回死信
+func (s *Service) ReplayDeadLetter(realm, id, user string) (*models.NotifyLog, error) {
+	notifyLog, err := s.GetDeadLetter(realm, id)
+	if err != nil {
+		return nil, err
+	}
+
+	notifyLog.RetryCounter = 0
+	notifyLog.NextRetryAt = nil
+	if err := s.resendNotifyLog(notifyLog); err != nil {
+		return nil, err
+	}
+	if err := s.mysql.UpdateNotifyLog(*notifyLog); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("死信通知已重送",
+		zap.String("notify_log_id", formatID(notifyLog.ID)),
+		zap.String("state", notifyLog.State),
+		zap.String("user", user))
+	return notifyLog, nil
+}
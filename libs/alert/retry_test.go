@@ -0,0 +1,114 @@
+package alert
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/notifier"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	cases := []struct {
+		name      string
+		contact   models.Contact
+		wantRetry int
+		wantDelay time.Duration
+	}{
+		{name: "MaxRetry 為 0 不重試", contact: models.Contact{MaxRetry: 0, RetryDelay: "1m"}, wantRetry: 0, wantDelay: time.Minute},
+		{name: "MaxRetry 小於 0 使用預設", contact: models.Contact{MaxRetry: -1, RetryDelay: "10m"}, wantRetry: DefaultMaxRetry, wantDelay: 10 * time.Minute},
+		{name: "延遲格式錯誤使用預設", contact: models.Contact{MaxRetry: 5, RetryDelay: "bad"}, wantRetry: 5, wantDelay: DefaultRetryDelay * time.Second},
+		{name: "延遲非正數使用預設", contact: models.Contact{MaxRetry: 2, RetryDelay: "-1m"}, wantRetry: 2, wantDelay: DefaultRetryDelay * time.Second},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			maxRetry, delay := retryPolicy(&tc.contact)
+			if maxRetry != tc.wantRetry || delay != tc.wantDelay {
+				t.Fatalf("got (%d, %v), want (%d, %v)", maxRetry, delay, tc.wantRetry, tc.wantDelay)
+			}
+		})
+	}
+}
+
+// 抖動為隨機值，重複計算並檢查落在 [上限/2, 上限] 之間
+func TestRetryBackoff(t *testing.T) {
+	cases := []struct {
+		name       string
+		base       time.Duration
+		attempt    int
+		retryAfter time.Duration
+		min, max   time.Duration
+	}{
+		{name: "第一次重試", base: 5 * time.Minute, attempt: 1, min: 150 * time.Second, max: 5 * time.Minute},
+		{name: "每次重試加倍", base: 5 * time.Minute, attempt: 3, min: 10 * time.Minute, max: 20 * time.Minute},
+		{name: "加倍後不超過上限", base: 5 * time.Minute, attempt: 20, min: maxRetryBackoff / 2, max: maxRetryBackoff},
+		{name: "基礎延遲超過上限", base: 8 * time.Hour, attempt: 1, min: maxRetryBackoff / 2, max: maxRetryBackoff},
+		{name: "Retry-After 較長時至少等待", base: time.Minute, attempt: 1, retryAfter: time.Hour, min: time.Hour, max: time.Hour},
+		{name: "Retry-After 較短時使用退避時間", base: 10 * time.Minute, attempt: 1, retryAfter: time.Second, min: 5 * time.Minute, max: 10 * time.Minute},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				got := retryBackoff(tc.base, tc.attempt, tc.retryAfter)
+				if got < tc.min || got > tc.max {
+					t.Fatalf("got %v, want between %v and %v", got, tc.min, tc.max)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkNotifyFailed(t *testing.T) {
+	s := &Service{logger: nopLogger{}}
+	now := time.Unix(1700000000, 0)
+	sendErr := errors.New("connection refused")
+	retryAfterErr := &notifier.SendError{Channel: "slack", StatusCode: 429, RetryAfter: 2 * time.Hour}
+
+	cases := []struct {
+		name         string
+		contact      *models.Contact
+		class        notifier.ErrorClass
+		err          error
+		retryCounter int
+		wantState    string
+		min, max     time.Duration // NextRetryAt 與 now 的間隔範圍
+	}{
+		{name: "永久錯誤移入死信", contact: &models.Contact{MaxRetry: 5, RetryDelay: "1m"}, class: notifier.ErrorPermanent, err: sendErr, wantState: NotifyStateDeadLetter},
+		{name: "聯絡人不存在移入死信", contact: nil, class: notifier.ErrorRetryable, err: sendErr, wantState: NotifyStateDeadLetter},
+		{name: "MaxRetry 為 0 不重試", contact: &models.Contact{MaxRetry: 0, RetryDelay: "1m"}, class: notifier.ErrorRetryable, err: sendErr, wantState: NotifyStateDeadLetter},
+		{name: "MaxRetry 小於 0 使用預設次數重試", contact: &models.Contact{MaxRetry: -1, RetryDelay: "1m"}, class: notifier.ErrorRetryable, err: sendErr, retryCounter: DefaultMaxRetry - 1, wantState: NotifyStateFailed, min: 2 * time.Minute, max: 4 * time.Minute},
+		{name: "MaxRetry 小於 0 超過預設次數", contact: &models.Contact{MaxRetry: -1, RetryDelay: "1m"}, class: notifier.ErrorRetryable, err: sendErr, retryCounter: DefaultMaxRetry, wantState: NotifyStateDeadLetter},
+		{name: "第二次重試加倍", contact: &models.Contact{MaxRetry: 3, RetryDelay: "1m"}, class: notifier.ErrorRetryable, err: sendErr, retryCounter: 1, wantState: NotifyStateFailed, min: time.Minute, max: 2 * time.Minute},
+		{name: "Retry-After 作為最短等待", contact: &models.Contact{MaxRetry: 3, RetryDelay: "1m"}, class: notifier.ErrorRetryable, err: retryAfterErr, wantState: NotifyStateFailed, min: 2 * time.Hour, max: 2 * time.Hour},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			notifyLog := &models.NotifyLog{RetryCounter: tc.retryCounter}
+			s.markNotifyFailed(notifyLog, tc.contact, tc.class, tc.err, now)
+
+			if notifyLog.State != tc.wantState {
+				t.Fatalf("state = %q, want %q", notifyLog.State, tc.wantState)
+			}
+			if notifyLog.ErrorClass != string(tc.class) {
+				t.Errorf("error class = %q, want %q", notifyLog.ErrorClass, tc.class)
+			}
+			if notifyLog.ErrorMessages == nil || (*notifyLog.ErrorMessages)["error"] != tc.err.Error() {
+				t.Errorf("error messages = %v", notifyLog.ErrorMessages)
+			}
+			if tc.wantState == NotifyStateDeadLetter {
+				if notifyLog.NextRetryAt != nil {
+					t.Fatalf("dead letter has next retry at %d", *notifyLog.NextRetryAt)
+				}
+				return
+			}
+			if notifyLog.NextRetryAt == nil {
+				t.Fatal("next retry at not set")
+			}
+			wait := time.Duration(*notifyLog.NextRetryAt-now.Unix()) * time.Second
+			if wait < tc.min || wait > tc.max {
+				t.Fatalf("next retry in %v, want between %v and %v", wait, tc.min, tc.max)
+			}
+		})
+	}
+}
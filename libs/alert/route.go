@@ -0,0 +1,85 @@
+package alert
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/detect-viz/shared-lib/models"
+	"go.uber.org/zap"
+)
+
+// 通知路由：
+// 域設定通知路由樹時，告警依欄位 (規則標籤、內建欄位與指標類別) 比對路由，
+// 符合路由的聯絡人與規則聯絡人、值班人員一起通知；規則設定升級策略時仍只依升級步驟通知
+// 符合的路由設定 group_policy 時，該路由聯絡人的告警以路由的分組設定優先於聯絡人與全域設定
+
+// routeMatches 告警符合的路由，未設定路由樹時回傳 nil
+func (s *Service) routeMatches(log models.TriggeredLog, fields map[string]string) []models.RouteMatch {
+	matches, err := s.routeService.Match(log.RealmName, fields)
+	if err != nil {
+		s.logger.Error("比對通知路由失敗", zap.Error(err), zap.String("realm", log.RealmName))
+		return nil
+	}
+	return matches
+}
+
+// routeContacts 符合路由的聯絡人，contacts 為聯絡人快取 (key 為 hex ID)
+func (s *Service) routeContacts(matches []models.RouteMatch, contacts map[string]*models.Contact) []models.Contact {
+	var result []models.Contact
+	seen := make(map[string]bool)
+	for _, match := range matches {
+		for _, receiver := range match.Receivers {
+			if seen[receiver] {
+				continue
+			}
+			seen[receiver] = true
+
+			contact, ok := contacts[receiver]
+			if !ok {
+				if id, err := hex.DecodeString(receiver); err == nil {
+					if contact, err = s.mysql.GetContact(id); err != nil {
+						s.logger.Error("獲取路由聯絡人失敗", zap.Error(err), zap.String("contact_id", receiver))
+					}
+				}
+				contacts[receiver] = contact
+			}
+			if contact != nil {
+				result = append(result, *contact)
+			}
+		}
+	}
+	return result
+}
+
+// routeGroupPolicy 聯絡人在符合路由中的分組設定與路由路徑，沒有設定時回傳 nil
+func routeGroupPolicy(matches []models.RouteMatch, contactID string) (*models.GroupPolicy, string) {
+	receiver := hex.EncodeToString([]byte(contactID))
+	for _, match := range matches {
+		if match.GroupPolicy == nil {
+			continue
+		}
+		for _, r := range match.Receivers {
+			if r == receiver {
+				return match.GroupPolicy, strings.Join(match.Path, "/")
+			}
+		}
+	}
+	return nil, ""
+}
+
+// mergeGroupPolicy 以路由的分組設定覆寫，路由未設定的欄位沿用原設定
+func mergeGroupPolicy(policy models.GroupPolicy, route models.GroupPolicy) models.GroupPolicy {
+	if route.GroupBy != nil {
+		policy.GroupBy = route.GroupBy
+	}
+	if route.GroupWait != "" {
+		policy.GroupWait = route.GroupWait
+	}
+	if route.GroupInterval != "" {
+		policy.GroupInterval = route.GroupInterval
+	}
+	if route.RepeatInterval != "" {
+		policy.RepeatInterval = route.RepeatInterval
+	}
+	return policy
+}
@@ -0,0 +1,131 @@
+package alert
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/models/common"
+	"go.uber.org/zap"
+)
+
+// 缺值偵測 (stale) 的定期檢查：
+// 設備停止送數據後不會再收到 payload，規則也不會被 ProcessAlert 匹配，
+// 因此定期以各規則最後收到數據的時間 (lastSeen) 與目前時間比較，不依賴新數據觸發
+
+// 預設定期檢查週期 (秒)
+const defaultStaleCheckPeriod = 60
+
+// 註冊缺值偵測定期檢查任務
+func (s *Service) registerStaleCheckTask() error {
+	period := s.config.StaleCheckPeriod
+	if period <= 0 {
+		period = defaultStaleCheckPeriod
+	}
+
+	job := common.Task{
+		Name:        "stale_check",
+		Spec:        fmt.Sprintf("@every %ds", period),
+		Type:        "cron",
+		Enabled:     true,
+		Timezone:    "Asia/Taipei",
+		Description: "缺值偵測定期檢查",
+		Duration:    10 * time.Second,
+		ExecFunc: func() error {
+			return s.checkStaleRules(time.Now().Unix())
+		},
+	}
+
+	if err := s.schedulerService.RegisterTask(job); err != nil {
+		s.logger.Error("註冊缺值偵測任務失敗",
+			zap.Error(err),
+			zap.Int("period", period))
+		return err
+	}
+	return nil
+}
+
+// 記錄規則最後收到數據的時間，只記錄需定期檢查的規則
+func (s *Service) recordLastSeen(rule models.Rule, metricRule models.MetricRule, data []models.MetricValue) {
+	if len(data) == 0 {
+		return
+	}
+	detector, ok := GetDetector(metricRule.DetectionType)
+	if !ok || !checksPeriodically(detector) {
+		return
+	}
+
+	var latest int64
+	for _, point := range data {
+		if point.Timestamp > latest {
+			latest = point.Timestamp
+		}
+	}
+	if previous, ok := s.lastSeen.Load(string(rule.ID)); ok && previous.(int64) >= latest {
+		return
+	}
+	s.lastSeen.Store(string(rule.ID), latest)
+}
+
+// 取得規則最後收到數據的時間，0 表示尚未記錄
+func (s *Service) lastSeenAt(ruleID []byte) int64 {
+	if value, ok := s.lastSeen.Load(string(ruleID)); ok {
+		return value.(int64)
+	}
+	return 0
+}
+
+// checkStaleRules 檢查所有需定期檢查的規則
+func (s *Service) checkStaleRules(currentTime int64) error {
+	var staleRules []models.Rule
+	globalRulesMutex.RLock()
+	for _, resources := range s.globalRules {
+		for _, ruleKeys := range resources {
+			for _, rules := range ruleKeys {
+				for _, rule := range rules {
+					metricRule, ok := s.global.MetricRules[rule.MetricRuleUID]
+					if !ok {
+						continue
+					}
+					if detector, ok := GetDetector(metricRule.DetectionType); ok && checksPeriodically(detector) {
+						staleRules = append(staleRules, rule)
+					}
+				}
+			}
+		}
+	}
+	globalRulesMutex.RUnlock()
+
+	triggered := 0
+	for _, rule := range staleRules {
+		metricRule := s.global.MetricRules[rule.MetricRuleUID]
+		metricKey := metricRule.MetricRawName
+		if rule.Target.PartitionName != "" {
+			metricKey = metricKey + ":" + rule.Target.PartitionName
+		}
+
+		// 服務啟動後尚未收到數據的規則，以第一次檢查的時間起算
+		s.lastSeen.LoadOrStore(string(rule.ID), currentTime)
+
+		result, err := s.evaluateRule(&rule, nil, nil, currentTime)
+		if err != nil {
+			s.logger.Error("缺值偵測評估失敗",
+				zap.String("rule_id", string(rule.ID)),
+				zap.String("metric_rule_uid", rule.MetricRuleUID),
+				zap.String("resource", rule.Target.ResourceName),
+				zap.Error(err))
+			s.recordEvaluationError(rule, result.Details, err, currentTime)
+			continue
+		}
+		if s.applyDetectResult(rule, metricRule, metricKey, result, currentTime) {
+			triggered++
+		}
+	}
+
+	if len(staleRules) > 0 {
+		s.logger.Debug("缺值偵測定期檢查完成",
+			zap.Int("rule_count", len(staleRules)),
+			zap.Int("triggered_rule_count", triggered))
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/detect-viz/shared-lib/models"
+)
+
+// 告警風暴：
+// 單則通知的告警數超過 storm_threshold (聯絡人設定優先於 alert.storm_threshold) 時，
+// 不套用通知模板逐筆列出，改發一則摘要 (依嚴重程度統計，列出告警數最多的資源與指標)
+// 通知日誌仍記錄全部告警 ID，完整清單可至告警歷史查看
+
+const (
+	stormTopResources = 10 // 摘要列出的資源數
+	stormTopMetrics   = 5  // 摘要列出的指標數
+)
+
+// stormCount 摘要中的統計項目
+type stormCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// stormThreshold 聯絡人的告警風暴門檻，0 表示停用
+func stormThreshold(contact *models.Contact, defaultThreshold int) int {
+	if v, err := strconv.Atoi(contact.Config["storm_threshold"]); err == nil && v >= 0 {
+		return v
+	}
+	return defaultThreshold
+}
+
+// isStorm 判斷是否改發告警風暴摘要
+func (s *Service) isStorm(contact *models.Contact, logs []models.TriggeredLog) bool {
+	threshold := stormThreshold(contact, s.config.StormThreshold)
+	return threshold > 0 && len(logs) > threshold
+}
+
+// renderNotification 渲染通知內容，告警風暴時改為摘要
+func (s *Service) renderNotification(contact *models.Contact, logs []models.TriggeredLog, notifyType string) (string, string, string, error) {
+	if s.isStorm(contact, logs) {
+		title, message, err := renderStormSummary(contact, logs, notifyType)
+		return title, message, "", err
+	}
+	return s.renderTemplate(contact, logs, notifyType)
+}
+
+// renderStormSummary 依聯絡人的通知格式產生告警風暴摘要
+func renderStormSummary(contact *models.Contact, logs []models.TriggeredLog, notifyType string) (string, string, error) {
+	severities := countBy(logs, func(log models.TriggeredLog) string { return formatSeverity(log.Severity) })
+	severityOrder := map[string]int{"Critical": 0, "Warning": 1, "Info": 2}
+	sort.SliceStable(severities, func(i, j int) bool {
+		return severityOrder[severities[i].Name] < severityOrder[severities[j].Name]
+	})
+	resources := countBy(logs, func(log models.TriggeredLog) string { return log.ResourceName })
+	metrics := countBy(logs, func(log models.TriggeredLog) string { return log.MetricRuleUID })
+
+	title := fmt.Sprintf("[告警風暴] %d 個告警同時觸發", len(logs))
+	if notifyType == "resolved" {
+		title = fmt.Sprintf("[告警風暴] %d 個告警已恢復", len(logs))
+	}
+
+	formatType := GetFormatByType(contact.ChannelType)
+	if formatType == "json" {
+		data, err := json.Marshal(map[string]interface{}{
+			"storm":            true,
+			"notify_type":      notifyType,
+			"count":            len(logs),
+			"severities":       severities,
+			"resources_count":  len(resources),
+			"top_resources":    topCounts(resources, stormTopResources),
+			"top_metric_rules": topCounts(metrics, stormTopMetrics),
+		})
+		if err != nil {
+			return "", "", fmt.Errorf("產生告警風暴摘要失敗: %w", err)
+		}
+		return title, string(data), nil
+	}
+
+	severityParts := make([]string, 0, len(severities))
+	for _, c := range severities {
+		severityParts = append(severityParts, fmt.Sprintf("%s %d", c.Name, c.Count))
+	}
+
+	var sections [][]string
+	sections = append(sections, []string{"嚴重程度: " + strings.Join(severityParts, " / ")})
+
+	resourceLines := []string{fmt.Sprintf("受影響資源 (共 %d 個):", len(resources))}
+	for _, c := range topCounts(resources, stormTopResources) {
+		resourceLines = append(resourceLines, fmt.Sprintf("%s: %d", c.Name, c.Count))
+	}
+	if len(resources) > stormTopResources {
+		resourceLines = append(resourceLines, fmt.Sprintf("… 其餘 %d 個資源", len(resources)-stormTopResources))
+	}
+	sections = append(sections, resourceLines)
+
+	metricLines := []string{"告警指標:"}
+	for _, c := range topCounts(metrics, stormTopMetrics) {
+		metricLines = append(metricLines, fmt.Sprintf("%s: %d", c.Name, c.Count))
+	}
+	sections = append(sections, metricLines)
+	sections = append(sections, []string{"完整清單請至告警歷史查看"})
+
+	return title, formatStormSections(formatType, sections), nil
+}
+
+// formatStormSections 依格式輸出摘要段落，每段第一行為標題、其餘為項目
+func formatStormSections(formatType string, sections [][]string) string {
+	parts := make([]string, 0, len(sections))
+	for _, section := range sections {
+		lines := make([]string, 0, len(section))
+		for i, line := range section {
+			switch {
+			case formatType == "html" && i == 0:
+				lines = append(lines, "<strong>"+html.EscapeString(line)+"</strong>")
+			case formatType == "html":
+				lines = append(lines, "&nbsp;&nbsp;- "+html.EscapeString(line))
+			case formatType == "markdown" && i == 0:
+				lines = append(lines, "**"+line+"**")
+			case i == 0:
+				lines = append(lines, line)
+			default:
+				lines = append(lines, "- "+line)
+			}
+		}
+		if formatType == "html" {
+			parts = append(parts, strings.Join(lines, "<br>\n"))
+		} else {
+			parts = append(parts, strings.Join(lines, "\n"))
+		}
+	}
+	if formatType == "html" {
+		return strings.Join(parts, "<br><br>\n")
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// appendSuppressedNote 在通知內容附註自上次發送後被限流的告警數 (JSON 格式不附註，只記錄於通知日誌)
+func appendSuppressedNote(formatType, message string, count int) string {
+	note := fmt.Sprintf("另有 %d 個告警因通知流量限制未發送，詳見通知記錄", count)
+	switch formatType {
+	case "json":
+		return message
+	case "html":
+		return message + "<br><br>\n<em>" + note + "</em>"
+	default:
+		return message + "\n\n" + note
+	}
+}
+
+// countBy 依 key 統計告警數，依數量遞減、名稱遞增排序
+func countBy(logs []models.TriggeredLog, key func(models.TriggeredLog) string) []stormCount {
+	counts := make(map[string]int)
+	for _, log := range logs {
+		counts[key(log)]++
+	}
+	result := make([]stormCount, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, stormCount{Name: name, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// topCounts 取前 n 項
+func topCounts(counts []stormCount, n int) []stormCount {
+	if len(counts) > n {
+		return counts[:n]
+	}
+	return counts
+}
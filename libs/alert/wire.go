@@ -4,13 +4,19 @@
 package alert
 
 import (
+	"fmt"
+
 	"github.com/detect-viz/shared-lib/auth/keycloak"
 	"github.com/detect-viz/shared-lib/contacts"
+	"github.com/detect-viz/shared-lib/escalations"
 	"github.com/detect-viz/shared-lib/infra/logger"
 	"github.com/detect-viz/shared-lib/infra/scheduler"
 	"github.com/detect-viz/shared-lib/labels"
 	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/mutes"
 	"github.com/detect-viz/shared-lib/notifier"
+	"github.com/detect-viz/shared-lib/oncall"
+	"github.com/detect-viz/shared-lib/routes"
 	"github.com/detect-viz/shared-lib/rules"
 
 	"github.com/detect-viz/shared-lib/storage/mysql"
@@ -52,6 +58,11 @@ func ProvideLabelService(mysqlClient *mysql.Client) labels.Service {
 	return labels.NewService(mysqlClient)
 }
 
+// 提供 mutes.Service
+func ProvideMuteService(mysqlClient *mysql.Client, log logger.Logger) mutes.Service {
+	return mutes.NewService(mysqlClient, log.GetLogger())
+}
+
 // 提供 contacts.Service
 func ProvideContactService(mysqlClient *mysql.Client, log logger.Logger, notifierService notifier.Service, keycloakClient *keycloak.Client) contacts.Service {
 	return contacts.NewService(mysqlClient, log, notifierService, keycloakClient)
@@ -65,6 +76,8 @@ var AlertSet = wire.NewSet(
 	ProvideGlobalConfig,
 	// 提供 notifier.Service
 	ProvideNotifierService,
+	// 提供 mutes.Service
+	ProvideMuteService,
 	// Alert 服務
 	ProvideAlertService,
 	// 各模組的 wire set
@@ -72,6 +85,9 @@ var AlertSet = wire.NewSet(
 	scheduler.SchedulerSet,
 	templates.TemplateSet,
 	contacts.ContactSet,
+	escalations.EscalationSet,
+	oncall.OnCallSet,
+	routes.RouteSet,
 )
 
 // InitializeAlertService 初始化 AlertService
@@ -97,7 +113,11 @@ func ProvideAlertService(
 	contact contacts.Service,
 	scheduler scheduler.Service,
 	template templates.Service,
-) *Service {
+	mute mutes.Service,
+	escalation escalations.Service,
+	onCall oncall.Service,
+	route routes.Service,
+) (*Service, error) {
 	logSvc.Debug("檢查 ProvideAlertService 參數", zap.Any("config", config), zap.Any("mysqlClient", mysqlClient))
 
 	if mysqlClient == nil {
@@ -118,6 +138,23 @@ func ProvideAlertService(
 	if template == nil {
 		panic("❌ template 是 nil")
 	}
+	if mute == nil {
+		panic("❌ mute 是 nil")
+	}
+	if escalation == nil {
+		panic("❌ escalation 是 nil")
+	}
+	if onCall == nil {
+		panic("❌ onCall 是 nil")
+	}
+	if route == nil {
+		panic("❌ route 是 nil")
+	}
+
+	// 檢查指標規則設定 (未知的 detection_type 直接拒絕啟動)
+	if err := ValidateMetricRules(global.MetricRules); err != nil {
+		return nil, fmt.Errorf("載入指標規則失敗: %w", err)
+	}
 
 	return NewService(
 		config,
@@ -129,5 +166,9 @@ func ProvideAlertService(
 		contact,
 		scheduler,
 		template,
-	)
+		mute,
+		escalation,
+		onCall,
+		route,
+	), nil
 }
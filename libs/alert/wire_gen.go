@@ -7,14 +7,20 @@
 package alert
 
 import (
+	"fmt"
+
 	"github.com/detect-viz/shared-lib/auth/keycloak"
 	"github.com/detect-viz/shared-lib/contacts"
+	"github.com/detect-viz/shared-lib/escalations"
 	"github.com/detect-viz/shared-lib/infra/logger"
 	"github.com/detect-viz/shared-lib/infra/scheduler"
 	"github.com/detect-viz/shared-lib/labels"
 	"github.com/detect-viz/shared-lib/models"
 	"github.com/detect-viz/shared-lib/models/config"
+	"github.com/detect-viz/shared-lib/mutes"
 	"github.com/detect-viz/shared-lib/notifier"
+	"github.com/detect-viz/shared-lib/oncall"
+	"github.com/detect-viz/shared-lib/routes"
 	"github.com/detect-viz/shared-lib/rules"
 	"github.com/detect-viz/shared-lib/storage/mysql"
 	"github.com/detect-viz/shared-lib/templates"
@@ -32,7 +38,14 @@ func InitializeAlertService(config2 config.AlertConfig, global config.GlobalConf
 	contactsServiceImpl := contacts.NewService(mysqlClient, log, service, keycloakClient)
 	schedulerServiceImpl := scheduler.NewService(log)
 	templatesServiceImpl := templates.NewService(log)
-	alertService := ProvideAlertService(config2, global, mysqlClient, log, serviceImpl, service, contactsServiceImpl, schedulerServiceImpl, templatesServiceImpl)
+	mutesService := ProvideMuteService(mysqlClient, log)
+	escalationsServiceImpl := escalations.NewService(mysqlClient, log)
+	oncallServiceImpl := oncall.NewService(mysqlClient, log)
+	routesServiceImpl := routes.NewService(mysqlClient, log)
+	alertService, err := ProvideAlertService(config2, global, mysqlClient, log, serviceImpl, service, contactsServiceImpl, schedulerServiceImpl, templatesServiceImpl, mutesService, escalationsServiceImpl, oncallServiceImpl, routesServiceImpl)
+	if err != nil {
+		return nil, err
+	}
 	return alertService, nil
 }
 
@@ -71,6 +84,11 @@ func ProvideLabelService(mysqlClient *mysql.Client) labels.Service {
 	return labels.NewService(mysqlClient)
 }
 
+// 提供 mutes.Service
+func ProvideMuteService(mysqlClient *mysql.Client, log logger.Logger) mutes.Service {
+	return mutes.NewService(mysqlClient, log.GetLogger())
+}
+
 // 提供 contacts.Service
 func ProvideContactService(mysqlClient *mysql.Client, log logger.Logger, notifierService notifier.Service, keycloakClient *keycloak.Client) contacts.Service {
 	return contacts.NewService(mysqlClient, log, notifierService, keycloakClient)
@@ -85,6 +103,8 @@ var AlertSet = wire.NewSet(
 
 	ProvideNotifierService,
 
+	ProvideMuteService,
+
 	ProvideAlertService, rules.RuleSet, scheduler.SchedulerSet, templates.TemplateSet, contacts.ContactSet,
 )
 
@@ -99,7 +119,11 @@ func ProvideAlertService(config2 models.AlertConfig,
 	contact contacts.Service, scheduler2 scheduler.Service,
 
 	template templates.Service,
-) *Service {
+	mute mutes.Service,
+	escalation escalations.Service,
+	onCall oncall.Service,
+	route routes.Service,
+) (*Service, error) {
 	logSvc.Debug("檢查 ProvideAlertService 參數", zap.Any("config", config2), zap.Any("mysqlClient", mysqlClient))
 
 	if mysqlClient == nil {
@@ -120,6 +144,23 @@ func ProvideAlertService(config2 models.AlertConfig,
 	if template == nil {
 		panic("❌ template 是 nil")
 	}
+	if mute == nil {
+		panic("❌ mute 是 nil")
+	}
+	if escalation == nil {
+		panic("❌ escalation 是 nil")
+	}
+	if onCall == nil {
+		panic("❌ onCall 是 nil")
+	}
+	if route == nil {
+		panic("❌ route 是 nil")
+	}
+
+	// 檢查指標規則設定 (未知的 detection_type 直接拒絕啟動)
+	if err := ValidateMetricRules(global.MetricRules); err != nil {
+		return nil, fmt.Errorf("載入指標規則失敗: %w", err)
+	}
 
 	return NewService(config2, global,
 		mysqlClient,
@@ -127,5 +168,9 @@ func ProvideAlertService(config2 models.AlertConfig,
 		rule,
 		notify,
 		contact, scheduler2, template,
-	)
+		mute,
+		escalation,
+		onCall,
+		route,
+	), nil
 }
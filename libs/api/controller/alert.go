@@ -5,27 +5,39 @@ import (
 	"github.com/detect-viz/shared-lib/api/middleware"
 	"github.com/detect-viz/shared-lib/auth/keycloak"
 	"github.com/detect-viz/shared-lib/contacts"
+	"github.com/detect-viz/shared-lib/escalations"
+	"github.com/detect-viz/shared-lib/mutes"
 	"github.com/detect-viz/shared-lib/notifier"
+	"github.com/detect-viz/shared-lib/oncall"
+	"github.com/detect-viz/shared-lib/routes"
 	"github.com/detect-viz/shared-lib/rules"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 type AlertAPI struct {
-	alertService   *alert.Service
-	ruleService    rules.Service
-	contactService contacts.Service
-	notifyService  notifier.Service
-	logger         *zap.Logger
+	alertService      *alert.Service
+	ruleService       rules.Service
+	contactService    contacts.Service
+	notifyService     notifier.Service
+	muteService       mutes.Service
+	escalationService escalations.Service
+	onCallService     oncall.Service
+	routeService      routes.Service
+	logger            *zap.Logger
 }
 
 func NewAlertAPI(alertService *alert.Service) *AlertAPI {
 	return &AlertAPI{
-		alertService:   alertService,
-		ruleService:    alertService.GetRuleService(),
-		contactService: alertService.GetContactService(),
-		notifyService:  alertService.GetNotifyService(),
-		logger:         alertService.GetLogger(),
+		alertService:      alertService,
+		ruleService:       alertService.GetRuleService(),
+		contactService:    alertService.GetContactService(),
+		notifyService:     alertService.GetNotifyService(),
+		muteService:       alertService.GetMuteService(),
+		escalationService: alertService.GetEscalationService(),
+		onCallService:     alertService.GetOnCallService(),
+		routeService:      alertService.GetRouteService(),
+		logger:            alertService.GetLogger(),
 	}
 }
 
@@ -35,6 +47,17 @@ func RegisterV1Routes(router *gin.Engine, alertService *alert.Service, keycloak
 
 	// 初始化 API Controller
 	alertAPI := NewAlertAPI(alertService)
+
+	// 走勢圖以簽章網址驗證，供 Slack / LINE 等外部服務直接讀取，不經過登入驗證
+	router.GET("/api/v1/alert/chart/:file", alertAPI.GetTriggeredLogChart)
+
+	// 通知訊息操作：簽章連結與聊天通道回呼各自驗證權杖或通道簽章，不經過登入驗證
+	router.GET("/api/v1/alert/action", alertAPI.GetNotifyActionPage)
+	router.POST("/api/v1/alert/action", alertAPI.ExecuteNotifyAction)
+	router.POST("/api/v1/alert/callback/slack", alertAPI.SlackActionCallback)
+	router.POST("/api/v1/alert/callback/line", alertAPI.LineActionCallback)
+	router.POST("/api/v1/alert/callback/teams", alertAPI.TeamsActionCallback)
+
 	v1.Use(middleware.GetUserInfo(keycloak, alertService, &gin.Context{}))
 
 	{
@@ -45,6 +68,11 @@ func RegisterV1Routes(router *gin.Engine, alertService *alert.Service, keycloak
 	{
 		v1.GET("/state", alertAPI.ListRuleState)
 		v1.GET("/history", alertAPI.ListAlertHistory)
+		v1.POST("/history/:id/ack", alertAPI.AckTriggeredLog)
+		v1.POST("/history/:id/unack", alertAPI.UnackTriggeredLog)
+		v1.POST("/history/:id/assign", alertAPI.AssignTriggeredLog)
+		v1.POST("/history/:id/resolve", alertAPI.ResolveTriggeredLog)
+		v1.GET("/history/:id/actions", alertAPI.ListNotifyActionLogs)
 		v1.GET("/rule/metric-rule/:uid", alertAPI.GetMetricRule)
 		v1.GET("/rule/metric-rule-options/:category", alertAPI.GetMetricRuleOptions)
 		v1.GET("/rule/metric-rule-category-options", alertAPI.GetMetricRuleCategoryOptions)
@@ -73,4 +101,63 @@ func RegisterV1Routes(router *gin.Engine, alertService *alert.Service, keycloak
 		contactRoutes.GET("/notify-methods", alertAPI.GetNotifyMethods)
 		contactRoutes.GET("/notify-options", alertAPI.GetNotifyOptions)
 	}
+
+	// 註冊靜默 API
+	silenceRoutes := v1.Group("/silence")
+	{
+		silenceRoutes.GET("", alertAPI.ListSilences)
+		silenceRoutes.GET("/:id", alertAPI.GetSilence)
+		silenceRoutes.POST("", alertAPI.CreateSilence)
+		silenceRoutes.DELETE("/:id", alertAPI.ExpireSilence)
+	}
+
+	// 註冊告警抑制規則 API
+	inhibitRoutes := v1.Group("/inhibit-rule")
+	{
+		inhibitRoutes.GET("", alertAPI.ListInhibitRules)
+		inhibitRoutes.GET("/:id", alertAPI.GetInhibitRule)
+		inhibitRoutes.POST("", alertAPI.CreateInhibitRule)
+		inhibitRoutes.PUT("/:id", alertAPI.UpdateInhibitRule)
+		inhibitRoutes.DELETE("/:id", alertAPI.DeleteInhibitRule)
+	}
+
+	// 註冊升級策略 API
+	escalationRoutes := v1.Group("/escalation-policy")
+	{
+		escalationRoutes.GET("", alertAPI.ListEscalationPolicies)
+		escalationRoutes.GET("/:id", alertAPI.GetEscalationPolicy)
+		escalationRoutes.POST("", alertAPI.CreateEscalationPolicy)
+		escalationRoutes.PUT("/:id", alertAPI.UpdateEscalationPolicy)
+		escalationRoutes.DELETE("/:id", alertAPI.DeleteEscalationPolicy)
+	}
+
+	// 註冊值班表 API
+	onCallRoutes := v1.Group("/oncall-schedule")
+	{
+		onCallRoutes.GET("", alertAPI.ListOnCallSchedules)
+		onCallRoutes.GET("/:id", alertAPI.GetOnCallSchedule)
+		onCallRoutes.POST("", alertAPI.CreateOnCallSchedule)
+		onCallRoutes.PUT("/:id", alertAPI.UpdateOnCallSchedule)
+		onCallRoutes.DELETE("/:id", alertAPI.DeleteOnCallSchedule)
+		onCallRoutes.GET("/:id/oncall", alertAPI.WhoIsOnCall)
+		onCallRoutes.POST("/:id/override", alertAPI.CreateOnCallOverride)
+		onCallRoutes.DELETE("/:id/override/:override_id", alertAPI.DeleteOnCallOverride)
+	}
+
+	// 註冊通知路由 API
+	routeRoutes := v1.Group("/route")
+	{
+		routeRoutes.GET("", alertAPI.GetNotifyRoute)
+		routeRoutes.PUT("", alertAPI.UpdateNotifyRoute)
+		routeRoutes.DELETE("", alertAPI.DeleteNotifyRoute)
+		routeRoutes.POST("/test", alertAPI.TestNotifyRoute)
+	}
+
+	// 註冊死信通知 API
+	deadLetterRoutes := v1.Group("/dead-letter")
+	{
+		deadLetterRoutes.GET("", alertAPI.ListDeadLetters)
+		deadLetterRoutes.GET("/:id", alertAPI.GetDeadLetter)
+		deadLetterRoutes.POST("/:id/replay", alertAPI.ReplayDeadLetter)
+	}
 }
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"github.com/detect-viz/shared-lib/api/response"
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/gin-gonic/gin"
+)
+
+// AssignRequest 指派告警請求
+type AssignRequest struct {
+	Assignee string `json:"assignee" binding:"required"`
+}
+
+// @Summary 確認告警
+// @Description 確認觸發中的告警，恢復或升級前不再重複通知
+// @Tags Alert
+// @Accept json
+// @Produce json
+// @Param id path string true "告警 (Triggered Log) ID"
+// @Success 200 {object} models.TriggeredLog "成功回應"
+// @Failure 400 {object} response.Response "無效的 ID"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 409 {object} response.Response "告警已恢復"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/history/{id}/ack [post]
+func (a *AlertAPI) AckTriggeredLog(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	log, err := a.alertService.AckTriggeredLog(user.Realm, c.Param("id"), operatorName(user))
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, log)
+}
+
+// @Summary 取消確認告警
+// @Description 取消告警確認，尚未發送的通知恢復發送
+// @Tags Alert
+// @Accept json
+// @Produce json
+// @Param id path string true "告警 (Triggered Log) ID"
+// @Success 200 {object} models.TriggeredLog "成功回應"
+// @Failure 400 {object} response.Response "無效的 ID"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/history/{id}/unack [post]
+func (a *AlertAPI) UnackTriggeredLog(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	log, err := a.alertService.UnackTriggeredLog(user.Realm, c.Param("id"), operatorName(user))
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, log)
+}
+
+// @Summary 指派告警
+// @Description 指派告警處理人
+// @Tags Alert
+// @Accept json
+// @Produce json
+// @Param id path string true "告警 (Triggered Log) ID"
+// @Param request body AssignRequest true "指派請求"
+// @Success 200 {object} models.TriggeredLog "成功回應"
+// @Failure 400 {object} response.Response "請求內容無效"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/history/{id}/assign [post]
+func (a *AlertAPI) AssignTriggeredLog(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	var req AssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+		return
+	}
+
+	log, err := a.alertService.AssignTriggeredLog(user.Realm, c.Param("id"), req.Assignee, operatorName(user))
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, log)
+}
+
+// * 操作人名稱 (無名稱時使用 ID)
+func operatorName(user models.SSOUser) string {
+	if user.Name != "" {
+		return user.Name
+	}
+	return user.ID
+}
@@ -8,41 +8,98 @@ import (
 )
 
 // @Summary 獲取告警狀態列表
-// @Description 取得所有告警狀態
+// @Description 取得目前告警狀態，支援篩選、分頁與排序 (依最後觸發時間排序)
 // @Tags Alert
 // @Accept json
 // @Produce json
-// @Success 200 {array} models.RuleState "成功回應"
+// @Param resource_name query string false "監控對象"
+// @Param partition_name query string false "分區"
+// @Param metric_rule_uid query string false "指標規則 UID"
+// @Param severity query string false "嚴重程度 (info/warn/crit)"
+// @Param state query string false "告警狀態 (alerting/resolved/normal/disabled)"
+// @Param start_time query int false "最後觸發時間起 (unix 秒)"
+// @Param end_time query int false "最後觸發時間迄 (unix 秒)"
+// @Param cursor query string false "上一頁的 next_cursor"
+// @Param limit query int false "最大筆數"
+// @Param order query string false "排序 (asc/desc)，預設 desc"
+// @Success 200 {object} response.Response "成功回應"
+// @Failure 400 {object} response.Response "無效的查詢條件"
 // @Failure 500 {object} response.Response "伺服器錯誤"
 // @Security ApiKeyAuth
 // @Router /alert/state [get]
 func (a *AlertAPI) ListRuleState(c *gin.Context) {
 	user := c.Keys["user"].(models.SSOUser)
-	ruleState, err := a.alertService.ListRuleState(user.Realm)
+	query, ok := bindMonitorQuery(c, user.Realm)
+	if !ok {
+		return
+	}
+
+	ruleStates, nextCursor, err := a.alertService.ListRuleState(query)
 	if err != nil {
-		response.JSONError(c, 500, err)
+		respondMonitorError(c, err)
 		return
 	}
-	response.JSONSuccess(c, ruleState)
+	response.JSONResponse(c, 200, gin.H{
+		"rule_states": ruleStates,
+		"next_cursor": nextCursor,
+	}, "success")
 }
 
 // @Summary 獲取告警歷史列表
-// @Description 取得所有告警歷史
+// @Description 取得告警歷史 (Triggered Log)，支援篩選、分頁與排序
 // @Tags Alert
 // @Accept json
 // @Produce json
-// @Success 200 {array} []models.TriggeredLog "成功回應"
+// @Param resource_name query string false "監控對象"
+// @Param partition_name query string false "分區"
+// @Param metric_rule_uid query string false "指標規則 UID"
+// @Param severity query string false "嚴重程度 (info/warn/crit)"
+// @Param state query string false "告警狀態 (alerting/resolved)"
+// @Param start_time query int false "觸發時間起 (unix 秒)"
+// @Param end_time query int false "觸發時間迄 (unix 秒)"
+// @Param cursor query string false "上一頁的 next_cursor"
+// @Param limit query int false "最大筆數"
+// @Param order query string false "排序 (asc/desc)，預設 desc"
+// @Success 200 {object} response.Response "成功回應"
+// @Failure 400 {object} response.Response "無效的查詢條件"
 // @Failure 500 {object} response.Response "伺服器錯誤"
 // @Security ApiKeyAuth
 // @Router /alert/history [get]
 func (a *AlertAPI) ListAlertHistory(c *gin.Context) {
 	user := c.Keys["user"].(models.SSOUser)
-	alertHistory, err := a.alertService.ListAlertHistory(user.Realm)
+	query, ok := bindMonitorQuery(c, user.Realm)
+	if !ok {
+		return
+	}
+
+	alertHistory, nextCursor, err := a.alertService.ListAlertHistory(query)
 	if err != nil {
-		response.JSONError(c, 500, err)
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONResponse(c, 200, gin.H{
+		"triggered_logs": alertHistory,
+		"next_cursor":    nextCursor,
+	}, "success")
+}
+
+// * 解析監控頁面查詢參數
+func bindMonitorQuery(c *gin.Context, realm string) (models.MonitorQuery, bool) {
+	var query models.MonitorQuery
+	if err := c.ShouldBindQuery(&query); err != nil || query.Limit < 0 {
+		response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+		return query, false
+	}
+	query.RealmName = realm
+	return query, true
+}
+
+func respondMonitorError(c *gin.Context, err error) {
+	if apiErr, ok := err.(*apierrors.APIError); ok {
+		response.JSONError(c, apiErr.Code, apiErr)
 		return
 	}
-	response.JSONSuccess(c, alertHistory)
+	response.JSONError(c, 500, err)
 }
 
 // @Summary 獲取告警規則列表
@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 獲取告警走勢圖
+// @Description 以通知中的簽章網址取得告警評估時間窗口的走勢圖 (含閾值線)，不需登入
+// @Tags Alert
+// @Produce png
+// @Produce image/svg+xml
+// @Param file path string true "{告警 ID}.png 或 {告警 ID}.svg"
+// @Param exp query int true "網址到期時間 (Unix 秒)"
+// @Param sig query string true "簽章"
+// @Success 200 {file} file "走勢圖"
+// @Failure 403 {object} response.Response "網址已失效或簽章無效"
+// @Failure 404 {object} response.Response "走勢圖不存在"
+// @Router /alert/chart/{file} [get]
+func (a *AlertAPI) GetTriggeredLogChart(c *gin.Context) {
+	data, contentType, err := a.alertService.RenderTriggeredLogChart(c.Param("file"), c.Query("exp"), c.Query("sig"))
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Data(200, contentType, data)
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/detect-viz/shared-lib/api/response"
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 獲取死信通知列表
+// @Description 取得永久錯誤或超過重試次數而停止發送的通知
+// @Tags DeadLetter
+// @Accept json
+// @Produce json
+// @Param cursor query int false "游標 (上一頁最後一筆的 created_at)"
+// @Param limit query int false "每頁筆數 (預設 10)"
+// @Success 200 {object} response.Response "成功回應"
+// @Failure 400 {object} response.Response "無效的查詢條件"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/dead-letter [get]
+func (a *AlertAPI) ListDeadLetters(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	cursor := int64(0)
+	limit := 10
+	var err error
+
+	if c.Query("cursor") != "" {
+		cursor, err = strconv.ParseInt(c.Query("cursor"), 10, 64)
+		if err != nil || cursor < 0 {
+			response.JSONError(c, 400, apierrors.ErrInvalidID)
+			return
+		}
+	}
+
+	if c.Query("limit") != "" {
+		limit, err = strconv.Atoi(c.Query("limit"))
+		if err != nil || limit <= 0 {
+			response.JSONError(c, 400, apierrors.ErrInvalidID)
+			return
+		}
+	}
+
+	logs, nextCursor, err := a.alertService.ListDeadLetters(user.Realm, cursor, limit)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+
+	response.JSONResponse(c, 200, gin.H{
+		"dead_letters": logs,
+		"next_cursor":  nextCursor,
+	}, "success")
+}
+
+// @Summary 獲取單一死信通知
+// @Description 根據 ID 獲取死信通知 (含錯誤分類與錯誤訊息)
+// @Tags DeadLetter
+// @Accept json
+// @Produce json
+// @Param id path string true "通知日誌 ID"
+// @Success 200 {object} models.NotifyLog "成功回應"
+// @Failure 400 {object} response.Response "無效的 ID"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/dead-letter/{id} [get]
+func (a *AlertAPI) GetDeadLetter(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	log, err := a.alertService.GetDeadLetter(user.Realm, c.Param("id"))
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, log)
+}
+
+// @Summary 重送死信通知
+// @Description 重試次數歸零後立即重新發送，再次失敗時依聯絡人的重試設定排程重試或移回死信
+// @Tags DeadLetter
+// @Accept json
+// @Produce json
+// @Param id path string true "通知日誌 ID"
+// @Success 200 {object} models.NotifyLog "成功回應"
+// @Failure 400 {object} response.Response "無效的 ID"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/dead-letter/{id}/replay [post]
+func (a *AlertAPI) ReplayDeadLetter(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	log, err := a.alertService.ReplayDeadLetter(user.Realm, c.Param("id"), operatorName(user))
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, log)
+}
@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/detect-viz/shared-lib/api/response"
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 獲取升級策略列表
+// @Description 取得所有升級策略
+// @Tags Escalation
+// @Accept json
+// @Produce json
+// @Param cursor query int false "游標 (上一頁最後一筆的 created_at)"
+// @Param limit query int false "每頁筆數 (預設 10)"
+// @Success 200 {object} response.Response "成功回應"
+// @Failure 400 {object} response.Response "無效的查詢條件"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/escalation-policy [get]
+func (a *AlertAPI) ListEscalationPolicies(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	cursor := int64(0)
+	limit := 10
+	var err error
+
+	if c.Query("cursor") != "" {
+		cursor, err = strconv.ParseInt(c.Query("cursor"), 10, 64)
+		if err != nil || cursor < 0 {
+			response.JSONError(c, 400, apierrors.ErrInvalidID)
+			return
+		}
+	}
+
+	if c.Query("limit") != "" {
+		limit, err = strconv.Atoi(c.Query("limit"))
+		if err != nil || limit <= 0 {
+			response.JSONError(c, 400, apierrors.ErrInvalidID)
+			return
+		}
+	}
+
+	policies, nextCursor, err := a.escalationService.List(user.Realm, cursor, limit)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+
+	response.JSONResponse(c, 200, gin.H{
+		"escalation_policies": policies,
+		"next_cursor":         nextCursor,
+	}, "success")
+}
+
+// @Summary 獲取單一升級策略
+// @Description 根據 ID 獲取升級策略
+// @Tags Escalation
+// @Accept json
+// @Produce json
+// @Param id path string true "升級策略 ID"
+// @Success 200 {object} models.EscalationPolicyResponse "成功回應"
+// @Failure 400 {object} response.Response "無效的 ID"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/escalation-policy/{id} [get]
+func (a *AlertAPI) GetEscalationPolicy(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.JSONError(c, 400, apierrors.ErrInvalidID)
+		return
+	}
+
+	policy, err := a.escalationService.Get(user.Realm, idStr)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, policy)
+}
+
+// @Summary 創建升級策略
+// @Description 新增升級策略，第一步於告警發送時立即通知，後續步驟在 delay 後仍未確認或恢復時通知
+// @Tags Escalation
+// @Accept json
+// @Produce json
+// @Param policy body models.EscalationPolicyResponse true "升級策略內容"
+// @Success 201 {object} models.EscalationPolicyResponse "成功創建"
+// @Failure 400 {object} response.Response "請求內容無效"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/escalation-policy [post]
+func (a *AlertAPI) CreateEscalationPolicy(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	var policyResp models.EscalationPolicyResponse
+	if err := c.ShouldBindJSON(&policyResp); err != nil {
+		response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+		return
+	}
+
+	policy, err := a.escalationService.Create(user.Realm, user.ID, &policyResp)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONCreated(c, policy)
+}
+
+// @Summary 更新升級策略
+// @Description 根據 ID 更新升級策略 (步驟整批取代)
+// @Tags Escalation
+// @Accept json
+// @Produce json
+// @Param id path string true "升級策略 ID"
+// @Param policy body models.EscalationPolicyResponse true "升級策略內容"
+// @Success 200 {object} models.EscalationPolicyResponse "成功更新"
+// @Failure 400 {object} response.Response "請求內容無效"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/escalation-policy/{id} [put]
+func (a *AlertAPI) UpdateEscalationPolicy(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.JSONError(c, 400, apierrors.ErrInvalidID)
+		return
+	}
+
+	var policyResp models.EscalationPolicyResponse
+	if err := c.ShouldBindJSON(&policyResp); err != nil {
+		response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+		return
+	}
+
+	// 確保 policyResp.ID 來自 path
+	policyResp.ID = idStr
+
+	policy, err := a.escalationService.Update(user.Realm, user.ID, &policyResp)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, policy)
+}
+
+// @Summary 刪除升級策略
+// @Description 根據 ID 刪除升級策略，仍被規則使用時無法刪除
+// @Tags Escalation
+// @Accept json
+// @Produce json
+// @Param id path string true "升級策略 ID"
+// @Success 200 {object} map[string]string "刪除成功"
+// @Failure 400 {object} response.Response "無效的 ID"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 409 {object} response.Response "仍被規則使用"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/escalation-policy/{id} [delete]
+func (a *AlertAPI) DeleteEscalationPolicy(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.JSONError(c, 400, apierrors.ErrInvalidID)
+		return
+	}
+
+	if err := a.escalationService.Delete(user.Realm, idStr); err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, gin.H{"message": "刪除成功"})
+}
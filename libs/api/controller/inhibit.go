@@ -0,0 +1,147 @@
+package controller
+
+import (
+	"github.com/detect-viz/shared-lib/api/response"
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 獲取告警抑制規則列表
+// @Description 取得告警抑制規則列表
+// @Tags Inhibit
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response "成功回應"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/inhibit-rule [get]
+func (a *AlertAPI) ListInhibitRules(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+
+	rules, err := a.muteService.ListInhibitRules(user.Realm)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONResponse(c, 200, gin.H{
+		"inhibit_rules": rules,
+	}, "success")
+}
+
+// @Summary 獲取單一告警抑制規則
+// @Description 根據 ID 獲取告警抑制規則
+// @Tags Inhibit
+// @Accept json
+// @Produce json
+// @Param id path string true "抑制規則 ID"
+// @Success 200 {object} models.InhibitRuleResponse "成功回應"
+// @Failure 400 {object} response.Response "無效的 ID"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/inhibit-rule/{id} [get]
+func (a *AlertAPI) GetInhibitRule(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.JSONError(c, 400, apierrors.ErrInvalidID)
+		return
+	}
+
+	rule, err := a.muteService.GetInhibitRule(user.Realm, idStr)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, rule)
+}
+
+// @Summary 創建告警抑制規則
+// @Description 新增告警抑制規則：符合 source_matchers 的告警未恢復時，抑制符合 target_matchers 且 equal 欄位值相同的告警
+// @Tags Inhibit
+// @Accept json
+// @Produce json
+// @Param rule body models.InhibitRuleResponse true "抑制規則內容"
+// @Success 201 {object} models.InhibitRuleResponse "成功創建"
+// @Failure 400 {object} response.Response "請求內容無效"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/inhibit-rule [post]
+func (a *AlertAPI) CreateInhibitRule(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	var req models.InhibitRuleResponse
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+		return
+	}
+
+	rule, err := a.muteService.CreateInhibitRule(user.Realm, operatorName(user), req)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONCreated(c, rule)
+}
+
+// @Summary 更新告警抑制規則
+// @Description 根據 ID 更新告警抑制規則
+// @Tags Inhibit
+// @Accept json
+// @Produce json
+// @Param id path string true "抑制規則 ID"
+// @Param rule body models.InhibitRuleResponse true "抑制規則內容"
+// @Success 200 {object} models.InhibitRuleResponse "成功更新"
+// @Failure 400 {object} response.Response "請求內容無效"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/inhibit-rule/{id} [put]
+func (a *AlertAPI) UpdateInhibitRule(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.JSONError(c, 400, apierrors.ErrInvalidID)
+		return
+	}
+
+	var req models.InhibitRuleResponse
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+		return
+	}
+
+	rule, err := a.muteService.UpdateInhibitRule(user.Realm, idStr, req)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, rule)
+}
+
+// @Summary 刪除告警抑制規則
+// @Description 根據 ID 刪除告警抑制規則
+// @Tags Inhibit
+// @Accept json
+// @Produce json
+// @Param id path string true "抑制規則 ID"
+// @Success 200 {object} response.Response "刪除成功"
+// @Failure 400 {object} response.Response "無效的 ID"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/inhibit-rule/{id} [delete]
+func (a *AlertAPI) DeleteInhibitRule(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.JSONError(c, 400, apierrors.ErrInvalidID)
+		return
+	}
+
+	if err := a.muteService.DeleteInhibitRule(user.Realm, idStr); err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, gin.H{"message": "刪除成功"})
+}
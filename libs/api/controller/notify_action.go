@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"fmt"
+	"html"
+	"io"
+
+	"github.com/detect-viz/shared-lib/alert"
+	"github.com/detect-viz/shared-lib/api/response"
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 通知操作確認頁面
+// @Description 郵件或未設定回呼驗證的通道以簽章連結開啟，顯示操作內容與確認按鈕 (開啟連結不會執行操作)，不需登入
+// @Tags Alert
+// @Produce html
+// @Param token query string true "操作權杖"
+// @Success 200 {string} string "確認頁面"
+// @Failure 403 {object} response.Response "連結已失效或簽章無效"
+// @Failure 404 {object} response.Response "通知不存在"
+// @Router /alert/action [get]
+func (a *AlertAPI) GetNotifyActionPage(c *gin.Context) {
+	token := c.Query("token")
+	action, count, err := a.alertService.DescribeNotifyAction(token)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+
+	label := html.EscapeString(alert.NotifyActionLabel(action))
+	body := fmt.Sprintf(`<p>對此通知的 %d 個告警執行「%s」？</p>
+<form method="post" action="action">
+<input type="hidden" name="token" value="%s">
+<button type="submit">%s</button>
+</form>`, count, label, html.EscapeString(token), label)
+	renderActionPage(c, 200, body)
+}
+
+// @Summary 執行通知操作
+// @Description 由確認頁面送出簽章連結的操作 (確認 / 靜默 1 小時 / 標記恢復)，不需登入
+// @Tags Alert
+// @Accept x-www-form-urlencoded
+// @Produce html
+// @Param token formData string true "操作權杖"
+// @Success 200 {string} string "執行結果"
+// @Failure 403 {object} response.Response "連結已失效或簽章無效"
+// @Failure 404 {object} response.Response "通知不存在"
+// @Router /alert/action [post]
+func (a *AlertAPI) ExecuteNotifyAction(c *gin.Context) {
+	result, err := a.alertService.ExecuteNotifyAction(c.PostForm("token"), "link", "")
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	renderActionPage(c, 200, "<p>"+html.EscapeString(result)+"</p>")
+}
+
+// @Summary Slack 互動按鈕回呼
+// @Description Slack interactive actions 的 Request URL，驗證 X-Slack-Signature 後執行操作，不需登入
+// @Tags Alert
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Success 200 {object} response.Response "執行結果"
+// @Failure 403 {object} response.Response "簽章無效"
+// @Failure 404 {object} response.Response "未設定 Slack 回呼驗證"
+// @Router /alert/callback/slack [post]
+func (a *AlertAPI) SlackActionCallback(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+		return
+	}
+	result, err := a.alertService.HandleSlackCallback(c.GetHeader("X-Slack-Request-Timestamp"), c.GetHeader("X-Slack-Signature"), body)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	// 以臨時訊息回覆按下按鈕的使用者
+	c.JSON(200, gin.H{"response_type": "ephemeral", "replace_original": false, "text": result})
+}
+
+// @Summary LINE postback 回呼
+// @Description LINE Messaging API 的 webhook URL，驗證 X-Line-Signature 後處理 postback 事件，不需登入
+// @Tags Alert
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response "成功回應"
+// @Failure 403 {object} response.Response "簽章無效"
+// @Failure 404 {object} response.Response "未設定 LINE 回呼驗證"
+// @Router /alert/callback/line [post]
+func (a *AlertAPI) LineActionCallback(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+		return
+	}
+	if err := a.alertService.HandleLineCallback(c.GetHeader("X-Line-Signature"), body); err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, nil)
+}
+
+// @Summary Teams 操作回呼
+// @Description Teams outgoing webhook 的回呼網址，驗證 Authorization (HMAC) 後執行 Action.Submit 的操作，不需登入
+// @Tags Alert
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response "執行結果"
+// @Failure 403 {object} response.Response "簽章無效"
+// @Failure 404 {object} response.Response "未設定 Teams 回呼驗證"
+// @Router /alert/callback/teams [post]
+func (a *AlertAPI) TeamsActionCallback(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+		return
+	}
+	result, err := a.alertService.HandleTeamsCallback(c.GetHeader("Authorization"), body)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"type": "message", "text": result})
+}
+
+// @Summary 手動恢復告警
+// @Description 將告警標記為已恢復並記錄恢復人，之後依一般流程發送恢復通知
+// @Tags Alert
+// @Accept json
+// @Produce json
+// @Param id path string true "告警 (Triggered Log) ID"
+// @Success 200 {object} models.TriggeredLog "成功回應"
+// @Failure 400 {object} response.Response "無效的 ID"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/history/{id}/resolve [post]
+func (a *AlertAPI) ResolveTriggeredLog(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	log, err := a.alertService.ResolveTriggeredLog(user.Realm, c.Param("id"), operatorName(user))
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, log)
+}
+
+// @Summary 獲取告警的通知操作紀錄
+// @Description 列出由通知訊息 (Slack / LINE / Teams / 簽章連結) 對告警執行的操作與執行者
+// @Tags Alert
+// @Accept json
+// @Produce json
+// @Param id path string true "告警 (Triggered Log) ID"
+// @Success 200 {array} models.NotifyActionLog "成功回應"
+// @Failure 400 {object} response.Response "無效的 ID"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/history/{id}/actions [get]
+func (a *AlertAPI) ListNotifyActionLogs(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	logs, err := a.alertService.ListNotifyActionLogs(user.Realm, c.Param("id"))
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, logs)
+}
+
+// * 簽章連結的操作頁面
+func renderActionPage(c *gin.Context, status int, body string) {
+	page := `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>告警操作</title></head>
+<body>` + body + `</body></html>`
+	c.Header("Cache-Control", "no-store")
+	c.Data(status, "text/html; charset=utf-8", []byte(page))
+}
@@ -0,0 +1,283 @@
+package controller
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/detect-viz/shared-lib/api/response"
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 獲取值班表列表
+// @Description 取得所有值班表 (含目前及未來的覆蓋)
+// @Tags OnCall
+// @Accept json
+// @Produce json
+// @Param cursor query int false "游標 (上一頁最後一筆的 created_at)"
+// @Param limit query int false "每頁筆數 (預設 10)"
+// @Success 200 {object} response.Response "成功回應"
+// @Failure 400 {object} response.Response "無效的查詢條件"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/oncall-schedule [get]
+func (a *AlertAPI) ListOnCallSchedules(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	cursor := int64(0)
+	limit := 10
+	var err error
+
+	if c.Query("cursor") != "" {
+		cursor, err = strconv.ParseInt(c.Query("cursor"), 10, 64)
+		if err != nil || cursor < 0 {
+			response.JSONError(c, 400, apierrors.ErrInvalidID)
+			return
+		}
+	}
+
+	if c.Query("limit") != "" {
+		limit, err = strconv.Atoi(c.Query("limit"))
+		if err != nil || limit <= 0 {
+			response.JSONError(c, 400, apierrors.ErrInvalidID)
+			return
+		}
+	}
+
+	schedules, nextCursor, err := a.onCallService.List(user.Realm, cursor, limit)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+
+	response.JSONResponse(c, 200, gin.H{
+		"oncall_schedules": schedules,
+		"next_cursor":      nextCursor,
+	}, "success")
+}
+
+// @Summary 獲取單一值班表
+// @Description 根據 ID 獲取值班表 (含目前及未來的覆蓋)
+// @Tags OnCall
+// @Accept json
+// @Produce json
+// @Param id path string true "值班表 ID"
+// @Success 200 {object} models.OnCallScheduleResponse "成功回應"
+// @Failure 400 {object} response.Response "無效的 ID"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/oncall-schedule/{id} [get]
+func (a *AlertAPI) GetOnCallSchedule(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.JSONError(c, 400, apierrors.ErrInvalidID)
+		return
+	}
+
+	schedule, err := a.onCallService.Get(user.Realm, idStr)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, schedule)
+}
+
+// @Summary 創建值班表
+// @Description 新增值班表，輪值的 start 為值班表時區的第一次交接時間 (格式 2006-01-02T15:04)
+// @Tags OnCall
+// @Accept json
+// @Produce json
+// @Param schedule body models.OnCallScheduleResponse true "值班表內容"
+// @Success 201 {object} models.OnCallScheduleResponse "成功創建"
+// @Failure 400 {object} response.Response "請求內容無效"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/oncall-schedule [post]
+func (a *AlertAPI) CreateOnCallSchedule(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	var scheduleResp models.OnCallScheduleResponse
+	if err := c.ShouldBindJSON(&scheduleResp); err != nil {
+		response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+		return
+	}
+
+	schedule, err := a.onCallService.Create(user.Realm, user.ID, &scheduleResp)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONCreated(c, schedule)
+}
+
+// @Summary 更新值班表
+// @Description 根據 ID 更新值班表 (覆蓋請使用 override API)
+// @Tags OnCall
+// @Accept json
+// @Produce json
+// @Param id path string true "值班表 ID"
+// @Param schedule body models.OnCallScheduleResponse true "值班表內容"
+// @Success 200 {object} models.OnCallScheduleResponse "成功更新"
+// @Failure 400 {object} response.Response "請求內容無效"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/oncall-schedule/{id} [put]
+func (a *AlertAPI) UpdateOnCallSchedule(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.JSONError(c, 400, apierrors.ErrInvalidID)
+		return
+	}
+
+	var scheduleResp models.OnCallScheduleResponse
+	if err := c.ShouldBindJSON(&scheduleResp); err != nil {
+		response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+		return
+	}
+
+	// 確保 scheduleResp.ID 來自 path
+	scheduleResp.ID = idStr
+
+	schedule, err := a.onCallService.Update(user.Realm, user.ID, &scheduleResp)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, schedule)
+}
+
+// @Summary 刪除值班表
+// @Description 根據 ID 刪除值班表，仍被規則使用時無法刪除
+// @Tags OnCall
+// @Accept json
+// @Produce json
+// @Param id path string true "值班表 ID"
+// @Success 200 {object} map[string]string "刪除成功"
+// @Failure 400 {object} response.Response "無效的 ID"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 409 {object} response.Response "仍被規則使用"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/oncall-schedule/{id} [delete]
+func (a *AlertAPI) DeleteOnCallSchedule(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.JSONError(c, 400, apierrors.ErrInvalidID)
+		return
+	}
+
+	if err := a.onCallService.Delete(user.Realm, idStr); err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, gin.H{"message": "刪除成功"})
+}
+
+// @Summary 查詢值班人員
+// @Description 查詢指定時間的值班人員，未指定 time 時為目前時間
+// @Tags OnCall
+// @Accept json
+// @Produce json
+// @Param id path string true "值班表 ID"
+// @Param time query string false "查詢時間 (RFC3339 或 Unix 秒)"
+// @Success 200 {object} models.OnCallResponse "成功回應"
+// @Failure 400 {object} response.Response "無效的查詢條件"
+// @Failure 404 {object} response.Response "資源不存在或沒有值班人員"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/oncall-schedule/{id}/oncall [get]
+func (a *AlertAPI) WhoIsOnCall(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.JSONError(c, 400, apierrors.ErrInvalidID)
+		return
+	}
+
+	t := time.Now()
+	if ts := c.Query("time"); ts != "" {
+		if unix, err := strconv.ParseInt(ts, 10, 64); err == nil {
+			t = time.Unix(unix, 0)
+		} else if parsed, err := time.Parse(time.RFC3339, ts); err == nil {
+			t = parsed
+		} else {
+			response.JSONError(c, 400, apierrors.NewAPIError(400, "time 格式錯誤，需為 RFC3339 或 Unix 秒", err))
+			return
+		}
+	}
+
+	onCall, err := a.onCallService.WhoIsOnCall(user.Realm, idStr, t)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, onCall)
+}
+
+// @Summary 新增值班覆蓋
+// @Description 指定期間由特定聯絡人值班 (例如假日代班)，覆蓋優先於輪值
+// @Tags OnCall
+// @Accept json
+// @Produce json
+// @Param id path string true "值班表 ID"
+// @Param override body models.OnCallOverrideResponse true "覆蓋內容"
+// @Success 201 {object} models.OnCallOverrideResponse "成功創建"
+// @Failure 400 {object} response.Response "請求內容無效"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/oncall-schedule/{id}/override [post]
+func (a *AlertAPI) CreateOnCallOverride(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.JSONError(c, 400, apierrors.ErrInvalidID)
+		return
+	}
+
+	var overrideResp models.OnCallOverrideResponse
+	if err := c.ShouldBindJSON(&overrideResp); err != nil {
+		response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+		return
+	}
+
+	override, err := a.onCallService.CreateOverride(user.Realm, idStr, operatorName(user), &overrideResp)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONCreated(c, override)
+}
+
+// @Summary 刪除值班覆蓋
+// @Description 根據 ID 刪除值班覆蓋
+// @Tags OnCall
+// @Accept json
+// @Produce json
+// @Param id path string true "值班表 ID"
+// @Param override_id path string true "覆蓋 ID"
+// @Success 200 {object} map[string]string "刪除成功"
+// @Failure 400 {object} response.Response "無效的 ID"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/oncall-schedule/{id}/override/{override_id} [delete]
+func (a *AlertAPI) DeleteOnCallOverride(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	idStr := c.Param("id")
+	overrideID := c.Param("override_id")
+	if idStr == "" || overrideID == "" {
+		response.JSONError(c, 400, apierrors.ErrInvalidID)
+		return
+	}
+
+	if err := a.onCallService.DeleteOverride(user.Realm, idStr, overrideID); err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, gin.H{"message": "刪除成功"})
+}
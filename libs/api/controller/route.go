@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"github.com/detect-viz/shared-lib/api/response"
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 獲取通知路由樹
+// @Description 取得域的通知路由樹，未設定時回傳空的根路由
+// @Tags Route
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.NotifyRouteResponse "成功回應"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/route [get]
+func (a *AlertAPI) GetNotifyRoute(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+
+	route, err := a.routeService.Get(user.Realm)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, route)
+}
+
+// @Summary 更新通知路由樹
+// @Description 取代域的通知路由樹：子路由依序比對 matchers (= / != / =~ / !~)，符合且未設定 continue 時不再比對後面的子路由，沒有子路由符合時由上層路由接收
+// @Tags Route
+// @Accept json
+// @Produce json
+// @Param route body models.NotifyRouteResponse true "通知路由樹"
+// @Success 200 {object} models.NotifyRouteResponse "成功更新"
+// @Failure 400 {object} response.Response "請求內容無效"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/route [put]
+func (a *AlertAPI) UpdateNotifyRoute(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+
+	var routeResp models.NotifyRouteResponse
+	if err := c.ShouldBindJSON(&routeResp); err != nil {
+		response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+		return
+	}
+
+	route, err := a.routeService.Update(user.Realm, user.ID, &routeResp)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, route)
+}
+
+// @Summary 刪除通知路由樹
+// @Description 刪除域的通知路由樹，告警只通知規則聯絡人與值班人員
+// @Tags Route
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response "刪除成功"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/route [delete]
+func (a *AlertAPI) DeleteNotifyRoute(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+
+	if err := a.routeService.Delete(user.Realm); err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, gin.H{"message": "刪除成功"})
+}
+
+// @Summary 測試通知路由
+// @Description 以假設的告警欄位比對目前的通知路由樹，回傳符合的路由與接收的聯絡人
+// @Tags Route
+// @Accept json
+// @Produce json
+// @Param test body models.RouteTestRequest true "告警欄位"
+// @Success 200 {object} models.RouteTestResponse "成功回應"
+// @Failure 400 {object} response.Response "請求內容無效"
+// @Failure 404 {object} response.Response "尚未設定通知路由"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/route/test [post]
+func (a *AlertAPI) TestNotifyRoute(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+
+	var req models.RouteTestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+		return
+	}
+	if req.Labels == nil {
+		req.Labels = map[string]string{}
+	}
+	// 假設的告警屬於目前的域
+	if _, ok := req.Labels["realm_name"]; !ok {
+		req.Labels["realm_name"] = user.Realm
+	}
+
+	result, err := a.routeService.Test(user.Realm, req.Labels)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, result)
+}
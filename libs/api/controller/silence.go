@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/detect-viz/shared-lib/api/response"
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 獲取靜默列表
+// @Description 取得臨時靜默列表
+// @Tags Silence
+// @Accept json
+// @Produce json
+// @Param state query string false "靜默狀態 (pending/active/expired)"
+// @Param cursor query string false "分頁游標，使用上一頁回傳的 next_cursor"
+// @Param limit query int false "每頁筆數 (預設 10，最多 1000)"
+// @Success 200 {object} response.Response "成功回應"
+// @Failure 400 {object} response.Response "無效的查詢條件"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/silence [get]
+func (a *AlertAPI) ListSilences(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit < 0 {
+			response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+			return
+		}
+	}
+
+	silences, nextCursor, err := a.muteService.ListSilences(user.Realm, c.Query("state"), c.Query("cursor"), limit)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONResponse(c, 200, gin.H{
+		"silences":    silences,
+		"next_cursor": nextCursor,
+	}, "success")
+}
+
+// @Summary 獲取單一靜默
+// @Description 根據 ID 獲取臨時靜默
+// @Tags Silence
+// @Accept json
+// @Produce json
+// @Param id path string true "靜默 ID"
+// @Success 200 {object} models.SilenceResponse "成功回應"
+// @Failure 400 {object} response.Response "無效的 ID"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/silence/{id} [get]
+func (a *AlertAPI) GetSilence(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.JSONError(c, 400, apierrors.ErrInvalidID)
+		return
+	}
+
+	silence, err := a.muteService.GetSilence(user.Realm, idStr)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, silence)
+}
+
+// @Summary 創建靜默
+// @Description 新增臨時靜默，可指定 triggered_log_id 由告警建立 (未提供 matchers 時以告警的資源、分區與指標規則比對)
+// @Tags Silence
+// @Accept json
+// @Produce json
+// @Param silence body models.SilenceResponse true "靜默內容"
+// @Success 201 {object} models.SilenceResponse "成功創建"
+// @Failure 400 {object} response.Response "請求內容無效"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/silence [post]
+func (a *AlertAPI) CreateSilence(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	var req models.SilenceResponse
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.JSONError(c, 400, apierrors.ErrInvalidPayload)
+		return
+	}
+
+	silence, err := a.muteService.CreateSilence(user.Realm, operatorName(user), req)
+	if err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONCreated(c, silence)
+}
+
+// @Summary 結束靜默
+// @Description 讓臨時靜默立即失效 (保留紀錄)
+// @Tags Silence
+// @Accept json
+// @Produce json
+// @Param id path string true "靜默 ID"
+// @Success 200 {object} response.Response "成功回應"
+// @Failure 400 {object} response.Response "無效的 ID"
+// @Failure 404 {object} response.Response "資源不存在"
+// @Failure 500 {object} response.Response "伺服器錯誤"
+// @Security ApiKeyAuth
+// @Router /alert/silence/{id} [delete]
+func (a *AlertAPI) ExpireSilence(c *gin.Context) {
+	user := c.Keys["user"].(models.SSOUser)
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.JSONError(c, 400, apierrors.ErrInvalidID)
+		return
+	}
+
+	if err := a.muteService.ExpireSilence(user.Realm, idStr); err != nil {
+		respondMonitorError(c, err)
+		return
+	}
+	response.JSONSuccess(c, gin.H{"message": "靜默已結束"})
+}
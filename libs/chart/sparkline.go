@@ -0,0 +1,250 @@
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+
+	"github.com/detect-viz/shared-lib/models"
+)
+
+// 預設尺寸
+const (
+	DefaultWidth  = 320
+	DefaultHeight = 80
+)
+
+// 預設顏色
+var (
+	BackgroundColor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	LineColor       = color.RGBA{0x1f, 0x4e, 0x79, 0xff}
+	CritColor       = color.RGBA{0xe5, 0x48, 0x4d, 0xff}
+	WarnColor       = color.RGBA{0xf5, 0xa5, 0x24, 0xff}
+	InfoColor       = color.RGBA{0x3e, 0x9c, 0xf5, 0xff}
+)
+
+// 圖內留白 (像素)
+const padding = 4
+
+// Threshold 閾值線
+type Threshold struct {
+	Name  string
+	Value float64
+	Color color.RGBA
+}
+
+// Sparkline 指標走勢圖：數據點折線加上閾值虛線，最後一個數據點以圓點標示
+type Sparkline struct {
+	Values     []models.MetricValue
+	Thresholds []Threshold
+	Width      int
+	Height     int
+}
+
+// point 像素座標
+type point struct {
+	x, y float64
+}
+
+// PNG 輸出 PNG 圖片
+func (s Sparkline) PNG() ([]byte, error) {
+	width, height := s.size()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, BackgroundColor)
+		}
+	}
+
+	series, thresholds := s.layout()
+	for _, t := range thresholds {
+		drawDashedLine(img, point{padding, t.y}, point{float64(width - padding), t.y}, t.color)
+	}
+	for i := 1; i < len(series); i++ {
+		drawLine(img, series[i-1], series[i], LineColor, 1)
+	}
+	if len(series) > 0 {
+		fillCircle(img, series[len(series)-1], 2, LineColor)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG 輸出 SVG 圖片
+func (s Sparkline) SVG() string {
+	width, height := s.size()
+	series, thresholds := s.layout()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="%s"/>`, width, height, hexColor(BackgroundColor))
+	for _, t := range thresholds {
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="%s" stroke-width="1" stroke-dasharray="4 3"/>`,
+			padding, t.y, width-padding, t.y, hexColor(t.color))
+	}
+	if len(series) > 0 {
+		coords := make([]string, len(series))
+		for i, p := range series {
+			coords[i] = fmt.Sprintf("%.1f,%.1f", p.x, p.y)
+		}
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2" stroke-linejoin="round"/>`,
+			strings.Join(coords, " "), hexColor(LineColor))
+		last := series[len(series)-1]
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="2.5" fill="%s"/>`, last.x, last.y, hexColor(LineColor))
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+func (s Sparkline) size() (int, int) {
+	width, height := s.Width, s.Height
+	if width <= 2*padding {
+		width = DefaultWidth
+	}
+	if height <= 2*padding {
+		height = DefaultHeight
+	}
+	return width, height
+}
+
+// thresholdLine 閾值線的像素位置
+type thresholdLine struct {
+	y     float64
+	color color.RGBA
+}
+
+// layout 將數據點與閾值換算為像素座標
+// Y 軸範圍涵蓋所有數據點與閾值並上下保留 10%；X 軸依時間戳，時間戳相同時依序平均分布
+func (s Sparkline) layout() ([]point, []thresholdLine) {
+	width, height := s.size()
+
+	minV, maxV := math.Inf(1), math.Inf(-1)
+	for _, v := range s.Values {
+		minV = math.Min(minV, v.Value)
+		maxV = math.Max(maxV, v.Value)
+	}
+	for _, t := range s.Thresholds {
+		minV = math.Min(minV, t.Value)
+		maxV = math.Max(maxV, t.Value)
+	}
+	if math.IsInf(minV, 0) {
+		return nil, nil
+	}
+	span := maxV - minV
+	if span == 0 {
+		span = math.Max(math.Abs(maxV), 1)
+	}
+	minV -= span * 0.1
+	maxV += span * 0.1
+
+	plotW := float64(width - 2*padding)
+	plotH := float64(height - 2*padding)
+	toY := func(v float64) float64 {
+		return padding + plotH*(maxV-v)/(maxV-minV)
+	}
+
+	series := make([]point, len(s.Values))
+	if len(s.Values) > 0 {
+		first, last := s.Values[0].Timestamp, s.Values[len(s.Values)-1].Timestamp
+		for i, v := range s.Values {
+			var ratio float64
+			switch {
+			case last > first:
+				ratio = float64(v.Timestamp-first) / float64(last-first)
+			case len(s.Values) > 1:
+				ratio = float64(i) / float64(len(s.Values)-1)
+			}
+			series[i] = point{padding + plotW*ratio, toY(v.Value)}
+		}
+	}
+
+	thresholds := make([]thresholdLine, len(s.Thresholds))
+	for i, t := range s.Thresholds {
+		thresholds[i] = thresholdLine{y: toY(t.Value), color: t.Color}
+	}
+	return series, thresholds
+}
+
+// drawLine 以 Bresenham 演算法畫線，radius 為線寬半徑
+func drawLine(img *image.RGBA, from, to point, c color.RGBA, radius int) {
+	x0, y0 := int(math.Round(from.x)), int(math.Round(from.y))
+	x1, y1 := int(math.Round(to.x)), int(math.Round(to.y))
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		fillSquare(img, x0, y0, radius, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+// drawDashedLine 畫水平虛線 (4 像素實線、3 像素間隔)
+func drawDashedLine(img *image.RGBA, from, to point, c color.RGBA) {
+	y := int(math.Round(from.y))
+	for x := int(from.x); x <= int(to.x); x++ {
+		if (x-int(from.x))%7 < 4 {
+			setPixel(img, x, y, c)
+		}
+	}
+}
+
+func fillCircle(img *image.RGBA, center point, radius int, c color.RGBA) {
+	cx, cy := int(math.Round(center.x)), int(math.Round(center.y))
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			if x*x+y*y <= radius*radius {
+				setPixel(img, cx+x, cy+y, c)
+			}
+		}
+	}
+}
+
+func fillSquare(img *image.RGBA, cx, cy, radius int, c color.RGBA) {
+	for y := cy - radius; y <= cy+radius; y++ {
+		for x := cx - radius; x <= cx+radius; x++ {
+			setPixel(img, x, y, c)
+		}
+	}
+}
+
+func setPixel(img *image.RGBA, x, y int, c color.RGBA) {
+	if (image.Point{x, y}).In(img.Rect) {
+		img.SetRGBA(x, y, c)
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/detect-viz/shared-lib/apierrors"
 	"github.com/detect-viz/shared-lib/auth/keycloak"
@@ -51,6 +53,12 @@ func (s *serviceImpl) SetContactChangeCallback(callback ContactChangeCallback) {
 // 創建通知管道
 func (s *serviceImpl) Create(realm string, contactResp *models.ContactResponse) (*models.ContactResponse, error) {
 	contact := s.FromResponse(*contactResp, realm)
+	if err := validateRetryPolicy(&contact); err != nil {
+		return nil, err
+	}
+	if err := validateNotifyLimits(&contact); err != nil {
+		return nil, err
+	}
 	createdContact, err := s.mysql.CreateContact(&contact)
 	if err != nil {
 		return nil, err
@@ -65,6 +73,45 @@ func (s *serviceImpl) Create(realm string, contactResp *models.ContactResponse)
 	return &response, nil
 }
 
+// 檢查重試設定，retry_delay 未設定時使用預設 5m
+func validateRetryPolicy(contact *models.Contact) error {
+	if contact.MaxRetry < 0 {
+		return apierrors.NewAPIError(400, "max_retry 不可小於 0", nil)
+	}
+	if contact.RetryDelay == "" {
+		contact.RetryDelay = "5m"
+	}
+	delay, err := time.ParseDuration(contact.RetryDelay)
+	if err != nil || delay <= 0 {
+		return apierrors.NewAPIError(400, fmt.Sprintf("retry_delay 格式無效 [%s]", contact.RetryDelay), err)
+	}
+	return nil
+}
+
+// 檢查流量限制、告警風暴與告警分組設定，未設定時使用全域設定
+func validateNotifyLimits(contact *models.Contact) error {
+	if v := contact.Config["rate_limit_per_minute"]; v != "" {
+		if rate, err := strconv.ParseFloat(v, 64); err != nil || rate < 0 {
+			return apierrors.NewAPIError(400, fmt.Sprintf("rate_limit_per_minute 格式無效 [%s]", v), err)
+		}
+	}
+	for _, key := range []string{"rate_limit_burst", "storm_threshold"} {
+		if v := contact.Config[key]; v != "" {
+			if n, err := strconv.Atoi(v); err != nil || n < 0 {
+				return apierrors.NewAPIError(400, fmt.Sprintf("%s 格式無效 [%s]", key, v), err)
+			}
+		}
+	}
+	for _, key := range []string{"group_wait", "group_interval", "repeat_interval"} {
+		if v := contact.Config[key]; v != "" {
+			if d, err := time.ParseDuration(v); err != nil || d < 0 {
+				return apierrors.NewAPIError(400, fmt.Sprintf("%s 格式無效 [%s]", key, v), err)
+			}
+		}
+	}
+	return nil
+}
+
 // 獲取通知管道
 func (s *serviceImpl) Get(id string) (*models.ContactResponse, error) {
 	// 將 ID 從 string 轉換為 []byte
@@ -83,7 +130,6 @@ func (s *serviceImpl) Get(id string) (*models.ContactResponse, error) {
 		if err != nil {
 			return nil, err
 		}
-		fmt.Printf("cfg: %v\n", cfg)
 		contact.Config = cfg
 	}
 
@@ -110,6 +156,12 @@ func (s *serviceImpl) List(realm string, cursor int64, limit int) ([]models.Cont
 // 更新通知管道
 func (s *serviceImpl) Update(realm string, contactResp *models.ContactResponse) (*models.ContactResponse, error) {
 	contact := s.FromResponse(*contactResp, realm)
+	if err := validateRetryPolicy(&contact); err != nil {
+		return nil, err
+	}
+	if err := validateNotifyLimits(&contact); err != nil {
+		return nil, err
+	}
 	updatedContact, err := s.mysql.UpdateContact(&contact)
 	if err != nil {
 		return nil, err
@@ -213,7 +265,7 @@ func (s *serviceImpl) GetContactsByRuleID(ruleID string) ([]models.ContactRespon
 
 // 獲取所有通知管道方法
 func (s *serviceImpl) GetNotifyMethods() []string {
-	return []string{"email", "slack", "discord", "teams", "webex", "webhook", "line"}
+	return s.notifyService.Drivers()
 }
 
 // 根據通知類型獲取對應的配置
@@ -288,19 +340,19 @@ func getNotifyOptions(tenantConfigs map[string]map[string]string) map[string]map
 	options := map[string]map[string][]string{
 		"email": {
 			"required": {"to"},
-			"optional": {"cc", "bcc", "from"},
+			"optional": {"cc", "bcc", "from", "reply_to", "attach_csv", "attach_chart"},
 		},
 		"line": {
 			"required": {"to"},
-			"optional": {},
+			"optional": {"attach_chart"},
 		},
 		"slack": {
 			"required": {"url"},
-			"optional": {},
+			"optional": {"token", "channel", "attach_chart"},
 		},
 		"discord": {
 			"required": {"url"},
-			"optional": {},
+			"optional": {"attach_chart"},
 		},
 		"teams": {
 			"required": {"url"},
@@ -312,10 +364,16 @@ func getNotifyOptions(tenantConfigs map[string]map[string]string) map[string]map
 		},
 		"webhook": {
 			"required": {"url"},
-			"optional": {},
+			"optional": {"method", "content_type", "body_template", "headers", "auth_type", "username", "password", "token", "hmac_secret", "hmac_header", "hmac_algorithm"},
 		},
 	}
 
+	// 所有通道共用的流量限制與告警分組設定
+	for _, option := range options {
+		option["optional"] = append(option["optional"], "rate_limit_per_minute", "rate_limit_burst", "storm_threshold",
+			"group_by", "group_wait", "group_interval", "repeat_interval")
+	}
+
 	if tenantConfigs == nil {
 		return options
 	}
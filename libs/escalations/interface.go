@@ -0,0 +1,26 @@
+package escalations
+
+import (
+	"github.com/detect-viz/shared-lib/models"
+)
+
+// Service 升級策略服務接口
+type Service interface {
+	// Create 創建升級策略
+	Create(realm, user string, policyResp *models.EscalationPolicyResponse) (*models.EscalationPolicyResponse, error)
+
+	// Get 獲取升級策略
+	Get(realm, id string) (*models.EscalationPolicyResponse, error)
+
+	// List 獲取升級策略列表
+	List(realm string, cursor int64, limit int) ([]models.EscalationPolicyResponse, int64, error)
+
+	// Update 更新升級策略
+	Update(realm, user string, policyResp *models.EscalationPolicyResponse) (*models.EscalationPolicyResponse, error)
+
+	// Delete 刪除升級策略
+	Delete(realm, id string) error
+
+	// ToResponse 將 EscalationPolicy 轉換為 EscalationPolicyResponse
+	ToResponse(policy models.EscalationPolicy) models.EscalationPolicyResponse
+}
@@ -0,0 +1,232 @@
+package escalations
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/infra/logger"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/storage/mysql"
+	"github.com/google/uuid"
+	"github.com/google/wire"
+)
+
+var EscalationSet = wire.NewSet(
+	NewService,
+	wire.Bind(new(Service), new(*serviceImpl)),
+)
+
+// Service 升級策略服務
+type serviceImpl struct {
+	mysql  *mysql.Client
+	logger logger.Logger
+}
+
+// 創建升級策略服務
+func NewService(mysql *mysql.Client, logger logger.Logger) *serviceImpl {
+	return &serviceImpl{
+		mysql:  mysql,
+		logger: logger,
+	}
+}
+
+// 創建升級策略
+func (s *serviceImpl) Create(realm, user string, policyResp *models.EscalationPolicyResponse) (*models.EscalationPolicyResponse, error) {
+	policy, err := s.fromResponse(*policyResp, realm)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validate(realm, policy); err != nil {
+		return nil, err
+	}
+	policy.CreatedBy = &user
+	policy.UpdatedBy = &user
+
+	created, err := s.mysql.CreateEscalationPolicy(&policy)
+	if err != nil {
+		return nil, err
+	}
+	response := s.ToResponse(*created)
+	return &response, nil
+}
+
+// 獲取升級策略
+func (s *serviceImpl) Get(realm, id string) (*models.EscalationPolicyResponse, error) {
+	policy, err := s.getRealmPolicy(realm, id)
+	if err != nil {
+		return nil, err
+	}
+	response := s.ToResponse(*policy)
+	return &response, nil
+}
+
+// 獲取升級策略列表
+func (s *serviceImpl) List(realm string, cursor int64, limit int) ([]models.EscalationPolicyResponse, int64, error) {
+	policies, nextCursor, err := s.mysql.ListEscalationPolicies(realm, cursor, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]models.EscalationPolicyResponse, len(policies))
+	for i, policy := range policies {
+		responses[i] = s.ToResponse(policy)
+	}
+	return responses, nextCursor, nil
+}
+
+// 更新升級策略
+func (s *serviceImpl) Update(realm, user string, policyResp *models.EscalationPolicyResponse) (*models.EscalationPolicyResponse, error) {
+	if _, err := s.getRealmPolicy(realm, policyResp.ID); err != nil {
+		return nil, err
+	}
+	policy, err := s.fromResponse(*policyResp, realm)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validate(realm, policy); err != nil {
+		return nil, err
+	}
+	policy.UpdatedBy = &user
+
+	updated, err := s.mysql.UpdateEscalationPolicy(&policy)
+	if err != nil {
+		return nil, err
+	}
+	response := s.ToResponse(*updated)
+	return &response, nil
+}
+
+// 刪除升級策略
+func (s *serviceImpl) Delete(realm, id string) error {
+	policy, err := s.getRealmPolicy(realm, id)
+	if err != nil {
+		return err
+	}
+	return s.mysql.DeleteEscalationPolicy(policy.ID)
+}
+
+// 取得指定域內的升級策略
+func (s *serviceImpl) getRealmPolicy(realm, id string) (*models.EscalationPolicy, error) {
+	policyID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, apierrors.ErrInvalidID
+	}
+	policy, err := s.mysql.GetEscalationPolicy(policyID[:])
+	if err != nil {
+		return nil, err
+	}
+	if policy.RealmName != realm {
+		return nil, apierrors.ErrNotFound
+	}
+	return policy, nil
+}
+
+// 檢查升級策略設定
+// 第一步為告警發送時立即通知，後續步驟的延遲必須遞增
+func (s *serviceImpl) validate(realm string, policy models.EscalationPolicy) error {
+	if policy.Name == "" {
+		return apierrors.NewAPIError(400, "升級策略名稱不可為空", nil)
+	}
+	if len(policy.Steps) == 0 {
+		return apierrors.NewAPIError(400, "至少需要一個升級步驟", nil)
+	}
+
+	var prevDelay time.Duration
+	for i, step := range policy.Steps {
+		if len(step.Contacts) == 0 {
+			return apierrors.NewAPIError(400, fmt.Sprintf("升級步驟至少需要一個聯絡人 [step:%d]", i), nil)
+		}
+		delay, err := ParseDelay(step.Delay)
+		if err != nil {
+			return apierrors.NewAPIError(400, fmt.Sprintf("升級步驟延遲格式錯誤 [step:%d]", i), err)
+		}
+		if i == 0 && delay != 0 {
+			return apierrors.NewAPIError(400, "第一個升級步驟不可設定延遲", nil)
+		}
+		if i > 0 && delay <= prevDelay {
+			return apierrors.NewAPIError(400, fmt.Sprintf("升級步驟延遲必須遞增 [step:%d]", i), nil)
+		}
+		prevDelay = delay
+
+		for _, contact := range step.Contacts {
+			c, err := s.mysql.GetContact(contact.ID)
+			if err != nil || c.RealmName != realm {
+				return apierrors.NewAPIError(400, fmt.Sprintf("聯絡人不存在 [step:%d]", i), err)
+			}
+		}
+	}
+	return nil
+}
+
+// ParseDelay 解析升級步驟延遲，空值視為立即通知
+func ParseDelay(delay string) (time.Duration, error) {
+	if delay == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(delay)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("延遲不可為負數 [delay:%s]", delay)
+	}
+	return d, nil
+}
+
+// 將 EscalationPolicy 轉換為 EscalationPolicyResponse
+func (s *serviceImpl) ToResponse(policy models.EscalationPolicy) models.EscalationPolicyResponse {
+	steps := make([]models.EscalationStepResponse, 0, len(policy.Steps))
+	for _, step := range policy.Steps {
+		contacts := make([]models.RuleContactResponse, 0, len(step.Contacts))
+		for _, contact := range step.Contacts {
+			contacts = append(contacts, models.RuleContactResponse{
+				ID:         hex.EncodeToString(contact.ID),
+				Name:       contact.Name,
+				Type:       contact.ChannelType,
+				Severities: contact.Severities,
+			})
+		}
+		steps = append(steps, models.EscalationStepResponse{
+			Delay:    step.Delay,
+			Contacts: contacts,
+		})
+	}
+
+	return models.EscalationPolicyResponse{
+		ID:          hex.EncodeToString(policy.ID),
+		Name:        policy.Name,
+		Description: policy.Description,
+		Steps:       steps,
+	}
+}
+
+// 將 EscalationPolicyResponse 轉換為 EscalationPolicy
+func (s *serviceImpl) fromResponse(resp models.EscalationPolicyResponse, realm string) (models.EscalationPolicy, error) {
+	policy := models.EscalationPolicy{
+		RealmName:   realm,
+		Name:        resp.Name,
+		Description: resp.Description,
+	}
+	if resp.ID != "" {
+		id, err := uuid.Parse(resp.ID)
+		if err != nil {
+			return policy, apierrors.ErrInvalidID
+		}
+		policy.ID = id[:]
+	}
+
+	for _, stepResp := range resp.Steps {
+		step := models.EscalationStep{Delay: stepResp.Delay}
+		for _, contactResp := range stepResp.Contacts {
+			id, err := uuid.Parse(contactResp.ID)
+			if err != nil {
+				return policy, apierrors.ErrInvalidID
+			}
+			step.Contacts = append(step.Contacts, models.Contact{ID: id[:]})
+		}
+		policy.Steps = append(policy.Steps, step)
+	}
+	return policy, nil
+}
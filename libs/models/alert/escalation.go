@@ -0,0 +1,45 @@
+package alert
+
+import (
+	"github.com/detect-viz/shared-lib/models/common"
+)
+
+// 升級策略：依序通知的步驟，告警在 Delay 內未確認或恢復即通知下一步驟
+type EscalationPolicy struct {
+	RealmName   string           `json:"realm_name"`
+	ID          []byte           `json:"id" gorm:"primaryKey"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Steps       []EscalationStep `json:"steps" gorm:"foreignKey:PolicyID;references:ID"`
+	common.AuditUserModel
+	common.AuditTimeModel
+}
+
+// 升級步驟，StepOrder 從 0 開始
+type EscalationStep struct {
+	PolicyID  []byte    `json:"-" gorm:"type:binary(16);primaryKey"`
+	StepOrder int       `json:"step_order" gorm:"primaryKey"`
+	Delay     string    `json:"delay"` // 告警觸發後多久通知此步驟 (第一步固定為立即通知)
+	Contacts  []Contact `json:"contacts" gorm:"-"`
+}
+
+// 綁定升級步驟聯絡人
+type EscalationStepContact struct {
+	PolicyID  []byte `gorm:"type:binary(16);primaryKey"`
+	StepOrder int    `gorm:"primaryKey"`
+	ContactID []byte `gorm:"type:binary(16);primaryKey"`
+}
+
+// * 升級策略設定
+type EscalationPolicyResponse struct {
+	ID          string                   `json:"id"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Steps       []EscalationStepResponse `json:"steps"`
+}
+
+// * 升級步驟設定
+type EscalationStepResponse struct {
+	Delay    string                `json:"delay"`
+	Contacts []RuleContactResponse `json:"contacts"`
+}
@@ -15,10 +15,20 @@ type MetricRule struct {
 	Duration             string    `yaml:"duration" json:"duration"`
 	Operator             string    `yaml:"operator" json:"operator"`
 	Thresholds           Threshold `yaml:"thresholds" json:"thresholds"`
+	Percentile           float64   `yaml:"percentile,omitempty" json:"percentile,omitempty"`   // percentile 偵測百分位，預設 95
+	MinSamples           int       `yaml:"min_samples,omitempty" json:"min_samples,omitempty"` // zscore 偵測最少樣本數，預設 5
+	Expression           string    `yaml:"expression,omitempty" json:"expression,omitempty"`   // expression 偵測的複合表達式，例如 "watt:L1 > 3000 && voltage < 200"
 }
 
 type Threshold struct {
 	Info *float64 `yaml:"info" json:"info"`
 	Warn *float64 `yaml:"warn" json:"warn"`
 	Crit float64  `yaml:"crit" json:"crit"`
+	// 區間運算子 (inside / outside) 的下限閾值
+	InfoLower *float64 `yaml:"info_lower,omitempty" json:"info_lower,omitempty"`
+	WarnLower *float64 `yaml:"warn_lower,omitempty" json:"warn_lower,omitempty"`
+	CritLower *float64 `yaml:"crit_lower,omitempty" json:"crit_lower,omitempty"`
+	// 恢復閾值 (hysteresis)
+	Recovery      *float64 `yaml:"recovery,omitempty" json:"recovery,omitempty"`
+	RecoveryLower *float64 `yaml:"recovery_lower,omitempty" json:"recovery_lower,omitempty"`
 }
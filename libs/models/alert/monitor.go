@@ -0,0 +1,64 @@
+package alert
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// * 監控頁面查詢條件 (告警狀態 / 告警歷史共用)
+type MonitorQuery struct {
+	RealmName     string `form:"-"`
+	ResourceName  string `form:"resource_name"`
+	PartitionName string `form:"partition_name"`
+	MetricRuleUID string `form:"metric_rule_uid"`
+	Severity      string `form:"severity"`   // info / warn / crit
+	State         string `form:"state"`      // 告警狀態: alerting / resolved / normal / disabled
+	StartTime     int64  `form:"start_time"` // 時間範圍起 (unix 秒)
+	EndTime       int64  `form:"end_time"`   // 時間範圍迄 (unix 秒)
+	Cursor        string `form:"cursor"`     // 上一頁的 next_cursor
+	Limit         int    `form:"limit"`      // 最大筆數
+	Order         string `form:"order"`      // asc / desc
+}
+
+// * 監控頁面分頁位置：上一頁最後一筆的排序時間與 ID，排序時間相同的資料以 ID 區分
+type MonitorCursor struct {
+	Time int64
+	ID   []byte
+}
+
+// ParseMonitorCursor 解析 "{排序時間}_{ID hex}" 格式的 cursor，空字串表示第一頁
+func ParseMonitorCursor(cursor string) (*MonitorCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	timeStr, idStr, ok := strings.Cut(cursor, "_")
+	if !ok {
+		return nil, errors.New("invalid cursor")
+	}
+	t, err := strconv.ParseInt(timeStr, 10, 64)
+	if err != nil || t < 0 {
+		return nil, errors.New("invalid cursor")
+	}
+	id, err := hex.DecodeString(idStr)
+	if err != nil || len(id) == 0 {
+		return nil, errors.New("invalid cursor")
+	}
+	return &MonitorCursor{Time: t, ID: id}, nil
+}
+
+func (c MonitorCursor) String() string {
+	return fmt.Sprintf("%d_%x", c.Time, c.ID)
+}
+
+// * 告警狀態總覽 (RuleState + 規則 / 監控對象資訊)
+type RuleStateOverview struct {
+	RealmName     string   `json:"realm_name"`
+	ResourceName  string   `json:"resource_name"`
+	PartitionName string   `json:"partition_name"`
+	MetricRuleUID string   `json:"metric_rule_uid"`
+	SilencedBy    []string `json:"silenced_by,omitempty" gorm:"-"` // 目前生效中且符合的靜默 ID
+	RuleState
+}
@@ -0,0 +1,15 @@
+package alert
+
+// NotifyActionLog 由通知訊息執行的操作紀錄 (每個告警一筆)
+type NotifyActionLog struct {
+	RealmName      string `json:"realm_name" gorm:"index"`
+	ID             []byte `json:"id" gorm:"primaryKey"`
+	NotifyLogID    []byte `json:"notify_log_id" gorm:"uniqueIndex:uk_notify_action_logs_action"`
+	TriggeredLogID []byte `json:"triggered_log_id" gorm:"index;uniqueIndex:uk_notify_action_logs_action"`
+	ContactID      []byte `json:"contact_id"`
+	ChannelType    string `json:"channel_type"`                                           // 回呼來源：slack / line / teams / link
+	Action         string `json:"action" gorm:"uniqueIndex:uk_notify_action_logs_action"` // ack / silence / resolve
+	Actor          string `json:"actor"`                                                  // 執行者 (通道的使用者名稱或 ID)
+	Error          string `json:"error,omitempty"`                                        // 操作失敗原因，成功時為空
+	CreatedAt      int64  `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,14 @@
+package alert
+
+// NotifyDelivery 告警對聯絡人的第一次異常通知
+// 告警依各聯絡人的分組設定分別發送，所有聯絡人的分組都發送後才更新告警的通知狀態；
+// 郵件聯絡人另記錄第一封異常通知的 Message-ID，之後的郵件以此串接
+type NotifyDelivery struct {
+	RealmName      string `json:"realm_name" gorm:"index"`
+	TriggeredLogID []byte `json:"triggered_log_id" gorm:"primaryKey"`
+	ContactID      []byte `json:"contact_id" gorm:"primaryKey"`
+	NotifyLogID    []byte `json:"notify_log_id"`
+	State          string `json:"state"`                // 告警通知狀態：sent / delayed / dead_letter / suppressed
+	MessageID      string `json:"message_id,omitempty"` // 郵件聯絡人的郵件串起點 Message-ID (不含網域)
+	CreatedAt      int64  `json:"created_at" gorm:"autoCreateTime"`
+}
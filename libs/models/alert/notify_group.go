@@ -0,0 +1,48 @@
+package alert
+
+import (
+	"time"
+
+	"github.com/detect-viz/shared-lib/models/common"
+)
+
+// GroupPolicy 告警分組設定
+// GroupBy 為內建欄位 (realm_name / resource_name / partition_name / metric_rule_uid / category / severity) 或規則標籤，
+// 為空時同一聯絡人的告警合併為一組；時間設定為 Go duration 字串 (例如 30s / 5m / 4h)
+type GroupPolicy struct {
+	GroupBy        []string `json:"group_by" mapstructure:"group_by"`
+	GroupWait      string   `json:"group_wait" mapstructure:"group_wait"`           // 新分組第一次通知前的等待時間，用於收集同時發生的告警
+	GroupInterval  string   `json:"group_interval" mapstructure:"group_interval"`   // 已通知的分組有新告警時，兩次通知的最短間隔
+	RepeatInterval string   `json:"repeat_interval" mapstructure:"repeat_interval"` // 仍在告警中的分組重新通知的間隔，空值表示不重複通知
+}
+
+// Durations 解析時間設定，未設定的欄位回傳 0
+func (p GroupPolicy) Durations() (wait, interval, repeat time.Duration, err error) {
+	parse := func(value string) (time.Duration, error) {
+		if value == "" {
+			return 0, nil
+		}
+		return time.ParseDuration(value)
+	}
+	if wait, err = parse(p.GroupWait); err != nil {
+		return
+	}
+	if interval, err = parse(p.GroupInterval); err != nil {
+		return
+	}
+	repeat, err = parse(p.RepeatInterval)
+	return
+}
+
+// NotifyGroup 告警分組的通知狀態
+type NotifyGroup struct {
+	RealmName      string         `json:"realm_name" gorm:"index"`
+	ID             []byte         `json:"id" gorm:"primaryKey"`
+	ContactID      []byte         `json:"contact_id"`
+	GroupKey       string         `json:"group_key"`                  // 聯絡人與分組欄位值的雜湊
+	Labels         common.JSONMap `json:"labels" gorm:"type:json"`    // 分組欄位值
+	FirstSeenAt    int64          `json:"first_seen_at"`              // 分組建立 (開始 group_wait) 的時間
+	LastNotifiedAt *int64         `json:"last_notified_at,omitempty"` // 最後一次通知時間，尚未通知時為 nil
+	CreatedAt      int64          `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      int64          `json:"updated_at" gorm:"autoUpdateTime"`
+}
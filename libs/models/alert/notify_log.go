@@ -39,11 +39,17 @@ type NotifyLog struct {
 	ID              []byte             `json:"id" gorm:"primaryKey"`
 	SentAt          *int64             `json:"sent_at,omitempty"`
 	State           string             `json:"state"`
+	NotifyType      string             `json:"notify_type" gorm:"default:alerting"` // alerting / resolved
 	RetryCounter    int                `json:"retry_counter" gorm:"default:0"`
 	LastRetryAt     *int64             `json:"last_retry_at,omitempty"`
+	NextRetryAt     *int64             `json:"next_retry_at,omitempty"`
+	ErrorClass      string             `json:"error_class,omitempty"` // retryable / permanent
 	ErrorMessages   *common.JSONMap    `json:"error_messages" gorm:"type:json"`
 	TriggeredLogIDs TriggeredLogIDsMap `json:"triggered_log_ids" gorm:"type:json"`
 	ContactID       []byte             `json:"contact_id"`
 	ChannelType     string             `json:"channel_type"`
 	ContactSnapshot common.JSONMap     `json:"contact_snapshot" gorm:"type:json"`
+	CreatedAt       int64              `json:"created_at" gorm:"autoCreateTime"`
+	// 因流量限制未發送的告警數；已發送的通知為自上次發送後累計被限流的告警數
+	SuppressedCount int `json:"suppressed_count" gorm:"default:0"`
 }
@@ -0,0 +1,228 @@
+package alert
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/detect-viz/shared-lib/models/common"
+)
+
+// 值班輪值類型
+const (
+	OnCallRotationDaily  = "daily"
+	OnCallRotationWeekly = "weekly"
+)
+
+// 值班來源
+const (
+	OnCallSourceOverride = "override"
+	OnCallSourceRotation = "rotation"
+)
+
+// 輪值開始時間格式 (值班表時區)
+const OnCallTimeLayout = "2006-01-02T15:04"
+
+// 值班表：依輪值計算值班聯絡人，覆蓋 (例如假日代班) 優先於輪值，後面的輪值優先於前面的輪值
+type OnCallSchedule struct {
+	RealmName   string           `json:"realm_name"`
+	ID          []byte           `json:"id" gorm:"primaryKey"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Timezone    string           `json:"timezone" gorm:"default:'UTC'"`
+	Rotations   []OnCallRotation `json:"rotations" gorm:"type:json;serializer:json"`
+	Overrides   []OnCallOverride `json:"overrides" gorm:"foreignKey:ScheduleID;references:ID"`
+	common.AuditUserModel
+	common.AuditTimeModel
+}
+
+func (OnCallSchedule) TableName() string {
+	return "oncall_schedules"
+}
+
+// 輪值設定，Start 為第一次交接時間，之後每 Interval 天 (daily) 或週 (weekly) 於相同時刻交接
+type OnCallRotation struct {
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`          // daily / weekly
+	Interval     int      `json:"interval"`      // 交接間隔，預設 1
+	Start        string   `json:"start"`         // 2006-01-02T15:04
+	End          string   `json:"end,omitempty"` // 未設定時持續輪值
+	Participants []string `json:"participants"`  // 依序輪值的聯絡人 ID (hex)
+}
+
+// 值班覆蓋：期間內由指定聯絡人值班
+type OnCallOverride struct {
+	ID         []byte `json:"id" gorm:"primaryKey"`
+	ScheduleID []byte `json:"-" gorm:"type:binary(16);index"`
+	ContactID  []byte `json:"contact_id" gorm:"type:binary(16)"`
+	StartsAt   int64  `json:"starts_at"`
+	EndsAt     int64  `json:"ends_at"`
+	Reason     string `json:"reason"`
+	CreatedBy  string `json:"created_by"`
+	CreatedAt  int64  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (OnCallOverride) TableName() string {
+	return "oncall_overrides"
+}
+
+// 綁定規則值班表
+type RuleOnCallSchedule struct {
+	RuleID     []byte `gorm:"type:binary(16);primaryKey"`
+	ScheduleID []byte `gorm:"type:binary(16);primaryKey"`
+}
+
+func (RuleOnCallSchedule) TableName() string {
+	return "rule_oncall_schedules"
+}
+
+// 值班班次
+type OnCallShift struct {
+	ContactID []byte
+	Source    string
+	Rotation  string
+	Start     time.Time
+	End       time.Time
+}
+
+// Location 值班表時區，未設定時為 UTC
+func (s OnCallSchedule) Location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.Timezone)
+}
+
+// OnCallAt 計算指定時間的值班班次，沒有人值班時回傳 nil
+// 只考慮 s.Overrides 中已載入的覆蓋
+func (s OnCallSchedule) OnCallAt(t time.Time) (*OnCallShift, error) {
+	var override *OnCallOverride
+	for i := range s.Overrides {
+		o := &s.Overrides[i]
+		if t.Unix() < o.StartsAt || t.Unix() >= o.EndsAt {
+			continue
+		}
+		if override == nil || o.CreatedAt >= override.CreatedAt {
+			override = o
+		}
+	}
+	if override != nil {
+		return &OnCallShift{
+			ContactID: override.ContactID,
+			Source:    OnCallSourceOverride,
+			Start:     time.Unix(override.StartsAt, 0),
+			End:       time.Unix(override.EndsAt, 0),
+		}, nil
+	}
+
+	loc, err := s.Location()
+	if err != nil {
+		return nil, fmt.Errorf("值班表時區錯誤 [timezone:%s]: %w", s.Timezone, err)
+	}
+	for i := len(s.Rotations) - 1; i >= 0; i-- {
+		shift, err := s.Rotations[i].ShiftAt(t, loc)
+		if err != nil {
+			return nil, err
+		}
+		if shift != nil {
+			return shift, nil
+		}
+	}
+	return nil, nil
+}
+
+// ShiftAt 計算輪值在指定時間的班次，尚未開始或已結束時回傳 nil
+// 以日曆日計算交接，夏令時間切換時交接仍維持在相同的當地時刻
+func (r OnCallRotation) ShiftAt(t time.Time, loc *time.Location) (*OnCallShift, error) {
+	days, err := r.days()
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Participants) == 0 {
+		return nil, nil
+	}
+	start, err := time.ParseInLocation(OnCallTimeLayout, r.Start, loc)
+	if err != nil {
+		return nil, fmt.Errorf("輪值開始時間格式錯誤 [rotation:%s]: %w", r.Name, err)
+	}
+	if t.Before(start) {
+		return nil, nil
+	}
+	if r.End != "" {
+		end, err := time.ParseInLocation(OnCallTimeLayout, r.End, loc)
+		if err != nil {
+			return nil, fmt.Errorf("輪值結束時間格式錯誤 [rotation:%s]: %w", r.Name, err)
+		}
+		if !t.Before(end) {
+			return nil, nil
+		}
+	}
+
+	// 先以 24 小時估算班次，再依實際日曆交接時間修正
+	k := int(t.Sub(start).Hours()/24) / days
+	for !start.AddDate(0, 0, (k+1)*days).After(t) {
+		k++
+	}
+	for k > 0 && start.AddDate(0, 0, k*days).After(t) {
+		k--
+	}
+
+	participant, err := hex.DecodeString(r.Participants[k%len(r.Participants)])
+	if err != nil {
+		return nil, fmt.Errorf("輪值聯絡人 ID 錯誤 [rotation:%s]: %w", r.Name, err)
+	}
+	return &OnCallShift{
+		ContactID: participant,
+		Source:    OnCallSourceRotation,
+		Rotation:  r.Name,
+		Start:     start.AddDate(0, 0, k*days),
+		End:       start.AddDate(0, 0, (k+1)*days),
+	}, nil
+}
+
+// 每個班次的天數
+func (r OnCallRotation) days() (int, error) {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = 1
+	}
+	switch r.Type {
+	case OnCallRotationDaily:
+		return interval, nil
+	case OnCallRotationWeekly:
+		return interval * 7, nil
+	}
+	return 0, fmt.Errorf("不支援的輪值類型 [rotation:%s, type:%s]", r.Name, r.Type)
+}
+
+// * 值班表設定
+type OnCallScheduleResponse struct {
+	ID          string                   `json:"id"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Timezone    string                   `json:"timezone"`
+	Rotations   []OnCallRotation         `json:"rotations"`
+	Overrides   []OnCallOverrideResponse `json:"overrides"`
+}
+
+// * 值班覆蓋設定
+type OnCallOverrideResponse struct {
+	ID        string `json:"id"`
+	ContactID string `json:"contact_id"`
+	StartsAt  int64  `json:"starts_at"`
+	EndsAt    int64  `json:"ends_at"`
+	Reason    string `json:"reason"`
+	CreatedBy string `json:"created_by"`
+}
+
+// * 目前值班
+type OnCallResponse struct {
+	ScheduleID  string `json:"schedule_id"`
+	Time        int64  `json:"time"`
+	Source      string `json:"source"` // override / rotation
+	Rotation    string `json:"rotation,omitempty"`
+	ContactID   string `json:"contact_id"`
+	ContactName string `json:"contact_name"`
+	ShiftStart  int64  `json:"shift_start"`
+	ShiftEnd    int64  `json:"shift_end"`
+}
@@ -0,0 +1,153 @@
+package alert
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+)
+
+func TestRotationShiftAt(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("時區資料不存在: %v", err)
+	}
+	at := func(value string) time.Time {
+		tm, err := time.ParseInLocation(OnCallTimeLayout, value, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+
+	// 2024-03-10 02:00 開始夏令時間 (該日 23 小時)，2024-11-03 02:00 結束 (該日 25 小時)
+	daily := OnCallRotation{Name: "daily", Type: OnCallRotationDaily, Start: "2024-03-08T09:00", Participants: []string{"0a", "0b", "0c"}}
+	weekly := OnCallRotation{Name: "weekly", Type: OnCallRotationWeekly, Start: "2024-10-28T09:00", Participants: []string{"0a", "0b"}}
+	everyOther := OnCallRotation{Name: "every-other", Type: OnCallRotationDaily, Interval: 2, Start: "2024-03-08T09:00", End: "2024-03-14T09:00", Participants: []string{"0a", "0b"}}
+
+	cases := []struct {
+		name     string
+		rotation OnCallRotation
+		t        time.Time
+		contact  string // 空字串表示沒有班次
+		start    string
+		end      string
+	}{
+		{name: "開始前沒有班次", rotation: daily, t: at("2024-03-08T08:59")},
+		{name: "開始時間即為第一班", rotation: daily, t: at("2024-03-08T09:00"), contact: "0a", start: "2024-03-08T09:00", end: "2024-03-09T09:00"},
+		{name: "交接前一刻仍為上一班", rotation: daily, t: at("2024-03-09T09:00").Add(-time.Second), contact: "0a", start: "2024-03-08T09:00", end: "2024-03-09T09:00"},
+		{name: "剛好交接時為下一班", rotation: daily, t: at("2024-03-09T09:00"), contact: "0b", start: "2024-03-09T09:00", end: "2024-03-10T09:00"},
+		// 以 24 小時計算會在當地 10:00 才交接
+		{name: "夏令時間開始後交接維持當地時刻", rotation: daily, t: at("2024-03-10T09:30"), contact: "0c", start: "2024-03-10T09:00", end: "2024-03-11T09:00"},
+		{name: "夏令時間開始當日交接前", rotation: daily, t: at("2024-03-10T08:59"), contact: "0b", start: "2024-03-09T09:00", end: "2024-03-10T09:00"},
+		{name: "輪值一圈後回到第一位", rotation: daily, t: at("2024-03-11T09:00"), contact: "0a", start: "2024-03-11T09:00", end: "2024-03-12T09:00"},
+		// 以 168 小時計算會在當地 08:00 就交接
+		{name: "夏令時間結束後週輪值交接前", rotation: weekly, t: at("2024-11-04T08:30"), contact: "0a", start: "2024-10-28T09:00", end: "2024-11-04T09:00"},
+		{name: "夏令時間結束後週輪值剛好交接", rotation: weekly, t: at("2024-11-04T09:00"), contact: "0b", start: "2024-11-04T09:00", end: "2024-11-11T09:00"},
+		{name: "間隔兩天", rotation: everyOther, t: at("2024-03-11T08:00"), contact: "0b", start: "2024-03-10T09:00", end: "2024-03-12T09:00"},
+		{name: "結束時間後沒有班次", rotation: everyOther, t: at("2024-03-14T09:00")},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			shift, err := tc.rotation.ShiftAt(tc.t, loc)
+			if err != nil {
+				t.Fatalf("ShiftAt: %v", err)
+			}
+			if tc.contact == "" {
+				if shift != nil {
+					t.Fatalf("got shift %+v, want nil", shift)
+				}
+				return
+			}
+			if shift == nil {
+				t.Fatal("got nil shift")
+			}
+			if got := hex.EncodeToString(shift.ContactID); got != tc.contact {
+				t.Errorf("contact = %s, want %s", got, tc.contact)
+			}
+			if !shift.Start.Equal(at(tc.start)) || !shift.End.Equal(at(tc.end)) {
+				t.Errorf("shift = [%v, %v), want [%s, %s)", shift.Start, shift.End, tc.start, tc.end)
+			}
+		})
+	}
+}
+
+func TestRotationShiftAtInvalid(t *testing.T) {
+	rotations := []OnCallRotation{
+		{Name: "type", Type: "monthly", Start: "2024-03-08T09:00", Participants: []string{"0a"}},
+		{Name: "start", Type: OnCallRotationDaily, Start: "2024-03-08 09:00", Participants: []string{"0a"}},
+		{Name: "participant", Type: OnCallRotationDaily, Start: "2024-03-08T09:00", Participants: []string{"not-hex"}},
+	}
+	for _, r := range rotations {
+		if _, err := r.ShiftAt(time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC), time.UTC); err == nil {
+			t.Errorf("%s: expected error", r.Name)
+		}
+	}
+}
+
+func TestScheduleOnCallAt(t *testing.T) {
+	schedule := OnCallSchedule{
+		Timezone: "Asia/Taipei",
+		Rotations: []OnCallRotation{
+			{Name: "primary", Type: OnCallRotationDaily, Start: "2024-03-01T09:00", Participants: []string{"0a", "0b"}},
+			{Name: "holiday", Type: OnCallRotationDaily, Start: "2024-03-09T00:00", End: "2024-03-10T00:00", Participants: []string{"0e"}},
+		},
+	}
+	loc, err := schedule.Location()
+	if err != nil {
+		t.Skipf("時區資料不存在: %v", err)
+	}
+	at := func(value string) time.Time {
+		tm, _ := time.ParseInLocation(OnCallTimeLayout, value, loc)
+		return tm
+	}
+
+	// 覆蓋只涵蓋 2024-03-05 的班次中間一段，後建立的覆蓋優先
+	schedule.Overrides = []OnCallOverride{
+		{ContactID: []byte{0x0c}, StartsAt: at("2024-03-05T12:00").Unix(), EndsAt: at("2024-03-05T18:00").Unix(), CreatedAt: 1},
+		{ContactID: []byte{0x0d}, StartsAt: at("2024-03-05T15:00").Unix(), EndsAt: at("2024-03-05T16:00").Unix(), CreatedAt: 2},
+	}
+
+	cases := []struct {
+		name     string
+		t        time.Time
+		contact  string
+		source   string
+		rotation string
+	}{
+		{name: "覆蓋開始前為輪值", t: at("2024-03-05T11:59"), contact: "0a", source: OnCallSourceRotation, rotation: "primary"},
+		{name: "剛好覆蓋開始", t: at("2024-03-05T12:00"), contact: "0c", source: OnCallSourceOverride},
+		{name: "重疊時後建立的覆蓋優先", t: at("2024-03-05T15:30"), contact: "0d", source: OnCallSourceOverride},
+		{name: "剛好覆蓋結束回到輪值", t: at("2024-03-05T18:00"), contact: "0a", source: OnCallSourceRotation, rotation: "primary"},
+		{name: "後面的輪值優先", t: at("2024-03-09T12:00"), contact: "0e", source: OnCallSourceRotation, rotation: "holiday"},
+		{name: "後面的輪值結束後回到前面的輪值", t: at("2024-03-10T00:00"), contact: "0a", source: OnCallSourceRotation, rotation: "primary"},
+		{name: "所有輪值開始前沒有人值班", t: at("2024-02-29T12:00")},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			shift, err := schedule.OnCallAt(tc.t)
+			if err != nil {
+				t.Fatalf("OnCallAt: %v", err)
+			}
+			if tc.contact == "" {
+				if shift != nil {
+					t.Fatalf("got shift %+v, want nil", shift)
+				}
+				return
+			}
+			if shift == nil {
+				t.Fatal("got nil shift")
+			}
+			if got := hex.EncodeToString(shift.ContactID); got != tc.contact {
+				t.Errorf("contact = %s, want %s", got, tc.contact)
+			}
+			if shift.Source != tc.source || shift.Rotation != tc.rotation {
+				t.Errorf("source = %s/%s, want %s/%s", shift.Source, shift.Rotation, tc.source, tc.rotation)
+			}
+		})
+	}
+
+	schedule.Timezone = "Mars/Olympus"
+	if _, err := schedule.OnCallAt(at("2024-03-05T09:00")); err == nil {
+		t.Error("expected timezone error")
+	}
+}
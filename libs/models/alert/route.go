@@ -0,0 +1,124 @@
+package alert
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/detect-viz/shared-lib/models/common"
+	"github.com/detect-viz/shared-lib/models/mute"
+)
+
+// NotifyRoute 通知路由樹：每個域一棵，通知時依告警欄位 (規則標籤、內建欄位與指標類別) 決定接收的聯絡人
+type NotifyRoute struct {
+	RealmName string `json:"realm_name" gorm:"primaryKey"`
+	Root      Route  `json:"root" gorm:"type:json;serializer:json"`
+	common.AuditUserModel
+	CreatedAt int64 `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt int64 `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Route 路由節點
+// 根節點符合所有告警；子路由依序比對 Matchers (全部符合，未設定時符合所有告警)，
+// 符合的子路由未設定 Continue 時不再比對後面的子路由；沒有子路由符合時由節點本身接收
+// GroupPolicy 未設定時沿用上層路由，皆未設定時使用聯絡人或全域的分組設定
+type Route struct {
+	Name        string                `json:"name"`
+	Matchers    []mute.SilenceMatcher `json:"matchers"`
+	Receivers   []string              `json:"receivers"` // 聯絡人 ID
+	Continue    bool                  `json:"continue"`
+	GroupPolicy *GroupPolicy          `json:"group_policy,omitempty"`
+	Routes      []Route               `json:"routes"`
+}
+
+// RouteMatch 告警符合的路由
+type RouteMatch struct {
+	Path        []string     `json:"path"` // 由根節點到符合路由的名稱，未命名的子路由以 routes[i] 表示
+	Receivers   []string     `json:"receivers"`
+	GroupPolicy *GroupPolicy `json:"group_policy,omitempty"`
+}
+
+// * 通知路由樹設定
+type NotifyRouteResponse struct {
+	Root      Route  `json:"root"`
+	UpdatedAt int64  `json:"updated_at"`
+	UpdatedBy string `json:"updated_by"`
+}
+
+// * 路由測試條件：假設的告警欄位 (規則標籤與內建欄位，例如 realm_name / resource_name / category / severity)
+type RouteTestRequest struct {
+	Labels map[string]string `json:"labels"`
+}
+
+// * 路由測試結果
+type RouteTestResponse struct {
+	Labels  map[string]string    `json:"labels"`
+	Matches []RouteMatchResponse `json:"matches"`
+}
+
+// * 符合的路由與接收的聯絡人
+type RouteMatchResponse struct {
+	Path        []string              `json:"path"`
+	Receivers   []RuleContactResponse `json:"receivers"`
+	GroupPolicy *GroupPolicy          `json:"group_policy,omitempty"`
+}
+
+// Matches 判斷告警欄位是否符合所有 matcher，沒有 matcher 時符合所有告警
+func (r Route) Matches(fields map[string]string) bool {
+	for _, m := range r.Matchers {
+		if !m.Match(fields[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Compile 預先編譯路由樹所有節點的正規表示式 matcher，載入後比對多筆告警時不需重複編譯
+// 有無效的正規表示式時回傳錯誤
+func (r *Route) Compile() error {
+	if err := mute.CompileMatchers(r.Matchers); err != nil {
+		return fmt.Errorf("路由 %s: %w", r.Name, err)
+	}
+	for i := range r.Routes {
+		if err := r.Routes[i].Compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Match 由根節點比對告警欄位，回傳符合的路由 (依路由樹順序)
+func (r Route) Match(fields map[string]string) []RouteMatch {
+	name := r.Name
+	if name == "" {
+		name = "root"
+	}
+	return r.match(fields, []string{name}, nil)
+}
+
+func (r Route) match(fields map[string]string, path []string, inherited *GroupPolicy) []RouteMatch {
+	policy := inherited
+	if r.GroupPolicy != nil {
+		policy = r.GroupPolicy
+	}
+
+	var matches []RouteMatch
+	for i, child := range r.Routes {
+		if !child.Matches(fields) {
+			continue
+		}
+		name := child.Name
+		if name == "" {
+			name = "routes[" + strconv.Itoa(i) + "]"
+		}
+		childPath := append(append(make([]string, 0, len(path)+1), path...), name)
+		matches = append(matches, child.match(fields, childPath, policy)...)
+		if !child.Continue {
+			break
+		}
+	}
+
+	if len(matches) == 0 {
+		matches = []RouteMatch{{Path: path, Receivers: r.Receivers, GroupPolicy: policy}}
+	}
+	return matches
+}
@@ -34,18 +34,28 @@ type RuleOverview struct {
 
 // * 規則設定
 type RuleResponse struct {
-	ID            string                `json:"id"`
-	AutoApply     bool                  `json:"auto_apply"`
-	Enabled       bool                  `json:"enabled"`
-	InfoThreshold *float64              `json:"info_threshold"`
-	WarnThreshold *float64              `json:"warn_threshold"`
-	CritThreshold float64               `json:"crit_threshold"`
-	Times         int                   `json:"times"`
-	Duration      string                `json:"duration"`
-	SilencePeriod string                `json:"silence_period"`
-	MetricRule    MetricRule            `json:"metric_rule"`
-	Target        Target                `json:"target"`
-	Contacts      []RuleContactResponse `json:"contacts"`
+	ID                     string                `json:"id"`
+	AutoApply              bool                  `json:"auto_apply"`
+	Enabled                bool                  `json:"enabled"`
+	InfoThreshold          *float64              `json:"info_threshold"`
+	WarnThreshold          *float64              `json:"warn_threshold"`
+	CritThreshold          float64               `json:"crit_threshold"`
+	InfoLowerThreshold     *float64              `json:"info_lower_threshold"`
+	WarnLowerThreshold     *float64              `json:"warn_lower_threshold"`
+	CritLowerThreshold     *float64              `json:"crit_lower_threshold"`
+	RecoveryThreshold      *float64              `json:"recovery_threshold"`
+	RecoveryLowerThreshold *float64              `json:"recovery_lower_threshold"`
+	EvaluationMode         string                `json:"evaluation_mode"`
+	EvaluationPoints       int                   `json:"evaluation_points"`
+	Times                  int                   `json:"times"`
+	NotifyTimes            int                   `json:"notify_times"`
+	Duration               string                `json:"duration"`
+	SilencePeriod          string                `json:"silence_period"`
+	EscalationPolicyID     string                `json:"escalation_policy_id"` // 設定後以升級策略取代 Contacts
+	OnCallScheduleIDs      []string              `json:"oncall_schedule_ids"`  // 通知值班表目前的值班人員
+	MetricRule             MetricRule            `json:"metric_rule"`
+	Target                 Target                `json:"target"`
+	Contacts               []RuleContactResponse `json:"contacts"`
 }
 
 // * 聯絡人設定
@@ -57,21 +67,36 @@ type RuleContactResponse struct {
 }
 
 type Rule struct {
-	RealmName     string    `json:"realm_name"`
-	ID            []byte    `json:"id" gorm:"primaryKey"`
-	TargetID      []byte    `json:"target_id"`
-	MetricRuleUID string    `json:"metric_rule_uid"`
-	CreateType    string    `json:"create_type"`
-	AutoApply     bool      `json:"auto_apply" gorm:"default:false"`
-	Enabled       bool      `json:"enabled" gorm:"default:1"`
-	InfoThreshold *float64  `json:"info_threshold"`
-	WarnThreshold *float64  `json:"warn_threshold"`
-	CritThreshold float64   `json:"crit_threshold"`
-	Times         int       `json:"times" gorm:"default:3"`
-	Duration      string    `json:"duration" gorm:"default:'5m'"`
-	SilencePeriod string    `json:"silence_period" gorm:"default:'1h'"`
-	Contacts      []Contact `json:"contacts" gorm:"many2many:rule_contacts"`
-	Target        Target    `json:"target" gorm:"foreignKey:TargetID"`
+	RealmName     string   `json:"realm_name"`
+	ID            []byte   `json:"id" gorm:"primaryKey"`
+	TargetID      []byte   `json:"target_id"`
+	MetricRuleUID string   `json:"metric_rule_uid"`
+	CreateType    string   `json:"create_type"`
+	AutoApply     bool     `json:"auto_apply" gorm:"default:false"`
+	Enabled       bool     `json:"enabled" gorm:"default:1"`
+	InfoThreshold *float64 `json:"info_threshold"`
+	WarnThreshold *float64 `json:"warn_threshold"`
+	CritThreshold float64  `json:"crit_threshold"`
+	// 區間運算子 (inside / outside) 的下限閾值
+	InfoLowerThreshold *float64 `json:"info_lower_threshold"`
+	WarnLowerThreshold *float64 `json:"warn_lower_threshold"`
+	CritLowerThreshold *float64 `json:"crit_lower_threshold"`
+	// 恢復閾值 (hysteresis)，未設定時未觸發即恢復
+	RecoveryThreshold      *float64 `json:"recovery_threshold"`
+	RecoveryLowerThreshold *float64 `json:"recovery_lower_threshold"`
+	// 判斷模式: all / n_of_m / consecutive，n_of_m 的 M 為 EvaluationPoints (0 表示窗口內所有數據點)
+	EvaluationMode   string    `json:"evaluation_mode" gorm:"default:'all'"`
+	EvaluationPoints int       `json:"evaluation_points" gorm:"default:0"`
+	Times            int       `json:"times" gorm:"default:3"`        // 判斷模式的觸發次數 N
+	NotifyTimes      int       `json:"notify_times" gorm:"default:3"` // 連續通知次數，達到後進入靜默期 (SilencePeriod)
+	Duration         string    `json:"duration" gorm:"default:'5m'"`
+	SilencePeriod    string    `json:"silence_period" gorm:"default:'1h'"`
+	Contacts         []Contact `json:"contacts" gorm:"many2many:rule_contacts"`
+	Target           Target    `json:"target" gorm:"foreignKey:TargetID"`
+	// 升級策略，設定後通知依策略步驟發送，不使用 Contacts
+	EscalationPolicyID []byte `json:"escalation_policy_id"`
+	// 值班表，通知時解析為當下的值班聯絡人 (未設定升級策略時與 Contacts 一併通知)
+	OnCallSchedules []OnCallSchedule `json:"oncall_schedules" gorm:"many2many:rule_oncall_schedules;joinForeignKey:RuleID;joinReferences:ScheduleID"`
 	common.AuditUserModel
 	common.AuditTimeModel
 }
@@ -7,6 +7,7 @@ type RuleState struct {
 	State                 string   `json:"state"`
 	ContactState          string   `json:"contact_state"`
 	ContactCounter        int      `json:"contact_counter"`
+	BreachCounter         int      `json:"breach_counter"` // 連續超過閾值的檢查次數
 	SilenceStartAt        *int64   `json:"silence_start_at"`
 	SilenceEndAt          *int64   `json:"silence_end_at"`
 	LastCheckValue        float64  `json:"last_check_value"`
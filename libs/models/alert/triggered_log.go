@@ -24,5 +24,17 @@ type TriggeredLog struct {
 	TriggeredValue      float64        `json:"triggered_value"`
 	ResolvedValue       *float64       `json:"resolved_value"`
 	Threshold           float64        `json:"threshold"`
+	AckedBy             *string        `json:"acked_by"`    // 確認人
+	AckedAt             *int64         `json:"acked_at"`    // 確認時間
+	ResolvedBy          *string        `json:"resolved_by"` // 手動恢復人，自動恢復時為 nil
+	AssignedTo          *string        `json:"assigned_to"` // 指派處理人
+	AssignedBy          *string        `json:"assigned_by"`
+	AssignedAt          *int64         `json:"assigned_at"`
+	EscalationStep      int            `json:"escalation_step" gorm:"default:0"` // 已通知的升級步驟
+	EscalatedAt         *int64         `json:"escalated_at"`
+	// 抑制此告警的來源告警 (告警抑制規則)，未被抑制時為 nil
+	InhibitedBy *[]byte `json:"inhibited_by,omitempty"`
+	// 觸發時評估的時間窗口 (已依 MetricRule.Scale 換算)，用於繪製走勢圖
+	MetricWindow []MetricValue `json:"metric_window,omitempty" gorm:"type:json;serializer:json"`
 	common.AuditTimeModel
 }
@@ -2,9 +2,30 @@ package common
 
 // 通知渠道配置
 type NotifySetting struct {
-	ID      int64             `json:"id"`
-	Type    string            `json:"type"`
-	Name    string            `json:"name"`
-	Enabled bool              `json:"enabled"`
-	Config  map[string]string `json:"config"`
+	ID          int64              `json:"id"`
+	Type        string             `json:"type"`
+	Name        string             `json:"name"`
+	Enabled     bool               `json:"enabled"`
+	Config      map[string]string  `json:"config"`
+	Attachments []NotifyAttachment `json:"attachments,omitempty"`
+	Actions     []NotifyAction     `json:"actions,omitempty"`
+}
+
+// 通知訊息的操作按鈕，Value 為通道回呼 (互動按鈕) 帶回的簽章權杖，URL 為簽章連結；
+// 通道未設定回呼驗證時 Value 為空，改以連結開啟操作頁面
+type NotifyAction struct {
+	Name  string `json:"name"` // ack / silence / resolve
+	Label string `json:"label"`
+	Value string `json:"value,omitempty"`
+	URL   string `json:"url"`
+}
+
+// 通知附件，Inline 為 true 時以 ContentID 內嵌於內容中 (例如 HTML 的 <img src="cid:...">)
+type NotifyAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"-"`
+	Inline      bool   `json:"inline,omitempty"`
+	ContentID   string `json:"content_id,omitempty"`
+	URL         string `json:"url,omitempty"` // 可公開存取的網址 (需以網址傳送圖片的通道使用)
 }
@@ -1,12 +1,41 @@
 package config
 
+import "github.com/detect-viz/shared-lib/models/alert"
+
 // AlertConfig 告警配置
 type AlertConfig struct {
-	Enabled       bool   `mapstructure:"enabled"`
-	AutoApplyRule bool   `mapstructure:"auto_apply_rule"`
-	NotifyPeriod  int    `mapstructure:"notify_period"`
-	RetryCount    int    `mapstructure:"retry_limit"`
-	RetryInterval int    `mapstructure:"retry_interval"`
-	MigratePath   string `mapstructure:"migrate_path"`
-	TemplatePath  string `mapstructure:"template_path"`
+	Enabled          bool                  `mapstructure:"enabled"`
+	AutoApplyRule    bool                  `mapstructure:"auto_apply_rule"`
+	NotifyPeriod     int                   `mapstructure:"notify_period"`
+	StaleCheckPeriod int                   `mapstructure:"stale_check_period"` // 缺值偵測 (stale) 定期檢查週期 (秒)，預設 60
+	RetryCount       int                   `mapstructure:"retry_limit"`
+	RetryInterval    int                   `mapstructure:"retry_interval"`
+	MigratePath      string                `mapstructure:"migrate_path"`
+	TemplatePath     string                `mapstructure:"template_path"`
+	PublicURL        string                `mapstructure:"public_url"`      // 對外網址，用於產生通知中的走勢圖連結
+	ChartSecret      string                `mapstructure:"chart_secret"`    // 走勢圖連結簽章金鑰
+	RateLimit        NotifyRateLimitConfig `mapstructure:"rate_limit"`      // 通知發送速率限制
+	StormThreshold   int                   `mapstructure:"storm_threshold"` // 單則通知的告警數超過此值時改發摘要，0 表示停用
+	Grouping         alert.GroupPolicy     `mapstructure:"grouping"`        // 預設告警分組設定
+	ActionSecret     string                `mapstructure:"action_secret"`   // 通知訊息操作 (確認 / 靜默 / 恢復) 的簽章金鑰，未設定時不附上操作
+	Callback         NotifyCallbackConfig  `mapstructure:"callback"`        // 通道回呼驗證設定
+}
+
+// NotifyCallbackConfig 聊天通道回呼 (互動按鈕) 的驗證金鑰，未設定的通道改以簽章連結操作
+type NotifyCallbackConfig struct {
+	SlackSigningSecret string `mapstructure:"slack_signing_secret"` // Slack App 的 Signing Secret
+	LineChannelSecret  string `mapstructure:"line_channel_secret"`  // LINE Messaging API 的 Channel secret
+	TeamsSecret        string `mapstructure:"teams_secret"`         // Teams outgoing webhook 的安全性權杖 (base64)
+}
+
+// NotifyRateLimitConfig 通知速率限制 (token bucket)
+type NotifyRateLimitConfig struct {
+	Contact  RateLimit            `mapstructure:"contact"`  // 每個聯絡人
+	Channels map[string]RateLimit `mapstructure:"channels"` // 每種通道 (line / slack ...)，同通道的聯絡人共用
+}
+
+// RateLimit 每分鐘可發送的通知數與可累積的突發數，PerMinute 為 0 表示不限制
+type RateLimit struct {
+	PerMinute float64 `mapstructure:"per_minute"`
+	Burst     int     `mapstructure:"burst"`
 }
@@ -27,6 +27,18 @@ type (
 	RuleContactResponse = alert.RuleContactResponse
 	ContactResponse     = alert.ContactResponse
 
+	EscalationPolicyResponse = alert.EscalationPolicyResponse
+	EscalationStepResponse   = alert.EscalationStepResponse
+
+	OnCallScheduleResponse = alert.OnCallScheduleResponse
+	OnCallOverrideResponse = alert.OnCallOverrideResponse
+	OnCallResponse         = alert.OnCallResponse
+
+	NotifyRouteResponse = alert.NotifyRouteResponse
+	RouteTestRequest    = alert.RouteTestRequest
+	RouteTestResponse   = alert.RouteTestResponse
+	RouteMatchResponse  = alert.RouteMatchResponse
+
 	// Alert 相關
 	Rule               = alert.Rule
 	Target             = alert.Target
@@ -37,7 +49,27 @@ type (
 	RuleContact        = alert.RuleContact
 	TriggeredLog       = alert.TriggeredLog
 	NotifyLog          = alert.NotifyLog
+	NotifyGroup        = alert.NotifyGroup
+	NotifyDelivery     = alert.NotifyDelivery
+	GroupPolicy        = alert.GroupPolicy
+	NotifyRoute        = alert.NotifyRoute
+	Route              = alert.Route
+	RouteMatch         = alert.RouteMatch
+	NotifyActionLog    = alert.NotifyActionLog
 	TriggeredLogIDsMap = alert.TriggeredLogIDsMap
+	MonitorQuery       = alert.MonitorQuery
+	MonitorCursor      = alert.MonitorCursor
+	RuleStateOverview  = alert.RuleStateOverview
+
+	EscalationPolicy      = alert.EscalationPolicy
+	EscalationStep        = alert.EscalationStep
+	EscalationStepContact = alert.EscalationStepContact
+
+	OnCallSchedule     = alert.OnCallSchedule
+	OnCallRotation     = alert.OnCallRotation
+	OnCallOverride     = alert.OnCallOverride
+	OnCallShift        = alert.OnCallShift
+	RuleOnCallSchedule = alert.RuleOnCallSchedule
 
 	//* Alert Input Schema
 	AlertPayload = alert.AlertPayload
@@ -81,6 +113,7 @@ type (
 	AlertConfig    = config.AlertConfig
 	Code           = config.Code
 	KeycloakConfig = config.KeycloakConfig
+	RateLimit      = config.RateLimit
 
 	// Template 相關
 	TemplateData      = template.TemplateData
@@ -105,9 +138,14 @@ type (
 	LabelValue = label.LabelValue
 
 	// Mute 相關
-	Mute              = mute.Mute
-	TimeRange         = mute.TimeRange
-	MuteResourceGroup = mute.MuteResourceGroup
+	Mute                = mute.Mute
+	TimeRange           = mute.TimeRange
+	MuteResourceGroup   = mute.MuteResourceGroup
+	Silence             = mute.Silence
+	SilenceMatcher      = mute.SilenceMatcher
+	SilenceResponse     = mute.SilenceResponse
+	InhibitRule         = mute.InhibitRule
+	InhibitRuleResponse = mute.InhibitRuleResponse
 
 	//RuleLabelValue       = alert.RuleLabelValue
 )
@@ -0,0 +1,72 @@
+package mute
+
+import (
+	"github.com/detect-viz/shared-lib/models/common"
+)
+
+// 告警抑制 (inhibition)：符合 SourceMatchers 的告警仍在告警中 (未恢復) 時，
+// 符合 TargetMatchers 且 Equal 欄位值與來源告警相同的告警不發送通知
+// 例如機房 UPS 告警時抑制同機房 PDU 的低電壓告警：source severity=crit, category=ups；target category=pdu；equal [room]
+type InhibitRule struct {
+	RealmName      string           `json:"realm_name" gorm:"index"`
+	ID             []byte           `json:"id" gorm:"primaryKey"`
+	Name           string           `json:"name"`
+	SourceMatchers []SilenceMatcher `json:"source_matchers" gorm:"type:json;serializer:json"`
+	TargetMatchers []SilenceMatcher `json:"target_matchers" gorm:"type:json;serializer:json"`
+	Equal          []string         `json:"equal" gorm:"type:json;serializer:json"` // 來源與目標告警需相同的欄位
+	CreatedBy      string           `json:"created_by"`
+	common.AuditTimeModel
+}
+
+// 告警抑制設定
+type InhibitRuleResponse struct {
+	ID             string           `json:"id"`
+	Name           string           `json:"name"`
+	SourceMatchers []SilenceMatcher `json:"source_matchers"`
+	TargetMatchers []SilenceMatcher `json:"target_matchers"`
+	Equal          []string         `json:"equal"`
+	CreatedBy      string           `json:"created_by"`
+	CreatedAt      int64            `json:"created_at"`
+	UpdatedAt      int64            `json:"updated_at"`
+}
+
+// SourceMatches 判斷告警欄位是否符合來源 matcher
+func (r *InhibitRule) SourceMatches(fields map[string]string) bool {
+	return matchAll(r.SourceMatchers, fields)
+}
+
+// TargetMatches 判斷告警欄位是否符合目標 matcher
+func (r *InhibitRule) TargetMatches(fields map[string]string) bool {
+	return matchAll(r.TargetMatchers, fields)
+}
+
+// Compile 預先編譯來源與目標的正規表示式 matcher，載入後比對多筆告警時不需重複編譯
+// 無效的正規表示式維持未編譯，比對時視為不符合
+func (r *InhibitRule) Compile() {
+	CompileMatchers(r.SourceMatchers)
+	CompileMatchers(r.TargetMatchers)
+}
+
+// EqualMatches 判斷來源與目標告警的 Equal 欄位值是否相同
+// 呼叫端需另外確認來源 / 目標 matcher；同時符合兩者的告警，不會被同樣同時符合兩者的告警 (包含自己) 抑制
+func (r *InhibitRule) EqualMatches(source, target map[string]string) bool {
+	for _, name := range r.Equal {
+		if source[name] != target[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// matchAll 判斷告警欄位是否符合所有 matcher，沒有 matcher 時不符合任何告警
+func matchAll(matchers []SilenceMatcher, fields map[string]string) bool {
+	if len(matchers) == 0 {
+		return false
+	}
+	for _, m := range matchers {
+		if !m.Match(fields[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
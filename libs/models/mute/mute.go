@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/detect-viz/shared-lib/models/common"
 	"github.com/detect-viz/shared-lib/models/resource"
 )
 
@@ -14,13 +15,15 @@ import (
 type Mute struct {
 	ID             string                   `json:"id" gorm:"primaryKey"`
 	RealmName      string                   `json:"realm_name" gorm:"default:master"`
-	Name           string                   `json:"name"`                             // 抑制規則名稱
-	Years          []string                 `json:"years" gorm:"type:json"`           // 限制特定年份
-	TimeIntervals  []TimeRange              `json:"times" gorm:"type:json"`           // 允許一天內多個時間範圍
-	RepeatType     string                   `json:"repeat_type" gorm:"default:never"` // 重複類型: never, daily, weekly, monthly
-	Weekdays       []string                 `json:"weekdays" gorm:"type:json"`        // 支援 `"monday:wednesday"`
-	Months         []string                 `json:"months" gorm:"type:json"`          // `"may:august"`
+	Name           string                   `json:"name"`                                      // 抑制規則名稱
+	Years          []string                 `json:"years" gorm:"type:json;serializer:json"`    // 限制特定年份
+	TimeIntervals  []TimeRange              `json:"times" gorm:"type:json;serializer:json"`    // 允許一天內多個時間範圍
+	RepeatType     string                   `json:"repeat_type" gorm:"default:never"`          // 重複類型: never, daily, weekly, monthly
+	Weekdays       []string                 `json:"weekdays" gorm:"type:json;serializer:json"` // 支援 `"monday:wednesday"`
+	Months         []string                 `json:"months" gorm:"type:json;serializer:json"`   // `"may:august"`
 	ResourceGroups []resource.ResourceGroup `gorm:"many2many:mute_resource_groups"`
+	Resources      []string                 `json:"resources" gorm:"type:json;serializer:json"` // 指定資源名稱
+	Labels         common.JSONMap           `json:"labels" gorm:"type:json"`                    // 規則標籤條件 (全部符合才抑制)
 	CreatedAt      int64                    `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt      int64                    `json:"updated_at" gorm:"autoUpdateTime"`
 	DeletedAt      int64                    `json:"-" gorm:"index"`
@@ -32,6 +35,44 @@ type MuteResourceGroup struct {
 	ResourceGroupID int64  `json:"resource_group_id" gorm:"primaryKey"`
 }
 
+// MatchScope 判斷 mute 的適用範圍是否涵蓋指定資源與規則標籤
+// 資源 (ResourceGroups / Resources) 與標籤 (Labels) 皆有設定時需同時符合，
+// 未設定任何範圍的 mute 不抑制任何告警
+func (m *Mute) MatchScope(resourceName string, ruleLabels map[string]string) bool {
+	hasResource := len(m.ResourceGroups) > 0 || len(m.Resources) > 0
+	if !hasResource && len(m.Labels) == 0 {
+		return false
+	}
+
+	if hasResource && !m.matchResource(resourceName) {
+		return false
+	}
+
+	for key, value := range m.Labels {
+		if v, ok := ruleLabels[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// matchResource 判斷資源是否在指定資源或資源群組內
+func (m *Mute) matchResource(resourceName string) bool {
+	for _, name := range m.Resources {
+		if name == resourceName {
+			return true
+		}
+	}
+	for _, group := range m.ResourceGroups {
+		for _, r := range group.Resources {
+			if r.Name == resourceName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // 時間範圍 (允許一天內多個時段)
 type TimeRange struct {
 	StartMinute string `json:"start_time"`
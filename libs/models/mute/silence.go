@@ -0,0 +1,159 @@
+package mute
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/detect-viz/shared-lib/models/common"
+)
+
+// 靜默狀態
+const (
+	SilenceStatePending = "pending" // 尚未開始
+	SilenceStateActive  = "active"  // 生效中
+	SilenceStateExpired = "expired" // 已過期
+)
+
+// 靜默比對的內建欄位，其他名稱視為規則標籤
+const (
+	SilenceFieldResourceName  = "resource_name"
+	SilenceFieldPartitionName = "partition_name"
+	SilenceFieldMetricRuleUID = "metric_rule_uid"
+)
+
+// 臨時靜默 (one-off silence)，依 matcher 比對告警，超過 EndsAt 自動失效
+type Silence struct {
+	RealmName      string           `json:"realm_name" gorm:"index"`
+	ID             []byte           `json:"id" gorm:"primaryKey"`
+	Matchers       []SilenceMatcher `json:"matchers" gorm:"type:json;serializer:json"`
+	StartsAt       int64            `json:"starts_at"`
+	EndsAt         int64            `json:"ends_at"`
+	CreatedBy      string           `json:"created_by"`
+	Comment        string           `json:"comment"`
+	TriggeredLogID []byte           `json:"triggered_log_id"` // 由告警建立時的來源 TriggeredLog
+	common.AuditTimeModel
+}
+
+// 靜默比對條件
+// Operator: = / != / =~ / !~ (正規表示式需完整比對)
+type SilenceMatcher struct {
+	Name     string `json:"name"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+
+	re *regexp.Regexp // 已編譯的正規表示式 (Compile)
+}
+
+// 靜默設定
+type SilenceResponse struct {
+	ID             string           `json:"id"`
+	Matchers       []SilenceMatcher `json:"matchers"`
+	StartsAt       int64            `json:"starts_at"`
+	EndsAt         int64            `json:"ends_at"`
+	CreatedBy      string           `json:"created_by"`
+	Comment        string           `json:"comment"`
+	TriggeredLogID string           `json:"triggered_log_id,omitempty"`
+	State          string           `json:"state"`
+	CreatedAt      int64            `json:"created_at"`
+}
+
+// State 依時間判斷靜默狀態
+func (s *Silence) State(now int64) string {
+	switch {
+	case now < s.StartsAt:
+		return SilenceStatePending
+	case now < s.EndsAt:
+		return SilenceStateActive
+	default:
+		return SilenceStateExpired
+	}
+}
+
+// Matches 判斷告警欄位是否符合所有 matcher，沒有 matcher 的靜默不符合任何告警
+func (s *Silence) Matches(fields map[string]string) bool {
+	if len(s.Matchers) == 0 {
+		return false
+	}
+	for _, m := range s.Matchers {
+		if !m.Match(fields[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Compile 預先編譯正規表示式 matcher，載入後比對多筆告警時不需重複編譯
+// 無效的正規表示式維持未編譯，比對時視為不符合
+func (s *Silence) Compile() {
+	CompileMatchers(s.Matchers)
+}
+
+// CompileMatchers 預先編譯正規表示式 matcher，回傳第一個無效正規表示式的錯誤 (其餘 matcher 仍會編譯)
+func CompileMatchers(matchers []SilenceMatcher) error {
+	var firstErr error
+	for i := range matchers {
+		m := &matchers[i]
+		if m.re != nil || (m.Operator != "=~" && m.Operator != "!~") {
+			continue
+		}
+		re, err := m.Regexp()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("matcher %s 正規表示式無效: %w", m.Name, err)
+			}
+			continue
+		}
+		m.re = re
+	}
+	return firstErr
+}
+
+// NeedsLabels 是否有 matcher 需要比對規則標籤 (非內建欄位)
+func (s *Silence) NeedsLabels() bool {
+	for _, m := range s.Matchers {
+		switch m.Name {
+		case SilenceFieldResourceName, SilenceFieldPartitionName, SilenceFieldMetricRuleUID:
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// Match 比對單一欄位值，欄位不存在時以空字串比對
+func (m SilenceMatcher) Match(value string) bool {
+	switch m.Operator {
+	case "", "=":
+		return value == m.Value
+	case "!=":
+		return value != m.Value
+	case "=~", "!~":
+		re := m.re
+		if re == nil {
+			compiled, err := m.Regexp()
+			if err != nil {
+				return false
+			}
+			re = compiled
+		}
+		return re.MatchString(value) == (m.Operator == "=~")
+	}
+	return false
+}
+
+// Regexp 編譯正規表示式 matcher (完整比對)
+func (m SilenceMatcher) Regexp() (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + m.Value + ")$")
+}
+
+// SilenceFields 組合靜默比對用的告警欄位 (規則標籤 + 內建欄位)
+func SilenceFields(resourceName, partitionName, metricRuleUID string, labels map[string]string) map[string]string {
+	fields := make(map[string]string, len(labels)+3)
+	for k, v := range labels {
+		fields[k] = v
+	}
+	fields[SilenceFieldResourceName] = resourceName
+	fields[SilenceFieldPartitionName] = partitionName
+	fields[SilenceFieldMetricRuleUID] = metricRuleUID
+	return fields
+}
@@ -0,0 +1,170 @@
+package mutes
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/google/uuid"
+)
+
+// CreateInhibitRule 創建告警抑制規則
+func (s *serviceImpl) CreateInhibitRule(realm, createdBy string, req models.InhibitRuleResponse) (*models.InhibitRuleResponse, error) {
+	rule := models.InhibitRule{
+		RealmName:      realm,
+		Name:           req.Name,
+		SourceMatchers: req.SourceMatchers,
+		TargetMatchers: req.TargetMatchers,
+		Equal:          req.Equal,
+		CreatedBy:      createdBy,
+	}
+	if err := validateInhibitRule(&rule); err != nil {
+		return nil, err
+	}
+	if err := s.mysql.CreateInhibitRule(&rule); err != nil {
+		return nil, err
+	}
+	return toInhibitRuleResponse(rule), nil
+}
+
+// GetInhibitRule 獲取告警抑制規則
+func (s *serviceImpl) GetInhibitRule(realm, id string) (*models.InhibitRuleResponse, error) {
+	ruleID, err := parseInhibitRuleID(id)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := s.mysql.GetInhibitRule(realm, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	return toInhibitRuleResponse(*rule), nil
+}
+
+// ListInhibitRules 獲取告警抑制規則列表
+func (s *serviceImpl) ListInhibitRules(realm string) ([]models.InhibitRuleResponse, error) {
+	rules, err := s.mysql.ListInhibitRules(realm)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]models.InhibitRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, *toInhibitRuleResponse(rule))
+	}
+	return result, nil
+}
+
+// UpdateInhibitRule 更新告警抑制規則
+func (s *serviceImpl) UpdateInhibitRule(realm, id string, req models.InhibitRuleResponse) (*models.InhibitRuleResponse, error) {
+	ruleID, err := parseInhibitRuleID(id)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := s.mysql.GetInhibitRule(realm, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	rule.Name = req.Name
+	rule.SourceMatchers = req.SourceMatchers
+	rule.TargetMatchers = req.TargetMatchers
+	rule.Equal = req.Equal
+	if err := validateInhibitRule(rule); err != nil {
+		return nil, err
+	}
+	if err := s.mysql.UpdateInhibitRule(rule); err != nil {
+		return nil, err
+	}
+	return s.GetInhibitRule(realm, id)
+}
+
+// DeleteInhibitRule 刪除告警抑制規則
+func (s *serviceImpl) DeleteInhibitRule(realm, id string) error {
+	ruleID, err := parseInhibitRuleID(id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.mysql.GetInhibitRule(realm, ruleID); err != nil {
+		return err
+	}
+	return s.mysql.DeleteInhibitRule(realm, ruleID)
+}
+
+// ListRealmInhibitRules 獲取域內所有告警抑制規則 (通知時比對用，已預先編譯正規表示式 matcher)
+func (s *serviceImpl) ListRealmInhibitRules(realm string) ([]models.InhibitRule, error) {
+	rules, err := s.mysql.ListInhibitRules(realm)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		rules[i].Compile()
+	}
+	return rules, nil
+}
+
+// 檢查告警抑制規則設定
+func validateInhibitRule(rule *models.InhibitRule) error {
+	if rule.Name == "" {
+		return apierrors.NewAPIError(400, "抑制規則名稱不可為空", nil)
+	}
+	if len(rule.SourceMatchers) == 0 {
+		return apierrors.NewAPIError(400, "至少需要一個來源 matcher", nil)
+	}
+	if len(rule.TargetMatchers) == 0 {
+		return apierrors.NewAPIError(400, "至少需要一個目標 matcher", nil)
+	}
+	if err := validateMatchers(rule.SourceMatchers, "source"); err != nil {
+		return err
+	}
+	if err := validateMatchers(rule.TargetMatchers, "target"); err != nil {
+		return err
+	}
+	for i, name := range rule.Equal {
+		if name == "" {
+			return apierrors.NewAPIError(400, fmt.Sprintf("equal 欄位名稱不可為空 [index:%d]", i), nil)
+		}
+	}
+	return nil
+}
+
+// validateMatchers 檢查 matcher 設定，未指定 operator 時視為 =
+func validateMatchers(matchers []models.SilenceMatcher, side string) error {
+	for i, m := range matchers {
+		if m.Name == "" {
+			return apierrors.NewAPIError(400, fmt.Sprintf("matcher 名稱不可為空 [%s:%d]", side, i), nil)
+		}
+		switch m.Operator {
+		case "":
+			matchers[i].Operator = "="
+		case "=", "!=":
+		case "=~", "!~":
+			if _, err := m.Regexp(); err != nil {
+				return apierrors.NewAPIError(400, fmt.Sprintf("matcher 正規表示式無效 [%s:%s]", side, m.Name), err)
+			}
+		default:
+			return apierrors.NewAPIError(400, fmt.Sprintf("matcher operator 僅支援 = / != / =~ / !~ [%s:%s]", side, m.Name), nil)
+		}
+	}
+	return nil
+}
+
+func toInhibitRuleResponse(rule models.InhibitRule) *models.InhibitRuleResponse {
+	return &models.InhibitRuleResponse{
+		ID:             hex.EncodeToString(rule.ID),
+		Name:           rule.Name,
+		SourceMatchers: rule.SourceMatchers,
+		TargetMatchers: rule.TargetMatchers,
+		Equal:          rule.Equal,
+		CreatedBy:      rule.CreatedBy,
+		CreatedAt:      rule.CreatedAt,
+		UpdatedAt:      rule.UpdatedAt,
+	}
+}
+
+// parseInhibitRuleID 將字符串 ID 轉換為 []byte
+func parseInhibitRuleID(idStr string) ([]byte, error) {
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil, apierrors.ErrInvalidID
+	}
+	return id[:], nil
+}
@@ -24,4 +24,20 @@ type Service interface {
 	IsRuleMuted(ruleID int64, t time.Time) bool
 	GetMutePeriod(resourceGroupID int64, t time.Time) (int64, int64)
 	ValidateTimeRange(start, end time.Time) error
+	MatchTriggeredLogs(logs []models.TriggeredLog, t time.Time) []MuteMatch
+
+	// 臨時靜默
+	CreateSilence(realm, createdBy string, req models.SilenceResponse) (*models.SilenceResponse, error)
+	GetSilence(realm, id string) (*models.SilenceResponse, error)
+	ListSilences(realm, state, cursor string, limit int) ([]models.SilenceResponse, string, error)
+	ExpireSilence(realm, id string) error
+	ListActiveSilences(realm string, t time.Time) ([]models.Silence, error)
+
+	// 告警抑制規則
+	CreateInhibitRule(realm, createdBy string, req models.InhibitRuleResponse) (*models.InhibitRuleResponse, error)
+	GetInhibitRule(realm, id string) (*models.InhibitRuleResponse, error)
+	ListInhibitRules(realm string) ([]models.InhibitRuleResponse, error)
+	UpdateInhibitRule(realm, id string, req models.InhibitRuleResponse) (*models.InhibitRuleResponse, error)
+	DeleteInhibitRule(realm, id string) error
+	ListRealmInhibitRules(realm string) ([]models.InhibitRule, error)
 }
@@ -0,0 +1,137 @@
+package mutes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/models/mute"
+)
+
+// MuteMatch 告警的抑制比對結果，先比對 mute，未抑制時再比對臨時靜默
+// Err 不為 nil 時表示查詢失敗，無法判斷是否在抑制期間
+type MuteMatch struct {
+	Mute    *models.Mute
+	Silence *models.Silence
+	Err     error
+}
+
+// Muted 是否被 mute 或臨時靜默抑制
+func (m MuteMatch) Muted() bool {
+	return m.Mute != nil || m.Silence != nil
+}
+
+// 域內目前生效中的抑制條件
+type activeScope struct {
+	mutes      []models.Mute
+	silences   []models.Silence
+	needLabels bool // 是否有條件需要比對規則標籤
+	err        error
+}
+
+// MatchTriggeredLogs 批次比對多筆告警是否被抑制，回傳結果與 logs 順序一致
+// 每個 realm 只載入一次生效中的 mute 與臨時靜默，規則標籤以一次批次查詢取得，於記憶體中比對
+func (s *serviceImpl) MatchTriggeredLogs(logs []models.TriggeredLog, t time.Time) []MuteMatch {
+	results := make([]MuteMatch, len(logs))
+	if len(logs) == 0 {
+		return results
+	}
+
+	scopes := make(map[string]*activeScope)
+	var ruleIDs [][]byte
+	for _, log := range logs {
+		scope, ok := scopes[log.RealmName]
+		if !ok {
+			scope = s.loadActiveScope(log.RealmName, t)
+			scopes[log.RealmName] = scope
+		}
+		if scope.err == nil && scope.needLabels {
+			ruleIDs = append(ruleIDs, log.RuleID)
+		}
+	}
+
+	// 只有在條件需要比對標籤時才查詢規則標籤
+	var ruleLabels map[string]map[string]string
+	var labelErr error
+	if len(ruleIDs) > 0 {
+		ruleLabels, labelErr = s.mysql.GetRuleLabelsByRuleIDs(ruleIDs)
+		if labelErr != nil {
+			labelErr = fmt.Errorf("獲取規則標籤失敗: %w", labelErr)
+		}
+	}
+
+	for i, log := range logs {
+		scope := scopes[log.RealmName]
+		if scope.err != nil {
+			results[i].Err = scope.err
+			continue
+		}
+		if scope.needLabels && labelErr != nil {
+			results[i].Err = labelErr
+			continue
+		}
+		labels := ruleLabels[string(log.RuleID)]
+		results[i].Mute = matchMute(scope.mutes, log, labels)
+		if results[i].Mute == nil {
+			results[i].Silence = matchSilence(scope.silences, log, labels)
+		}
+	}
+	return results
+}
+
+// 載入域內目前生效中的 mute 與臨時靜默
+func (s *serviceImpl) loadActiveScope(realm string, t time.Time) *activeScope {
+	scope := &activeScope{}
+
+	mutes, err := s.mysql.ListActiveMutes(realm)
+	if err != nil {
+		scope.err = err
+		return scope
+	}
+	for _, m := range mutes {
+		if !m.IsMuted(t) {
+			continue
+		}
+		scope.mutes = append(scope.mutes, m)
+		if len(m.Labels) > 0 {
+			scope.needLabels = true
+		}
+	}
+
+	silences, err := s.ListActiveSilences(realm, t)
+	if err != nil {
+		scope.err = err
+		return scope
+	}
+	for _, silence := range silences {
+		if silence.NeedsLabels() {
+			scope.needLabels = true
+		}
+	}
+	scope.silences = silences
+	return scope
+}
+
+// 依資源群組、資源與規則標籤找出抑制該告警的 mute，沒有符合時回傳 nil
+func matchMute(mutes []models.Mute, log models.TriggeredLog, ruleLabels map[string]string) *models.Mute {
+	for i := range mutes {
+		if mutes[i].MatchScope(log.ResourceName, ruleLabels) {
+			return &mutes[i]
+		}
+	}
+	return nil
+}
+
+// 找出靜默該告警的臨時靜默，沒有符合時回傳 nil
+func matchSilence(silences []models.Silence, log models.TriggeredLog, ruleLabels map[string]string) *models.Silence {
+	if len(silences) == 0 {
+		return nil
+	}
+	fields := mute.SilenceFields(log.ResourceName, log.PartitionName, log.MetricRuleUID, ruleLabels)
+	for i := range silences {
+		if silences[i].Matches(fields) {
+			return &silences[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package mutes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/models/mute"
+	"github.com/detect-viz/shared-lib/storage/mysql"
+	"github.com/google/uuid"
+)
+
+// CreateSilence 創建臨時靜默
+// 指定 TriggeredLogID 且未提供 matcher 時，以該告警的資源、分區與指標規則作為 matcher
+func (s *serviceImpl) CreateSilence(realm, createdBy string, req models.SilenceResponse) (*models.SilenceResponse, error) {
+	now := time.Now().Unix()
+	silence := models.Silence{
+		RealmName: realm,
+		Matchers:  req.Matchers,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		CreatedBy: createdBy,
+		Comment:   req.Comment,
+	}
+
+	if req.TriggeredLogID != "" {
+		id, err := mysql.ParseTriggeredLogID(req.TriggeredLogID)
+		if err != nil {
+			return nil, err
+		}
+		log, err := s.mysql.GetTriggeredLog(id)
+		if err != nil || log.RealmName != realm {
+			return nil, apierrors.NewAPIError(404, "找不到來源告警", err)
+		}
+		silence.TriggeredLogID = log.ID
+		if len(silence.Matchers) == 0 {
+			silence.Matchers = []models.SilenceMatcher{
+				{Name: mute.SilenceFieldResourceName, Operator: "=", Value: log.ResourceName},
+				{Name: mute.SilenceFieldPartitionName, Operator: "=", Value: log.PartitionName},
+				{Name: mute.SilenceFieldMetricRuleUID, Operator: "=", Value: log.MetricRuleUID},
+			}
+		}
+	}
+
+	if silence.StartsAt == 0 {
+		silence.StartsAt = now
+	}
+	if err := validateSilence(&silence, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.mysql.CreateSilence(&silence); err != nil {
+		return nil, err
+	}
+	return toSilenceResponse(silence, now), nil
+}
+
+// GetSilence 獲取靜默
+func (s *serviceImpl) GetSilence(realm, id string) (*models.SilenceResponse, error) {
+	silenceID, err := parseSilenceID(id)
+	if err != nil {
+		return nil, err
+	}
+	silence, err := s.mysql.GetSilence(realm, silenceID)
+	if err != nil {
+		return nil, err
+	}
+	return toSilenceResponse(*silence, time.Now().Unix()), nil
+}
+
+// ListSilences 獲取靜默列表，state: pending / active / expired，空值為全部
+// 以 cursor 分頁，limit 預設 10、最多 1000，沒有下一頁時 next_cursor 為空字串
+func (s *serviceImpl) ListSilences(realm, state, cursor string, limit int) ([]models.SilenceResponse, string, error) {
+	switch state {
+	case "", mute.SilenceStatePending, mute.SilenceStateActive, mute.SilenceStateExpired:
+	default:
+		return nil, "", apierrors.NewAPIError(400, "state 僅支援 pending / active / expired", nil)
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	now := time.Now().Unix()
+	silences, nextCursor, err := s.mysql.ListSilences(realm, state, now, cursor, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result := make([]models.SilenceResponse, 0, len(silences))
+	for _, silence := range silences {
+		result = append(result, *toSilenceResponse(silence, now))
+	}
+	return result, nextCursor, nil
+}
+
+// ExpireSilence 讓靜默立即失效
+func (s *serviceImpl) ExpireSilence(realm, id string) error {
+	silenceID, err := parseSilenceID(id)
+	if err != nil {
+		return err
+	}
+	return s.mysql.ExpireSilence(realm, silenceID, time.Now().Unix())
+}
+
+// ListActiveSilences 獲取指定時間生效中的靜默，matcher 已預先編譯
+func (s *serviceImpl) ListActiveSilences(realm string, t time.Time) ([]models.Silence, error) {
+	silences, err := s.mysql.ListActiveSilences(realm, t.Unix())
+	if err != nil {
+		return nil, err
+	}
+	for i := range silences {
+		silences[i].Compile()
+	}
+	return silences, nil
+}
+
+// 檢查靜默設定
+func validateSilence(silence *models.Silence, now int64) error {
+	if len(silence.Matchers) == 0 {
+		return apierrors.NewAPIError(400, "至少需要一個 matcher", nil)
+	}
+	for i, m := range silence.Matchers {
+		if m.Name == "" {
+			return apierrors.NewAPIError(400, fmt.Sprintf("matcher 名稱不可為空 [index:%d]", i), nil)
+		}
+		switch m.Operator {
+		case "":
+			silence.Matchers[i].Operator = "="
+		case "=", "!=":
+		case "=~", "!~":
+			if _, err := m.Regexp(); err != nil {
+				return apierrors.NewAPIError(400, fmt.Sprintf("matcher 正規表示式無效 [name:%s]", m.Name), err)
+			}
+		default:
+			return apierrors.NewAPIError(400, fmt.Sprintf("matcher operator 僅支援 = / != / =~ / !~ [name:%s]", m.Name), nil)
+		}
+	}
+	if silence.EndsAt <= silence.StartsAt {
+		return apierrors.NewAPIError(400, "ends_at 必須大於 starts_at", nil)
+	}
+	if silence.EndsAt <= now {
+		return apierrors.NewAPIError(400, "ends_at 必須晚於目前時間", nil)
+	}
+	return nil
+}
+
+func toSilenceResponse(silence models.Silence, now int64) *models.SilenceResponse {
+	resp := &models.SilenceResponse{
+		ID:        hex.EncodeToString(silence.ID),
+		Matchers:  silence.Matchers,
+		StartsAt:  silence.StartsAt,
+		EndsAt:    silence.EndsAt,
+		CreatedBy: silence.CreatedBy,
+		Comment:   silence.Comment,
+		State:     silence.State(now),
+		CreatedAt: silence.CreatedAt,
+	}
+	if len(silence.TriggeredLogID) > 0 {
+		resp.TriggeredLogID = hex.EncodeToString(silence.TriggeredLogID)
+	}
+	return resp
+}
+
+// parseSilenceID 將字符串 ID 轉換為 []byte
+func parseSilenceID(idStr string) ([]byte, error) {
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil, apierrors.ErrInvalidID
+	}
+	return id[:], nil
+}
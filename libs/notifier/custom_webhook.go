@@ -0,0 +1,158 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/detect-viz/shared-lib/models/common"
+	notifyerrors "github.com/detect-viz/shared-lib/notifier/errors"
+	"github.com/detect-viz/shared-lib/notifier/validate"
+)
+
+// 通用 webhook 設定：
+// body         - 由 body_template 渲染後的請求內容 (告警模組以完整告警資料渲染)，未設定時送出 title 與 message
+// content_type - application/json (預設) 或 application/x-www-form-urlencoded
+// headers      - 自訂請求頭，JSON 物件字串，例如 {"X-Api-Key":"..."}
+// auth_type    - basic (username / password) 或 bearer (token)
+// hmac_secret  - 設定後以 HMAC 簽署請求內容，簽章放在 hmac_header (預設 X-Signature)
+//                格式為 "<algorithm>=<hex>"，hmac_algorithm 支援 sha256 (預設) / sha1 / sha512
+
+const (
+	ContentTypeJSON = "application/json"
+	ContentTypeForm = "application/x-www-form-urlencoded"
+
+	defaultHMACHeader = "X-Signature"
+)
+
+type customWebhookDriver struct {
+	httpDriver
+}
+
+func newWebhookDriver() Driver {
+	return &customWebhookDriver{
+		httpDriver: httpDriver{
+			name:      "webhook",
+			validator: &validate.CustomWebhookValidator{},
+		},
+	}
+}
+
+func (d *customWebhookDriver) BuildPayload(info common.NotifySetting) (*Payload, error) {
+	config := info.Config
+	contentType := config["content_type"]
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+
+	// 1. 請求內容
+	var body []byte
+	switch {
+	case config["body"] != "":
+		body = []byte(config["body"])
+	case strings.HasPrefix(contentType, ContentTypeForm):
+		form := url.Values{}
+		form.Set("title", config["title"])
+		form.Set("message", config["message"])
+		body = []byte(form.Encode())
+	default:
+		data, err := json.Marshal(map[string]interface{}{
+			"title":   config["title"],
+			"message": config["message"],
+		})
+		if err != nil {
+			return nil, notifyerrors.NewNotifyError(d.name, "marshal payload failed", err)
+		}
+		body = data
+	}
+
+	method := config["method"]
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	// 2. 請求頭，自訂請求頭可覆蓋預設值
+	headers := map[string]string{
+		"Content-Type": contentType,
+		"User-Agent":   "DetectViz-Notifier/1.0",
+	}
+	if raw := config["headers"]; raw != "" {
+		custom := make(map[string]string)
+		if err := json.Unmarshal([]byte(raw), &custom); err != nil {
+			return nil, notifyerrors.NewNotifyError(d.name, "invalid headers", notifyerrors.ErrInvalidConfig)
+		}
+		for k, v := range custom {
+			headers[k] = v
+		}
+	}
+
+	// 3. 認證
+	switch strings.ToLower(config["auth_type"]) {
+	case "basic":
+		credential := config["username"] + ":" + config["password"]
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credential))
+	case "bearer":
+		headers["Authorization"] = "Bearer " + strings.TrimSpace(config["token"])
+	}
+
+	// 4. HMAC 簽章
+	if secret := config["hmac_secret"]; secret != "" {
+		signature, err := signHMAC(config["hmac_algorithm"], secret, body)
+		if err != nil {
+			return nil, notifyerrors.NewNotifyError(d.name, err.Error(), notifyerrors.ErrInvalidConfig)
+		}
+		header := config["hmac_header"]
+		if header == "" {
+			header = defaultHMACHeader
+		}
+		headers[header] = signature
+	}
+
+	return &Payload{
+		Method:  method,
+		URL:     config["url"],
+		Headers: headers,
+		Body:    body,
+	}, nil
+}
+
+func (d *customWebhookDriver) Send(ctx context.Context, info common.NotifySetting) error {
+	payload, err := d.BuildPayload(info)
+	if err != nil {
+		return err
+	}
+	return sendWebhook(ctx, d.name, payload)
+}
+
+// signHMAC 計算請求內容的 HMAC 簽章，回傳 "<algorithm>=<hex>"
+func signHMAC(algorithm, secret string, body []byte) (string, error) {
+	if algorithm == "" {
+		algorithm = "sha256"
+	}
+
+	var newHash func() hash.Hash
+	switch algorithm {
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return "", fmt.Errorf("unsupported hmac algorithm: %s", algorithm)
+	}
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return algorithm + "=" + hex.EncodeToString(mac.Sum(nil)), nil
+}
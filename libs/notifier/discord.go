@@ -0,0 +1,119 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"time"
+
+	"github.com/detect-viz/shared-lib/models/common"
+	notifyerrors "github.com/detect-viz/shared-lib/notifier/errors"
+	"github.com/detect-viz/shared-lib/notifier/validate"
+)
+
+// Discord 單則訊息最多的 embed 數量
+const maxDiscordEmbeds = 10
+
+// Discord webhook：以 embed 呈現告警
+// 有圖片附件 (走勢圖) 時改以 multipart/form-data 上傳檔案，並在 embed 中以 attachment://<檔名> 引用
+type discordDriver struct {
+	*httpDriver
+}
+
+func newDiscordDriver() Driver {
+	return &discordDriver{
+		httpDriver: &httpDriver{
+			name:      "discord",
+			validator: &validate.WebhookValidator{},
+			buildBody: discordBody,
+		},
+	}
+}
+
+func discordBody(info common.NotifySetting) interface{} {
+	embeds := []map[string]interface{}{
+		{
+			"title":       info.Config["title"],
+			"description": info.Config["message"],
+			"color":       16711680, // 紅色
+			"timestamp":   time.Now().Format(time.RFC3339),
+			"footer": map[string]string{
+				"text": "Alert System",
+			},
+		},
+	}
+	for _, image := range imageAttachments(info, false) {
+		if len(embeds) >= maxDiscordEmbeds {
+			break
+		}
+		url := image.URL
+		if len(image.Data) > 0 {
+			url = "attachment://" + image.Filename
+		}
+		if url == "" {
+			continue
+		}
+		embeds = append(embeds, map[string]interface{}{
+			"image": map[string]string{"url": url},
+		})
+	}
+	return map[string]interface{}{
+		"embeds": embeds,
+	}
+}
+
+func (d *discordDriver) BuildPayload(info common.NotifySetting) (*Payload, error) {
+	payload, err := d.httpDriver.BuildPayload(info)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []common.NotifyAttachment
+	for _, image := range imageAttachments(info, false) {
+		if len(image.Data) > 0 && len(files) < maxDiscordEmbeds-1 {
+			files = append(files, image)
+		}
+	}
+	if len(files) == 0 {
+		return payload, nil
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="payload_json"`)
+	header.Set("Content-Type", "application/json")
+	pw, err := w.CreatePart(header)
+	if err != nil {
+		return nil, notifyerrors.NewNotifyError(d.name, "build multipart failed", err)
+	}
+	pw.Write(payload.Body)
+
+	for i, file := range files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="files[%d]"; filename="%s"`, i, file.Filename))
+		header.Set("Content-Type", file.ContentType)
+		fw, err := w.CreatePart(header)
+		if err != nil {
+			return nil, notifyerrors.NewNotifyError(d.name, "build multipart failed", err)
+		}
+		fw.Write(file.Data)
+	}
+	if err := w.Close(); err != nil {
+		return nil, notifyerrors.NewNotifyError(d.name, "build multipart failed", err)
+	}
+
+	payload.Headers["Content-Type"] = w.FormDataContentType()
+	payload.Body = buf.Bytes()
+	return payload, nil
+}
+
+func (d *discordDriver) Send(ctx context.Context, info common.NotifySetting) error {
+	payload, err := d.BuildPayload(info)
+	if err != nil {
+		return err
+	}
+	return sendWebhook(ctx, d.name, payload)
+}
@@ -0,0 +1,176 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/detect-viz/shared-lib/models/common"
+	notifyerrors "github.com/detect-viz/shared-lib/notifier/errors"
+)
+
+// Driver 通知通道驅動
+// 每種通道 (email / slack / line ...) 實作一個 Driver，並以 Name() 註冊到 Registry
+type Driver interface {
+	// Name 通道名稱，對應 NotifySetting.Type
+	Name() string
+
+	// ValidateConfig 檢查通道設定
+	ValidateConfig(config map[string]string) error
+
+	// BuildPayload 依通知內容組出要送出的請求
+	BuildPayload(info common.NotifySetting) (*Payload, error)
+
+	// Send 發送通知
+	Send(ctx context.Context, info common.NotifySetting) error
+
+	// ClassifyError 判斷發送錯誤可否重試
+	ClassifyError(err error) ErrorClass
+}
+
+// Payload 通道請求內容
+type Payload struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    []byte
+}
+
+// ErrorClass 錯誤分類
+type ErrorClass string
+
+const (
+	ErrorRetryable ErrorClass = "retryable" // 暫時性錯誤，可稍後重試
+	ErrorPermanent ErrorClass = "permanent" // 設定或內容錯誤，重試也不會成功
+)
+
+// SendError 通道回應非 2xx 時的錯誤
+type SendError struct {
+	Channel    string
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // 回應帶有 Retry-After 時的建議等待時間
+}
+
+func (e *SendError) Error() string {
+	return fmt.Sprintf("failed to send message to %s: %d, response: %s", e.Channel, e.StatusCode, e.Body)
+}
+
+// RetryAfter 取得錯誤中通道建議的重試等待時間，沒有時回傳 0
+func RetryAfter(err error) time.Duration {
+	var sendErr *SendError
+	if errors.As(err, &sendErr) {
+		return sendErr.RetryAfter
+	}
+	return 0
+}
+
+// parseRetryAfter 解析 Retry-After 標頭，支援秒數與 HTTP 日期格式
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// Registry 通道驅動註冊表
+type Registry struct {
+	mu      sync.RWMutex
+	drivers map[string]Driver
+}
+
+// NewRegistry 創建註冊表並註冊內建通道
+func NewRegistry() *Registry {
+	r := &Registry{
+		drivers: make(map[string]Driver),
+	}
+
+	// 註冊內建通道
+	r.Register(newEmailDriver())
+	r.Register(newDiscordDriver())
+	r.Register(newSlackDriver())
+	r.Register(newWebexDriver())
+	r.Register(newLineDriver())
+	r.Register(newTeamsDriver())
+	r.Register(newWebhookDriver())
+
+	return r
+}
+
+// Register 註冊通道驅動，同名驅動會被取代
+func (r *Registry) Register(driver Driver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.drivers[driver.Name()] = driver
+}
+
+// Get 獲取通道驅動
+func (r *Registry) Get(name string) (Driver, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	driver, ok := r.drivers[name]
+	if !ok {
+		return nil, notifyerrors.NewNotifyError("Registry", "no driver for type "+name, notifyerrors.ErrUnsupportedType)
+	}
+	return driver, nil
+}
+
+// Names 已註冊的通道名稱 (排序)
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.drivers))
+	for name := range r.drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// classifyError 通用錯誤分類
+// 設定錯誤、4xx 回應為永久錯誤；網路錯誤、逾時、408 / 429 / 5xx 回應為可重試錯誤
+func classifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorRetryable
+	}
+
+	var sendErr *SendError
+	if errors.As(err, &sendErr) {
+		switch {
+		case sendErr.StatusCode == http.StatusRequestTimeout,
+			sendErr.StatusCode == http.StatusTooManyRequests,
+			sendErr.StatusCode >= 500:
+			return ErrorRetryable
+		case sendErr.StatusCode >= 400:
+			return ErrorPermanent
+		}
+		return ErrorRetryable
+	}
+
+	if errors.Is(err, notifyerrors.ErrInvalidConfig) ||
+		errors.Is(err, notifyerrors.ErrMissingRequiredConf) ||
+		errors.Is(err, notifyerrors.ErrUnsupportedType) {
+		return ErrorPermanent
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorRetryable
+	}
+	return ErrorRetryable
+}
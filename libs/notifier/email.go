@@ -1,36 +1,105 @@
 package notifier
 
 import (
+	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"html"
 	"net"
 	"net/smtp"
+	"net/textproto"
 	"strings"
 
 	"github.com/detect-viz/shared-lib/models"
 	"github.com/detect-viz/shared-lib/models/common"
+	notifyerrors "github.com/detect-viz/shared-lib/notifier/errors"
+	"github.com/detect-viz/shared-lib/notifier/validate"
 )
 
-// Send 發送郵件通知
-func (s *serviceImpl) sendEmail(info common.NotifySetting) error {
+type emailDriver struct{}
+
+func newEmailDriver() Driver {
+	return &emailDriver{}
+}
+
+func (d *emailDriver) Name() string {
+	return "email"
+}
+
+func (d *emailDriver) ValidateConfig(config map[string]string) error {
+	return (&validate.EmailValidator{}).Validate(config)
+}
+
+// BuildPayload 組合郵件內容，URL 為 SMTP 伺服器位址
+// config 的 message 為 HTML 內容，text 可另外指定純文字內容；
+// message_id / in_reply_to / references 可指定郵件串使用的 Message-ID (未含網域時以寄件人網域補上)
+func (d *emailDriver) BuildPayload(info common.NotifySetting) (*Payload, error) {
 	config := parseEmailConfig(info.Config)
 
-	// 設置郵件頭
+	msg := &MailMessage{
+		From:        config.From,
+		To:          config.To,
+		Cc:          config.Cc,
+		ReplyTo:     config.ReplyTo,
+		Subject:     info.Config["title"],
+		MessageID:   MessageID(info.Config["message_id"], config.From),
+		Text:        info.Config["text"],
+		HTML:        info.Config["message"],
+		Attachments: info.Attachments,
+	}
+	if len(info.Actions) > 0 {
+		msg.HTML, msg.Text = appendEmailActions(msg.HTML, msg.Text, info.Actions)
+	}
+	if info.Config["in_reply_to"] != "" {
+		msg.InReplyTo = MessageID(info.Config["in_reply_to"], config.From)
+	}
+	for _, ref := range splitList(info.Config["references"]) {
+		msg.References = append(msg.References, MessageID(ref, config.From))
+	}
+
+	body, err := msg.Bytes()
+	if err != nil {
+		return nil, notifyerrors.NewNotifyError("Email", "build message failed", fmt.Errorf("%w: %v", notifyerrors.ErrInvalidConfig, err))
+	}
+
 	headers := make(map[string]string)
-	headers["From"] = config.From
-	headers["To"] = strings.Join(config.To, ",")
-	headers["Cc"] = strings.Join(config.Cc, ",")
-	headers["Subject"] = info.Config["title"]
-	headers["Reply-To"] = config.ReplyTo
-	headers["MIME-Version"] = "1.0"
-	headers["Content-Type"] = "text/html; charset=UTF-8"
+	for _, h := range msg.headers() {
+		headers[h[0]] = h[1]
+	}
 
-	// 組合郵件內容
-	var body string
-	for k, v := range headers {
-		body += fmt.Sprintf("%s: %s\r\n", k, v)
+	return &Payload{
+		Method:  "SMTP",
+		URL:     fmt.Sprintf("%s:%s", config.Host, config.Port),
+		Headers: headers,
+		Body:    body,
+	}, nil
+}
+
+// appendEmailActions 在郵件內容末端附上通知操作的簽章連結
+func appendEmailActions(htmlContent, text string, actions []common.NotifyAction) (string, string) {
+	links := make([]string, 0, len(actions))
+	lines := make([]string, 0, len(actions))
+	for _, action := range actions {
+		links = append(links, fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(action.URL), html.EscapeString(action.Label)))
+		lines = append(lines, action.Label+": "+action.URL)
+	}
+	// 未指定純文字內容時由 HTML 轉換，避免純文字內容只剩按鈕名稱而沒有連結
+	if text == "" {
+		text = htmlToText(htmlContent)
 	}
-	body += "\r\n" + info.Config["message"]
+	htmlContent += "<br><br>\n<p>" + strings.Join(links, " | ") + "</p>"
+	text += "\n\n" + strings.Join(lines, "\n")
+	return htmlContent, text
+}
+
+// Send 發送郵件通知
+func (d *emailDriver) Send(ctx context.Context, info common.NotifySetting) error {
+	payload, err := d.BuildPayload(info)
+	if err != nil {
+		return err
+	}
+	config := parseEmailConfig(info.Config)
 
 	// 合併所有收件人
 	recipients := mergeRecipients(config.To, config.Cc, config.Bcc)
@@ -41,17 +110,19 @@ func (s *serviceImpl) sendEmail(info common.NotifySetting) error {
 		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
 	}
 
-	// 發送郵件
-	addr := fmt.Sprintf("%s:%s", config.Host, config.Port)
-
-	fmt.Printf("config: %+v\n", config)
-	fmt.Printf("addr: %v\n", addr)
-	fmt.Printf("auth: %v\n", auth)
-	fmt.Printf("config.From: %v\n", config.From)
-	fmt.Printf("recipients: %v\n", recipients)
-	fmt.Printf("body: %v\n", body)
+	return sendMailUsingTLS(payload.URL, auth, config.From, recipients, payload.Body, config.UseSTARTTLS)
+}
 
-	return sendMailUsingTLS(addr, auth, config.From, recipients, []byte(body), config.UseSTARTTLS)
+// ClassifyError SMTP 5xx 回應為永久錯誤，4xx 及連線錯誤可重試
+func (d *emailDriver) ClassifyError(err error) ErrorClass {
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		if smtpErr.Code >= 500 {
+			return ErrorPermanent
+		}
+		return ErrorRetryable
+	}
+	return classifyError(err)
 }
 
 // 解析郵件配置
@@ -89,9 +160,9 @@ func parseEmailConfig(config map[string]string) models.EmailSetting {
 		Username:    config["username"],
 		Password:    config["password"],
 		From:        config["from"],
-		To:          strings.Split(config["to"], ","),
-		Cc:          strings.Split(config["cc"], ","),
-		Bcc:         strings.Split(config["bcc"], ","),
+		To:          splitList(config["to"]),
+		Cc:          splitList(config["cc"]),
+		Bcc:         splitList(config["bcc"]),
 		ReplyTo:     config["reply_to"],
 		UseTLS:      useTLS,      // 465 用 `tls.Dial()`
 		UseSTARTTLS: useSTARTTLS, // 587/25 需要 `STARTTLS`
@@ -99,6 +170,17 @@ func parseEmailConfig(config map[string]string) models.EmailSetting {
 	}
 }
 
+// 以逗號分隔並忽略空值
+func splitList(value string) []string {
+	var list []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
 // 合併收件人列表
 func mergeRecipients(to, cc, bcc []string) []string {
 	var recipients []string
@@ -123,11 +205,9 @@ func sendMailUsingTLS(addr string, auth smtp.Auth, from string, to []string, msg
 	hostname := strings.Split(addr, ":")[0] // 獲取主機名稱
 	if useSTARTTLS {
 		// 587 使用 net.Dial()，稍後發送 STARTTLS
-		fmt.Println("🔍 連線到 SMTP 伺服器 (STARTTLS 模式)...")
 		conn, err = net.Dial("tcp", addr)
 	} else {
 		// 465 使用 tls.Dial() 直接建立加密連線
-		fmt.Println("🔍 連線到 SMTP 伺服器 (TLS 直連)...")
 		conn, err = tls.Dial("tcp", addr, &tls.Config{
 			ServerName:         hostname,
 			InsecureSkipVerify: true,
@@ -148,7 +228,6 @@ func sendMailUsingTLS(addr string, auth smtp.Auth, from string, to []string, msg
 
 	// 如果是 `587`，發送 `STARTTLS`
 	if useSTARTTLS {
-		fmt.Println("🔍 發送 STARTTLS 指令...")
 		if err = client.StartTLS(&tls.Config{ServerName: hostname}); err != nil {
 			return fmt.Errorf("❌ STARTTLS 失敗: %w", err)
 		}
@@ -156,20 +235,17 @@ func sendMailUsingTLS(addr string, auth smtp.Auth, from string, to []string, msg
 
 	// SMTP 身份驗證
 	if auth != nil {
-		fmt.Println("🔍 進行 SMTP 身份驗證...")
 		if err = client.Auth(auth); err != nil {
 			return fmt.Errorf("❌ SMTP 認證失敗: %w", err)
 		}
 	}
 
 	// 設定寄件人
-	fmt.Println("📨 設定寄件人...")
 	if err = client.Mail(from); err != nil {
 		return fmt.Errorf("❌ 設定寄件人失敗: %w", err)
 	}
 
 	// 設定收件人
-	fmt.Println("📨 設定收件人...")
 	for _, recipient := range to {
 		if err = client.Rcpt(recipient); err != nil {
 			return fmt.Errorf("❌ 設定收件人 %s 失敗: %w", recipient, err)
@@ -177,7 +253,6 @@ func sendMailUsingTLS(addr string, auth smtp.Auth, from string, to []string, msg
 	}
 
 	// 發送郵件內容
-	fmt.Println("📨 發送郵件內容...")
 	w, err := client.Data()
 	if err != nil {
 		return fmt.Errorf("❌ SMTP Data 指令失敗: %w", err)
@@ -189,6 +264,5 @@ func sendMailUsingTLS(addr string, auth smtp.Auth, from string, to []string, msg
 		return fmt.Errorf("❌ 關閉郵件內容流失敗: %w", err)
 	}
 
-	fmt.Println("✅ 郵件發送成功！")
 	return nil
 }
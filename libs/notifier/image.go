@@ -0,0 +1,23 @@
+package notifier
+
+import (
+	"strings"
+
+	"github.com/detect-viz/shared-lib/models/common"
+)
+
+// imageAttachments 取得通知中的圖片附件 (例如告警走勢圖)
+// withURL 為 true 時只回傳有公開網址的圖片，供只接受圖片網址的通道 (Slack / LINE) 使用
+func imageAttachments(info common.NotifySetting, withURL bool) []common.NotifyAttachment {
+	var images []common.NotifyAttachment
+	for _, a := range info.Attachments {
+		if !strings.HasPrefix(a.ContentType, "image/") {
+			continue
+		}
+		if withURL && a.URL == "" {
+			continue
+		}
+		images = append(images, a)
+	}
+	return images
+}
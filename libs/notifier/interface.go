@@ -8,4 +8,9 @@ import (
 type Service interface {
 	Send(notify common.NotifySetting) error
 	Validate(config common.NotifySetting) error
+
+	// 通道驅動
+	RegisterDriver(driver Driver)
+	Drivers() []string
+	Classify(channelType string, err error) ErrorClass
 }
@@ -0,0 +1,92 @@
+package notifier
+
+import (
+	"strings"
+
+	"github.com/detect-viz/shared-lib/models/common"
+	"github.com/detect-viz/shared-lib/notifier/validate"
+)
+
+// LINE 單次 push 最多的訊息數量
+const maxLineMessages = 5
+
+// LINE Messaging API push message
+func newLineDriver() Driver {
+	return &httpDriver{
+		name:       "line",
+		defaultURL: "https://api.line.me/v2/bot/message/push",
+		validator:  &validate.LineValidator{},
+		buildBody: func(info common.NotifySetting) interface{} {
+			// 處理訊息格式，確保換行符號正確顯示
+			lineMessage := info.Config["title"] + "\n\n" + info.Config["message"]
+			// 移除多餘的換行符號
+			lineMessage = strings.ReplaceAll(lineMessage, "\n\n\n", "\n\n")
+
+			messages := []map[string]interface{}{
+				{
+					"type": "text",
+					"text": lineMessage,
+				},
+			}
+			// 通知操作以 buttons template 呈現：有回呼權杖時為 postback，否則開啟簽章連結
+			if len(info.Actions) > 0 {
+				messages = append(messages, lineActionMessage(info.Actions))
+			}
+			// 走勢圖以 image message 呈現 (LINE 只接受公開網址)，單次 push 最多 5 則訊息
+			for _, image := range imageAttachments(info, true) {
+				if len(messages) >= maxLineMessages {
+					break
+				}
+				messages = append(messages, map[string]interface{}{
+					"type":               "image",
+					"originalContentUrl": image.URL,
+					"previewImageUrl":    image.URL,
+				})
+			}
+
+			return map[string]interface{}{
+				"to":       info.Config["to"],
+				"messages": messages,
+			}
+		},
+		headers: func(info common.NotifySetting) map[string]string {
+			// 只有當 channel_token 不為空時才添加到請求頭
+			token := strings.TrimSpace(info.Config["channel_token"])
+			if token == "" {
+				return nil
+			}
+			return map[string]string{
+				"Authorization": "Bearer " + token,
+			}
+		},
+	}
+}
+
+// lineActionMessage 通知操作的 buttons template 訊息 (最多 4 個按鈕)
+func lineActionMessage(actions []common.NotifyAction) map[string]interface{} {
+	buttons := make([]map[string]interface{}, 0, len(actions))
+	for _, action := range actions {
+		if len(buttons) >= 4 {
+			break
+		}
+		button := map[string]interface{}{"label": action.Label}
+		if action.Value != "" {
+			button["type"] = "postback"
+			button["data"] = action.Value
+			button["displayText"] = action.Label
+		} else {
+			button["type"] = "uri"
+			button["uri"] = action.URL
+		}
+		buttons = append(buttons, button)
+	}
+	return map[string]interface{}{
+		"type":    "template",
+		"altText": "告警操作",
+		"template": map[string]interface{}{
+			"type":    "buttons",
+			"text":    "告警操作",
+			"actions": buttons,
+		},
+	}
+}
@@ -0,0 +1,286 @@
+package notifier
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/detect-viz/shared-lib/models/common"
+)
+
+// MailMessage 郵件內容
+// 結構由外而內為 multipart/mixed (附件) > multipart/related (內嵌圖片) > multipart/alternative (純文字 + HTML)，
+// 沒有附件或內嵌圖片時省略對應的層級
+type MailMessage struct {
+	From        string
+	To          []string
+	Cc          []string
+	ReplyTo     string
+	Subject     string
+	Date        time.Time
+	MessageID   string   // 含角括號，例如 <alert.xxx@example.com>
+	InReplyTo   string   // 回覆的 Message-ID，用於郵件串
+	References  []string // 同一郵件串的 Message-ID
+	Text        string   // 純文字內容，未設定時由 HTML 轉換
+	HTML        string
+	Attachments []common.NotifyAttachment
+}
+
+// mimePart MIME 節點
+type mimePart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// Bytes 組合完整的郵件內容 (含郵件頭)
+func (m *MailMessage) Bytes() ([]byte, error) {
+	content, err := m.content()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for _, h := range m.headers() {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h[0], h[1])
+	}
+	for _, key := range []string{"Content-Type", "Content-Transfer-Encoding"} {
+		if v := content.header.Get(key); v != "" {
+			fmt.Fprintf(&buf, "%s: %s\r\n", key, v)
+		}
+	}
+	buf.WriteString("\r\n")
+	buf.Write(content.body)
+	return buf.Bytes(), nil
+}
+
+// headers 郵件頭 (依寫入順序)
+func (m *MailMessage) headers() [][2]string {
+	headers := [][2]string{
+		{"From", formatAddress(m.From)},
+		{"To", formatAddressList(m.To)},
+	}
+	if cc := formatAddressList(m.Cc); cc != "" {
+		headers = append(headers, [2]string{"Cc", cc})
+	}
+	if m.ReplyTo != "" {
+		headers = append(headers, [2]string{"Reply-To", formatAddress(m.ReplyTo)})
+	}
+	headers = append(headers, [2]string{"Subject", mime.BEncoding.Encode("UTF-8", m.Subject)})
+
+	date := m.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	headers = append(headers, [2]string{"Date", date.Format(time.RFC1123Z)})
+
+	if m.MessageID != "" {
+		headers = append(headers, [2]string{"Message-ID", m.MessageID})
+	}
+	if m.InReplyTo != "" {
+		headers = append(headers, [2]string{"In-Reply-To", m.InReplyTo})
+	}
+	if len(m.References) > 0 {
+		headers = append(headers, [2]string{"References", strings.Join(m.References, " ")})
+	}
+	return append(headers, [2]string{"MIME-Version", "1.0"})
+}
+
+// content 組合郵件本文
+func (m *MailMessage) content() (*mimePart, error) {
+	text := m.Text
+	if text == "" {
+		text = htmlToText(m.HTML)
+	}
+
+	content := textPart("text/plain", text)
+	var err error
+	if m.HTML != "" {
+		content, err = m.multipart("alternative", content, textPart("text/html", m.HTML))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var inline, attached []*mimePart
+	for _, a := range m.Attachments {
+		if a.Inline {
+			inline = append(inline, attachmentPart(a))
+		} else {
+			attached = append(attached, attachmentPart(a))
+		}
+	}
+	if len(inline) > 0 {
+		if content, err = m.multipart("related", append([]*mimePart{content}, inline...)...); err != nil {
+			return nil, err
+		}
+	}
+	if len(attached) > 0 {
+		if content, err = m.multipart("mixed", append([]*mimePart{content}, attached...)...); err != nil {
+			return nil, err
+		}
+	}
+	return content, nil
+}
+
+// multipart 將多個節點組成 multipart/<subtype>
+func (m *MailMessage) multipart(subtype string, parts ...*mimePart) (*mimePart, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.SetBoundary(m.boundary(subtype)); err != nil {
+		return nil, err
+	}
+	for _, p := range parts {
+		pw, err := w.CreatePart(p.header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := pw.Write(p.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", mime.FormatMediaType("multipart/"+subtype, map[string]string{"boundary": w.Boundary()}))
+	return &mimePart{header: header, body: buf.Bytes()}, nil
+}
+
+// boundary 依 Message-ID 產生固定的分隔字串，"=_" 不會出現在 quoted-printable 與 base64 內容中
+func (m *MailMessage) boundary(subtype string) string {
+	seed := m.MessageID
+	if seed == "" {
+		seed = randomToken()
+	}
+	sum := sha1.Sum([]byte(subtype + seed))
+	return "=_" + subtype + "_" + hex.EncodeToString(sum[:12])
+}
+
+// textPart 文字節點，以 quoted-printable 編碼
+func textPart(contentType, content string) *mimePart {
+	var buf bytes.Buffer
+	qp := quotedprintable.NewWriter(&buf)
+	qp.Write([]byte(content))
+	qp.Close()
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", contentType+"; charset=UTF-8")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	return &mimePart{header: header, body: buf.Bytes()}
+}
+
+// attachmentPart 附件節點，以 base64 編碼 (每行 76 字元)
+func attachmentPart(a common.NotifyAttachment) *mimePart {
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	disposition := "attachment"
+	if a.Inline {
+		disposition = "inline"
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", mime.FormatMediaType(contentType, map[string]string{"name": a.Filename}))
+	header.Set("Content-Transfer-Encoding", "base64")
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.Filename}))
+	if a.ContentID != "" {
+		header.Set("Content-ID", "<"+a.ContentID+">")
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(a.Data)
+	var buf bytes.Buffer
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return &mimePart{header: header, body: buf.Bytes()}
+}
+
+// formatAddress 格式化郵件地址，顯示名稱以 RFC 2047 編碼
+func formatAddress(addr string) string {
+	parsed, err := mail.ParseAddress(addr)
+	if err != nil {
+		return addr
+	}
+	if parsed.Name == "" {
+		return parsed.Address
+	}
+	return parsed.String()
+}
+
+// formatAddressList 格式化郵件地址列表，忽略空值
+func formatAddressList(addrs []string) string {
+	var list []string
+	for _, addr := range addrs {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			list = append(list, formatAddress(addr))
+		}
+	}
+	return strings.Join(list, ", ")
+}
+
+// MessageID 以寄件人網域產生 Message-ID，id 已包含網域時直接使用
+func MessageID(id, from string) string {
+	id = strings.Trim(id, "<>")
+	if id == "" {
+		id = fmt.Sprintf("%d.%s", time.Now().UnixNano(), randomToken())
+	}
+	if !strings.Contains(id, "@") {
+		domain := "localhost"
+		if parsed, err := mail.ParseAddress(from); err == nil {
+			if i := strings.LastIndex(parsed.Address, "@"); i >= 0 {
+				domain = parsed.Address[i+1:]
+			}
+		}
+		id += "@" + domain
+	}
+	return "<" + id + ">"
+}
+
+func randomToken() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+var (
+	htmlHiddenRegex   = regexp.MustCompile(`(?is)<(style|script|head)[^>]*>.*?</(style|script|head)>`)
+	htmlBreakRegex    = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|tr|li|h[1-6]|table)>`)
+	htmlCellRegex     = regexp.MustCompile(`(?i)</t[dh]>`)
+	htmlTagRegex      = regexp.MustCompile(`<[^>]*>`)
+	blankLinesRegex   = regexp.MustCompile(`\n{3,}`)
+	lineSpacesRegex   = regexp.MustCompile(`[ \t]+\n`)
+	leadingSpaceRegex = regexp.MustCompile(`\n[ \t]+`)
+)
+
+// htmlToText 將 HTML 內容轉為純文字
+func htmlToText(content string) string {
+	if content == "" {
+		return ""
+	}
+	text := htmlHiddenRegex.ReplaceAllString(content, "")
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(text)
+	text = htmlBreakRegex.ReplaceAllString(text, "\n")
+	text = htmlCellRegex.ReplaceAllString(text, "\t")
+	text = htmlTagRegex.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+	text = lineSpacesRegex.ReplaceAllString(text, "\n")
+	text = leadingSpaceRegex.ReplaceAllString(text, "\n")
+	text = blankLinesRegex.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
@@ -1,9 +1,10 @@
 package notifier
 
 import (
+	"context"
+
 	"github.com/detect-viz/shared-lib/auth/keycloak"
 	"github.com/detect-viz/shared-lib/models/common"
-	"github.com/detect-viz/shared-lib/notifier/validate"
 	"github.com/google/wire"
 )
 
@@ -14,32 +15,50 @@ var NotifySet = wire.NewSet(
 
 // serviceImpl 通知服務實現
 type serviceImpl struct {
-	validator *validate.Validator
-	keycloak  *keycloak.Client
+	registry *Registry
+	keycloak *keycloak.Client
 }
 
 // NewService 創建通知服務
 func NewService(keycloakClient *keycloak.Client) *serviceImpl {
 	return &serviceImpl{
-		validator: validate.New(),
-		keycloak:  keycloakClient,
+		registry: NewRegistry(),
+		keycloak: keycloakClient,
 	}
 }
 
 // 發送通知
 func (s *serviceImpl) Send(config common.NotifySetting) error {
-	switch config.Type {
-	case "email":
-		return s.sendEmail(config)
-	default:
-		return s.sendWebhook(config)
+	driver, err := s.registry.Get(config.Type)
+	if err != nil {
+		return err
 	}
+	return driver.Send(context.Background(), config)
 }
 
 func (s *serviceImpl) Validate(config common.NotifySetting) error {
-	// 驗證配置
-	if err := s.validator.Validate(config); err != nil {
+	driver, err := s.registry.Get(config.Type)
+	if err != nil {
 		return err
 	}
-	return nil
+	return driver.ValidateConfig(config.Config)
+}
+
+// 註冊通道驅動
+func (s *serviceImpl) RegisterDriver(driver Driver) {
+	s.registry.Register(driver)
+}
+
+// 已註冊的通道
+func (s *serviceImpl) Drivers() []string {
+	return s.registry.Names()
+}
+
+// 判斷發送錯誤可否重試，未知通道視為永久錯誤
+func (s *serviceImpl) Classify(channelType string, err error) ErrorClass {
+	driver, derr := s.registry.Get(channelType)
+	if derr != nil {
+		return ErrorPermanent
+	}
+	return driver.ClassifyError(err)
 }
@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"strings"
+
+	"github.com/detect-viz/shared-lib/models/common"
+	"github.com/detect-viz/shared-lib/notifier/validate"
+)
+
+// Slack：預設使用 incoming webhook，設定 token 時改以 Bot token 呼叫 chat.postMessage (需設定 channel)
+func newSlackDriver() Driver {
+	return &httpDriver{
+		name:      "slack",
+		validator: &validate.WebhookValidator{},
+		buildBody: func(info common.NotifySetting) interface{} {
+			payload := map[string]interface{}{
+				"text": info.Config["title"] + "\n" + info.Config["message"],
+				"blocks": []map[string]interface{}{
+					{
+						"type": "header",
+						"text": map[string]string{
+							"type": "plain_text",
+							"text": info.Config["title"],
+						},
+					},
+					{
+						"type": "section",
+						"text": map[string]string{
+							"type": "mrkdwn",
+							"text": info.Config["message"],
+						},
+					},
+				},
+			}
+			// 走勢圖以 image block 呈現 (Slack 只接受公開網址)
+			blocks := payload["blocks"].([]map[string]interface{})
+			for _, image := range imageAttachments(info, true) {
+				blocks = append(blocks, map[string]interface{}{
+					"type":      "image",
+					"image_url": image.URL,
+					"alt_text":  image.Filename,
+				})
+			}
+			// 通知操作以 actions block 的按鈕呈現：有回呼權杖時送出 value，否則開啟簽章連結
+			if len(info.Actions) > 0 {
+				elements := make([]map[string]interface{}, 0, len(info.Actions))
+				for _, action := range info.Actions {
+					button := map[string]interface{}{
+						"type":      "button",
+						"action_id": action.Name,
+						"text": map[string]string{
+							"type": "plain_text",
+							"text": action.Label,
+						},
+					}
+					if action.Value != "" {
+						button["value"] = action.Value
+					} else {
+						button["url"] = action.URL
+					}
+					elements = append(elements, button)
+				}
+				blocks = append(blocks, map[string]interface{}{
+					"type":     "actions",
+					"elements": elements,
+				})
+			}
+			payload["blocks"] = blocks
+			if channel := info.Config["channel"]; channel != "" {
+				payload["channel"] = channel
+			}
+			return payload
+		},
+		headers: func(info common.NotifySetting) map[string]string {
+			token := strings.TrimSpace(info.Config["token"])
+			if token == "" {
+				return nil
+			}
+			return map[string]string{
+				"Authorization": "Bearer " + token,
+			}
+		},
+	}
+}
@@ -0,0 +1,91 @@
+package notifier
+
+import (
+	"github.com/detect-viz/shared-lib/models/common"
+	"github.com/detect-viz/shared-lib/notifier/validate"
+)
+
+// Microsoft Teams incoming webhook：以 MessageCard 呈現告警
+// 通知操作有回呼權杖時改以 Adaptive Card 的 Action.Submit 送出 (由 outgoing webhook 回呼)，否則以 OpenUri 開啟簽章連結；
+// Adaptive Card 中沒有回呼權杖的操作以 Action.OpenUrl 開啟簽章連結
+func newTeamsDriver() Driver {
+	return &httpDriver{
+		name:      "teams",
+		validator: &validate.WebhookValidator{},
+		buildBody: func(info common.NotifySetting) interface{} {
+			if hasActionValue(info.Actions) {
+				return teamsAdaptiveCard(info)
+			}
+			card := map[string]interface{}{
+				"@type":      "MessageCard",
+				"@context":   "http://schema.org/extensions",
+				"summary":    info.Config["title"],
+				"themeColor": "0076D7",
+				"title":      info.Config["title"],
+				"text":       info.Config["message"],
+			}
+			if len(info.Actions) > 0 {
+				potentialActions := make([]map[string]interface{}, 0, len(info.Actions))
+				for _, action := range info.Actions {
+					potentialActions = append(potentialActions, map[string]interface{}{
+						"@type": "OpenUri",
+						"name":  action.Label,
+						"targets": []map[string]string{
+							{"os": "default", "uri": action.URL},
+						},
+					})
+				}
+				card["potentialAction"] = potentialActions
+			}
+			return card
+		},
+	}
+}
+
+// teamsAdaptiveCard 帶有 Action.Submit 操作的 Adaptive Card
+func teamsAdaptiveCard(info common.NotifySetting) map[string]interface{} {
+	actions := make([]map[string]interface{}, 0, len(info.Actions))
+	for _, action := range info.Actions {
+		if action.Value == "" {
+			actions = append(actions, map[string]interface{}{
+				"type":  "Action.OpenUrl",
+				"title": action.Label,
+				"url":   action.URL,
+			})
+			continue
+		}
+		actions = append(actions, map[string]interface{}{
+			"type":  "Action.Submit",
+			"title": action.Label,
+			"data":  map[string]string{"token": action.Value},
+		})
+	}
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body": []map[string]interface{}{
+						{"type": "TextBlock", "text": info.Config["title"], "weight": "Bolder", "size": "Medium", "wrap": true},
+						{"type": "TextBlock", "text": info.Config["message"], "wrap": true},
+					},
+					"actions": actions,
+				},
+			},
+		},
+	}
+}
+
+// hasActionValue 判斷通知操作是否帶有回呼權杖
+func hasActionValue(actions []common.NotifyAction) bool {
+	for _, action := range actions {
+		if action.Value != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -1,5 +1,11 @@
 package validate
 
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
 // WebhookValidator Webhook配置驗證器
 type WebhookValidator struct{}
 
@@ -12,3 +18,46 @@ func (v *WebhookValidator) Validate(config map[string]string) error {
 	}
 	return nil
 }
+
+// CustomWebhookValidator 通用 webhook 配置驗證器 (自訂內容、請求頭、認證與簽章)
+type CustomWebhookValidator struct{}
+
+func (v *CustomWebhookValidator) Validate(config map[string]string) error {
+	if err := (&WebhookValidator{}).Validate(config); err != nil {
+		return err
+	}
+	if method := config["method"]; method != "" {
+		if err := Method(method); err != nil {
+			return err
+		}
+	}
+	if contentType := config["content_type"]; contentType != "" {
+		if err := InList("content_type", contentType, []string{"application/json", "application/x-www-form-urlencoded"}); err != nil {
+			return err
+		}
+	}
+	if headers := config["headers"]; headers != "" {
+		if err := json.Unmarshal([]byte(headers), &map[string]string{}); err != nil {
+			return fmt.Errorf("headers must be a JSON object of strings: %v", err)
+		}
+	}
+	switch strings.ToLower(config["auth_type"]) {
+	case "":
+	case "basic":
+		if err := NotEmpty("username", config["username"]); err != nil {
+			return err
+		}
+	case "bearer":
+		if err := NotEmpty("token", config["token"]); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("auth_type must be one of [basic bearer]")
+	}
+	if config["hmac_secret"] != "" && config["hmac_algorithm"] != "" {
+		if err := InList("hmac_algorithm", config["hmac_algorithm"], []string{"sha1", "sha256", "sha512"}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package notifier
+
+import (
+	"github.com/detect-viz/shared-lib/models/common"
+	"github.com/detect-viz/shared-lib/notifier/validate"
+)
+
+// Webex incoming webhook：以 markdown 呈現告警
+func newWebexDriver() Driver {
+	return &httpDriver{
+		name:      "webex",
+		validator: &validate.WebhookValidator{},
+		buildBody: func(info common.NotifySetting) interface{} {
+			return map[string]interface{}{
+				"markdown": true,
+				"text":     "**" + info.Config["title"] + "**\n" + info.Config["message"],
+			}
+		},
+	}
+}
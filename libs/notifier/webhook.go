@@ -2,149 +2,115 @@ package notifier
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/detect-viz/shared-lib/models/common"
+	notifyerrors "github.com/detect-viz/shared-lib/notifier/errors"
+	"github.com/detect-viz/shared-lib/notifier/validate"
 )
 
-// 發送通知
-func (s *serviceImpl) sendWebhook(info common.NotifySetting) error {
-
-	var payload map[string]interface{}
-	switch info.Type {
-	case "discord":
-		payload = map[string]interface{}{
-			"embeds": []map[string]interface{}{
-				{
-					"title":       info.Config["title"],
-					"description": info.Config["message"],
-					"color":       16711680, // 紅色
-					"timestamp":   time.Now().Format(time.RFC3339),
-					"footer": map[string]string{
-						"text": "Alert System",
-					},
-				},
-			},
-		}
-	case "slack":
-		payload = map[string]interface{}{
-			"text": info.Config["title"] + "\n" + info.Config["message"],
-			"blocks": []map[string]interface{}{
-				{
-					"type": "header",
-					"text": map[string]string{
-						"type": "plain_text",
-						"text": info.Config["title"],
-					},
-				},
-				{
-					"type": "section",
-					"text": map[string]string{
-						"type": "mrkdwn",
-						"text": info.Config["message"],
-					},
-				},
-			},
-		}
-	case "webex":
-		payload = map[string]interface{}{
-			"markdown": true,
-			"text":     "**" + info.Config["title"] + "**\n" + info.Config["message"],
-		}
+// 通道回應內容最多記錄的長度
+const maxResponseBody = 4096
 
-	case "line":
-		if info.Config["url"] == "" {
-			info.Config["url"] = "https://api.line.me/v2/bot/message/push"
-		}
+// webhookClient 發送 HTTP 通道使用的客戶端
+var webhookClient = &http.Client{
+	Timeout: 30 * time.Second,
+}
 
-		// 處理訊息格式，確保換行符號正確顯示
-		lineMessage := info.Config["title"] + "\n\n" + info.Config["message"]
-		// 移除多餘的換行符號
-		lineMessage = strings.ReplaceAll(lineMessage, "\n\n\n", "\n\n")
-
-		payload = map[string]interface{}{
-			"to": info.Config["to"],
-			"messages": []map[string]interface{}{
-				{
-					"type": "text",
-					"text": lineMessage,
-				},
-			},
-		}
-	case "teams":
-		payload = map[string]interface{}{
-			"@type":      "MessageCard",
-			"@context":   "http://schema.org/extensions",
-			"summary":    info.Config["title"],
-			"themeColor": "0076D7",
-			"title":      info.Config["title"],
-			"text":       info.Config["message"],
-		}
+// httpDriver HTTP 類通道的共用實作，各通道只需提供 body 與 header
+type httpDriver struct {
+	name       string
+	defaultURL string
+	validator  validate.ConfigValidator
+	buildBody  func(info common.NotifySetting) interface{}
+	headers    func(info common.NotifySetting) map[string]string
+}
 
-	case "webhook":
-		payload = map[string]interface{}{
-			"title":   info.Config["title"],
-			"message": info.Config["message"],
-		}
-	}
-	data, err := json.Marshal(payload)
+func (d *httpDriver) Name() string {
+	return d.name
+}
+
+func (d *httpDriver) ValidateConfig(config map[string]string) error {
+	return d.validator.Validate(config)
+}
+
+func (d *httpDriver) BuildPayload(info common.NotifySetting) (*Payload, error) {
+	data, err := json.Marshal(d.buildBody(info))
 	if err != nil {
-		return err
+		return nil, notifyerrors.NewNotifyError(d.name, "marshal payload failed", err)
 	}
 
+	url := info.Config["url"]
+	if url == "" {
+		url = d.defaultURL
+	}
 	method := info.Config["method"]
 	if method == "" {
-		method = "POST"
+		method = http.MethodPost
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"User-Agent":   "DetectViz-Notifier/1.0",
 	}
+	if d.headers != nil {
+		for k, v := range d.headers(info) {
+			headers[k] = v
+		}
+	}
+
+	return &Payload{
+		Method:  method,
+		URL:     url,
+		Headers: headers,
+		Body:    data,
+	}, nil
+}
 
-	req, err := http.NewRequest(method, info.Config["url"], bytes.NewBuffer(data))
+func (d *httpDriver) Send(ctx context.Context, info common.NotifySetting) error {
+	payload, err := d.BuildPayload(info)
 	if err != nil {
 		return err
 	}
+	return sendWebhook(ctx, d.name, payload)
+}
 
-	// 設置請求頭
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", "DetectViz-Notifier/1.0")
-
-	// 根據通知類型設置特定請求頭
-	switch info.Type {
-	case "line":
-		// 只有當 channel_token 不為空時才添加到請求頭
-		if token, ok := info.Config["channel_token"]; ok && token != "" {
-			// 確保 token 格式正確
-			token = strings.TrimSpace(token)
-			req.Header.Set("Authorization", "Bearer "+token)
-			fmt.Printf("LINE 通知 Authorization 頭: Bearer %s\n", token)
-		} else {
-			fmt.Println("警告: LINE 通知缺少 channel_token")
-		}
+func (d *httpDriver) ClassifyError(err error) ErrorClass {
+	return classifyError(err)
+}
 
-	case "slack":
-		// ... existing code ...
+// sendWebhook 送出 HTTP 請求，非 2xx 回應回傳 *SendError
+func sendWebhook(ctx context.Context, channel string, payload *Payload) error {
+	req, err := http.NewRequestWithContext(ctx, payload.Method, payload.URL, bytes.NewReader(payload.Body))
+	if err != nil {
+		return notifyerrors.NewNotifyError(channel, "build request failed", fmt.Errorf("%w: %v", notifyerrors.ErrInvalidConfig, err))
+	}
+	for k, v := range payload.Headers {
+		req.Header.Set(k, v)
 	}
 
-	client := http.DefaultClient
-	resp, err := client.Do(req)
+	resp, err := webhookClient.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, err := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
 		if err != nil {
 			return fmt.Errorf("failed to read response body: %w", err)
 		}
-		return fmt.Errorf(
-			"failed to send message to %s: %d, config: %v, payload: %s, response: %s",
-			info.Type, resp.StatusCode, info.Config, string(data), string(bodyBytes),
-		)
+		return &SendError{
+			Channel:    channel,
+			StatusCode: resp.StatusCode,
+			Body:       string(bodyBytes),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
 	}
-
 	return nil
 }
@@ -0,0 +1,37 @@
+package oncall
+
+import (
+	"time"
+
+	"github.com/detect-viz/shared-lib/models"
+)
+
+// Service 值班表服務接口
+type Service interface {
+	// Create 創建值班表
+	Create(realm, user string, scheduleResp *models.OnCallScheduleResponse) (*models.OnCallScheduleResponse, error)
+
+	// Get 獲取值班表 (含目前及未來的覆蓋)
+	Get(realm, id string) (*models.OnCallScheduleResponse, error)
+
+	// List 獲取值班表列表
+	List(realm string, cursor int64, limit int) ([]models.OnCallScheduleResponse, int64, error)
+
+	// Update 更新值班表 (不含覆蓋)
+	Update(realm, user string, scheduleResp *models.OnCallScheduleResponse) (*models.OnCallScheduleResponse, error)
+
+	// Delete 刪除值班表
+	Delete(realm, id string) error
+
+	// CreateOverride 新增值班覆蓋
+	CreateOverride(realm, scheduleID, user string, overrideResp *models.OnCallOverrideResponse) (*models.OnCallOverrideResponse, error)
+
+	// DeleteOverride 刪除值班覆蓋
+	DeleteOverride(realm, scheduleID, overrideID string) error
+
+	// WhoIsOnCall 查詢指定時間的值班人員，沒有人值班時回傳 404
+	WhoIsOnCall(realm, scheduleID string, t time.Time) (*models.OnCallResponse, error)
+
+	// ResolveRuleContacts 解析規則綁定的值班表在指定時間的值班聯絡人
+	ResolveRuleContacts(ruleID []byte, t time.Time) ([]models.Contact, error)
+}
@@ -0,0 +1,374 @@
+package oncall
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/infra/logger"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/models/alert"
+	"github.com/detect-viz/shared-lib/storage/mysql"
+	"github.com/google/uuid"
+	"github.com/google/wire"
+	"go.uber.org/zap"
+)
+
+var OnCallSet = wire.NewSet(
+	NewService,
+	wire.Bind(new(Service), new(*serviceImpl)),
+)
+
+// Service 值班表服務
+type serviceImpl struct {
+	mysql  *mysql.Client
+	logger logger.Logger
+}
+
+// 創建值班表服務
+func NewService(mysql *mysql.Client, logger logger.Logger) *serviceImpl {
+	return &serviceImpl{
+		mysql:  mysql,
+		logger: logger,
+	}
+}
+
+// 創建值班表
+func (s *serviceImpl) Create(realm, user string, scheduleResp *models.OnCallScheduleResponse) (*models.OnCallScheduleResponse, error) {
+	schedule, err := s.fromResponse(*scheduleResp, realm)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validate(realm, &schedule); err != nil {
+		return nil, err
+	}
+	schedule.CreatedBy = &user
+	schedule.UpdatedBy = &user
+
+	created, err := s.mysql.CreateOnCallSchedule(&schedule)
+	if err != nil {
+		return nil, err
+	}
+	response := toResponse(*created)
+	return &response, nil
+}
+
+// 獲取值班表
+func (s *serviceImpl) Get(realm, id string) (*models.OnCallScheduleResponse, error) {
+	schedule, err := s.getRealmSchedule(realm, id, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	response := toResponse(*schedule)
+	return &response, nil
+}
+
+// 獲取值班表列表
+func (s *serviceImpl) List(realm string, cursor int64, limit int) ([]models.OnCallScheduleResponse, int64, error) {
+	schedules, nextCursor, err := s.mysql.ListOnCallSchedules(realm, cursor, limit, time.Now().Unix())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]models.OnCallScheduleResponse, len(schedules))
+	for i, schedule := range schedules {
+		responses[i] = toResponse(schedule)
+	}
+	return responses, nextCursor, nil
+}
+
+// 更新值班表
+func (s *serviceImpl) Update(realm, user string, scheduleResp *models.OnCallScheduleResponse) (*models.OnCallScheduleResponse, error) {
+	if _, err := s.getRealmSchedule(realm, scheduleResp.ID, 0); err != nil {
+		return nil, err
+	}
+	schedule, err := s.fromResponse(*scheduleResp, realm)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validate(realm, &schedule); err != nil {
+		return nil, err
+	}
+	schedule.UpdatedBy = &user
+
+	updated, err := s.mysql.UpdateOnCallSchedule(&schedule)
+	if err != nil {
+		return nil, err
+	}
+	response := toResponse(*updated)
+	return &response, nil
+}
+
+// 刪除值班表
+func (s *serviceImpl) Delete(realm, id string) error {
+	schedule, err := s.getRealmSchedule(realm, id, 0)
+	if err != nil {
+		return err
+	}
+	return s.mysql.DeleteOnCallSchedule(schedule.ID)
+}
+
+// 新增值班覆蓋
+func (s *serviceImpl) CreateOverride(realm, scheduleID, user string, overrideResp *models.OnCallOverrideResponse) (*models.OnCallOverrideResponse, error) {
+	schedule, err := s.getRealmSchedule(realm, scheduleID, 0)
+	if err != nil {
+		return nil, err
+	}
+	contactID, err := parseID(overrideResp.ContactID)
+	if err != nil {
+		return nil, err
+	}
+	if overrideResp.EndsAt <= overrideResp.StartsAt {
+		return nil, apierrors.NewAPIError(400, "ends_at 必須大於 starts_at", nil)
+	}
+	if err := s.checkContact(realm, contactID); err != nil {
+		return nil, err
+	}
+
+	override := models.OnCallOverride{
+		ScheduleID: schedule.ID,
+		ContactID:  contactID,
+		StartsAt:   overrideResp.StartsAt,
+		EndsAt:     overrideResp.EndsAt,
+		Reason:     overrideResp.Reason,
+		CreatedBy:  user,
+	}
+	if err := s.mysql.CreateOnCallOverride(&override); err != nil {
+		return nil, err
+	}
+	s.logger.Info("新增值班覆蓋",
+		zap.String("schedule", schedule.Name),
+		zap.String("contact_id", hex.EncodeToString(contactID)),
+		zap.Int64("starts_at", override.StartsAt),
+		zap.Int64("ends_at", override.EndsAt))
+
+	response := toOverrideResponse(override)
+	return &response, nil
+}
+
+// 刪除值班覆蓋
+func (s *serviceImpl) DeleteOverride(realm, scheduleID, overrideID string) error {
+	schedule, err := s.getRealmSchedule(realm, scheduleID, 0)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(overrideID)
+	if err != nil {
+		return err
+	}
+	return s.mysql.DeleteOnCallOverride(schedule.ID, id)
+}
+
+// 查詢指定時間的值班人員
+func (s *serviceImpl) WhoIsOnCall(realm, scheduleID string, t time.Time) (*models.OnCallResponse, error) {
+	schedule, err := s.getRealmSchedule(realm, scheduleID, 0)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := s.mysql.ListOnCallOverridesAt(schedule.ID, t.Unix())
+	if err != nil {
+		return nil, err
+	}
+	schedule.Overrides = overrides
+
+	shift, err := schedule.OnCallAt(t)
+	if err != nil {
+		return nil, apierrors.NewAPIError(500, "計算值班人員失敗", err)
+	}
+	if shift == nil {
+		return nil, apierrors.NewAPIError(404, "指定時間沒有值班人員", nil)
+	}
+
+	response := &models.OnCallResponse{
+		ScheduleID: hex.EncodeToString(schedule.ID),
+		Time:       t.Unix(),
+		Source:     shift.Source,
+		Rotation:   shift.Rotation,
+		ContactID:  hex.EncodeToString(shift.ContactID),
+		ShiftStart: shift.Start.Unix(),
+		ShiftEnd:   shift.End.Unix(),
+	}
+	if contact, err := s.mysql.GetContact(shift.ContactID); err == nil {
+		response.ContactName = contact.Name
+	}
+	return response, nil
+}
+
+// 解析規則綁定的值班表在指定時間的值班聯絡人
+func (s *serviceImpl) ResolveRuleContacts(ruleID []byte, t time.Time) ([]models.Contact, error) {
+	schedules, err := s.mysql.GetOnCallSchedulesByRuleID(ruleID, t.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	var contacts []models.Contact
+	seen := make(map[string]bool)
+	for _, schedule := range schedules {
+		shift, err := schedule.OnCallAt(t)
+		if err != nil {
+			s.logger.Error("計算值班人員失敗",
+				zap.Error(err),
+				zap.String("schedule", schedule.Name))
+			continue
+		}
+		if shift == nil || seen[string(shift.ContactID)] {
+			continue
+		}
+		seen[string(shift.ContactID)] = true
+
+		contact, err := s.mysql.GetContact(shift.ContactID)
+		if err != nil {
+			s.logger.Error("獲取值班聯絡人失敗",
+				zap.Error(err),
+				zap.String("schedule", schedule.Name),
+				zap.String("contact_id", hex.EncodeToString(shift.ContactID)))
+			continue
+		}
+		contacts = append(contacts, *contact)
+	}
+	return contacts, nil
+}
+
+// 取得指定域內的值班表，since 為載入覆蓋的起始時間
+func (s *serviceImpl) getRealmSchedule(realm, id string, since int64) (*models.OnCallSchedule, error) {
+	scheduleID, err := parseID(id)
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := s.mysql.GetOnCallSchedule(scheduleID, since)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.RealmName != realm {
+		return nil, apierrors.ErrNotFound
+	}
+	return schedule, nil
+}
+
+// 檢查值班表設定，並將輪值聯絡人 ID 統一為 hex 格式
+func (s *serviceImpl) validate(realm string, schedule *models.OnCallSchedule) error {
+	if schedule.Name == "" {
+		return apierrors.NewAPIError(400, "值班表名稱不可為空", nil)
+	}
+	if schedule.Timezone == "" {
+		schedule.Timezone = "UTC"
+	}
+	loc, err := schedule.Location()
+	if err != nil {
+		return apierrors.NewAPIError(400, fmt.Sprintf("時區無效 [timezone:%s]", schedule.Timezone), err)
+	}
+	if len(schedule.Rotations) == 0 {
+		return apierrors.NewAPIError(400, "至少需要一個輪值", nil)
+	}
+
+	for i := range schedule.Rotations {
+		rotation := &schedule.Rotations[i]
+		switch rotation.Type {
+		case alert.OnCallRotationDaily, alert.OnCallRotationWeekly:
+		default:
+			return apierrors.NewAPIError(400, fmt.Sprintf("輪值類型僅支援 daily / weekly [rotation:%d]", i), nil)
+		}
+		if rotation.Interval <= 0 {
+			rotation.Interval = 1
+		}
+
+		start, err := time.ParseInLocation(alert.OnCallTimeLayout, rotation.Start, loc)
+		if err != nil {
+			return apierrors.NewAPIError(400, fmt.Sprintf("輪值開始時間格式錯誤，需為 %s [rotation:%d]", alert.OnCallTimeLayout, i), err)
+		}
+		if rotation.End != "" {
+			end, err := time.ParseInLocation(alert.OnCallTimeLayout, rotation.End, loc)
+			if err != nil {
+				return apierrors.NewAPIError(400, fmt.Sprintf("輪值結束時間格式錯誤，需為 %s [rotation:%d]", alert.OnCallTimeLayout, i), err)
+			}
+			if !end.After(start) {
+				return apierrors.NewAPIError(400, fmt.Sprintf("輪值結束時間必須晚於開始時間 [rotation:%d]", i), nil)
+			}
+		}
+
+		if len(rotation.Participants) == 0 {
+			return apierrors.NewAPIError(400, fmt.Sprintf("輪值至少需要一個聯絡人 [rotation:%d]", i), nil)
+		}
+		for j, participant := range rotation.Participants {
+			contactID, err := parseID(participant)
+			if err != nil {
+				return err
+			}
+			if err := s.checkContact(realm, contactID); err != nil {
+				return err
+			}
+			rotation.Participants[j] = hex.EncodeToString(contactID)
+		}
+	}
+	return nil
+}
+
+// 檢查聯絡人是否存在於同一 realm
+func (s *serviceImpl) checkContact(realm string, contactID []byte) error {
+	contact, err := s.mysql.GetContact(contactID)
+	if err != nil || contact.RealmName != realm {
+		return apierrors.NewAPIError(400, fmt.Sprintf("聯絡人不存在 [contact_id:%s]", hex.EncodeToString(contactID)), err)
+	}
+	return nil
+}
+
+// 將 OnCallSchedule 轉換為 OnCallScheduleResponse
+func toResponse(schedule models.OnCallSchedule) models.OnCallScheduleResponse {
+	overrides := make([]models.OnCallOverrideResponse, 0, len(schedule.Overrides))
+	for _, override := range schedule.Overrides {
+		overrides = append(overrides, toOverrideResponse(override))
+	}
+	rotations := schedule.Rotations
+	if rotations == nil {
+		rotations = []models.OnCallRotation{}
+	}
+
+	return models.OnCallScheduleResponse{
+		ID:          hex.EncodeToString(schedule.ID),
+		Name:        schedule.Name,
+		Description: schedule.Description,
+		Timezone:    schedule.Timezone,
+		Rotations:   rotations,
+		Overrides:   overrides,
+	}
+}
+
+func toOverrideResponse(override models.OnCallOverride) models.OnCallOverrideResponse {
+	return models.OnCallOverrideResponse{
+		ID:        hex.EncodeToString(override.ID),
+		ContactID: hex.EncodeToString(override.ContactID),
+		StartsAt:  override.StartsAt,
+		EndsAt:    override.EndsAt,
+		Reason:    override.Reason,
+		CreatedBy: override.CreatedBy,
+	}
+}
+
+// 將 OnCallScheduleResponse 轉換為 OnCallSchedule (覆蓋另由 CreateOverride 管理)
+func (s *serviceImpl) fromResponse(resp models.OnCallScheduleResponse, realm string) (models.OnCallSchedule, error) {
+	schedule := models.OnCallSchedule{
+		RealmName:   realm,
+		Name:        resp.Name,
+		Description: resp.Description,
+		Timezone:    resp.Timezone,
+		Rotations:   resp.Rotations,
+	}
+	if resp.ID != "" {
+		id, err := parseID(resp.ID)
+		if err != nil {
+			return schedule, err
+		}
+		schedule.ID = id
+	}
+	return schedule, nil
+}
+
+// parseID 將字符串 ID 轉換為 []byte
+func parseID(idStr string) ([]byte, error) {
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return nil, apierrors.ErrInvalidID
+	}
+	return id[:], nil
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/detect-viz/shared-lib/models"
+)
+
+// Service 通知路由服務接口
+type Service interface {
+	// Get 獲取通知路由樹，未設定時回傳空的根路由
+	Get(realm string) (*models.NotifyRouteResponse, error)
+
+	// Update 取代通知路由樹
+	Update(realm, user string, routeResp *models.NotifyRouteResponse) (*models.NotifyRouteResponse, error)
+
+	// Delete 刪除通知路由樹
+	Delete(realm string) error
+
+	// Match 依告警欄位比對通知路由樹，未設定路由樹時回傳 nil
+	Match(realm string, fields map[string]string) ([]models.RouteMatch, error)
+
+	// Test 測試假設的告警欄位會符合的路由與接收的聯絡人
+	Test(realm string, fields map[string]string) (*models.RouteTestResponse, error)
+}
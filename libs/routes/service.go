@@ -0,0 +1,263 @@
+package routes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/infra/logger"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/storage/mysql"
+	"github.com/google/uuid"
+	"github.com/google/wire"
+)
+
+var RouteSet = wire.NewSet(
+	NewService,
+	wire.Bind(new(Service), new(*serviceImpl)),
+)
+
+const (
+	maxRouteDepth = 10               // 路由樹最大深度
+	routeCacheTTL = 30 * time.Second // 路由樹快取時間 (其他實例更新後最多延遲此時間生效)
+)
+
+// cachedRoute 快取的路由樹 (已預先編譯正規表示式 matcher)，root 為 nil 表示未設定
+// err 為路由樹無法編譯的錯誤，快取期間不重複讀取
+type cachedRoute struct {
+	root     *models.Route
+	err      error
+	loadedAt time.Time
+}
+
+// Service 通知路由服務
+type serviceImpl struct {
+	mysql  *mysql.Client
+	logger logger.Logger
+
+	mu    sync.Mutex
+	cache map[string]cachedRoute
+}
+
+// 創建通知路由服務
+func NewService(mysql *mysql.Client, logger logger.Logger) *serviceImpl {
+	return &serviceImpl{
+		mysql:  mysql,
+		logger: logger,
+		cache:  make(map[string]cachedRoute),
+	}
+}
+
+// 獲取通知路由樹
+func (s *serviceImpl) Get(realm string) (*models.NotifyRouteResponse, error) {
+	route, err := s.mysql.GetNotifyRoute(realm)
+	if err != nil {
+		return nil, err
+	}
+	if route == nil {
+		return &models.NotifyRouteResponse{Root: models.Route{Name: "root"}}, nil
+	}
+	return toResponse(*route), nil
+}
+
+// 取代通知路由樹
+func (s *serviceImpl) Update(realm, user string, routeResp *models.NotifyRouteResponse) (*models.NotifyRouteResponse, error) {
+	root := routeResp.Root
+	if len(root.Matchers) > 0 {
+		return nil, apierrors.NewAPIError(400, "根路由不可設定 matcher", nil)
+	}
+	if root.Continue {
+		return nil, apierrors.NewAPIError(400, "根路由不可設定 continue", nil)
+	}
+	if root.Name == "" {
+		root.Name = "root"
+	}
+	if err := s.validate(realm, &root, root.Name, 1); err != nil {
+		return nil, err
+	}
+
+	route := models.NotifyRoute{RealmName: realm, Root: root}
+	route.CreatedBy = &user
+	route.UpdatedBy = &user
+	if err := s.mysql.SaveNotifyRoute(&route); err != nil {
+		return nil, err
+	}
+	s.invalidate(realm)
+	return s.Get(realm)
+}
+
+// 刪除通知路由樹
+func (s *serviceImpl) Delete(realm string) error {
+	if err := s.mysql.DeleteNotifyRoute(realm); err != nil {
+		return err
+	}
+	s.invalidate(realm)
+	return nil
+}
+
+// 依告警欄位比對通知路由樹
+func (s *serviceImpl) Match(realm string, fields map[string]string) ([]models.RouteMatch, error) {
+	root, err := s.root(realm)
+	if err != nil || root == nil {
+		return nil, err
+	}
+	return root.Match(fields), nil
+}
+
+// 測試假設的告警欄位會符合的路由與接收的聯絡人
+func (s *serviceImpl) Test(realm string, fields map[string]string) (*models.RouteTestResponse, error) {
+	route, err := s.mysql.GetNotifyRoute(realm)
+	if err != nil {
+		return nil, err
+	}
+	if route == nil {
+		return nil, apierrors.NewAPIError(404, "尚未設定通知路由", nil)
+	}
+
+	response := &models.RouteTestResponse{
+		Labels:  fields,
+		Matches: []models.RouteMatchResponse{},
+	}
+	for _, match := range route.Root.Match(fields) {
+		receivers := make([]models.RuleContactResponse, 0, len(match.Receivers))
+		for _, receiver := range match.Receivers {
+			id, err := hex.DecodeString(receiver)
+			if err != nil {
+				continue
+			}
+			contact, err := s.mysql.GetContact(id)
+			if err != nil || contact.RealmName != realm {
+				// 路由設定後聯絡人已被刪除
+				receivers = append(receivers, models.RuleContactResponse{ID: receiver})
+				continue
+			}
+			receivers = append(receivers, models.RuleContactResponse{
+				ID:         receiver,
+				Name:       contact.Name,
+				Type:       contact.ChannelType,
+				Severities: contact.Severities,
+			})
+		}
+		response.Matches = append(response.Matches, models.RouteMatchResponse{
+			Path:        match.Path,
+			Receivers:   receivers,
+			GroupPolicy: match.GroupPolicy,
+		})
+	}
+	return response, nil
+}
+
+// root 取得快取的路由樹，過期時重新讀取
+func (s *serviceImpl) root(realm string) (*models.Route, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[realm]
+	s.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < routeCacheTTL {
+		return cached.root, cached.err
+	}
+
+	route, err := s.mysql.GetNotifyRoute(realm)
+	if err != nil {
+		return nil, err
+	}
+	cached = cachedRoute{loadedAt: time.Now()}
+	if route != nil {
+		if err := route.Root.Compile(); err != nil {
+			cached.err = fmt.Errorf("通知路由樹無效: %w", err)
+		} else {
+			cached.root = &route.Root
+		}
+	}
+
+	s.mu.Lock()
+	s.cache[realm] = cached
+	s.mu.Unlock()
+	return cached.root, cached.err
+}
+
+// invalidate 清除域的路由樹快取
+func (s *serviceImpl) invalidate(realm string) {
+	s.mu.Lock()
+	delete(s.cache, realm)
+	s.mu.Unlock()
+}
+
+// validate 檢查路由設定，並將 matcher operator 與聯絡人 ID 正規化
+func (s *serviceImpl) validate(realm string, route *models.Route, path string, depth int) error {
+	if depth > maxRouteDepth {
+		return apierrors.NewAPIError(400, fmt.Sprintf("路由樹深度不可超過 %d 層", maxRouteDepth), nil)
+	}
+
+	for i, m := range route.Matchers {
+		if m.Name == "" {
+			return apierrors.NewAPIError(400, fmt.Sprintf("matcher 名稱不可為空 [route:%s, index:%d]", path, i), nil)
+		}
+		switch m.Operator {
+		case "":
+			route.Matchers[i].Operator = "="
+		case "=", "!=":
+		case "=~", "!~":
+			if _, err := m.Regexp(); err != nil {
+				return apierrors.NewAPIError(400, fmt.Sprintf("matcher 正規表示式無效 [route:%s, name:%s]", path, m.Name), err)
+			}
+		default:
+			return apierrors.NewAPIError(400, fmt.Sprintf("matcher operator 僅支援 = / != / =~ / !~ [route:%s, name:%s]", path, m.Name), nil)
+		}
+	}
+
+	receivers := make([]string, 0, len(route.Receivers))
+	seen := make(map[string]bool)
+	for _, receiver := range route.Receivers {
+		id, err := uuid.Parse(receiver)
+		if err != nil {
+			return apierrors.NewAPIError(400, fmt.Sprintf("聯絡人 ID 格式錯誤 [route:%s, receiver:%s]", path, receiver), err)
+		}
+		contact, err := s.mysql.GetContact(id[:])
+		if err != nil || contact.RealmName != realm {
+			return apierrors.NewAPIError(400, fmt.Sprintf("聯絡人不存在 [route:%s, receiver:%s]", path, receiver), err)
+		}
+		key := hex.EncodeToString(id[:])
+		if !seen[key] {
+			seen[key] = true
+			receivers = append(receivers, key)
+		}
+	}
+	route.Receivers = receivers
+
+	if route.GroupPolicy != nil {
+		for _, field := range route.GroupPolicy.GroupBy {
+			if field == "" {
+				return apierrors.NewAPIError(400, fmt.Sprintf("group_by 欄位不可為空 [route:%s]", path), nil)
+			}
+		}
+		if _, _, _, err := route.GroupPolicy.Durations(); err != nil {
+			return apierrors.NewAPIError(400, fmt.Sprintf("分組時間格式錯誤 [route:%s]", path), err)
+		}
+	}
+
+	for i := range route.Routes {
+		child := &route.Routes[i]
+		name := child.Name
+		if name == "" {
+			name = fmt.Sprintf("routes[%d]", i)
+		}
+		if err := s.validate(realm, child, path+"/"+name, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 將 NotifyRoute 轉換為 NotifyRouteResponse
+func toResponse(route models.NotifyRoute) *models.NotifyRouteResponse {
+	response := &models.NotifyRouteResponse{
+		Root:      route.Root,
+		UpdatedAt: route.UpdatedAt,
+	}
+	if route.UpdatedBy != nil {
+		response.UpdatedBy = *route.UpdatedBy
+	}
+	return response
+}
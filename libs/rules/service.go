@@ -1,8 +1,10 @@
 package rules
 
 import (
+	"encoding/hex"
 	"fmt"
 
+	"github.com/detect-viz/shared-lib/apierrors"
 	"github.com/detect-viz/shared-lib/infra/logger"
 	"github.com/detect-viz/shared-lib/models"
 	"github.com/detect-viz/shared-lib/storage/mysql"
@@ -47,6 +49,20 @@ func (s *serviceImpl) Create(realm string, ruleResp *models.RuleResponse) (*mode
 	rule := s.FromResponse(*ruleResp)
 	rule.RealmName = realm // 設置 realm
 
+	// 檢查閾值及判斷模式設定
+	if err := s.validateThresholds(rule); err != nil {
+		return nil, err
+	}
+	if err := validateEvaluation(rule); err != nil {
+		return nil, err
+	}
+	if err := s.validateEscalationPolicy(realm, ruleResp.EscalationPolicyID, rule); err != nil {
+		return nil, err
+	}
+	if err := s.validateOnCallSchedules(realm, ruleResp.OnCallScheduleIDs, rule); err != nil {
+		return nil, err
+	}
+
 	// 創建規則
 	createdRule, err := s.mysql.CreateRule(&rule)
 	if err != nil {
@@ -123,16 +139,26 @@ func (s *serviceImpl) List(realm string, cursor int64, limit int) ([]models.Metr
 
 			// 創建 RuleResponse
 			ruleResponse := models.RuleResponse{
-				ID:            string(rule.ID),
-				AutoApply:     rule.AutoApply,
-				Enabled:       rule.Enabled,
-				InfoThreshold: rule.InfoThreshold,
-				WarnThreshold: rule.WarnThreshold,
-				CritThreshold: rule.CritThreshold,
-				Times:         rule.Times,
-				Duration:      rule.Duration,
-				SilencePeriod: rule.SilencePeriod,
-				Target:        rule.Target,
+				ID:                     string(rule.ID),
+				AutoApply:              rule.AutoApply,
+				Enabled:                rule.Enabled,
+				InfoThreshold:          rule.InfoThreshold,
+				WarnThreshold:          rule.WarnThreshold,
+				CritThreshold:          rule.CritThreshold,
+				InfoLowerThreshold:     rule.InfoLowerThreshold,
+				WarnLowerThreshold:     rule.WarnLowerThreshold,
+				CritLowerThreshold:     rule.CritLowerThreshold,
+				RecoveryThreshold:      rule.RecoveryThreshold,
+				RecoveryLowerThreshold: rule.RecoveryLowerThreshold,
+				EvaluationMode:         rule.EvaluationMode,
+				EvaluationPoints:       rule.EvaluationPoints,
+				Times:                  rule.Times,
+				NotifyTimes:            rule.NotifyTimes,
+				Duration:               rule.Duration,
+				SilencePeriod:          rule.SilencePeriod,
+				EscalationPolicyID:     formatPolicyID(rule.EscalationPolicyID),
+				OnCallScheduleIDs:      formatScheduleIDs(rule.OnCallSchedules),
+				Target:                 rule.Target,
 				// 需要從其他地方獲取 MetricRule 和 Contacts
 			}
 			ruleResponses = append(ruleResponses, ruleResponse)
@@ -171,6 +197,20 @@ func (s *serviceImpl) Update(realm string, ruleResp *models.RuleResponse) (*mode
 	// 保留原有的 ID
 	updatedRule.ID = existingRule.ID
 
+	// 檢查閾值及判斷模式設定
+	if err := s.validateThresholds(updatedRule); err != nil {
+		return nil, err
+	}
+	if err := validateEvaluation(updatedRule); err != nil {
+		return nil, err
+	}
+	if err := s.validateEscalationPolicy(realm, ruleResp.EscalationPolicyID, updatedRule); err != nil {
+		return nil, err
+	}
+	if err := s.validateOnCallSchedules(realm, ruleResp.OnCallScheduleIDs, updatedRule); err != nil {
+		return nil, err
+	}
+
 	// 更新規則
 	savedRule, err := s.mysql.UpdateRule(&updatedRule)
 	if err != nil {
@@ -242,6 +282,84 @@ func (s *serviceImpl) getMetricRuleByUID(uid string) (*models.MetricRule, error)
 	return &metricRule, nil
 }
 
+// validateThresholds 檢查區間運算子 (inside / outside) 的上下限設定
+func (s *serviceImpl) validateThresholds(rule models.Rule) error {
+	metricRule, err := s.getMetricRuleByUID(rule.MetricRuleUID)
+	if err != nil || (metricRule.Operator != "inside" && metricRule.Operator != "outside") {
+		return nil
+	}
+
+	type bound struct {
+		name  string
+		lower *float64
+		upper *float64
+	}
+	bounds := []bound{
+		{"crit", rule.CritLowerThreshold, &rule.CritThreshold},
+		{"warn", rule.WarnLowerThreshold, rule.WarnThreshold},
+		{"info", rule.InfoLowerThreshold, rule.InfoThreshold},
+		{"recovery", rule.RecoveryLowerThreshold, rule.RecoveryThreshold},
+	}
+	for _, b := range bounds {
+		if b.upper == nil {
+			continue
+		}
+		if b.lower == nil {
+			return apierrors.NewAPIError(400, fmt.Sprintf("區間運算子需設定 %s 下限閾值", b.name), nil)
+		}
+		if *b.lower > *b.upper {
+			return apierrors.NewAPIError(400, fmt.Sprintf("%s 下限閾值不可大於上限閾值", b.name), nil)
+		}
+	}
+	return nil
+}
+
+// validateEvaluation 檢查判斷模式 (all / n_of_m / consecutive) 設定
+func validateEvaluation(rule models.Rule) error {
+	if rule.EvaluationPoints < 0 {
+		return apierrors.NewAPIError(400, "evaluation_points 不可小於 0", nil)
+	}
+	switch rule.EvaluationMode {
+	case "", "all", "consecutive":
+	case "n_of_m":
+		if rule.EvaluationPoints > 0 && rule.Times > rule.EvaluationPoints {
+			return apierrors.NewAPIError(400, "times 不可大於 evaluation_points", nil)
+		}
+	default:
+		return apierrors.NewAPIError(400, fmt.Sprintf("不支援的 evaluation_mode: %s", rule.EvaluationMode), nil)
+	}
+	return nil
+}
+
+// validateEscalationPolicy 檢查規則引用的升級策略是否存在於同一 realm
+func (s *serviceImpl) validateEscalationPolicy(realm, policyIDStr string, rule models.Rule) error {
+	if policyIDStr == "" {
+		return nil
+	}
+	if len(rule.EscalationPolicyID) == 0 {
+		return apierrors.ErrInvalidID
+	}
+	policy, err := s.mysql.GetEscalationPolicy(rule.EscalationPolicyID)
+	if err != nil || policy.RealmName != realm {
+		return apierrors.NewAPIError(400, "升級策略不存在", err)
+	}
+	return nil
+}
+
+// validateOnCallSchedules 檢查規則綁定的值班表是否存在於同一 realm
+func (s *serviceImpl) validateOnCallSchedules(realm string, scheduleIDs []string, rule models.Rule) error {
+	if len(rule.OnCallSchedules) != len(scheduleIDs) {
+		return apierrors.ErrInvalidID
+	}
+	for _, schedule := range rule.OnCallSchedules {
+		found, err := s.mysql.GetOnCallSchedule(schedule.ID, 0)
+		if err != nil || found.RealmName != realm {
+			return apierrors.NewAPIError(400, "值班表不存在", err)
+		}
+	}
+	return nil
+}
+
 // ToResponse 將 Rule 轉換為 RuleResponse
 func (s *serviceImpl) ToResponse(rule models.Rule) models.RuleResponse {
 	// 獲取 MetricRule 信息
@@ -266,18 +384,28 @@ func (s *serviceImpl) ToResponse(rule models.Rule) models.RuleResponse {
 
 	// 創建 RuleResponse
 	response := models.RuleResponse{
-		ID:            string(rule.ID),
-		AutoApply:     rule.AutoApply,
-		Enabled:       rule.Enabled,
-		InfoThreshold: rule.InfoThreshold,
-		WarnThreshold: rule.WarnThreshold,
-		CritThreshold: rule.CritThreshold,
-		Times:         rule.Times,
-		Duration:      rule.Duration,
-		SilencePeriod: rule.SilencePeriod,
-		Target:        rule.Target,
-		MetricRule:    metricRule,
-		Contacts:      contactsResponse,
+		ID:                     string(rule.ID),
+		AutoApply:              rule.AutoApply,
+		Enabled:                rule.Enabled,
+		InfoThreshold:          rule.InfoThreshold,
+		WarnThreshold:          rule.WarnThreshold,
+		CritThreshold:          rule.CritThreshold,
+		InfoLowerThreshold:     rule.InfoLowerThreshold,
+		WarnLowerThreshold:     rule.WarnLowerThreshold,
+		CritLowerThreshold:     rule.CritLowerThreshold,
+		RecoveryThreshold:      rule.RecoveryThreshold,
+		RecoveryLowerThreshold: rule.RecoveryLowerThreshold,
+		EvaluationMode:         rule.EvaluationMode,
+		EvaluationPoints:       rule.EvaluationPoints,
+		Times:                  rule.Times,
+		NotifyTimes:            rule.NotifyTimes,
+		Duration:               rule.Duration,
+		SilencePeriod:          rule.SilencePeriod,
+		EscalationPolicyID:     formatPolicyID(rule.EscalationPolicyID),
+		OnCallScheduleIDs:      formatScheduleIDs(rule.OnCallSchedules),
+		Target:                 rule.Target,
+		MetricRule:             metricRule,
+		Contacts:               contactsResponse,
 	}
 
 	return response
@@ -294,14 +422,22 @@ func (s *serviceImpl) FromResponse(ruleResp models.RuleResponse) models.Rule {
 			PartitionName:  ruleResp.Target.PartitionName,
 			DatasourceName: ruleResp.Target.DatasourceName,
 		},
-		AutoApply:     ruleResp.AutoApply,
-		Enabled:       ruleResp.Enabled,
-		InfoThreshold: ruleResp.InfoThreshold,
-		WarnThreshold: ruleResp.WarnThreshold,
-		CritThreshold: ruleResp.CritThreshold,
-		Times:         ruleResp.Times,
-		Duration:      ruleResp.Duration,
-		SilencePeriod: ruleResp.SilencePeriod,
+		AutoApply:              ruleResp.AutoApply,
+		Enabled:                ruleResp.Enabled,
+		InfoThreshold:          ruleResp.InfoThreshold,
+		WarnThreshold:          ruleResp.WarnThreshold,
+		CritThreshold:          ruleResp.CritThreshold,
+		InfoLowerThreshold:     ruleResp.InfoLowerThreshold,
+		WarnLowerThreshold:     ruleResp.WarnLowerThreshold,
+		CritLowerThreshold:     ruleResp.CritLowerThreshold,
+		RecoveryThreshold:      ruleResp.RecoveryThreshold,
+		RecoveryLowerThreshold: ruleResp.RecoveryLowerThreshold,
+		EvaluationMode:         ruleResp.EvaluationMode,
+		EvaluationPoints:       ruleResp.EvaluationPoints,
+		Times:                  ruleResp.Times,
+		NotifyTimes:            ruleResp.NotifyTimes,
+		Duration:               ruleResp.Duration,
+		SilencePeriod:          ruleResp.SilencePeriod,
 	}
 
 	// 如果有 ID，則設置
@@ -312,6 +448,26 @@ func (s *serviceImpl) FromResponse(ruleResp models.RuleResponse) models.Rule {
 		}
 	}
 
+	// 升級策略
+	if ruleResp.EscalationPolicyID != "" {
+		id, err := s.parseID(ruleResp.EscalationPolicyID)
+		if err != nil {
+			s.logger.Warn("解析升級策略 ID 失敗", zap.Error(err))
+		} else {
+			rule.EscalationPolicyID = id
+		}
+	}
+
+	// 值班表
+	for _, scheduleID := range ruleResp.OnCallScheduleIDs {
+		id, err := s.parseID(scheduleID)
+		if err != nil {
+			s.logger.Warn("解析值班表 ID 失敗", zap.Error(err))
+			continue
+		}
+		rule.OnCallSchedules = append(rule.OnCallSchedules, models.OnCallSchedule{ID: id})
+	}
+
 	// 處理聯絡人
 	for _, contactResp := range ruleResp.Contacts {
 		id, err := s.parseID(contactResp.ID)
@@ -330,6 +486,23 @@ func (s *serviceImpl) FromResponse(ruleResp models.RuleResponse) models.Rule {
 	return rule
 }
 
+// formatPolicyID 將升級策略 ID 轉換為字符串，未設定時為空字串
+func formatPolicyID(id []byte) string {
+	if len(id) == 0 {
+		return ""
+	}
+	return hex.EncodeToString(id)
+}
+
+// formatScheduleIDs 將值班表轉換為 ID 列表
+func formatScheduleIDs(schedules []models.OnCallSchedule) []string {
+	ids := make([]string, 0, len(schedules))
+	for _, schedule := range schedules {
+		ids = append(ids, hex.EncodeToString(schedule.ID))
+	}
+	return ids
+}
+
 // parseID 將字符串 ID 轉換為 []byte
 func (s *serviceImpl) parseID(idStr string) ([]byte, error) {
 	id, err := uuid.Parse(idStr)
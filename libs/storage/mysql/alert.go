@@ -135,3 +135,60 @@ func (c *Client) GetRuleLabelByRuleID(ruleID []byte) (map[string]string, error)
 
 	return result, nil
 }
+
+// 批次獲取多條規則的標籤，回傳以 string(rule_id) 為 key 的標籤 map
+func (c *Client) GetRuleLabelsByRuleIDs(ruleIDs [][]byte) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string, len(ruleIDs))
+	if len(ruleIDs) == 0 {
+		return result, nil
+	}
+
+	type RuleLabel struct {
+		RuleID       []byte `gorm:"type:binary(16);primaryKey"`
+		LabelValueID int64  `gorm:"primaryKey"`
+	}
+
+	// 一次取出所有規則的 label_value_id
+	var ruleLabels []RuleLabel
+	err := c.db.Model(&RuleLabel{}).
+		Where("rule_id IN (?)", ruleIDs).
+		Find(&ruleLabels).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(ruleLabels) == 0 {
+		return result, nil
+	}
+
+	labelValueIDs := make([]int64, 0, len(ruleLabels))
+	for _, rl := range ruleLabels {
+		labelValueIDs = append(labelValueIDs, rl.LabelValueID)
+	}
+
+	// 再一次取出 label_values 及其關聯的 label_keys
+	var labelValues []label.LabelValue
+	err = c.db.Preload("LabelKey").
+		Where("id IN (?)", labelValueIDs).
+		Find(&labelValues).Error
+	if err != nil {
+		return nil, err
+	}
+	valueByID := make(map[int64]label.LabelValue, len(labelValues))
+	for _, lv := range labelValues {
+		valueByID[lv.ID] = lv
+	}
+
+	for _, rl := range ruleLabels {
+		lv, ok := valueByID[rl.LabelValueID]
+		if !ok {
+			continue
+		}
+		labels, ok := result[string(rl.RuleID)]
+		if !ok {
+			labels = make(map[string]string)
+			result[string(rl.RuleID)] = labels
+		}
+		labels[lv.LabelKey.KeyName] = lv.Value
+	}
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package mysql
+
+import (
+	"errors"
+	"time"
+
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+)
+
+// ClaimNotifyActionLog 在執行操作前寫入操作紀錄，以唯一索引 (notify_log_id, triggered_log_id, action) 保證只執行一次
+// 已有成功紀錄時回傳 false；先前執行失敗的紀錄會被重新領取，允許再次執行
+func (c *Client) ClaimNotifyActionLog(action *models.NotifyActionLog) (bool, error) {
+	action.ID = GenerateUUID16()
+	err := ParseDBError(c.db.Create(action).Error)
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, apierrors.ErrDuplicateEntry) {
+		return false, err
+	}
+
+	result := c.db.Model(&models.NotifyActionLog{}).
+		Where("notify_log_id = ? AND triggered_log_id = ? AND action = ?", action.NotifyLogID, action.TriggeredLogID, action.Action).
+		Where("error IS NOT NULL AND error <> ''").
+		Updates(map[string]interface{}{
+			"contact_id":   action.ContactID,
+			"channel_type": action.ChannelType,
+			"actor":        action.Actor,
+			"error":        "",
+			"created_at":   time.Now().Unix(),
+		})
+	if result.Error != nil {
+		return false, ParseDBError(result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateNotifyActionLogError 記錄已領取操作的失敗原因
+func (c *Client) UpdateNotifyActionLogError(action *models.NotifyActionLog) error {
+	err := c.db.Model(&models.NotifyActionLog{}).
+		Where("notify_log_id = ? AND triggered_log_id = ? AND action = ?", action.NotifyLogID, action.TriggeredLogID, action.Action).
+		Update("error", action.Error).Error
+	return ParseDBError(err)
+}
+
+// ListNotifyActionLogs 獲取告警的通知訊息操作紀錄
+func (c *Client) ListNotifyActionLogs(triggeredLogID []byte) ([]models.NotifyActionLog, error) {
+	var actions []models.NotifyActionLog
+	err := c.db.Where("triggered_log_id = ?", triggeredLogID).Order("created_at").Find(&actions).Error
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+	return actions, nil
+}
@@ -0,0 +1,28 @@
+package mysql
+
+import (
+	"github.com/detect-viz/shared-lib/models"
+	"gorm.io/gorm/clause"
+)
+
+// CreateNotifyDeliveries 記錄告警對聯絡人的第一次異常通知，已有紀錄的告警與聯絡人不更新
+func (c *Client) CreateNotifyDeliveries(deliveries []models.NotifyDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	err := c.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+	return ParseDBError(err)
+}
+
+// GetNotifyDeliveries 獲取告警的異常通知紀錄
+func (c *Client) GetNotifyDeliveries(triggeredLogIDs [][]byte) ([]models.NotifyDelivery, error) {
+	var deliveries []models.NotifyDelivery
+	if len(triggeredLogIDs) == 0 {
+		return deliveries, nil
+	}
+	err := c.db.Where("triggered_log_id IN ?", triggeredLogIDs).Find(&deliveries).Error
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+	return deliveries, nil
+}
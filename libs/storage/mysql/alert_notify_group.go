@@ -0,0 +1,57 @@
+package mysql
+
+import (
+	"errors"
+
+	"github.com/detect-viz/shared-lib/models"
+	"gorm.io/gorm"
+)
+
+// GetNotifyGroup 獲取聯絡人的告警分組，不存在時回傳 nil
+func (c *Client) GetNotifyGroup(contactID []byte, groupKey string) (*models.NotifyGroup, error) {
+	var group models.NotifyGroup
+	err := c.db.Where("contact_id = ? AND group_key = ?", contactID, groupKey).First(&group).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+	return &group, nil
+}
+
+// CreateNotifyGroup 建立告警分組
+func (c *Client) CreateNotifyGroup(group *models.NotifyGroup) error {
+	group.ID = GenerateUUID16()
+	return ParseDBError(c.db.Create(group).Error)
+}
+
+// UpdateNotifyGroupNotifiedAt 更新告警分組的最後通知時間
+func (c *Client) UpdateNotifyGroupNotifiedAt(id []byte, notifiedAt int64) error {
+	return c.db.Model(&models.NotifyGroup{}).
+		Where("id = ?", id).
+		Update("last_notified_at", notifiedAt).Error
+}
+
+// DeleteIdleNotifyGroups 刪除已沒有告警的分組：
+// 已通知過且不在 activeIDs 中的分組，以及 waitingBefore 之前建立、仍未通知的分組
+func (c *Client) DeleteIdleNotifyGroups(activeIDs [][]byte, waitingBefore int64) error {
+	query := c.db.Where("(last_notified_at IS NOT NULL) OR (first_seen_at < ?)", waitingBefore)
+	if len(activeIDs) > 0 {
+		query = query.Where("id NOT IN ?", activeIDs)
+	}
+	return query.Delete(&models.NotifyGroup{}).Error
+}
+
+// GetFiringTriggeredLogs 獲取已發送通知、尚未確認及恢復的告警 (重複通知使用)
+func (c *Client) GetFiringTriggeredLogs() ([]models.TriggeredLog, error) {
+	var logs []models.TriggeredLog
+	err := c.db.
+		Where("notify_state = ?", "sent").
+		Where("acked_at IS NULL AND resolved_at IS NULL").
+		Find(&logs).Error
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+	return logs, nil
+}
@@ -13,7 +13,10 @@ func (c *Client) UpdateNotifyLog(notify models.NotifyLog) error {
 
 // 寫入通知日誌
 func (c *Client) CreateNotifyLog(notify models.NotifyLog) error {
-	notify.ID = GenerateUUID16()
+	// 發送前已產生 ID (通知訊息的操作權杖需引用) 時沿用
+	if len(notify.ID) == 0 {
+		notify.ID = GenerateUUID16()
+	}
 
 	// 開啟交易
 	tx := c.db.Begin()
@@ -31,11 +34,12 @@ func (c *Client) CreateNotifyLog(notify models.NotifyLog) error {
 	return tx.Commit().Error
 }
 
-// GetFailedNotifyLogs 獲取所有失敗的通知記錄
-func (c *Client) GetFailedNotifyLogs() ([]models.NotifyLog, error) {
+// GetFailedNotifyLogs 獲取已到達重試時間的失敗通知記錄
+func (c *Client) GetFailedNotifyLogs(now int64) ([]models.NotifyLog, error) {
 	var logs []models.NotifyLog
 	err := c.db.
-		Where("state = ? AND retry_counter < ?", "failed", 3).
+		Where("state = ?", "failed").
+		Where("next_retry_at IS NULL OR next_retry_at <= ?", now).
 		Find(&logs).Error
 	if err != nil {
 		return nil, fmt.Errorf("查詢失敗的通知記錄失敗: %w", err)
@@ -43,6 +47,40 @@ func (c *Client) GetFailedNotifyLogs() ([]models.NotifyLog, error) {
 	return logs, nil
 }
 
+// GetNotifyLog 根據 ID 獲取通知記錄
+func (c *Client) GetNotifyLog(id []byte) (*models.NotifyLog, error) {
+	var log models.NotifyLog
+	if err := c.db.Where("id = ?", id).First(&log).Error; err != nil {
+		return nil, ParseDBError(err)
+	}
+	return &log, nil
+}
+
+// ListNotifyLogsByState 依狀態獲取通知記錄列表
+func (c *Client) ListNotifyLogsByState(realm, state string, cursor int64, limit int) ([]models.NotifyLog, int64, error) {
+	var logs []models.NotifyLog
+
+	query := c.db.Model(&models.NotifyLog{}).
+		Where("realm_name = ? AND state = ?", realm, state)
+	if cursor > 0 {
+		query = query.Where("created_at > ?", cursor)
+	}
+
+	err := query.Order("created_at ASC").
+		Limit(limit).
+		Find(&logs).Error
+	if err != nil {
+		return nil, 0, ParseDBError(err)
+	}
+
+	// 計算 next_cursor
+	nextCursor := int64(-1)
+	if len(logs) > 0 && len(logs) >= limit {
+		nextCursor = logs[len(logs)-1].CreatedAt
+	}
+	return logs, nextCursor, nil
+}
+
 // GetTriggeredLog 根據 ID 獲取觸發日誌
 func (c *Client) GetTriggeredLog(id []byte) (*models.TriggeredLog, error) {
 	var log models.TriggeredLog
@@ -0,0 +1,36 @@
+package mysql
+
+import (
+	"errors"
+
+	"github.com/detect-viz/shared-lib/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetNotifyRoute 獲取域的通知路由樹，未設定時回傳 nil
+func (c *Client) GetNotifyRoute(realm string) (*models.NotifyRoute, error) {
+	var route models.NotifyRoute
+	err := c.db.Where("realm_name = ?", realm).First(&route).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+	return &route, nil
+}
+
+// SaveNotifyRoute 新增或取代域的通知路由樹
+func (c *Client) SaveNotifyRoute(route *models.NotifyRoute) error {
+	err := c.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "realm_name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"root", "updated_by", "updated_at"}),
+	}).Create(route).Error
+	return ParseDBError(err)
+}
+
+// DeleteNotifyRoute 刪除域的通知路由樹
+func (c *Client) DeleteNotifyRoute(realm string) error {
+	return ParseDBError(c.db.Where("realm_name = ?", realm).Delete(&models.NotifyRoute{}).Error)
+}
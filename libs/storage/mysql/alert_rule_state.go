@@ -21,7 +21,7 @@ func (c *Client) GetRuleStateAndLock(ruleID []byte) (*models.RuleState, error) {
 	return &state, nil
 }
 
-// * 如果 值為 nil 或 不變動，則不會更新該欄位。
+// * 如果 值為 nil 或 不變動，則不會更新該欄位；由有值變為 nil / 0 的欄位會被清除。
 func (c *Client) UpdateRuleStateWithUpdates(oldState, newState models.RuleState) error {
 
 	// **Step 1: 比對 oldState & newState，若無變更則跳過更新
@@ -37,5 +37,36 @@ func (c *Client) UpdateRuleStateWithUpdates(oldState, newState models.RuleState)
 		return fmt.Errorf("更新 RuleState 失敗: %w", err)
 	}
 
+	// **Step 3: Updates 會略過 nil / 零值，恢復時需明確清除的欄位另外更新
+	cleared := map[string]interface{}{}
+	if oldState.FirstTriggeredAt != nil && newState.FirstTriggeredAt == nil {
+		cleared["first_triggered_at"] = nil
+	}
+	if oldState.LastTriggeredSeverity != nil && newState.LastTriggeredSeverity == nil {
+		cleared["last_triggered_severity"] = nil
+	}
+	if oldState.LastTriggeredLogID != nil && newState.LastTriggeredLogID == nil {
+		cleared["last_triggered_log_id"] = nil
+	}
+	if oldState.SilenceStartAt != nil && newState.SilenceStartAt == nil {
+		cleared["silence_start_at"] = nil
+	}
+	if oldState.SilenceEndAt != nil && newState.SilenceEndAt == nil {
+		cleared["silence_end_at"] = nil
+	}
+	if oldState.ContactCounter != 0 && newState.ContactCounter == 0 {
+		cleared["contact_counter"] = 0
+	}
+	if oldState.BreachCounter != 0 && newState.BreachCounter == 0 {
+		cleared["breach_counter"] = 0
+	}
+	if len(cleared) > 0 {
+		if err := c.db.Model(&models.RuleState{}).
+			Where("rule_id = ?", oldState.RuleID).
+			Updates(cleared).Error; err != nil {
+			return fmt.Errorf("清除 RuleState 欄位失敗: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -64,13 +64,11 @@ func (c *Client) UpdateTriggeredLogResolved(ruleID string, resourceName, metricN
 }
 
 // 獲取活動的觸發日誌
-func (c *Client) GetActiveTriggeredLog(ruleID []byte, resourceName, metricName string) (*models.TriggeredLog, error) {
+func (c *Client) GetActiveTriggeredLog(ruleID []byte, resourceName string) (*models.TriggeredLog, error) {
 	var triggered models.TriggeredLog
 	err := c.db.
-		Where(`rule_id = ? AND resource_name = ? AND metric_name = ? 
-			   AND resolved_time IS NULL 
-			   AND (notify_state = 'pending' OR notify_state = 'failed')`,
-			ruleID, resourceName, metricName).
+		Where("rule_id = ? AND resource_name = ? AND resolved_at IS NULL", ruleID, resourceName).
+		Order("triggered_at DESC").
 		First(&triggered).Error
 
 	if err != nil {
@@ -91,7 +89,7 @@ func (c *Client) GetPendingTriggeredLogs(timestamp int64) ([]models.TriggeredLog
 		Joins("LEFT JOIN rule_states ON triggered_logs.rule_id = rule_states.rule_id").
 		Where(`triggered_logs.triggered_at < ? 
 			   AND (rule_states.silence_start_at IS NULL OR rule_states.silence_end_at IS NULL OR rule_states.silence_end_at < ?) 
-			   AND (triggered_logs.notify_state = 'pending' OR triggered_logs.notify_state = 'failed')
+			   AND triggered_logs.notify_state IN ('pending', 'failed', 'muted', 'inhibited')
 			   AND triggered_logs.resolved_at IS NULL`,
 			timestamp, time.Now().Unix()).
 		Find(&triggereds).Error
@@ -134,7 +132,88 @@ func (c *Client) UpdateTriggeredLogNotifyState(triggeredID []byte, notifyState s
 func (c *Client) UpdateTriggeredLogResolvedNotifyState(triggeredID []byte, notifyState string) error {
 	return c.db.
 		Model(&models.TriggeredLog{}).
-		Where("triggered_id = ?", triggeredID).
+		Where("id = ?", triggeredID).
 		Update("resolved_notify_state", notifyState).
 		Error
 }
+
+// ResolveTriggeredLogManually 手動將告警標記為已恢復，已恢復的告警不變更
+func (c *Client) ResolveTriggeredLogManually(triggeredID []byte, resolvedAt int64, resolvedBy string) error {
+	return c.db.
+		Model(&models.TriggeredLog{}).
+		Where("id = ? AND resolved_at IS NULL", triggeredID).
+		Updates(map[string]interface{}{
+			"resolved_at": resolvedAt,
+			"resolved_by": resolvedBy,
+		}).
+		Error
+}
+
+// GetUnresolvedTriggeredLogs 獲取域內尚未恢復的觸發日誌
+func (c *Client) GetUnresolvedTriggeredLogs(realm string) ([]models.TriggeredLog, error) {
+	var logs []models.TriggeredLog
+	err := c.db.Where("realm_name = ? AND resolved_at IS NULL", realm).Find(&logs).Error
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+	return logs, nil
+}
+
+// InhibitTriggeredLog 將告警標記為被抑制並記錄抑制它的來源告警
+func (c *Client) InhibitTriggeredLog(triggeredID, inhibitedBy []byte) error {
+	return c.db.
+		Model(&models.TriggeredLog{}).
+		Where("id = ?", triggeredID).
+		Updates(map[string]interface{}{
+			"notify_state": "inhibited",
+			"inhibited_by": inhibitedBy,
+		}).
+		Error
+}
+
+// ClearTriggeredLogInhibitedBy 清除告警的抑制來源
+func (c *Client) ClearTriggeredLogInhibitedBy(triggeredID []byte) error {
+	return c.db.
+		Model(&models.TriggeredLog{}).
+		Where("id = ?", triggeredID).
+		Update("inhibited_by", nil).
+		Error
+}
+
+// AckTriggeredLog 記錄告警確認人與確認時間
+func (c *Client) AckTriggeredLog(triggeredID []byte, ackedBy string, ackedAt int64) error {
+	return c.db.
+		Model(&models.TriggeredLog{}).
+		Where("id = ?", triggeredID).
+		Updates(map[string]interface{}{
+			"acked_by": ackedBy,
+			"acked_at": ackedAt,
+		}).
+		Error
+}
+
+// UnackTriggeredLog 取消告警確認，因確認而暫停的通知恢復為待發送
+func (c *Client) UnackTriggeredLog(triggeredID []byte, ackedState string) error {
+	return c.db.
+		Model(&models.TriggeredLog{}).
+		Where("id = ?", triggeredID).
+		Updates(map[string]interface{}{
+			"acked_by":     nil,
+			"acked_at":     nil,
+			"notify_state": gorm.Expr("CASE WHEN notify_state = ? THEN 'pending' ELSE notify_state END", ackedState),
+		}).
+		Error
+}
+
+// AssignTriggeredLog 指派告警處理人
+func (c *Client) AssignTriggeredLog(triggeredID []byte, assignee, assignedBy string, assignedAt int64) error {
+	return c.db.
+		Model(&models.TriggeredLog{}).
+		Where("id = ?", triggeredID).
+		Updates(map[string]interface{}{
+			"assigned_to": assignee,
+			"assigned_by": assignedBy,
+			"assigned_at": assignedAt,
+		}).
+		Error
+}
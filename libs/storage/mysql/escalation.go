@@ -0,0 +1,239 @@
+package mysql
+
+import (
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"gorm.io/gorm"
+)
+
+// 創建升級策略
+func (c *Client) CreateEscalationPolicy(policy *models.EscalationPolicy) (*models.EscalationPolicy, error) {
+	policy.ID = GenerateUUID16()
+	exists, err := c.Exists(policy.RealmName, "escalation_policies", "name", policy.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, apierrors.ErrDuplicateEntry
+	}
+
+	err = c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Steps").Create(policy).Error; err != nil {
+			return ParseDBError(err)
+		}
+		return createEscalationSteps(tx, policy)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.GetEscalationPolicy(policy.ID)
+}
+
+// 獲取升級策略 (含步驟與聯絡人)
+func (c *Client) GetEscalationPolicy(id []byte) (*models.EscalationPolicy, error) {
+	var policy models.EscalationPolicy
+	err := c.db.Preload("Steps", func(db *gorm.DB) *gorm.DB {
+		return db.Order("step_order ASC")
+	}).First(&policy, "id = ?", id).Error
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+
+	for i := range policy.Steps {
+		contacts, err := c.getEscalationStepContacts(policy.ID, policy.Steps[i].StepOrder)
+		if err != nil {
+			return nil, err
+		}
+		policy.Steps[i].Contacts = contacts
+	}
+	return &policy, nil
+}
+
+// 獲取升級策略列表
+func (c *Client) ListEscalationPolicies(realm string, cursor int64, limit int) ([]models.EscalationPolicy, int64, error) {
+	var policies []models.EscalationPolicy
+
+	query := c.db.Model(&models.EscalationPolicy{}).
+		Where("realm_name = ?", realm)
+	if cursor > 0 {
+		query = query.Where("created_at > ?", cursor)
+	}
+
+	err := query.Order("created_at ASC").
+		Limit(limit).
+		Preload("Steps", func(db *gorm.DB) *gorm.DB {
+			return db.Order("step_order ASC")
+		}).
+		Find(&policies).Error
+	if err != nil {
+		return nil, 0, ParseDBError(err)
+	}
+
+	for i := range policies {
+		for j := range policies[i].Steps {
+			contacts, err := c.getEscalationStepContacts(policies[i].ID, policies[i].Steps[j].StepOrder)
+			if err != nil {
+				return nil, 0, err
+			}
+			policies[i].Steps[j].Contacts = contacts
+		}
+	}
+
+	// 計算 next_cursor
+	nextCursor := int64(-1)
+	if len(policies) > 0 && len(policies) >= limit {
+		nextCursor = policies[len(policies)-1].CreatedAt
+	}
+	return policies, nextCursor, nil
+}
+
+// 更新升級策略 (步驟整批取代)
+func (c *Client) UpdateEscalationPolicy(policy *models.EscalationPolicy) (*models.EscalationPolicy, error) {
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		// 1. 檢查 name 是否已存在（排除自身 ID）
+		var count int64
+		err := tx.Model(&models.EscalationPolicy{}).
+			Where("realm_name = ? AND name = ? AND id != ?", policy.RealmName, policy.Name, policy.ID).
+			Count(&count).Error
+		if err != nil {
+			return ParseDBError(err)
+		}
+		if count > 0 {
+			return apierrors.ErrDuplicateEntry
+		}
+
+		// 2. 更新策略本身
+		result := tx.Model(&models.EscalationPolicy{}).
+			Where("id = ? AND realm_name = ?", policy.ID, policy.RealmName).
+			Updates(map[string]interface{}{
+				"name":        policy.Name,
+				"description": policy.Description,
+				"updated_by":  policy.UpdatedBy,
+			})
+		if result.Error != nil {
+			return ParseDBError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apierrors.ErrNotFound
+		}
+
+		// 3. 取代步驟
+		if err := tx.Where("policy_id = ?", policy.ID).Delete(&models.EscalationStepContact{}).Error; err != nil {
+			return ParseDBError(err)
+		}
+		if err := tx.Where("policy_id = ?", policy.ID).Delete(&models.EscalationStep{}).Error; err != nil {
+			return ParseDBError(err)
+		}
+		return createEscalationSteps(tx, policy)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c.GetEscalationPolicy(policy.ID)
+}
+
+// 刪除升級策略，仍被規則使用時拒絕刪除
+func (c *Client) DeleteEscalationPolicy(id []byte) error {
+	var count int64
+	if err := c.db.Model(&models.Rule{}).Where("escalation_policy_id = ?", id).Count(&count).Error; err != nil {
+		return ParseDBError(err)
+	}
+	if count > 0 {
+		return apierrors.ErrUsedByRules
+	}
+
+	result := c.db.Delete(&models.EscalationPolicy{}, "id = ?", id)
+	if result.Error != nil {
+		return ParseDBError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apierrors.ErrNotFound
+	}
+	return nil
+}
+
+// 獲取規則使用的升級策略，未設定時回傳 nil
+func (c *Client) GetEscalationPolicyByRuleID(ruleID []byte) (*models.EscalationPolicy, error) {
+	var policyID []byte
+	err := c.db.Model(&models.Rule{}).
+		Where("id = ?", ruleID).
+		Pluck("escalation_policy_id", &policyID).Error
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+	if len(policyID) == 0 {
+		return nil, nil
+	}
+	return c.GetEscalationPolicy(policyID)
+}
+
+// 獲取需要檢查升級的告警 (已發送或被流量限制、未確認、未恢復且規則設定了升級策略)
+func (c *Client) GetEscalatableTriggeredLogs() ([]models.TriggeredLog, error) {
+	var logs []models.TriggeredLog
+	err := c.db.Model(&models.TriggeredLog{}).
+		Joins("JOIN rules ON rules.id = triggered_logs.rule_id AND rules.escalation_policy_id IS NOT NULL").
+		Where("triggered_logs.notify_state IN ?", []string{"sent", "suppressed"}).
+		Where("triggered_logs.acked_at IS NULL AND triggered_logs.resolved_at IS NULL").
+		Find(&logs).Error
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+	return logs, nil
+}
+
+// 更新告警已通知的升級步驟
+func (c *Client) UpdateTriggeredLogEscalation(triggeredID []byte, step int, escalatedAt int64) error {
+	return c.db.
+		Model(&models.TriggeredLog{}).
+		Where("id = ?", triggeredID).
+		Updates(map[string]interface{}{
+			"escalation_step": step,
+			"escalated_at":    escalatedAt,
+		}).
+		Error
+}
+
+// 寫入升級步驟與步驟聯絡人
+func createEscalationSteps(tx *gorm.DB, policy *models.EscalationPolicy) error {
+	for i, step := range policy.Steps {
+		step.PolicyID = policy.ID
+		step.StepOrder = i
+		if err := tx.Create(&step).Error; err != nil {
+			return ParseDBError(err)
+		}
+		for _, contact := range step.Contacts {
+			stepContact := models.EscalationStepContact{
+				PolicyID:  policy.ID,
+				StepOrder: i,
+				ContactID: contact.ID,
+			}
+			if err := tx.Create(&stepContact).Error; err != nil {
+				return ParseDBError(err)
+			}
+		}
+	}
+	return nil
+}
+
+// 獲取升級步驟的聯絡人
+func (c *Client) getEscalationStepContacts(policyID []byte, stepOrder int) ([]models.Contact, error) {
+	var contacts []models.Contact
+	err := c.db.Model(&models.Contact{}).
+		Select("id, realm_name, name, channel_type, enabled, send_resolved, auto_apply, max_retry, retry_delay, config").
+		Joins("JOIN escalation_step_contacts ON escalation_step_contacts.contact_id = contacts.id").
+		Where("escalation_step_contacts.policy_id = ? AND escalation_step_contacts.step_order = ?", policyID, stepOrder).
+		Find(&contacts).Error
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+
+	for i := range contacts {
+		severities, err := c.GetContactSeverities(contacts[i].ID)
+		if err != nil {
+			contacts[i].Severities = []string{}
+			continue
+		}
+		contacts[i].Severities = severities
+	}
+	return contacts, nil
+}
@@ -2,9 +2,11 @@ package mysql
 
 import (
 	"database/sql/driver"
+	"encoding/base64"
 	"errors"
 	"strings"
 
+	"github.com/detect-viz/shared-lib/apierrors"
 	"github.com/google/uuid"
 )
 
@@ -14,6 +16,18 @@ func GenerateUUID16() []byte {
 	return id[:16] // 直接取 16-byte binary
 }
 
+// ParseTriggeredLogID 解析告警 ID，支援 UUID / hex 以及告警歷史回傳的 base64 格式
+func ParseTriggeredLogID(idStr string) ([]byte, error) {
+	if id, err := uuid.Parse(idStr); err == nil {
+		return id[:], nil
+	}
+	id, err := base64.StdEncoding.DecodeString(idStr)
+	if err != nil || len(id) != 16 {
+		return nil, apierrors.ErrInvalidID
+	}
+	return id, nil
+}
+
 // SeveritySet 讓 GORM 正確處理 MySQL SET
 type SeveritySet []string
 
@@ -0,0 +1,47 @@
+package mysql
+
+import (
+	"github.com/detect-viz/shared-lib/models"
+)
+
+// CreateInhibitRule 創建告警抑制規則
+func (c *Client) CreateInhibitRule(rule *models.InhibitRule) error {
+	rule.ID = GenerateUUID16()
+	if err := c.db.Create(rule).Error; err != nil {
+		return ParseDBError(err)
+	}
+	return nil
+}
+
+// GetInhibitRule 獲取告警抑制規則
+func (c *Client) GetInhibitRule(realm string, id []byte) (*models.InhibitRule, error) {
+	var rule models.InhibitRule
+	err := c.db.Where("realm_name = ? AND id = ?", realm, id).First(&rule).Error
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+	return &rule, nil
+}
+
+// ListInhibitRules 獲取域內的告警抑制規則
+func (c *Client) ListInhibitRules(realm string) ([]models.InhibitRule, error) {
+	var rules []models.InhibitRule
+	if err := c.db.Where("realm_name = ?", realm).Order("created_at").Find(&rules).Error; err != nil {
+		return nil, ParseDBError(err)
+	}
+	return rules, nil
+}
+
+// UpdateInhibitRule 更新告警抑制規則
+func (c *Client) UpdateInhibitRule(rule *models.InhibitRule) error {
+	err := c.db.Model(&models.InhibitRule{}).
+		Where("realm_name = ? AND id = ?", rule.RealmName, rule.ID).
+		Select("name", "source_matchers", "target_matchers", "equal").
+		Updates(rule).Error
+	return ParseDBError(err)
+}
+
+// DeleteInhibitRule 刪除告警抑制規則
+func (c *Client) DeleteInhibitRule(realm string, id []byte) error {
+	return ParseDBError(c.db.Where("realm_name = ? AND id = ?", realm, id).Delete(&models.InhibitRule{}).Error)
+}
@@ -47,17 +47,48 @@ type Database interface {
 	CreateTarget(target *models.Target) (*models.Target, error)
 
 	// TriggeredLog 相關
-	GetActiveTriggeredLog(ruleID []byte, resourceName, metricName string) (*models.TriggeredLog, error)
+	GetActiveTriggeredLog(ruleID []byte, resourceName string) (*models.TriggeredLog, error)
 	CreateTriggeredLog(triggered models.TriggeredLog) error
 	UpdateTriggeredLog(triggered models.TriggeredLog) error
 	UpdateTriggeredLogNotifyState(id []byte, state string) error
 	UpdateTriggeredLogResolvedNotifyState(id []byte, state string) error
+	ResolveTriggeredLogManually(id []byte, resolvedAt int64, resolvedBy string) error
+	GetUnresolvedTriggeredLogs(realm string) ([]models.TriggeredLog, error)
+	InhibitTriggeredLog(id, inhibitedBy []byte) error
+	ClearTriggeredLogInhibitedBy(id []byte) error
+	AckTriggeredLog(id []byte, ackedBy string, ackedAt int64) error
+	UnackTriggeredLog(id []byte, ackedState string) error
+	AssignTriggeredLog(id []byte, assignee, assignedBy string, assignedAt int64) error
 	GetTriggeredLogsForAlertNotify(timestamp int64) ([]models.TriggeredLog, error)
 	GetTriggeredLogsForResolvedNotify(timestamp int64) ([]models.TriggeredLog, error)
 
 	// NotifyLog 相關
 	CreateNotifyLog(notify models.NotifyLog) error
 	UpdateNotifyLog(notify models.NotifyLog) error
+	GetFailedNotifyLogs(now int64) ([]models.NotifyLog, error)
+	GetNotifyLog(id []byte) (*models.NotifyLog, error)
+	ListNotifyLogsByState(realm, state string, cursor int64, limit int) ([]models.NotifyLog, int64, error)
+
+	// NotifyActionLog 相關
+	ClaimNotifyActionLog(action *models.NotifyActionLog) (bool, error)
+	UpdateNotifyActionLogError(action *models.NotifyActionLog) error
+	ListNotifyActionLogs(triggeredLogID []byte) ([]models.NotifyActionLog, error)
+
+	// NotifyGroup 相關
+	GetNotifyGroup(contactID []byte, groupKey string) (*models.NotifyGroup, error)
+	CreateNotifyGroup(group *models.NotifyGroup) error
+	UpdateNotifyGroupNotifiedAt(id []byte, notifiedAt int64) error
+	DeleteIdleNotifyGroups(activeIDs [][]byte, waitingBefore int64) error
+	GetFiringTriggeredLogs() ([]models.TriggeredLog, error)
+
+	// NotifyDelivery 相關
+	CreateNotifyDeliveries(deliveries []models.NotifyDelivery) error
+	GetNotifyDeliveries(triggeredLogIDs [][]byte) ([]models.NotifyDelivery, error)
+
+	// NotifyRoute 相關
+	GetNotifyRoute(realm string) (*models.NotifyRoute, error)
+	SaveNotifyRoute(route *models.NotifyRoute) error
+	DeleteNotifyRoute(realm string) error
 
 	// CheckTriggeredLogExists 相關
 	CheckTriggeredLogExists(ruleID []byte, resourceName string, metricName string, firstTriggeredTime int64) (bool, error)
@@ -66,12 +97,48 @@ type Database interface {
 	// Template 相關
 	GetTemplate(realm string, ruleState string, format string) (models.Template, error)
 
+	// 升級策略相關
+	CreateEscalationPolicy(policy *models.EscalationPolicy) (*models.EscalationPolicy, error)
+	GetEscalationPolicy(id []byte) (*models.EscalationPolicy, error)
+	ListEscalationPolicies(realm string, cursor int64, limit int) ([]models.EscalationPolicy, int64, error)
+	UpdateEscalationPolicy(policy *models.EscalationPolicy) (*models.EscalationPolicy, error)
+	DeleteEscalationPolicy(id []byte) error
+	GetEscalationPolicyByRuleID(ruleID []byte) (*models.EscalationPolicy, error)
+	GetEscalatableTriggeredLogs() ([]models.TriggeredLog, error)
+	UpdateTriggeredLogEscalation(id []byte, step int, escalatedAt int64) error
+
+	// 值班表相關
+	CreateOnCallSchedule(schedule *models.OnCallSchedule) (*models.OnCallSchedule, error)
+	GetOnCallSchedule(id []byte, since int64) (*models.OnCallSchedule, error)
+	ListOnCallSchedules(realm string, cursor int64, limit int, since int64) ([]models.OnCallSchedule, int64, error)
+	UpdateOnCallSchedule(schedule *models.OnCallSchedule) (*models.OnCallSchedule, error)
+	DeleteOnCallSchedule(id []byte) error
+	CreateOnCallOverride(override *models.OnCallOverride) error
+	DeleteOnCallOverride(scheduleID, id []byte) error
+	ListOnCallOverridesAt(scheduleID []byte, at int64) ([]models.OnCallOverride, error)
+	GetOnCallSchedulesByRuleID(ruleID []byte, at int64) ([]models.OnCallSchedule, error)
+
 	// 抑制規則相關
 	CreateMute(mute *models.Mute) error
 	ListMutes(realm string) ([]models.Mute, error)
 	GetMute(id string) (*models.Mute, error)
 	UpdateMute(mute *models.Mute) error
 	DeleteMute(id string) error
+	ListActiveMutes(realm string) ([]models.Mute, error)
+
+	// 靜默相關
+	CreateSilence(silence *models.Silence) error
+	GetSilence(realm string, id []byte) (*models.Silence, error)
+	ListSilences(realm, state string, now int64, cursor string, limit int) ([]models.Silence, string, error)
+	ListActiveSilences(realm string, now int64) ([]models.Silence, error)
+	ExpireSilence(realm string, id []byte, now int64) error
+
+	// InhibitRule 相關
+	CreateInhibitRule(rule *models.InhibitRule) error
+	GetInhibitRule(realm string, id []byte) (*models.InhibitRule, error)
+	ListInhibitRules(realm string) ([]models.InhibitRule, error)
+	UpdateInhibitRule(rule *models.InhibitRule) error
+	DeleteInhibitRule(realm string, id []byte) error
 
 	// 標籤相關
 	CreateLabel(label *label.LabelKey, values []string) (*label.LabelKey, error)
@@ -82,6 +149,7 @@ type Database interface {
 	UpdateLabelKeyName(realm, oldKey, newKey string) (*label.LabelKey, error)
 	BulkCreateOrUpdateLabel(realm string, labels []models.LabelDTO) error
 	GetRuleLabelByRuleID(ruleID []byte) (map[string]string, error)
+	GetRuleLabelsByRuleIDs(ruleIDs [][]byte) (map[string]map[string]string, error)
 
 	// 資源群組相關
 	CreateResourceGroup(resourceGroup *models.ResourceGroup) error
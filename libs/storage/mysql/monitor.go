@@ -0,0 +1,156 @@
+package mysql
+
+import (
+	"strings"
+
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/models/alert"
+	"gorm.io/gorm"
+)
+
+// * 監控頁面排序方向，預設為新到舊
+func monitorOrder(order string) string {
+	if strings.ToLower(order) == "asc" {
+		return "ASC"
+	}
+	return "DESC"
+}
+
+// * 告警狀態的排序時間，從未觸發的規則視為 0
+const ruleStateSortColumn = "COALESCE(rule_states.last_triggered_at, 0)"
+
+// * 依排序方向套用 (排序時間, ID) cursor 條件，排序時間相同的資料不會被跳過
+func applyMonitorCursor(query *gorm.DB, timeColumn, idColumn, cursor, order string) (*gorm.DB, error) {
+	after, err := alert.ParseMonitorCursor(cursor)
+	if err != nil {
+		return nil, apierrors.NewAPIError(400, "無效的 cursor", err)
+	}
+	if after == nil {
+		return query, nil
+	}
+	op := "<"
+	if order == "ASC" {
+		op = ">"
+	}
+	return query.Where("("+timeColumn+" "+op+" ? OR ("+timeColumn+" = ? AND "+idColumn+" "+op+" ?))",
+		after.Time, after.Time, after.ID), nil
+}
+
+// 獲取告警狀態列表
+func (c *Client) ListRuleStates(q models.MonitorQuery) ([]models.RuleStateOverview, string, error) {
+	var states []models.RuleStateOverview
+	order := monitorOrder(q.Order)
+
+	query := c.db.Table("rule_states").
+		Select("rules.realm_name, targets.resource_name, targets.partition_name, rules.metric_rule_uid, rule_states.*").
+		Joins("JOIN rules ON rules.id = rule_states.rule_id AND rules.deleted_at IS NULL").
+		Joins("JOIN targets ON targets.id = rules.target_id").
+		Where("rules.realm_name = ?", q.RealmName).
+		Where("rule_states.deleted_at IS NULL")
+
+	if q.ResourceName != "" {
+		query = query.Where("targets.resource_name = ?", q.ResourceName)
+	}
+	if q.PartitionName != "" {
+		query = query.Where("targets.partition_name = ?", q.PartitionName)
+	}
+	if q.MetricRuleUID != "" {
+		query = query.Where("rules.metric_rule_uid = ?", q.MetricRuleUID)
+	}
+	if q.Severity != "" {
+		query = query.Where("rule_states.last_triggered_severity = ?", q.Severity)
+	}
+	if q.State != "" {
+		query = query.Where("rule_states.state = ?", q.State)
+	}
+	if q.StartTime > 0 {
+		query = query.Where("rule_states.last_triggered_at >= ?", q.StartTime)
+	}
+	if q.EndTime > 0 {
+		query = query.Where("rule_states.last_triggered_at <= ?", q.EndTime)
+	}
+
+	// 依最後觸發時間 (與時間範圍篩選相同) 分頁，updated_at 每次評估都會變動，翻頁期間資料會在頁面間移動
+	query, err := applyMonitorCursor(query, ruleStateSortColumn, "rule_states.rule_id", q.Cursor, order)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = query.Order(ruleStateSortColumn + " " + order).
+		Order("rule_states.rule_id " + order).
+		Limit(q.Limit).
+		Scan(&states).Error
+	if err != nil {
+		return nil, "", ParseDBError(err)
+	}
+
+	// 計算 next_cursor，沒有下一頁時為空字串
+	nextCursor := ""
+	if len(states) > 0 && len(states) >= q.Limit {
+		last := states[len(states)-1].RuleState
+		lastTriggeredAt := int64(0)
+		if last.LastTriggeredAt != nil {
+			lastTriggeredAt = *last.LastTriggeredAt
+		}
+		nextCursor = alert.MonitorCursor{Time: lastTriggeredAt, ID: last.RuleID}.String()
+	}
+
+	return states, nextCursor, nil
+}
+
+// 獲取告警歷史列表
+func (c *Client) ListTriggeredLogs(q models.MonitorQuery) ([]models.TriggeredLog, string, error) {
+	var logs []models.TriggeredLog
+	order := monitorOrder(q.Order)
+
+	query := c.db.Model(&models.TriggeredLog{}).
+		Where("realm_name = ?", q.RealmName)
+
+	if q.ResourceName != "" {
+		query = query.Where("resource_name = ?", q.ResourceName)
+	}
+	if q.PartitionName != "" {
+		query = query.Where("partition_name = ?", q.PartitionName)
+	}
+	if q.MetricRuleUID != "" {
+		query = query.Where("metric_rule_uid = ?", q.MetricRuleUID)
+	}
+	if q.Severity != "" {
+		query = query.Where("severity = ?", q.Severity)
+	}
+	switch q.State {
+	case "alerting":
+		query = query.Where("resolved_at IS NULL")
+	case "resolved":
+		query = query.Where("resolved_at IS NOT NULL")
+	}
+	if q.StartTime > 0 {
+		query = query.Where("triggered_at >= ?", q.StartTime)
+	}
+	if q.EndTime > 0 {
+		query = query.Where("triggered_at <= ?", q.EndTime)
+	}
+
+	query, err := applyMonitorCursor(query, "triggered_at", "id", q.Cursor, order)
+	if err != nil {
+		return nil, "", err
+	}
+
+	err = query.Order("triggered_at " + order).
+		Order("id " + order).
+		Limit(q.Limit).
+		Find(&logs).Error
+	if err != nil {
+		return nil, "", ParseDBError(err)
+	}
+
+	// 計算 next_cursor，沒有下一頁時為空字串
+	nextCursor := ""
+	if len(logs) > 0 && len(logs) >= q.Limit {
+		last := logs[len(logs)-1]
+		nextCursor = alert.MonitorCursor{Time: last.TriggeredAt, ID: last.ID}.String()
+	}
+
+	return logs, nextCursor, nil
+}
@@ -60,6 +60,17 @@ func (c *Client) GetMutesByResourceGroup(resourceGroupID int64) ([]models.Mute,
 	return mutes, nil
 }
 
+// ListActiveMutes 獲取域內未刪除的抑制規則 (含資源群組內的資源)
+func (c *Client) ListActiveMutes(realm string) ([]models.Mute, error) {
+	var mutes []models.Mute
+	if err := c.db.Preload("ResourceGroups.Resources").
+		Where("realm_name = ? AND (deleted_at IS NULL OR deleted_at = 0)", realm).
+		Find(&mutes).Error; err != nil {
+		return nil, fmt.Errorf("獲取抑制規則列表失敗: %w", err)
+	}
+	return mutes, nil
+}
+
 // CheckMuteName 檢查名稱是否重複
 func (c *Client) CheckMuteName(mute models.Mute) (bool, string) {
 	var count int64
@@ -0,0 +1,161 @@
+package mysql
+
+import (
+	"github.com/detect-viz/shared-lib/apierrors"
+	"github.com/detect-viz/shared-lib/models"
+	"gorm.io/gorm"
+)
+
+// 創建值班表
+func (c *Client) CreateOnCallSchedule(schedule *models.OnCallSchedule) (*models.OnCallSchedule, error) {
+	schedule.ID = GenerateUUID16()
+	exists, err := c.Exists(schedule.RealmName, "oncall_schedules", "name", schedule.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, apierrors.ErrDuplicateEntry
+	}
+
+	if err := c.db.Omit("Overrides").Create(schedule).Error; err != nil {
+		return nil, ParseDBError(err)
+	}
+	return c.GetOnCallSchedule(schedule.ID, 0)
+}
+
+// 獲取值班表，只載入 ends_at 晚於 since 的覆蓋
+func (c *Client) GetOnCallSchedule(id []byte, since int64) (*models.OnCallSchedule, error) {
+	var schedule models.OnCallSchedule
+	err := c.db.Preload("Overrides", func(db *gorm.DB) *gorm.DB {
+		return db.Where("ends_at > ?", since).Order("starts_at ASC")
+	}).First(&schedule, "id = ?", id).Error
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+	return &schedule, nil
+}
+
+// 獲取值班表列表
+func (c *Client) ListOnCallSchedules(realm string, cursor int64, limit int, since int64) ([]models.OnCallSchedule, int64, error) {
+	var schedules []models.OnCallSchedule
+
+	query := c.db.Model(&models.OnCallSchedule{}).
+		Where("realm_name = ?", realm)
+	if cursor > 0 {
+		query = query.Where("created_at > ?", cursor)
+	}
+
+	err := query.Order("created_at ASC").
+		Limit(limit).
+		Preload("Overrides", func(db *gorm.DB) *gorm.DB {
+			return db.Where("ends_at > ?", since).Order("starts_at ASC")
+		}).
+		Find(&schedules).Error
+	if err != nil {
+		return nil, 0, ParseDBError(err)
+	}
+
+	// 計算 next_cursor
+	nextCursor := int64(-1)
+	if len(schedules) > 0 && len(schedules) >= limit {
+		nextCursor = schedules[len(schedules)-1].CreatedAt
+	}
+	return schedules, nextCursor, nil
+}
+
+// 更新值班表 (不含覆蓋)
+func (c *Client) UpdateOnCallSchedule(schedule *models.OnCallSchedule) (*models.OnCallSchedule, error) {
+	var count int64
+	err := c.db.Model(&models.OnCallSchedule{}).
+		Where("realm_name = ? AND name = ? AND id != ?", schedule.RealmName, schedule.Name, schedule.ID).
+		Count(&count).Error
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+	if count > 0 {
+		return nil, apierrors.ErrDuplicateEntry
+	}
+
+	result := c.db.Model(&models.OnCallSchedule{}).
+		Where("id = ? AND realm_name = ?", schedule.ID, schedule.RealmName).
+		Select("name", "description", "timezone", "rotations", "updated_by").
+		Updates(schedule)
+	if result.Error != nil {
+		return nil, ParseDBError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, apierrors.ErrNotFound
+	}
+	return c.GetOnCallSchedule(schedule.ID, 0)
+}
+
+// 刪除值班表，仍被規則使用時拒絕刪除
+func (c *Client) DeleteOnCallSchedule(id []byte) error {
+	var count int64
+	if err := c.db.Model(&models.RuleOnCallSchedule{}).Where("schedule_id = ?", id).Count(&count).Error; err != nil {
+		return ParseDBError(err)
+	}
+	if count > 0 {
+		return apierrors.ErrUsedByRules
+	}
+
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("schedule_id = ?", id).Delete(&models.OnCallOverride{}).Error; err != nil {
+			return ParseDBError(err)
+		}
+		result := tx.Delete(&models.OnCallSchedule{}, "id = ?", id)
+		if result.Error != nil {
+			return ParseDBError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apierrors.ErrNotFound
+		}
+		return nil
+	})
+}
+
+// 創建值班覆蓋
+func (c *Client) CreateOnCallOverride(override *models.OnCallOverride) error {
+	override.ID = GenerateUUID16()
+	return ParseDBError(c.db.Create(override).Error)
+}
+
+// 刪除值班覆蓋
+func (c *Client) DeleteOnCallOverride(scheduleID, id []byte) error {
+	result := c.db.Where("schedule_id = ? AND id = ?", scheduleID, id).Delete(&models.OnCallOverride{})
+	if result.Error != nil {
+		return ParseDBError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apierrors.ErrNotFound
+	}
+	return nil
+}
+
+// 獲取指定時間生效中的值班覆蓋
+func (c *Client) ListOnCallOverridesAt(scheduleID []byte, at int64) ([]models.OnCallOverride, error) {
+	var overrides []models.OnCallOverride
+	err := c.db.Where("schedule_id = ? AND starts_at <= ? AND ends_at > ?", scheduleID, at, at).
+		Order("created_at ASC").
+		Find(&overrides).Error
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+	return overrides, nil
+}
+
+// 獲取規則綁定的值班表 (含指定時間生效中的覆蓋)
+func (c *Client) GetOnCallSchedulesByRuleID(ruleID []byte, at int64) ([]models.OnCallSchedule, error) {
+	var schedules []models.OnCallSchedule
+	err := c.db.Model(&models.OnCallSchedule{}).
+		Joins("JOIN rule_oncall_schedules ON rule_oncall_schedules.schedule_id = oncall_schedules.id").
+		Where("rule_oncall_schedules.rule_id = ?", ruleID).
+		Preload("Overrides", func(db *gorm.DB) *gorm.DB {
+			return db.Where("starts_at <= ? AND ends_at > ?", at, at)
+		}).
+		Find(&schedules).Error
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+	return schedules, nil
+}
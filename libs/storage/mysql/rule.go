@@ -1,6 +1,8 @@
 package mysql
 
 import (
+	"fmt"
+
 	"github.com/detect-viz/shared-lib/apierrors"
 	"github.com/detect-viz/shared-lib/models"
 
@@ -10,6 +12,9 @@ import (
 
 // * 創建規則 + Alert State
 func (c *Client) CreateRule(rule *models.Rule) (*models.Rule, error) {
+	if err := normalizeEvaluationMode(rule); err != nil {
+		return nil, err
+	}
 	rule.ID = GenerateUUID16()
 	err := c.db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(rule).Error; err != nil {
@@ -89,6 +94,10 @@ func (c *Client) ListRules(realm string, cursor int64, limit int) ([]models.Rule
 
 // 更新規則
 func (c *Client) UpdateRule(rule *models.Rule) (*models.Rule, error) {
+	if err := normalizeEvaluationMode(rule); err != nil {
+		return nil, err
+	}
+
 	err := c.db.Transaction(func(tx *gorm.DB) error {
 		// 1. 檢查規則是否存在
 		var exists bool
@@ -105,20 +114,29 @@ func (c *Client) UpdateRule(rule *models.Rule) (*models.Rule, error) {
 		}
 
 		// 2. 更新 Rule 本身（排除關聯）
-		if err := tx.Omit("Contacts").Model(&models.Rule{}).
+		if err := tx.Omit("Contacts", "OnCallSchedules").Model(&models.Rule{}).
 			Where("id = ?", rule.ID).
 			Updates(map[string]interface{}{
-				"target_id":       rule.TargetID,
-				"metric_rule_uid": rule.MetricRuleUID,
-				"create_type":     rule.CreateType,
-				"auto_apply":      rule.AutoApply,
-				"enabled":         rule.Enabled,
-				"info_threshold":  rule.InfoThreshold,
-				"warn_threshold":  rule.WarnThreshold,
-				"crit_threshold":  rule.CritThreshold,
-				"times":           rule.Times,
-				"duration":        rule.Duration,
-				"silence_period":  rule.SilencePeriod,
+				"target_id":                rule.TargetID,
+				"metric_rule_uid":          rule.MetricRuleUID,
+				"create_type":              rule.CreateType,
+				"auto_apply":               rule.AutoApply,
+				"enabled":                  rule.Enabled,
+				"info_threshold":           rule.InfoThreshold,
+				"warn_threshold":           rule.WarnThreshold,
+				"crit_threshold":           rule.CritThreshold,
+				"info_lower_threshold":     rule.InfoLowerThreshold,
+				"warn_lower_threshold":     rule.WarnLowerThreshold,
+				"crit_lower_threshold":     rule.CritLowerThreshold,
+				"recovery_threshold":       rule.RecoveryThreshold,
+				"recovery_lower_threshold": rule.RecoveryLowerThreshold,
+				"evaluation_mode":          rule.EvaluationMode,
+				"evaluation_points":        rule.EvaluationPoints,
+				"times":                    rule.Times,
+				"notify_times":             rule.NotifyTimes,
+				"duration":                 rule.Duration,
+				"silence_period":           rule.SilencePeriod,
+				"escalation_policy_id":     rule.EscalationPolicyID,
 			}).Error; err != nil {
 			return ParseDBError(err)
 		}
@@ -128,6 +146,11 @@ func (c *Client) UpdateRule(rule *models.Rule) (*models.Rule, error) {
 			return ParseDBError(err)
 		}
 
+		// 4. 更新值班表關聯
+		if err := tx.Model(rule).Association("OnCallSchedules").Replace(rule.OnCallSchedules); err != nil {
+			return ParseDBError(err)
+		}
+
 		return nil
 	})
 
@@ -139,6 +162,18 @@ func (c *Client) UpdateRule(rule *models.Rule) (*models.Rule, error) {
 	return c.GetRule(rule.ID)
 }
 
+// 判斷模式需符合 rules.evaluation_mode 的 enum，未設定時為 all
+func normalizeEvaluationMode(rule *models.Rule) error {
+	switch rule.EvaluationMode {
+	case "":
+		rule.EvaluationMode = "all"
+	case "all", "n_of_m", "consecutive":
+	default:
+		return apierrors.NewAPIError(400, fmt.Sprintf("不支援的 evaluation_mode: %s", rule.EvaluationMode), nil)
+	}
+	return nil
+}
+
 // 刪除規則
 func (c *Client) DeleteRule(id []byte) error {
 	result := c.db.Delete(&models.Rule{}, "id = ?", id)
@@ -0,0 +1,91 @@
+package mysql
+
+import (
+	"github.com/detect-viz/shared-lib/models"
+	"github.com/detect-viz/shared-lib/models/alert"
+	"github.com/detect-viz/shared-lib/models/mute"
+	"gorm.io/gorm"
+)
+
+// CreateSilence 創建靜默
+func (c *Client) CreateSilence(silence *models.Silence) error {
+	silence.ID = GenerateUUID16()
+	if err := c.db.Create(silence).Error; err != nil {
+		return ParseDBError(err)
+	}
+	return nil
+}
+
+// GetSilence 獲取靜默
+func (c *Client) GetSilence(realm string, id []byte) (*models.Silence, error) {
+	var silence models.Silence
+	err := c.db.Where("realm_name = ? AND id = ?", realm, id).First(&silence).Error
+	if err != nil {
+		return nil, ParseDBError(err)
+	}
+	return &silence, nil
+}
+
+// ListSilences 獲取靜默列表，state 為空時回傳全部
+// 依 (ends_at, id) 遞減排序並以 cursor 分頁，沒有下一頁時 next_cursor 為空字串
+func (c *Client) ListSilences(realm, state string, now int64, cursor string, limit int) ([]models.Silence, string, error) {
+	var silences []models.Silence
+	query, err := applyMonitorCursor(c.silenceQuery(realm, state, now), "ends_at", "id", cursor, "DESC")
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := query.Order("ends_at DESC").Order("id DESC").Limit(limit).Find(&silences).Error; err != nil {
+		return nil, "", ParseDBError(err)
+	}
+
+	nextCursor := ""
+	if len(silences) > 0 && len(silences) >= limit {
+		last := silences[len(silences)-1]
+		nextCursor = alert.MonitorCursor{Time: last.EndsAt, ID: last.ID}.String()
+	}
+	return silences, nextCursor, nil
+}
+
+// ListActiveSilences 獲取目前生效中的靜默 (不分頁)
+func (c *Client) ListActiveSilences(realm string, now int64) ([]models.Silence, error) {
+	var silences []models.Silence
+	if err := c.silenceQuery(realm, mute.SilenceStateActive, now).Order("ends_at DESC").Find(&silences).Error; err != nil {
+		return nil, ParseDBError(err)
+	}
+	return silences, nil
+}
+
+// 依 realm 與靜默狀態組合查詢條件
+func (c *Client) silenceQuery(realm, state string, now int64) *gorm.DB {
+	query := c.db.Model(&models.Silence{}).Where("realm_name = ?", realm)
+	switch state {
+	case mute.SilenceStatePending:
+		query = query.Where("starts_at > ?", now)
+	case mute.SilenceStateActive:
+		query = query.Where("starts_at <= ? AND ends_at > ?", now, now)
+	case mute.SilenceStateExpired:
+		query = query.Where("ends_at <= ?", now)
+	}
+	return query
+}
+
+// ExpireSilence 讓靜默立即失效 (保留紀錄)
+func (c *Client) ExpireSilence(realm string, id []byte, now int64) error {
+	silence, err := c.GetSilence(realm, id)
+	if err != nil {
+		return err
+	}
+	if silence.EndsAt <= now {
+		return nil
+	}
+
+	updates := map[string]interface{}{"ends_at": now}
+	if silence.StartsAt > now {
+		updates["starts_at"] = now
+	}
+	if err := c.db.Model(&models.Silence{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return ParseDBError(err)
+	}
+	return nil
+}
@@ -38,6 +38,9 @@ func (s *serviceImpl) RenderMessage(t models.Template, data map[string]interface
 		messageTemplate = t.Message
 	case "text":
 		messageTemplate = t.Message
+	case "raw":
+		// 原樣輸出，不整理行首空白與空行 (例如 webhook 自訂內容)
+		messageTemplate = t.Message
 	case "json":
 		// JSON 需要額外解析成標準格式
 		return renderJSONTemplate(t.Message, data)
@@ -73,6 +76,9 @@ func (s *serviceImpl) RenderMessage(t models.Template, data map[string]interface
 			}
 			return result
 		},
+		// 添加 json/toJSON 函數，將值輸出為 JSON 字面值 (含引號與跳脫)
+		"json":   toJSON,
+		"toJSON": toJSON,
 		// 添加 if_eq 函數，用於比較兩個值是否相等
 		"if_eq": func(a, b interface{}) bool {
 			return a == b
@@ -122,7 +128,7 @@ func (s *serviceImpl) RenderMessage(t models.Template, data map[string]interface
 
 	// 處理消息格式
 	message := messageBuf.String()
-	if t.FormatType != "json" { // JSON 格式不需要處理每一行
+	if t.FormatType != "json" && t.FormatType != "raw" { // JSON 與原樣格式不需要處理每一行
 		// 1. 分割成行
 		lines := strings.Split(message, "\n")
 
@@ -215,6 +221,9 @@ func renderJSONTemplate(jsonTemplate string, data map[string]interface{}) (strin
 			}
 			return result
 		},
+		// 添加 json/toJSON 函數，將值輸出為 JSON 字面值 (含引號與跳脫)
+		"json":   toJSON,
+		"toJSON": toJSON,
 		// 添加 if_eq 函數，用於比較兩個值是否相等
 		"if_eq": func(a, b interface{}) bool {
 			return a == b
@@ -262,17 +271,19 @@ func renderJSONTemplate(jsonTemplate string, data map[string]interface{}) (strin
 		return "", fmt.Errorf("渲染 JSON 內容失敗: %w", err)
 	}
 
-	// 解析渲染後的 JSON 來確保格式正確
-	var formattedJSON map[string]interface{}
-	if err := json.Unmarshal(jsonBuf.Bytes(), &formattedJSON); err != nil {
-		return "", fmt.Errorf("渲染後的 JSON 格式錯誤: %w", err)
+	// 驗證渲染後的 JSON 格式正確，原樣輸出以保留根節點型別與欄位順序
+	if !json.Valid(jsonBuf.Bytes()) {
+		return "", fmt.Errorf("渲染後的 JSON 格式錯誤: %s", jsonBuf.String())
 	}
 
-	// 美化輸出的 JSON
-	formattedOutput, err := json.MarshalIndent(formattedJSON, "", "  ")
+	return jsonBuf.String(), nil
+}
+
+// toJSON 將值編碼為 JSON，供模板在字串中安全嵌入多行或含引號的內容
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
 	if err != nil {
-		return "", fmt.Errorf("格式化 JSON 失敗: %w", err)
+		return "", err
 	}
-
-	return string(formattedOutput), nil
+	return string(b), nil
 }
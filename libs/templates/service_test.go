@@ -0,0 +1,58 @@
+package templates
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/detect-viz/shared-lib/models"
+)
+
+// webhook body_template：多行且含引號的訊息需透過 json 函數輸出，結果原樣送出
+func TestRenderJSONBodyTemplate(t *testing.T) {
+	s := &serviceImpl{}
+	message := "CPU 使用率過高\n  host: \"web-01\"\n  value: 95%"
+
+	cases := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			name:     "物件保留欄位順序",
+			template: `{"title": {{json .title}}, "text": {{toJSON .message}}, "severity": {{json .severity}}}`,
+			want:     `{"title": "[Critical] cpu", "text": "CPU 使用率過高\n  host: \"web-01\"\n  value: 95%", "severity": "crit"}`,
+		},
+		{
+			name:     "陣列根節點",
+			template: `[{"text": {{json .message}}}]`,
+			want:     `[{"text": "CPU 使用率過高\n  host: \"web-01\"\n  value: 95%"}]`,
+		},
+		{
+			name:     "純量根節點",
+			template: `{{json .message}}`,
+			want:     `"CPU 使用率過高\n  host: \"web-01\"\n  value: 95%"`,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := map[string]interface{}{"title": "[Critical] cpu", "message": message}
+			got, err := s.RenderMessage(models.Template{FormatType: "json", Message: tc.template}, data)
+			if err != nil {
+				t.Fatalf("RenderMessage: %v", err)
+			}
+			if got != tc.want {
+				t.Fatalf("got %s, want %s", got, tc.want)
+			}
+			var decoded interface{}
+			if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+		})
+	}
+
+	// 未經 json 函數直接嵌入多行訊息會產生不合法的 JSON
+	data := map[string]interface{}{"message": message}
+	if _, err := s.RenderMessage(models.Template{FormatType: "json", Message: `{"text": "{{.message}}"}`}, data); err == nil {
+		t.Fatal("expected error for invalid JSON body")
+	}
+}